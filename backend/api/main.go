@@ -1,16 +1,27 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"pittsix/internal/articles"
 	"pittsix/internal/auth"
 	"pittsix/internal/bootstrap"
 	"pittsix/internal/db"
+	"pittsix/internal/media"
 	"pittsix/internal/organizations"
 	"pittsix/internal/upload"
 	"pittsix/internal/users"
+	"pittsix/internal/webhooks"
+	"pittsix/pkg/cache"
+	"pittsix/pkg/config"
+	"pittsix/pkg/events"
 	"pittsix/pkg/middleware"
 
 	"github.com/minio/minio-go/v7"
@@ -19,20 +30,92 @@ import (
 )
 
 func main() {
+	cfg := config.LoadConfig()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	mux := http.NewServeMux()
 	mongoClient := db.ConnectMongo()
 
 	userCollection := mongoClient.Database("pittsix_users").Collection("users")
 	usersRepo := users.NewMongoRepository(userCollection)
-	articleCollection := mongoClient.Database("pittsix_articles").Collection("articles")
-	articles.Init(articleCollection, usersRepo)
 	orgCollection := mongoClient.Database("pittsix_orgs").Collection("organizations")
 	orgRepo := organizations.NewMongoRepository(orgCollection)
 	bootstrap.InitUsersAndOrgs(usersRepo, orgRepo)
 
+	// 📣 Bus de eventos: lo alimentan los handlers y lo consume GET /events
+	bus := events.NewBus(cfg.Events.BufferSize)
+
+	articleCollection := mongoClient.Database("pittsix_articles").Collection("articles")
+	articlesRepo := articles.NewMongoRepository(articleCollection)
+	// ⚡ Caché de lecturas públicas delante del repositorio
+	var articlesStore articles.Repository = articlesRepo
+	var readCache *articles.CachedRepository
+	if !cfg.ReadCache.Disabled {
+		var store cache.Store = cache.NewLRU(cfg.ReadCache.Size)
+		if cfg.ReadCache.RedisAddr != "" {
+			redis := cache.NewRedis(cfg.ReadCache.RedisAddr, cfg.ReadCache.RedisPassword, cfg.ReadCache.RedisDB)
+			redis.Prefix = "pittsix:"
+			store = redis
+		} else {
+			log.Println("⚠️ REDIS_ADDR no configurado: el caché de lecturas es de esta réplica; con varias réplicas, las demás ven los cambios recién al vencer READ_CACHE_TTL")
+		}
+		readCache = articles.NewCachedRepository(articlesRepo, store, cfg.ReadCache.TTL)
+		articlesStore = readCache
+	}
+	articleHandlers := articles.NewHandlers(articlesStore, articles.NewMongoTaxonomyRepository(articleCollection.Database()), usersRepo, orgRepo, cfg.Tenancy.DefaultOrgSlug)
+	switch {
+	case cfg.Security.PreviewSecret != "":
+		articleHandlers.PreviewSecret = []byte(cfg.Security.PreviewSecret)
+	case cfg.Env == "production":
+		// Con una clave por proceso los enlaces no sirven en otra réplica ni tras reiniciar
+		log.Fatal("❌ PREVIEW_SECRET es obligatorio en producción")
+	default:
+		log.Println("⚠️ PREVIEW_SECRET no configurado: los enlaces de vista previa vencen al reiniciar")
+	}
+	// 🔍 Búsqueda con el índice de texto de Mongo, el mismo para todas las réplicas
+	articleHandlers.Search = articles.NewMongoIndex(articleCollection)
+	articleHandlers.Events = bus
+	articleHandlers.SiteURL = cfg.Site.BaseURL
+	articleHandlers.ArticlePath = cfg.Site.ArticlePath
+	articleHandlers.RobotsRules = cfg.Site.Robots
+	for route, value := range cfg.HTTPCache.CacheControl {
+		articleHandlers.CacheControl[route] = value
+	}
+	// 🌐 Purga de la CDN cuando cambia el contenido
+	purger := &articles.Purger{}
+	if cfg.HTTPCache.PurgeURL != "" {
+		purger.Hooks = append(purger.Hooks, articles.HTTPPurgeHook(cfg.HTTPCache.PurgeURL, cfg.HTTPCache.PurgeToken, nil))
+	}
+	articleHandlers.Purge = purger
+	// 🧰 Migraciones de datos: cada una corre una sola vez, en una sola réplica
+	migrations := db.NewMigrations(articleCollection.Database().Collection("migrations"))
+	if err := migrations.Run(ctx, "articles-organizations", articleHandlers.BackfillOrganizations); err != nil {
+		log.Printf("❌ Error asignando organización a artículos existentes: %v", err)
+	}
+	// El índice único va en la misma migración: si no se puede crear, se reintenta
+	err := migrations.Run(ctx, "articles-slugs", func(ctx context.Context) error {
+		if err := articleHandlers.BackfillSlugs(ctx); err != nil {
+			return err
+		}
+		return articlesRepo.EnsureSlugIndex(ctx)
+	})
+	if err != nil {
+		log.Printf("❌ Error desambiguando slugs de artículos: %v", err)
+	}
+	if err := migrations.Run(ctx, "articles-authors", articleHandlers.BackfillAuthors); err != nil {
+		log.Printf("❌ Error actualizando la firma de los artículos: %v", err)
+	}
+
 	authHandlers := auth.NewAuthHandlers(usersRepo)
 	userHandlers := users.NewHandlers(usersRepo)
+	userHandlers.OnDelete = append(userHandlers.OnDelete, articleHandlers.ReassignAuthor)
+	userHandlers.OnProfileChange = append(userHandlers.OnProfileChange, articleHandlers.SyncAuthor)
+	authHandlers.OnProfileChange = append(authHandlers.OnProfileChange, articleHandlers.SyncAuthor)
+	userHandlers.Events = bus
+	authHandlers.Events = bus
 	orgHandlers := organizations.NewHandlers(orgRepo, usersRepo)
+	orgHandlers.Events = bus
 
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("OK"))
@@ -44,7 +127,7 @@ func main() {
 	mux.HandleFunc("/auth/reset-password", authHandlers.ResetPassword)
 
 	// Artículos
-	articles.RegisterHandlers(mux)
+	articles.RegisterHandlers(mux, articleHandlers)
 
 	// Crear cliente real de Minio
 	minioClient, err := minio.New("minio:9000", &minio.Options{
@@ -55,7 +138,39 @@ func main() {
 		log.Fatalf("❌ Error conectando a MinIO: %v", err)
 	}
 	uploadHandler := upload.NewHandler(minioClient)
+	renditions, err := upload.ParseRenditions(cfg.Images.Renditions, cfg.Images.WebP)
+	if err != nil {
+		log.Fatalf("❌ IMAGE_RENDITIONS inválido: %v", err)
+	}
+	uploadHandler.Renditions = renditions
+	uploadHandler.Quality = cfg.Images.Quality
+	uploadHandler.MaxBytes = int64(cfg.Images.MaxBytes)
+	uploadHandler.MaxPixels = cfg.Images.MaxPixels
+
+	// 🗂️ Biblioteca de medios: registra cada subida y no deja borrar lo que usan los artículos
+	mediaHandlers := media.NewHandlers(media.NewMongoRepository(mongoClient.Database("pittsix_media")), minioClient)
+	mediaHandlers.References = append(mediaHandlers.References, articleHandlers.MediaReferences)
+	uploadHandler.OnUpload = append(uploadHandler.OnUpload, mediaHandlers.RecordUpload)
 	upload.RegisterHandlers(mux, uploadHandler)
+	media.RegisterHandlers(mux, mediaHandlers)
+
+	// 🪝 Webhooks salientes: el dispatcher encola lo que pasa por el bus
+	webhooksRepo := webhooks.NewMongoRepository(mongoClient.Database("pittsix_webhooks"))
+	dispatcher := webhooks.NewDispatcher(webhooksRepo)
+	dispatcher.Interval = cfg.Webhooks.Interval
+	dispatcher.MaxAttempts = cfg.Webhooks.MaxAttempts
+	dispatcher.DisableAfter = cfg.Webhooks.DisableAfter
+	webhooks.RegisterHandlers(mux, webhooks.NewHandlers(webhooksRepo, dispatcher))
+
+	if readCache != nil {
+		mux.Handle("GET /cache/stats", middleware.JWTAuth(middleware.RequireRole("superadmin")(articles.CacheStatsHandler(readCache))))
+	}
+
+	// Stream de eventos. EventSource no manda headers: pide un ticket de vida
+	// corta y conecta con ?ticket=, sin poner el token de acceso en la URL
+	tickets := middleware.NewStreamTickets(cfg.Events.TicketTTL)
+	mux.Handle("POST /events/ticket", middleware.JWTAuth(http.HandlerFunc(tickets.Issue)))
+	mux.Handle("GET /events", tickets.Auth(events.NewStream(bus)))
 
 	mux.Handle("/profile", middleware.JWTAuth(http.HandlerFunc(authHandlers.Profile)))
 	mux.Handle("/users", middleware.JWTAuth(middleware.RequireOrgAdminOrSuperadmin()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -128,9 +243,51 @@ func main() {
 		},
 		AllowCredentials: true,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", "If-Match", "If-None-Match", "If-Modified-Since", "Last-Event-ID"},
+		ExposedHeaders:   []string{"ETag", "Surrogate-Key"},
 	}).Handler(mux)
 
-	log.Println("Server running on :8080")
-	log.Fatal(http.ListenAndServe(":8080", mainHandler))
+	// Tareas en segundo plano
+	var wg sync.WaitGroup
+	scheduler := articles.NewScheduler(articlesStore, articleHandlers.Search, cfg.Scheduler.Interval)
+	scheduler.Events = bus
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		scheduler.Run(ctx)
+	}()
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		purger.Listen(ctx, bus)
+	}()
+	go func() {
+		defer wg.Done()
+		dispatcher.Listen(ctx, bus)
+	}()
+	go func() {
+		defer wg.Done()
+		dispatcher.Run(ctx)
+	}()
+
+	srv := &http.Server{Addr: cfg.Server.Port, Handler: mainHandler}
+	srv.RegisterOnShutdown(bus.Close)
+	go func() {
+		log.Println("Server running on " + cfg.Server.Port)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("❌ Error en el servidor HTTP: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("🛑 Apagando servidor...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("❌ Error al apagar el servidor: %v", err)
+	}
+	wg.Wait()
+	if err := mongoClient.Disconnect(shutdownCtx); err != nil {
+		log.Printf("❌ Error al desconectar MongoDB: %v", err)
+	}
 }
@@ -0,0 +1,152 @@
+package articles
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"pittsix/internal/users"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ✍️ Los artículos guardan una copia de los datos públicos del autor (firma,
+// avatar y bio) para listarse sin consultar usuarios. SyncAuthor se engancha
+// en los cambios de perfil y la mantiene al día.
+
+// Author son los datos públicos de quien firma un artículo
+type Author struct {
+	ID     primitive.ObjectID `json:"id"`
+	Name   string             `json:"name"`
+	Avatar string             `json:"avatar,omitempty"`
+	Bio    string             `json:"bio,omitempty"`
+}
+
+// AuthorPage es la página pública de un autor con sus artículos publicados
+type AuthorPage struct {
+	Author
+	Articles *ArticlePage `json:"articles"`
+}
+
+func authorOf(u *users.User) Author {
+	return Author{
+		ID:     u.ID,
+		Name:   strings.TrimSpace(u.FirstName + " " + u.LastName),
+		Avatar: u.ProfileImage,
+		Bio:    u.Bio,
+	}
+}
+
+// byline devuelve la copia del autor guardada en el artículo
+func byline(a *Article) Author {
+	return Author{ID: a.AuthorID, Name: a.AuthorName, Avatar: a.AuthorAvatar, Bio: a.AuthorBio}
+}
+
+func setByline(a *Article, author Author) {
+	a.AuthorName = author.Name
+	a.AuthorAvatar = author.Avatar
+	a.AuthorBio = author.Bio
+}
+
+// SyncAuthor propaga el perfil del usuario a sus artículos (hook de users)
+func (h *Handlers) SyncAuthor(ctx context.Context, user *users.User) error {
+	n, err := h.Repo.UpdateAuthor(ctx, authorOf(user))
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		log.Printf("✍️ Firma actualizada en %d artículos de %s", n, user.ID.Hex())
+		h.Purge.Purge(ctx, authorKey(user.ID))
+		// El índice de búsqueda también filtra y muestra por autor
+		found, err := h.Repo.FindArticles(ctx, Filter{AuthorID: user.ID, System: true})
+		if err != nil {
+			return err
+		}
+		for i := range found {
+			h.reindex(ctx, found[i].ID)
+		}
+	}
+	return nil
+}
+
+// BackfillAuthors completa la firma de los artículos creados antes de copiar
+// avatar y bio, y corrige los nombres que quedaron viejos
+func (h *Handlers) BackfillAuthors(ctx context.Context) error {
+	all, err := h.Repo.FindArticles(ctx, Filter{System: true})
+	if err != nil {
+		return err
+	}
+	seen := map[primitive.ObjectID]bool{}
+	for i := range all {
+		id := all[i].AuthorID
+		if id.IsZero() || seen[id] {
+			continue
+		}
+		seen[id] = true
+		user, err := h.Users.GetUserByID(id)
+		if err != nil || user == nil {
+			// Autor borrado: el artículo conserva la última firma conocida
+			continue
+		}
+		if err := h.SyncAuthor(ctx, user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 👤 Página pública de un autor: sus datos y sus artículos publicados (paginado)
+func (h *Handlers) GetAuthor(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenant, err := h.publicTenant(r)
+	if err != nil {
+		http.Error(w, err.Error(), tenantErrorStatus(err))
+		return
+	}
+	id, err := primitive.ObjectIDFromHex(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	q, err := ParseListQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	present, err := h.publicPresenter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	q.Status = nil
+	q.AuthorID = &id
+	now := time.Now()
+	page, err := listArticles(ctx, h.Repo, tenant.scope(Filter{VisibleAt: &now}), q, present)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+
+	var author Author
+	if user, err := h.Users.GetUserByID(id); err == nil && user != nil && tenant.OrgID == user.OrganizationID {
+		author = authorOf(user)
+	} else if len(page.Items) > 0 {
+		// Autor borrado: queda la firma de sus artículos
+		author = byline(&page.Items[0])
+	} else {
+		http.Error(w, "Author not found", http.StatusNotFound)
+		return
+	}
+	modified, keys := pageMeta(tenant.OrgID, page)
+	keys = append(keys, authorKey(id))
+	etag := pageETag(r, page, author.Name+"|"+author.Avatar+"|"+author.Bio)
+	if h.cacheHeaders(w, r, RouteAuthors, etag, modified, keys) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(AuthorPage{Author: author, Articles: page})
+}
@@ -0,0 +1,78 @@
+package articles
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSyncAuthor(t *testing.T) {
+	e := newTestEnv()
+	ctx := context.Background()
+	author, other := e.newUser(e.orgA), e.newUser(e.orgA)
+	a := e.create(t, author, e.orgA, "Primero")
+	b := e.create(t, author, e.orgA, "Segundo")
+	c := e.create(t, other, e.orgA, "Ajeno")
+
+	u := e.users.byID[author]
+	u.FirstName, u.Bio, u.ProfileImage = "Ana María", "Periodista", "https://cdn.example.com/ana.png"
+	if err := e.h.SyncAuthor(ctx, u); err != nil {
+		t.Fatal(err)
+	}
+	for _, before := range []Article{a, b} {
+		got, _ := e.h.Repo.FindArticle(ctx, Filter{ID: before.ID, System: true})
+		if got.AuthorName != "Ana María Pérez" || got.AuthorBio != "Periodista" || got.AuthorAvatar != u.ProfileImage {
+			t.Errorf("stale byline: %+v", byline(got))
+		}
+		if !got.UpdatedAt.Equal(before.UpdatedAt) {
+			t.Errorf("a byline change must not touch updated_at")
+		}
+	}
+	if got, _ := e.h.Repo.FindArticle(ctx, Filter{ID: c.ID, System: true}); got.AuthorName != "Ana Pérez" {
+		t.Errorf("other authors must not change: %q", got.AuthorName)
+	}
+}
+
+func TestHandlers_GetAuthor(t *testing.T) {
+	e := newTestEnv()
+	author := e.newUser(e.orgA)
+	e.users.byID[author].Bio = "Cronista"
+	pub := e.create(t, author, e.orgA, "Publicado")
+	e.create(t, author, e.orgA, "Borrador")
+	for _, name := range []string{"submit", "approve", "publish"} {
+		e.transition(t, pub.ID, name, author, e.orgA)
+	}
+
+	get := func(id primitive.ObjectID, query string) (int, AuthorPage) {
+		r := request("GET", "/authors/"+id.Hex()+query, "", primitive.NilObjectID, nil)
+		w := serve(e.h.GetAuthor, r, "id", id.Hex())
+		var page AuthorPage
+		json.NewDecoder(w.Body).Decode(&page)
+		return w.Code, page
+	}
+	code, page := get(author, "")
+	if code != http.StatusOK || page.Name != "Ana Pérez" || page.Bio != "Cronista" {
+		t.Fatalf("unexpected author page: %d %+v", code, page.Author)
+	}
+	if page.Articles == nil || len(page.Articles.Items) != 1 || page.Articles.Items[0].ID != pub.ID {
+		t.Errorf("only published articles are listed: %+v", page.Articles)
+	}
+	if code, _ := get(author, "?org=org-b"); code != http.StatusNotFound {
+		t.Errorf("authors of another organization are not found, got %d", code)
+	}
+	if code, _ := get(e.newUser(e.orgA), ""); code != http.StatusOK {
+		t.Errorf("authors without articles still have a page, got %d", code)
+	}
+
+	// Un autor borrado conserva la firma de sus artículos
+	delete(e.users.byID, author)
+	if code, page := get(author, ""); code != http.StatusOK || page.Name != "Ana Pérez" || len(page.Articles.Items) != 1 {
+		t.Errorf("deleted authors keep their byline: %d %+v", code, page)
+	}
+	if code, _ := get(primitive.NewObjectID(), ""); code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown authors, got %d", code)
+	}
+}
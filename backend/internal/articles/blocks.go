@@ -0,0 +1,173 @@
+package articles
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// 🧩 Contenido estructurado: el cuerpo del artículo como lista de bloques.
+// Los campos de texto de paragraph, heading, quote y list admiten un
+// subconjunto de HTML inline (strong, em, code, a, br) que se sanea al renderizar.
+
+const (
+	BlockParagraph = "paragraph"
+	BlockHeading   = "heading"
+	BlockImage     = "image"
+	BlockEmbed     = "embed"
+	BlockQuote     = "quote"
+	BlockCode      = "code"
+	BlockList      = "list"
+)
+
+// Block es un bloque de contenido; cada tipo usa solo algunos campos
+type Block struct {
+	Type string `bson:"type" json:"type"`
+	// Text: paragraph, heading, quote (HTML inline) y code (texto plano)
+	Text string `bson:"text,omitempty" json:"text,omitempty"`
+	// Level: heading, de 1 a 6
+	Level int `bson:"level,omitempty" json:"level,omitempty"`
+	// URL, Alt y Caption: image y embed
+	URL     string `bson:"url,omitempty" json:"url,omitempty"`
+	Alt     string `bson:"alt,omitempty" json:"alt,omitempty"`
+	Caption string `bson:"caption,omitempty" json:"caption,omitempty"`
+	// Cite: autor o fuente de una quote
+	Cite string `bson:"cite,omitempty" json:"cite,omitempty"`
+	// Language: lenguaje de un bloque code, para el resaltado
+	Language string `bson:"language,omitempty" json:"language,omitempty"`
+	// Items y Ordered: list
+	Items   []string `bson:"items,omitempty" json:"items,omitempty"`
+	Ordered bool     `bson:"ordered,omitempty" json:"ordered,omitempty"`
+}
+
+// Límites del esquema
+const (
+	maxBlocks     = 1000
+	maxBlockText  = 20000
+	maxListItems  = 500
+	maxCaptionLen = 1000
+)
+
+var codeLanguageRegex = regexp.MustCompile(`^[a-z0-9+#_-]{1,32}$`)
+
+// BlockError indica qué bloque no cumple el esquema
+type BlockError struct {
+	Index   int    `json:"index"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *BlockError) Error() string {
+	return fmt.Sprintf("block %d: %s %s", e.Index, e.Field, e.Message)
+}
+
+// ValidateBlocks aplica del lado del servidor las reglas de BlockSchema
+func ValidateBlocks(blocks []Block) error {
+	if len(blocks) > maxBlocks {
+		return &BlockError{Index: maxBlocks, Field: "blocks", Message: fmt.Sprintf("must have at most %d items", maxBlocks)}
+	}
+	for i, b := range blocks {
+		fail := func(field, msg string) error { return &BlockError{Index: i, Field: field, Message: msg} }
+		if len(b.Text) > maxBlockText {
+			return fail("text", fmt.Sprintf("must be at most %d bytes", maxBlockText))
+		}
+		if len(b.Caption) > maxCaptionLen || len(b.Alt) > maxCaptionLen || len(b.Cite) > maxCaptionLen {
+			return fail("caption", fmt.Sprintf("caption, alt and cite must be at most %d bytes", maxCaptionLen))
+		}
+		switch b.Type {
+		case BlockParagraph, BlockQuote:
+			if strings.TrimSpace(b.Text) == "" {
+				return fail("text", "is required")
+			}
+		case BlockHeading:
+			if strings.TrimSpace(b.Text) == "" {
+				return fail("text", "is required")
+			}
+			if b.Level < 1 || b.Level > 6 {
+				return fail("level", "must be between 1 and 6")
+			}
+		case BlockImage:
+			if !safeURL(b.URL, false) {
+				return fail("url", "must be an http(s) or relative URL")
+			}
+		case BlockEmbed:
+			if !safeURL(b.URL, true) {
+				return fail("url", "must be an absolute https URL")
+			}
+		case BlockCode:
+			if b.Text == "" {
+				return fail("text", "is required")
+			}
+			if b.Language != "" && !codeLanguageRegex.MatchString(b.Language) {
+				return fail("language", "must match "+codeLanguageRegex.String())
+			}
+		case BlockList:
+			if len(b.Items) == 0 || len(b.Items) > maxListItems {
+				return fail("items", fmt.Sprintf("must have between 1 and %d items", maxListItems))
+			}
+			for _, item := range b.Items {
+				if len(item) > maxBlockText {
+					return fail("items", fmt.Sprintf("each item must be at most %d bytes", maxBlockText))
+				}
+			}
+		default:
+			return fail("type", "must be one of paragraph, heading, image, embed, quote, code, list")
+		}
+	}
+	return nil
+}
+
+// safeURL acepta http(s) absolutas o rutas relativas; con httpsOnly solo https absolutas
+func safeURL(raw string, httpsOnly bool) bool {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return false
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	if httpsOnly {
+		return u.Scheme == "https" && u.Host != ""
+	}
+	switch u.Scheme {
+	case "http", "https":
+		return u.Host != ""
+	case "":
+		// Relativa, pero no "//host" (protocol-relative)
+		return u.Host == "" && !strings.HasPrefix(raw, "//")
+	}
+	return false
+}
+
+// BlockSchema es el JSON Schema publicado en GET /content/schema
+const BlockSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$id": "/content/schema",
+  "title": "Article blocks",
+  "type": "array",
+  "maxItems": 1000,
+  "items": {
+    "type": "object",
+    "required": ["type"],
+    "properties": {
+      "type": {"enum": ["paragraph", "heading", "image", "embed", "quote", "code", "list"]},
+      "text": {"type": "string", "maxLength": 20000},
+      "level": {"type": "integer", "minimum": 1, "maximum": 6},
+      "url": {"type": "string", "format": "uri-reference"},
+      "alt": {"type": "string", "maxLength": 1000},
+      "caption": {"type": "string", "maxLength": 1000},
+      "cite": {"type": "string", "maxLength": 1000},
+      "language": {"type": "string", "pattern": "^[a-z0-9+#_-]{1,32}$"},
+      "items": {"type": "array", "minItems": 1, "maxItems": 500, "items": {"type": "string", "maxLength": 20000}},
+      "ordered": {"type": "boolean"}
+    },
+    "allOf": [
+      {"if": {"properties": {"type": {"enum": ["paragraph", "quote", "code"]}}}, "then": {"required": ["text"]}},
+      {"if": {"properties": {"type": {"const": "heading"}}}, "then": {"required": ["text", "level"]}},
+      {"if": {"properties": {"type": {"enum": ["image", "embed"]}}}, "then": {"required": ["url"]}},
+      {"if": {"properties": {"type": {"const": "list"}}}, "then": {"required": ["items"]}}
+    ]
+  }
+}`
@@ -0,0 +1,231 @@
+package articles
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	nethtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Conversión del contenido heredado (HTML o texto plano en Article.Content) a bloques
+
+var blankLinesRegex = regexp.MustCompile(`\n\s*\n`)
+
+// HTMLToBlocks convierte contenido heredado en bloques. El texto sin etiquetas
+// se parte en párrafos por líneas en blanco.
+func HTMLToBlocks(s string) []Block {
+	nodes, err := parseFragment(s)
+	if err != nil {
+		return plainTextBlocks(s)
+	}
+	c := &blockConverter{blocks: []Block{}}
+	c.walk(nodes)
+	c.flush()
+	return c.blocks
+}
+
+func plainTextBlocks(s string) []Block {
+	blocks := []Block{}
+	for _, para := range blankLinesRegex.Split(strings.ReplaceAll(s, "\r\n", "\n"), -1) {
+		para = strings.TrimSpace(para)
+		if para == "" {
+			continue
+		}
+		text := strings.ReplaceAll(html.EscapeString(para), "\n", "<br>")
+		blocks = append(blocks, Block{Type: BlockParagraph, Text: text})
+	}
+	return blocks
+}
+
+// blockConverter acumula el contenido inline suelto hasta encontrar un bloque
+type blockConverter struct {
+	blocks []Block
+	inline strings.Builder
+}
+
+// flush cierra el párrafo pendiente con el texto inline acumulado
+func (c *blockConverter) flush() {
+	pending := c.inline.String()
+	c.inline.Reset()
+	if strings.TrimSpace(nodeTextOf(pending)) == "" {
+		return
+	}
+	if !strings.Contains(pending, "<") && strings.Contains(pending, "\n") {
+		// Texto plano heredado: párrafos por líneas en blanco
+		c.blocks = append(c.blocks, plainTextBlocks(html.UnescapeString(pending))...)
+		return
+	}
+	c.blocks = append(c.blocks, Block{Type: BlockParagraph, Text: strings.TrimSpace(pending)})
+}
+
+func (c *blockConverter) add(b Block) {
+	c.flush()
+	c.blocks = append(c.blocks, b)
+}
+
+func (c *blockConverter) walk(nodes []*nethtml.Node) {
+	for _, n := range nodes {
+		c.node(n)
+	}
+}
+
+func children(n *nethtml.Node) []*nethtml.Node {
+	out := []*nethtml.Node{}
+	for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+		out = append(out, ch)
+	}
+	return out
+}
+
+// innerInline devuelve el HTML inline saneado de los hijos de n
+func innerInline(n *nethtml.Node) string {
+	var b strings.Builder
+	for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+		var raw strings.Builder
+		nethtml.Render(&raw, ch)
+		b.WriteString(raw.String())
+	}
+	return strings.TrimSpace(sanitizeInline(b.String()))
+}
+
+func attr(n *nethtml.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func (c *blockConverter) node(n *nethtml.Node) {
+	switch n.Type {
+	case nethtml.TextNode:
+		c.inline.WriteString(html.EscapeString(n.Data))
+		return
+	case nethtml.ElementNode:
+	default:
+		return
+	}
+	if droppedTags[n.DataAtom] && n.DataAtom != atom.Iframe {
+		return
+	}
+	switch n.DataAtom {
+	case atom.P:
+		if text := innerInline(n); strings.TrimSpace(nodeTextOf(text)) != "" {
+			c.add(Block{Type: BlockParagraph, Text: text})
+		} else {
+			// Un <p> que solo envuelve una imagen o un embed
+			c.flush()
+			c.walk(children(n))
+			c.flush()
+		}
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		if text := innerInline(n); text != "" {
+			c.add(Block{Type: BlockHeading, Text: text, Level: int(n.Data[1] - '0')})
+		}
+	case atom.Blockquote:
+		b := Block{Type: BlockQuote}
+		parts := []string{}
+		for _, ch := range children(n) {
+			if ch.Type == nethtml.ElementNode && (ch.DataAtom == atom.Footer || ch.DataAtom == atom.Cite) {
+				b.Cite = strings.TrimSpace(nodeText(ch))
+				continue
+			}
+			var raw strings.Builder
+			nethtml.Render(&raw, ch)
+			parts = append(parts, raw.String())
+		}
+		b.Text = strings.TrimSpace(sanitizeInline(strings.Join(parts, "<br>")))
+		if b.Text != "" {
+			c.add(b)
+		}
+	case atom.Pre:
+		b := Block{Type: BlockCode, Text: nodeText(n)}
+		for _, ch := range children(n) {
+			if ch.DataAtom == atom.Code {
+				b.Language = codeLanguage(attr(ch, "class"))
+			}
+		}
+		if b.Text != "" {
+			c.add(b)
+		}
+	case atom.Ul, atom.Ol:
+		b := Block{Type: BlockList, Ordered: n.DataAtom == atom.Ol}
+		for _, ch := range children(n) {
+			if ch.DataAtom == atom.Li {
+				b.Items = append(b.Items, innerInline(ch))
+			}
+		}
+		if len(b.Items) > 0 {
+			c.add(b)
+		}
+	case atom.Img:
+		if src := attr(n, "src"); safeURL(src, false) {
+			c.add(Block{Type: BlockImage, URL: src, Alt: attr(n, "alt")})
+		}
+	case atom.Figure:
+		c.figure(n)
+	case atom.Iframe:
+		if src := attr(n, "src"); safeURL(src, true) {
+			c.add(Block{Type: BlockEmbed, URL: src})
+		}
+	case atom.Div, atom.Section, atom.Article, atom.Main, atom.Header, atom.Aside, atom.Body:
+		c.flush()
+		c.walk(children(n))
+		c.flush()
+	case atom.Hr:
+		c.flush()
+	default:
+		var raw strings.Builder
+		nethtml.Render(&raw, n)
+		c.inline.WriteString(sanitizeInline(raw.String()))
+	}
+}
+
+// figure convierte <figure><img|iframe><figcaption> en un bloque con leyenda
+func (c *blockConverter) figure(n *nethtml.Node) {
+	var b Block
+	var caption string
+	var visit func(*nethtml.Node)
+	visit = func(x *nethtml.Node) {
+		for _, ch := range children(x) {
+			switch ch.DataAtom {
+			case atom.Img:
+				if b.Type == "" && safeURL(attr(ch, "src"), false) {
+					b = Block{Type: BlockImage, URL: attr(ch, "src"), Alt: attr(ch, "alt")}
+				}
+			case atom.Iframe:
+				if b.Type == "" && safeURL(attr(ch, "src"), true) {
+					b = Block{Type: BlockEmbed, URL: attr(ch, "src")}
+				}
+			case atom.Figcaption:
+				caption = innerInline(ch)
+			default:
+				visit(ch)
+			}
+		}
+	}
+	visit(n)
+	if b.Type == "" {
+		c.flush()
+		c.walk(children(n))
+		c.flush()
+		return
+	}
+	b.Caption = caption
+	c.add(b)
+}
+
+// codeLanguage extrae el lenguaje de class="language-go" (o "lang-go")
+func codeLanguage(class string) string {
+	for _, cls := range strings.Fields(class) {
+		for _, prefix := range []string{"language-", "lang-"} {
+			if lang, ok := strings.CutPrefix(cls, prefix); ok && codeLanguageRegex.MatchString(lang) {
+				return lang
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,215 @@
+package articles
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"pittsix/pkg/events"
+	"pittsix/pkg/middleware"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// prepareContent normaliza el cuerpo según su formato: el HTML se sanea, los
+// bloques se validan y se renderizan en Content, y el Markdown se guarda como
+// fuente (se renderiza en lectura)
+func prepareContent(a *Article) error {
+	format := contentFormat(a)
+	switch format {
+	case FormatHTML:
+		a.Blocks = nil
+		a.Content = SanitizeHTML(a.Content)
+	case FormatMarkdown:
+		a.Blocks = nil
+	case FormatBlocks:
+		if err := ValidateBlocks(a.Blocks); err != nil {
+			return err
+		}
+		a.Content = RenderHTML(a.Blocks)
+	default:
+		return errContentFormat
+	}
+	a.ContentFormat = format
+	return nil
+}
+
+// writeContentError responde 422 con el bloque inválido, o 400 si el problema
+// es el formato
+func writeContentError(w http.ResponseWriter, err error) {
+	if _, invalid := err.(*BlockError); !invalid {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(map[string]interface{}{"error": err.Error(), "detail": err})
+}
+
+// 📐 JSON Schema de los bloques (público)
+func (h *Handlers) GetContentSchema(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/schema+json")
+	w.Write([]byte(BlockSchema))
+}
+
+// 🖨️ Renderizar bloques a HTML o Markdown sin guardar nada (requiere JWT)
+func (h *Handlers) RenderContent(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Blocks   []Block `json:"blocks"`
+		Markdown string  `json:"markdown"`
+		Format   string  `json:"format"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeBodyError(w, err)
+		return
+	}
+	// Vista previa de Markdown: solo se puede pedir HTML
+	if input.Markdown != "" {
+		if input.Format != "" && input.Format != "html" {
+			http.Error(w, "markdown can only be rendered to html", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(RenderMarkdownHTML(input.Markdown)))
+		return
+	}
+	if err := ValidateBlocks(input.Blocks); err != nil {
+		writeContentError(w, err)
+		return
+	}
+	switch input.Format {
+	case "", "html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(RenderHTML(input.Blocks)))
+	case "markdown":
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.Write([]byte(RenderMarkdown(input.Blocks)))
+	default:
+		http.Error(w, "format must be html or markdown", http.StatusBadRequest)
+	}
+}
+
+// 🔁 Convertir contenido (HTML, texto o Markdown) a bloques sin guardar nada (requiere JWT)
+func (h *Handlers) ConvertContent(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Content string `json:"content"`
+		Format  string `json:"format"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeBodyError(w, err)
+		return
+	}
+	source := &Article{Content: input.Content, ContentFormat: input.Format}
+	if f := contentFormat(source); f != FormatHTML && f != FormatMarkdown {
+		http.Error(w, "format must be html or markdown", http.StatusBadRequest)
+		return
+	}
+	blocks := HTMLToBlocks(renderArticleHTML(source))
+	if err := ValidateBlocks(blocks); err != nil {
+		writeContentError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"blocks": blocks})
+}
+
+// migrateContent pasa a bloques el contenido HTML o Markdown del artículo a
+// nombre de editor y guarda la versión anterior como revisión. Devuelve
+// ErrNotFound si alguien lo guardó después de leerlo.
+func (h *Handlers) migrateContent(ctx context.Context, a *Article, editor primitive.ObjectID) error {
+	blocks := HTMLToBlocks(renderArticleHTML(a))
+	if err := ValidateBlocks(blocks); err != nil {
+		return err
+	}
+	updated := *a
+	updated.Blocks = blocks
+	updated.Content = RenderHTML(blocks)
+	updated.ContentFormat = FormatBlocks
+	updated.Revision = currentRevision(a) + 1
+	updated.LastEditedBy = editor
+	updated.UpdatedAt = time.Now()
+	filter := Filter{ID: a.ID, OrganizationID: a.OrganizationID, Revision: currentRevision(a)}
+	if err := h.Repo.UpdateArticle(ctx, filter, &updated); err != nil {
+		return err
+	}
+	keepSnapshot(ctx, h.Repo, a)
+	*a = updated
+	h.reindex(ctx, a.ID)
+	h.publish(ctx, events.ArticleUpdated, a)
+	return nil
+}
+
+// 🧩 Migrar a bloques el contenido de un artículo (requiere JWT y poder editarlo)
+func (h *Handlers) MigrateArticleContent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	article, userID, code, err := h.findArticleFor(ctx, r, canEdit)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	if contentFormat(article) == FormatBlocks {
+		http.Error(w, "Article already uses blocks", http.StatusConflict)
+		return
+	}
+	err = h.migrateContent(ctx, article, userID)
+	if _, invalid := err.(*BlockError); invalid {
+		writeContentError(w, err)
+		return
+	}
+	if err == ErrNotFound {
+		writePreconditionFailed(w, nil)
+		return
+	}
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(article)
+}
+
+// 🧩 Migrar a bloques todos los artículos heredados de la organización (org_admin)
+func (h *Handlers) MigrateContent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !middleware.HasRole(ctx, "superadmin", "org_admin") {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	tenant, err := tenantFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	userID, _ := ctx.Value("user_id").(string)
+	adminID, _ := primitive.ObjectIDFromHex(userID)
+	all, err := h.Repo.FindArticles(ctx, tenant.scope(Filter{}))
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+
+	result := struct {
+		Migrated int               `json:"migrated"`
+		Failed   map[string]string `json:"failed,omitempty"`
+		Skipped  int               `json:"skipped"`
+	}{Failed: map[string]string{}}
+	for i := range all {
+		a := &all[i]
+		if contentFormat(a) == FormatBlocks || a.Content == "" {
+			result.Skipped++
+			continue
+		}
+		if err := h.migrateContent(ctx, a, adminID); err != nil {
+			result.Failed[a.ID.Hex()] = err.Error()
+			continue
+		}
+		result.Migrated++
+	}
+	log.Printf("🧩 Migración a bloques: %d migrados, %d con error", result.Migrated, len(result.Failed))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
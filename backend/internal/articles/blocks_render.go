@@ -0,0 +1,266 @@
+package articles
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// RenderHTML genera HTML saneado a partir de bloques ya validados
+func RenderHTML(blocks []Block) string {
+	var b strings.Builder
+	for _, bl := range blocks {
+		switch bl.Type {
+		case BlockParagraph:
+			b.WriteString("<p>" + sanitizeInline(bl.Text) + "</p>\n")
+		case BlockHeading:
+			tag := "h" + strconv.Itoa(min(max(bl.Level, 1), 6))
+			b.WriteString("<" + tag + ">" + sanitizeInline(bl.Text) + "</" + tag + ">\n")
+		case BlockImage:
+			if !safeURL(bl.URL, false) {
+				continue
+			}
+			b.WriteString(`<figure><img src="` + html.EscapeString(bl.URL) + `" alt="` + html.EscapeString(bl.Alt) + `">`)
+			if bl.Caption != "" {
+				b.WriteString("<figcaption>" + sanitizeInline(bl.Caption) + "</figcaption>")
+			}
+			b.WriteString("</figure>\n")
+		case BlockEmbed:
+			if !safeURL(bl.URL, true) {
+				continue
+			}
+			if src := embedURL(bl.URL); src != "" {
+				b.WriteString(`<figure class="embed"><iframe src="` + html.EscapeString(src) + `" loading="lazy" allowfullscreen></iframe>`)
+			} else {
+				// Proveedor desconocido: se enlaza en lugar de incrustarlo
+				b.WriteString(`<figure class="embed"><a href="` + html.EscapeString(bl.URL) + `" rel="nofollow noopener">` + html.EscapeString(bl.URL) + `</a>`)
+			}
+			if bl.Caption != "" {
+				b.WriteString("<figcaption>" + sanitizeInline(bl.Caption) + "</figcaption>")
+			}
+			b.WriteString("</figure>\n")
+		case BlockQuote:
+			b.WriteString("<blockquote><p>" + sanitizeInline(bl.Text) + "</p>")
+			if bl.Cite != "" {
+				b.WriteString("<footer><cite>" + html.EscapeString(bl.Cite) + "</cite></footer>")
+			}
+			b.WriteString("</blockquote>\n")
+		case BlockCode:
+			b.WriteString("<pre><code")
+			if codeLanguageRegex.MatchString(bl.Language) {
+				b.WriteString(` class="language-` + bl.Language + `"`)
+			}
+			b.WriteString(">" + html.EscapeString(bl.Text) + "</code></pre>\n")
+		case BlockList:
+			tag := "ul"
+			if bl.Ordered {
+				tag = "ol"
+			}
+			b.WriteString("<" + tag + ">")
+			for _, item := range bl.Items {
+				b.WriteString("<li>" + sanitizeInline(item) + "</li>")
+			}
+			b.WriteString("</" + tag + ">\n")
+		}
+	}
+	return b.String()
+}
+
+// embedURL traduce la URL pública de los proveedores conocidos a su reproductor
+// incrustable; devuelve "" para el resto
+func embedURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	id := strings.Trim(u.Path, "/")
+	switch host {
+	case "youtube.com", "m.youtube.com":
+		if v := u.Query().Get("v"); v != "" {
+			id = v
+		} else if rest, ok := strings.CutPrefix(id, "embed/"); ok {
+			id = rest
+		} else {
+			return ""
+		}
+		return youtubeEmbed(id)
+	case "youtu.be":
+		return youtubeEmbed(id)
+	case "vimeo.com", "player.vimeo.com":
+		id = strings.TrimPrefix(id, "video/")
+		if _, err := strconv.ParseUint(id, 10, 64); err != nil {
+			return ""
+		}
+		return "https://player.vimeo.com/video/" + id
+	}
+	return ""
+}
+
+func youtubeEmbed(id string) string {
+	for _, r := range id {
+		if !(r == '-' || r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return ""
+		}
+	}
+	if id == "" {
+		return ""
+	}
+	return "https://www.youtube-nocookie.com/embed/" + id
+}
+
+// RenderMarkdown genera Markdown a partir de bloques ya validados
+func RenderMarkdown(blocks []Block) string {
+	parts := []string{}
+	for _, bl := range blocks {
+		switch bl.Type {
+		case BlockParagraph:
+			parts = append(parts, inlineMarkdown(bl.Text))
+		case BlockHeading:
+			parts = append(parts, strings.Repeat("#", min(max(bl.Level, 1), 6))+" "+inlineMarkdown(bl.Text))
+		case BlockImage:
+			img := "![" + escapeMarkdown(bl.Alt) + "](" + markdownURL(bl.URL)
+			if bl.Caption != "" {
+				img += ` "` + strings.ReplaceAll(nodeTextOf(bl.Caption), `"`, `\"`) + `"`
+			}
+			parts = append(parts, img+")")
+		case BlockEmbed:
+			embed := "<" + bl.URL + ">"
+			if bl.Caption != "" {
+				embed += "\n" + inlineMarkdown(bl.Caption)
+			}
+			parts = append(parts, embed)
+		case BlockQuote:
+			lines := strings.Split(inlineMarkdown(bl.Text), "\n")
+			if bl.Cite != "" {
+				lines = append(lines, "", "— "+escapeMarkdown(bl.Cite))
+			}
+			for i, l := range lines {
+				lines[i] = strings.TrimRight("> "+l, " ")
+			}
+			parts = append(parts, strings.Join(lines, "\n"))
+		case BlockCode:
+			fence := codeFence(bl.Text)
+			parts = append(parts, fence+bl.Language+"\n"+strings.TrimSuffix(bl.Text, "\n")+"\n"+fence)
+		case BlockList:
+			items := make([]string, len(bl.Items))
+			for i, item := range bl.Items {
+				marker := "- "
+				if bl.Ordered {
+					marker = strconv.Itoa(i+1) + ". "
+				}
+				// Las líneas de continuación se indentan para seguir dentro del ítem
+				text := strings.ReplaceAll(inlineMarkdown(item), "\n", "\n"+strings.Repeat(" ", len(marker)))
+				items[i] = marker + text
+			}
+			parts = append(parts, strings.Join(items, "\n"))
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return strings.Join(parts, "\n\n") + "\n"
+}
+
+// codeFence elige un cerco de backticks más largo que cualquier tira del código
+func codeFence(code string) string {
+	longest, run := 0, 0
+	for _, r := range code {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", `\<`,
+)
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+func markdownURL(u string) string {
+	return strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(u)
+}
+
+// nodeTextOf devuelve el texto plano de un fragmento HTML inline
+func nodeTextOf(s string) string {
+	nodes, err := parseFragment(s)
+	if err != nil {
+		return s
+	}
+	var b strings.Builder
+	for _, n := range nodes {
+		b.WriteString(nodeText(n))
+	}
+	return b.String()
+}
+
+// inlineMarkdown convierte el HTML inline de un bloque a Markdown
+func inlineMarkdown(s string) string {
+	nodes, err := parseFragment(s)
+	if err != nil {
+		return escapeMarkdown(s)
+	}
+	var b strings.Builder
+	for _, n := range nodes {
+		writeInlineMarkdown(&b, n)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func writeInlineMarkdown(b *strings.Builder, n *html.Node) {
+	if n.Type == html.TextNode {
+		b.WriteString(escapeMarkdown(n.Data))
+		return
+	}
+	if n.Type != html.ElementNode || droppedTags[n.DataAtom] {
+		return
+	}
+	children := func() string {
+		var inner strings.Builder
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			writeInlineMarkdown(&inner, c)
+		}
+		return inner.String()
+	}
+	switch n.DataAtom {
+	case atom.Strong, atom.B:
+		b.WriteString("**" + children() + "**")
+	case atom.Em, atom.I:
+		b.WriteString("*" + children() + "*")
+	case atom.S:
+		b.WriteString("~~" + children() + "~~")
+	case atom.Code:
+		text := nodeText(n)
+		fence := "`"
+		if strings.Contains(text, "`") {
+			fence = "``"
+		}
+		b.WriteString(fence + text + fence)
+	case atom.Br:
+		b.WriteString("  \n")
+	case atom.A:
+		href := ""
+		for _, a := range n.Attr {
+			if a.Key == "href" && safeLink(a.Val) {
+				href = a.Val
+			}
+		}
+		if href == "" {
+			b.WriteString(children())
+			return
+		}
+		b.WriteString("[" + children() + "](" + markdownURL(href) + ")")
+	default:
+		b.WriteString(children())
+	}
+}
@@ -0,0 +1,167 @@
+package articles
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateBlocks(t *testing.T) {
+	valid := []Block{
+		{Type: BlockHeading, Text: "Intro", Level: 2},
+		{Type: BlockParagraph, Text: "Hola <strong>mundo</strong>"},
+		{Type: BlockImage, URL: "/uploads/a.png", Alt: "a"},
+		{Type: BlockEmbed, URL: "https://youtu.be/abc123"},
+		{Type: BlockQuote, Text: "Cita", Cite: "Alguien"},
+		{Type: BlockCode, Text: "fmt.Println()", Language: "go"},
+		{Type: BlockList, Items: []string{"uno", "dos"}, Ordered: true},
+	}
+	if err := ValidateBlocks(valid); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	invalid := map[string]Block{
+		"type":     {Type: "video"},
+		"level":    {Type: BlockHeading, Text: "x", Level: 7},
+		"text":     {Type: BlockParagraph, Text: "  "},
+		"url":      {Type: BlockImage, URL: "javascript:alert(1)"},
+		"embed":    {Type: BlockEmbed, URL: "http://example.com/video"},
+		"language": {Type: BlockCode, Text: "x", Language: "Go Lang"},
+		"items":    {Type: BlockList},
+	}
+	for name, b := range invalid {
+		err := ValidateBlocks([]Block{{Type: BlockParagraph, Text: "ok"}, b})
+		be, ok := err.(*BlockError)
+		if !ok || be.Index != 1 {
+			t.Errorf("%s: expected BlockError at index 1, got %v", name, err)
+		}
+	}
+}
+
+func TestSanitizeHTML(t *testing.T) {
+	cases := map[string]string{
+		`<p onclick="x()">Hola<script>alert(1)</script></p>`:     `<p>Hola</p>`,
+		`<a href="javascript:alert(1)">link</a>`:                 `<a rel="nofollow noopener">link</a>`,
+		`<a href="https://go.dev" target="_blank">Go</a>`:        `<a href="https://go.dev" rel="nofollow noopener">Go</a>`,
+		`<div><span>texto</span><iframe src="x"></iframe></div>`: `texto`,
+		`<img src="/a.png" onerror="x()">`:                       `<img src="/a.png">`,
+		`1 < 2 & 3`:                                              `1 &lt; 2 &amp; 3`,
+	}
+	for in, want := range cases {
+		if got := SanitizeHTML(in); got != want {
+			t.Errorf("SanitizeHTML(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := sanitizeInline("<h1>Título</h1> <em>ok</em>"); got != "Título <em>ok</em>" {
+		t.Errorf("inline sanitizer should drop block tags, got %q", got)
+	}
+}
+
+func TestRenderHTMLAndMarkdown(t *testing.T) {
+	blocks := []Block{
+		{Type: BlockHeading, Text: "Guía", Level: 2},
+		{Type: BlockParagraph, Text: `Ver <a href="https://go.dev">Go</a> y <b>más</b><script>x</script>`},
+		{Type: BlockImage, URL: "/a.png", Alt: "Logo", Caption: "El logo"},
+		{Type: BlockEmbed, URL: "https://www.youtube.com/watch?v=abc_123"},
+		{Type: BlockQuote, Text: "Menos es más", Cite: "Mies"},
+		{Type: BlockCode, Text: "a := \"```\"", Language: "go"},
+		{Type: BlockList, Items: []string{"uno", "<em>dos</em>"}, Ordered: true},
+	}
+	gotHTML := RenderHTML(blocks)
+	for _, want := range []string{
+		"<h2>Guía</h2>",
+		`<p>Ver <a href="https://go.dev" rel="nofollow noopener">Go</a> y <b>más</b></p>`,
+		`<figure><img src="/a.png" alt="Logo"><figcaption>El logo</figcaption></figure>`,
+		`<iframe src="https://www.youtube-nocookie.com/embed/abc_123"`,
+		"<blockquote><p>Menos es más</p><footer><cite>Mies</cite></footer></blockquote>",
+		`<pre><code class="language-go">a := &#34;` + "```" + `&#34;</code></pre>`,
+		"<ol><li>uno</li><li><em>dos</em></li></ol>",
+	} {
+		if !strings.Contains(gotHTML, want) {
+			t.Errorf("HTML missing %q in:\n%s", want, gotHTML)
+		}
+	}
+	if strings.Contains(gotHTML, "script") {
+		t.Errorf("rendered HTML must be sanitized:\n%s", gotHTML)
+	}
+
+	wantMD := "## Guía\n\n" +
+		"Ver [Go](https://go.dev) y **más**\n\n" +
+		"![Logo](/a.png \"El logo\")\n\n" +
+		"<https://www.youtube.com/watch?v=abc_123>\n\n" +
+		"> Menos es más\n>\n> — Mies\n\n" +
+		"````go\na := \"```\"\n````\n\n" +
+		"1. uno\n2. *dos*\n"
+	if got := RenderMarkdown(blocks); got != wantMD {
+		t.Errorf("unexpected markdown:\n%s\nwant:\n%s", got, wantMD)
+	}
+}
+
+func TestHTMLToBlocks(t *testing.T) {
+	legacy := `<h2>Título</h2><p>Hola <strong>mundo</strong></p>texto suelto
+<figure><img src="/a.png" alt="A"><figcaption>Leyenda</figcaption></figure>
+<pre><code class="language-go">x := 1</code></pre>
+<ul><li>uno</li><li>dos</li></ul><blockquote>Cita<cite>Autor</cite></blockquote>
+<iframe src="https://player.vimeo.com/video/1"></iframe><script>alert(1)</script>`
+	want := []Block{
+		{Type: BlockHeading, Text: "Título", Level: 2},
+		{Type: BlockParagraph, Text: "Hola <strong>mundo</strong>"},
+		{Type: BlockParagraph, Text: "texto suelto"},
+		{Type: BlockImage, URL: "/a.png", Alt: "A", Caption: "Leyenda"},
+		{Type: BlockCode, Text: "x := 1", Language: "go"},
+		{Type: BlockList, Items: []string{"uno", "dos"}},
+		{Type: BlockQuote, Text: "Cita", Cite: "Autor"},
+		{Type: BlockEmbed, URL: "https://player.vimeo.com/video/1"},
+	}
+	if got := HTMLToBlocks(legacy); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected blocks:\n%+v\nwant:\n%+v", got, want)
+	}
+
+	plain := HTMLToBlocks("Primer párrafo\ncon salto\n\nSegundo & último")
+	if len(plain) != 2 || plain[0].Text != "Primer párrafo<br>con salto" || plain[1].Text != "Segundo &amp; último" {
+		t.Errorf("unexpected plain text conversion: %+v", plain)
+	}
+}
+
+func TestHandlers_BlocksAndContentMigration(t *testing.T) {
+	e := newTestEnv()
+	author := e.newUser(e.orgA)
+
+	body := `{"title":"Con bloques","blocks":[{"type":"heading","text":"Hola","level":9}]}`
+	if w := serve(e.h.CreateArticle, request("POST", "/articles", body, author, e.orgA)); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for invalid blocks, got %d", w.Code)
+	}
+
+	body = `{"title":"Heredado","content":"<p>Hola</p><script>alert(1)</script>"}`
+	w := serve(e.h.CreateArticle, request("POST", "/articles", body, author, e.orgA))
+	var a Article
+	json.NewDecoder(w.Body).Decode(&a)
+	if a.Content != "<p>Hola</p>" {
+		t.Errorf("legacy content should be sanitized on write, got %q", a.Content)
+	}
+
+	// Lo migra otro editor: la revisión queda a su nombre
+	migrator := e.newUser(e.orgA)
+	e.addContributor(t, a, author, migrator, RoleEditor)
+	stale, _ := e.h.Repo.FindArticle(context.Background(), Filter{ID: a.ID, System: true})
+	r := request("POST", "/articles/"+a.ID.Hex()+"/content/migrate", "", migrator, e.orgA)
+	if w := serve(e.h.MigrateArticleContent, r, "id", a.ID.Hex()); w.Code != http.StatusOK {
+		t.Fatalf("migrate: expected 200, got %d %s", w.Code, w.Body.String())
+	}
+	got, _ := e.h.Repo.FindArticle(context.Background(), Filter{ID: a.ID, System: true})
+	if len(got.Blocks) != 1 || got.Blocks[0].Text != "Hola" || got.Revision != 2 || got.LastEditedBy != migrator {
+		t.Errorf("unexpected migrated article: %+v", got)
+	}
+	if rev, err := e.h.Repo.GetRevision(context.Background(), a.ID, 1); err != nil || rev.Content != "<p>Hola</p>" {
+		t.Errorf("previous content should be kept as a revision: %+v %v", rev, err)
+	}
+	if w := serve(e.h.MigrateArticleContent, r, "id", a.ID.Hex()); w.Code != http.StatusConflict {
+		t.Errorf("expected 409 migrating twice, got %d", w.Code)
+	}
+	if err := e.h.migrateContent(context.Background(), stale, migrator); err != ErrNotFound {
+		t.Errorf("a stale migration must not overwrite the article, got %v", err)
+	}
+}
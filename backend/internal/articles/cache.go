@@ -0,0 +1,424 @@
+package articles
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"pittsix/pkg/cache"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/sync/singleflight"
+)
+
+// ⚡ CachedRepository pone un caché delante de las lecturas públicas: un
+// artículo visible por ID o por slug y las páginas de listados públicos. Como
+// todas las escrituras pasan por el mismo Repository (handlers y scheduler),
+// cada una invalida exactamente lo que cambia.
+//
+// Las claves llevan dos generaciones por organización guardadas en el mismo
+// Store: la de la organización (los cambios masivos, como renombrar una
+// etiqueta, la renuevan y descartan todo) y la de listados (cualquier cambio
+// de un artículo la renueva, porque puede entrar, salir o moverse en cualquier
+// página). Un artículo puntual se invalida borrando sus claves de ID y slug.
+
+const defaultCacheTTL = time.Minute
+
+type CachedRepository struct {
+	Repository
+	Store cache.Store
+	TTL   time.Duration
+
+	// flight junta las cargas concurrentes de una misma clave
+	flight singleflight.Group
+	// epoch sube con cada invalidación; una carga que vio pasar alguna no
+	// guarda lo que leyó, porque puede ser anterior al cambio
+	epoch atomic.Uint64
+
+	hits, misses, shared, loadErrors, storeErrors, invalidations atomic.Uint64
+}
+
+// CacheStats son las métricas del caché de lecturas
+type CacheStats struct {
+	Hits          uint64            `json:"hits"`
+	Misses        uint64            `json:"misses"`
+	HitRatio      float64           `json:"hit_ratio"`
+	Shared        uint64            `json:"shared_loads"`
+	LoadErrors    uint64            `json:"load_errors"`
+	StoreErrors   uint64            `json:"store_errors"`
+	Invalidations uint64            `json:"invalidations"`
+	Store         *cache.StoreStats `json:"store,omitempty"`
+}
+
+// NewCachedRepository envuelve repo; ttl <= 0 usa un minuto
+func NewCachedRepository(repo Repository, store cache.Store, ttl time.Duration) *CachedRepository {
+	if ttl <= 0 {
+		ttl = defaultCacheTTL
+	}
+	return &CachedRepository{Repository: repo, Store: store, TTL: ttl}
+}
+
+// publicLookup reconoce las búsquedas públicas cacheables: organización,
+// VisibleAt y además solo ID o solo slug
+func publicLookup(f Filter) (kind, value string, ok bool) {
+	if f.VisibleAt == nil || f.OrganizationID.IsZero() {
+		return "", "", false
+	}
+	rest := f
+	rest.VisibleAt, rest.OrganizationID, rest.ID, rest.Slug = nil, primitive.NilObjectID, primitive.NilObjectID, ""
+	if rest != (Filter{}) {
+		return "", "", false
+	}
+	switch {
+	case !f.ID.IsZero() && f.Slug == "":
+		return "id", f.ID.Hex(), true
+	case f.ID.IsZero() && f.Slug != "":
+		return "slug", f.Slug, true
+	}
+	return "", "", false
+}
+
+// publicListing reconoce los listados públicos: solo organización y VisibleAt
+func publicListing(f Filter) bool {
+	return f.VisibleAt != nil && !f.OrganizationID.IsZero() &&
+		f == Filter{OrganizationID: f.OrganizationID, VisibleAt: f.VisibleAt}
+}
+
+func (r *CachedRepository) FindArticle(ctx context.Context, f Filter) (*Article, error) {
+	kind, value, ok := publicLookup(f)
+	if !ok {
+		return r.Repository.FindArticle(ctx, f)
+	}
+	orgGen, err := r.generation(ctx, "org", f.OrganizationID)
+	if err != nil {
+		return r.Repository.FindArticle(ctx, f)
+	}
+	key := articleCacheKey(f.OrganizationID, orgGen, kind, value)
+
+	var cached Article
+	if r.get(ctx, key, &cached) && IsPubliclyVisible(&cached, *f.VisibleAt) {
+		r.hits.Add(1)
+		return &cached, nil
+	}
+	r.misses.Add(1)
+	v, err, shared := r.flight.Do(key, func() (interface{}, error) {
+		epoch := r.epoch.Load()
+		found, err := r.Repository.FindArticle(ctx, f)
+		if err != nil {
+			return nil, err
+		}
+		if r.epoch.Load() == epoch {
+			r.set(ctx, key, found, r.ttlFor([]Article{*found}))
+		}
+		return found, nil
+	})
+	if shared {
+		r.shared.Add(1)
+	}
+	if err != nil {
+		if err != ErrNotFound {
+			r.loadErrors.Add(1)
+		}
+		return nil, err
+	}
+	a := cloneArticle(*v.(*Article))
+	return &a, nil
+}
+
+// cachedPage es lo que se guarda de un listado
+type cachedPage struct {
+	Items []Article `bson:"items"`
+	Total int64     `bson:"total"`
+}
+
+func (r *CachedRepository) ListArticles(ctx context.Context, f Filter, q ListQuery) ([]Article, int64, error) {
+	if !publicListing(f) {
+		return r.Repository.ListArticles(ctx, f, q)
+	}
+	orgGen, err := r.generation(ctx, "org", f.OrganizationID)
+	var listGen string
+	if err == nil {
+		listGen, err = r.generation(ctx, "list", f.OrganizationID)
+	}
+	if err != nil {
+		return r.Repository.ListArticles(ctx, f, q)
+	}
+	query, _ := json.Marshal(q)
+	sum := sha256.Sum256(query)
+	key := articleCacheKey(f.OrganizationID, orgGen, "list", listGen+":"+hex.EncodeToString(sum[:12]))
+
+	var cached cachedPage
+	if r.get(ctx, key, &cached) {
+		r.hits.Add(1)
+		return cached.Items, cached.Total, nil
+	}
+	r.misses.Add(1)
+	v, err, shared := r.flight.Do(key, func() (interface{}, error) {
+		epoch := r.epoch.Load()
+		items, total, err := r.Repository.ListArticles(ctx, f, q)
+		if err != nil {
+			return nil, err
+		}
+		page := &cachedPage{Items: items, Total: total}
+		if ttl, ok := r.listTTL(ctx, f.OrganizationID, items); ok && r.epoch.Load() == epoch {
+			r.set(ctx, key, page, ttl)
+		}
+		return page, nil
+	})
+	if shared {
+		r.shared.Add(1)
+	}
+	if err != nil {
+		r.loadErrors.Add(1)
+		return nil, 0, err
+	}
+	page := v.(*cachedPage)
+	items := make([]Article, len(page.Items))
+	for i := range page.Items {
+		items[i] = cloneArticle(page.Items[i])
+	}
+	return items, page.Total, nil
+}
+
+func (r *CachedRepository) CreateArticle(ctx context.Context, a *Article) error {
+	if err := r.Repository.CreateArticle(ctx, a); err != nil {
+		return err
+	}
+	r.invalidate(ctx, a)
+	return nil
+}
+
+// UpdateArticle invalida también el slug anterior si cambió
+func (r *CachedRepository) UpdateArticle(ctx context.Context, f Filter, a *Article) error {
+	before, _ := r.Repository.FindArticle(ctx, Filter{ID: a.ID, System: true})
+	if err := r.Repository.UpdateArticle(ctx, f, a); err != nil {
+		return err
+	}
+	if before != nil {
+		r.invalidate(ctx, before, a)
+	} else {
+		r.invalidate(ctx, a)
+	}
+	return nil
+}
+
+func (r *CachedRepository) SetFields(ctx context.Context, f Filter, p Patch) (*Article, error) {
+	before, _ := r.Repository.FindArticle(ctx, Filter{ID: f.ID, System: true})
+	after, err := r.Repository.SetFields(ctx, f, p)
+	if err != nil {
+		return nil, err
+	}
+	if before != nil {
+		r.invalidate(ctx, before, after)
+	} else {
+		r.invalidate(ctx, after)
+	}
+	return after, nil
+}
+
+func (r *CachedRepository) DeleteArticle(ctx context.Context, f Filter) error {
+	before, _ := r.Repository.FindArticle(ctx, f)
+	if err := r.Repository.DeleteArticle(ctx, f); err != nil {
+		return err
+	}
+	if before != nil {
+		r.invalidate(ctx, before)
+	}
+	return nil
+}
+
+func (r *CachedRepository) ReplaceTag(ctx context.Context, orgID primitive.ObjectID, from, to string) (int64, error) {
+	n, err := r.Repository.ReplaceTag(ctx, orgID, from, to)
+	if n > 0 {
+		r.invalidateOrg(ctx, orgID)
+	}
+	return n, err
+}
+
+func (r *CachedRepository) RemoveCategory(ctx context.Context, orgID, categoryID primitive.ObjectID) (int64, error) {
+	n, err := r.Repository.RemoveCategory(ctx, orgID, categoryID)
+	if n > 0 {
+		r.invalidateOrg(ctx, orgID)
+	}
+	return n, err
+}
+
+// UpdateAuthor cambia la firma en todos los artículos del autor: se descarta
+// lo cacheado de las organizaciones donde firma
+func (r *CachedRepository) UpdateAuthor(ctx context.Context, author Author) (int64, error) {
+	n, err := r.Repository.UpdateAuthor(ctx, author)
+	if n == 0 {
+		return n, err
+	}
+	found, findErr := r.Repository.FindArticles(ctx, Filter{AuthorID: author.ID, System: true})
+	if findErr != nil {
+		log.Printf("⚠️ Caché: no se pudieron buscar los artículos de %s: %v", author.ID.Hex(), findErr)
+	}
+	orgs := map[primitive.ObjectID]bool{}
+	for i := range found {
+		if !orgs[found[i].OrganizationID] {
+			orgs[found[i].OrganizationID] = true
+			r.invalidateOrg(ctx, found[i].OrganizationID)
+		}
+	}
+	return n, err
+}
+
+// invalidate borra las claves de ID y slug de cada versión del artículo y
+// renueva los listados de su organización
+func (r *CachedRepository) invalidate(ctx context.Context, versions ...*Article) {
+	r.epoch.Add(1)
+	r.invalidations.Add(1)
+	orgs := map[primitive.ObjectID]bool{}
+	for _, a := range versions {
+		if a.OrganizationID.IsZero() {
+			continue
+		}
+		orgs[a.OrganizationID] = true
+		orgGen, err := r.generation(ctx, "org", a.OrganizationID)
+		if err != nil {
+			continue
+		}
+		keys := []string{articleCacheKey(a.OrganizationID, orgGen, "id", a.ID.Hex())}
+		if a.Slug != "" {
+			keys = append(keys, articleCacheKey(a.OrganizationID, orgGen, "slug", a.Slug))
+		}
+		if err := r.Store.Delete(ctx, keys...); err != nil {
+			r.storeError(err)
+		}
+	}
+	for org := range orgs {
+		r.renew(ctx, "list", org)
+	}
+}
+
+// invalidateOrg descarta todo lo cacheado de la organización
+func (r *CachedRepository) invalidateOrg(ctx context.Context, orgID primitive.ObjectID) {
+	r.epoch.Add(1)
+	r.invalidations.Add(1)
+	r.renew(ctx, "org", orgID)
+}
+
+func articleCacheKey(orgID primitive.ObjectID, orgGen, kind, value string) string {
+	return "articles:" + orgID.Hex() + ":" + orgGen + ":" + kind + ":" + value
+}
+
+func generationKey(kind string, orgID primitive.ObjectID) string {
+	return "articles:gen:" + kind + ":" + orgID.Hex()
+}
+
+// generation devuelve la generación vigente; si no hay (o el Store la
+// desalojó) crea una nueva, lo que equivale a invalidar
+func (r *CachedRepository) generation(ctx context.Context, kind string, orgID primitive.ObjectID) (string, error) {
+	v, ok, err := r.Store.Get(ctx, generationKey(kind, orgID))
+	if err != nil {
+		r.storeError(err)
+		return "", err
+	}
+	if ok {
+		return string(v), nil
+	}
+	return r.renew(ctx, kind, orgID)
+}
+
+func (r *CachedRepository) renew(ctx context.Context, kind string, orgID primitive.ObjectID) (string, error) {
+	b := make([]byte, 6)
+	rand.Read(b)
+	gen := hex.EncodeToString(b)
+	if err := r.Store.Set(ctx, generationKey(kind, orgID), []byte(gen), 0); err != nil {
+		r.storeError(err)
+		return "", err
+	}
+	return gen, nil
+}
+
+func (r *CachedRepository) get(ctx context.Context, key string, out interface{}) bool {
+	data, ok, err := r.Store.Get(ctx, key)
+	if err != nil {
+		r.storeError(err)
+		return false
+	}
+	return ok && bson.Unmarshal(data, out) == nil
+}
+
+func (r *CachedRepository) set(ctx context.Context, key string, v interface{}, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	data, err := bson.Marshal(v)
+	if err == nil {
+		err = r.Store.Set(ctx, key, data, ttl)
+	}
+	if err != nil {
+		r.storeError(err)
+	}
+}
+
+// ttlFor acorta el TTL hasta el próximo publish_at o unpublish_at de los
+// artículos, el que llegue antes: a esa hora cambia lo visible sin que pase
+// ninguna escritura que invalide
+func (r *CachedRepository) ttlFor(items []Article) time.Duration {
+	ttl := r.TTL
+	now := time.Now()
+	for i := range items {
+		for _, at := range []*time.Time{items[i].PublishAt, items[i].UnpublishAt} {
+			if at != nil && at.After(now) && at.Sub(now) < ttl {
+				ttl = at.Sub(now)
+			}
+		}
+	}
+	return ttl
+}
+
+// listTTL es el TTL de una página: además de lo que trae, cuentan los
+// artículos programados de la organización, que no están en la página pero
+// entran a su hora. Si no se pueden leer, la página no se guarda.
+func (r *CachedRepository) listTTL(ctx context.Context, orgID primitive.ObjectID, items []Article) (time.Duration, bool) {
+	now := time.Now()
+	scheduled, err := r.Repository.FindArticles(ctx, Filter{OrganizationID: orgID, ScheduledAfter: &now})
+	if err != nil {
+		r.loadErrors.Add(1)
+		return 0, false
+	}
+	return r.ttlFor(append(scheduled, items...)), true
+}
+
+func (r *CachedRepository) storeError(err error) {
+	r.storeErrors.Add(1)
+	log.Printf("⚠️ Caché de artículos: %v", err)
+}
+
+// Stats devuelve las métricas acumuladas desde el arranque
+func (r *CachedRepository) Stats() CacheStats {
+	s := CacheStats{
+		Hits:          r.hits.Load(),
+		Misses:        r.misses.Load(),
+		Shared:        r.shared.Load(),
+		LoadErrors:    r.loadErrors.Load(),
+		StoreErrors:   r.storeErrors.Load(),
+		Invalidations: r.invalidations.Load(),
+	}
+	if total := s.Hits + s.Misses; total > 0 {
+		s.HitRatio = float64(s.Hits) / float64(total)
+	}
+	if reporter, ok := r.Store.(cache.StatsReporter); ok {
+		stats := reporter.Stats()
+		s.Store = &stats
+	}
+	return s
+}
+
+// 📊 Métricas del caché de lecturas (solo superadmin)
+func CacheStatsHandler(c *CachedRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(c.Stats())
+	}
+}
@@ -0,0 +1,231 @@
+package articles
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"pittsix/pkg/cache"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// countingRepo cuenta las lecturas que llegan a la base
+type countingRepo struct {
+	Repository
+	finds, lists atomic.Int32
+	delay        time.Duration
+}
+
+func (r *countingRepo) FindArticle(ctx context.Context, f Filter) (*Article, error) {
+	if f.VisibleAt != nil {
+		r.finds.Add(1)
+		time.Sleep(r.delay)
+	}
+	return r.Repository.FindArticle(ctx, f)
+}
+
+func (r *countingRepo) ListArticles(ctx context.Context, f Filter, q ListQuery) ([]Article, int64, error) {
+	r.lists.Add(1)
+	return r.Repository.ListArticles(ctx, f, q)
+}
+
+func newCachedEnv() (*testEnv, *countingRepo, *CachedRepository) {
+	e := newTestEnv()
+	inner := &countingRepo{Repository: e.h.Repo}
+	cached := NewCachedRepository(inner, cache.NewLRU(100), time.Minute)
+	e.h.Repo = cached
+	return e, inner, cached
+}
+
+func TestCachedRepository_ReadsAndInvalidation(t *testing.T) {
+	e, inner, cached := newCachedEnv()
+	author := e.newUser(e.orgA)
+	a := e.published(t, author, "Cacheado")
+	id := a.ID.Hex()
+	byID := func() Article {
+		w := serve(e.h.GetArticleByID, request("GET", "/articles/"+id, "", primitive.NilObjectID, nil), "id", id)
+		var got Article
+		json.NewDecoder(w.Body).Decode(&got)
+		return got
+	}
+	bySlug := func(slug string) int {
+		return serve(e.h.GetArticleBySlug, request("GET", "/articles/slug/"+slug, "", primitive.NilObjectID, nil), "slug", slug).Code
+	}
+
+	byID()
+	first := byID()
+	if inner.finds.Load() != 1 || cached.Stats().Hits != 1 {
+		t.Fatalf("second read must be a hit: finds=%d %+v", inner.finds.Load(), cached.Stats())
+	}
+	if first.Title != "Cacheado" || first.Content == "" {
+		t.Errorf("cached article: %+v", first)
+	}
+
+	// Editar (con cambio de slug) invalida el ID y el slug viejo
+	w := serve(e.h.UpdateArticle, request("PUT", "/articles/"+id, `{"title":"Cacheado","slug":"nuevo","content":"otro texto"}`, author, e.orgA, "org_admin"), "id", id)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: %d %s", w.Code, w.Body.String())
+	}
+	if got := byID(); got.Slug != "nuevo" || got.Content != "<p>otro texto</p>" && got.Content != "otro texto" {
+		t.Errorf("stale read after update: %+v", got)
+	}
+	if code := bySlug(a.Slug); code != http.StatusMovedPermanently {
+		t.Errorf("old slug must redirect after the change, got %d", code)
+	}
+	if code := bySlug("nuevo"); code != http.StatusOK {
+		t.Errorf("new slug: %d", code)
+	}
+
+	// Archivar lo saca de la lectura pública
+	e.transition(t, a.ID, "archive", author, e.orgA)
+	if code := serve(e.h.GetArticleByID, request("GET", "/articles/"+id, "", primitive.NilObjectID, nil), "id", id).Code; code != http.StatusNotFound {
+		t.Errorf("archived articles must not be served from the cache, got %d", code)
+	}
+	if cached.Stats().Invalidations == 0 {
+		t.Error("invalidations are counted")
+	}
+}
+
+func TestCachedRepository_Lists(t *testing.T) {
+	e, inner, _ := newCachedEnv()
+	author := e.newUser(e.orgA)
+	e.published(t, author, "Uno")
+	list := func(target string) ArticlePage {
+		var page ArticlePage
+		json.NewDecoder(serve(e.h.ListArticles, request("GET", target, "", primitive.NilObjectID, nil)).Body).Decode(&page)
+		return page
+	}
+	list("/articles")
+	list("/articles")
+	if inner.lists.Load() != 1 {
+		t.Errorf("repeated list must be cached, hit the repo %d times", inner.lists.Load())
+	}
+	list("/articles?limit=1")
+	if inner.lists.Load() != 2 {
+		t.Error("each query has its own entry")
+	}
+
+	e.published(t, author, "Dos")
+	if page := list("/articles"); page.Total != 2 {
+		t.Errorf("publishing invalidates the lists: %+v", page)
+	}
+
+	// Un cambio masivo que no tocó artículos conserva el caché
+	ctx := context.Background()
+	before := inner.lists.Load()
+	e.h.Repo.ReplaceTag(ctx, e.orgA.ID, "no-existe", "otra")
+	list("/articles")
+	if inner.lists.Load() != before {
+		t.Error("a bulk change that touched nothing keeps the cache")
+	}
+	// Renombrar una etiqueta en uso descarta toda la organización
+	tagged := e.published(t, author, "Etiquetado")
+	tagged.Tags = []string{"go"}
+	inner.Repository.UpdateArticle(ctx, Filter{System: true}, &tagged)
+	list("/articles")
+	before = inner.lists.Load()
+	e.h.Repo.ReplaceTag(ctx, e.orgA.ID, "go", "golang")
+	list("/articles")
+	if inner.lists.Load() != before+1 {
+		t.Error("renaming a used tag invalidates the organization")
+	}
+}
+
+// ttlStore anota el TTL con que se guarda cada clave
+type ttlStore struct {
+	cache.Store
+	mu   sync.Mutex
+	ttls map[string]time.Duration
+}
+
+func (s *ttlStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	s.ttls[key] = ttl
+	s.mu.Unlock()
+	return s.Store.Set(ctx, key, value, ttl)
+}
+
+func TestCachedRepository_ListTTLStopsAtNextSchedule(t *testing.T) {
+	e := newTestEnv()
+	store := &ttlStore{Store: cache.NewLRU(100), ttls: map[string]time.Duration{}}
+	e.h.Repo = NewCachedRepository(e.h.Repo, store, time.Hour)
+	author := e.newUser(e.orgA)
+	e.published(t, author, "Ya publicado")
+	// Publicado con publish_at por llegar: a esa hora cambia lo visible
+	soon := time.Now().Add(10 * time.Minute)
+	later := time.Now().Add(30 * time.Minute)
+	err := e.h.Repo.CreateArticle(context.Background(), &Article{
+		OrganizationID: e.orgA.ID, Title: "Programado", Slug: "programado",
+		Status: StatusPublished, PublishAt: &soon, UnpublishAt: &later,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if w := serve(e.h.ListArticles, request("GET", "/articles", "", primitive.NilObjectID, nil)); w.Code != http.StatusOK {
+		t.Fatalf("list: %d %s", w.Code, w.Body.String())
+	}
+	var listTTL time.Duration
+	for key, ttl := range store.ttls {
+		if strings.Contains(key, ":list:") && !strings.HasPrefix(key, "articles:gen:") {
+			listTTL = ttl
+		}
+	}
+	if listTTL <= 0 || listTTL > 10*time.Minute {
+		t.Errorf("the list must expire when the scheduled article goes live, got %s", listTTL)
+	}
+}
+
+func TestCachedRepository_Stampede(t *testing.T) {
+	e, inner, cached := newCachedEnv()
+	author := e.newUser(e.orgA)
+	a := e.published(t, author, "Popular")
+	inner.delay = 50 * time.Millisecond
+	now := time.Now()
+	f := Filter{OrganizationID: e.orgA.ID, ID: a.ID, VisibleAt: &now}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got, err := cached.FindArticle(context.Background(), f); err != nil || got.ID != a.ID {
+				t.Errorf("concurrent read: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := inner.finds.Load(); n != 1 {
+		t.Errorf("concurrent misses must load once, loaded %d times", n)
+	}
+	if s := cached.Stats(); s.Shared == 0 || s.Store == nil || s.Store.Entries == 0 {
+		t.Errorf("stats: %+v", s)
+	}
+}
+
+func TestPublicLookup(t *testing.T) {
+	now := time.Now()
+	org, id := primitive.NewObjectID(), primitive.NewObjectID()
+	cases := []struct {
+		f    Filter
+		kind string
+	}{
+		{Filter{OrganizationID: org, ID: id, VisibleAt: &now}, "id"},
+		{Filter{OrganizationID: org, Slug: "hola", VisibleAt: &now}, "slug"},
+		{Filter{OrganizationID: org, ID: id}, ""},
+		{Filter{ID: id, VisibleAt: &now}, ""},
+		{Filter{OrganizationID: org, ID: id, VisibleAt: &now, AuthorID: id}, ""},
+		{Filter{OrganizationID: org, FormerSlug: "hola", VisibleAt: &now}, ""},
+	}
+	for _, c := range cases {
+		if kind, _, _ := publicLookup(c.f); kind != c.kind {
+			t.Errorf("%+v: got %q, want %q", c.f, kind, c.kind)
+		}
+	}
+}
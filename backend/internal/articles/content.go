@@ -0,0 +1,91 @@
+package articles
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// Formatos del cuerpo de un artículo. En html y markdown, Content guarda la
+// fuente que escribió el autor; en blocks, la fuente son los Blocks y Content
+// su render.
+const (
+	FormatHTML     = "html"
+	FormatMarkdown = "markdown"
+	FormatBlocks   = "blocks"
+)
+
+// renderCacheSize es la cantidad de revisiones renderizadas que se guardan en
+// memoria. Cada edición sube la revisión, así que una entrada nunca queda
+// vieja: solo deja de usarse y el LRU la descarta.
+const renderCacheSize = 2048
+
+var errContentFormat = errors.New("content_format must be html, markdown or blocks")
+
+// contentFormat devuelve el formato del artículo; los anteriores al campo se infieren
+func contentFormat(a *Article) string {
+	if a.ContentFormat != "" {
+		return a.ContentFormat
+	}
+	if len(a.Blocks) > 0 {
+		return FormatBlocks
+	}
+	return FormatHTML
+}
+
+// renderArticleHTML arma el HTML público del artículo a partir de su fuente
+func renderArticleHTML(a *Article) string {
+	switch contentFormat(a) {
+	case FormatMarkdown:
+		return RenderMarkdownHTML(a.Content)
+	case FormatBlocks:
+		return RenderHTML(a.Blocks)
+	}
+	return SanitizeHTML(a.Content)
+}
+
+func renderKey(a *Article) string {
+	return "rendered:" + a.ID.Hex() + ":" + strconv.Itoa(currentRevision(a))
+}
+
+// renderedHTML es renderArticleHTML pasando por el caché (solo el markdown se
+// renderiza en lectura; html y blocks ya están listos en Content)
+func (h *Handlers) renderedHTML(a *Article) string {
+	if contentFormat(a) != FormatMarkdown || h.Rendered == nil || a.ID.IsZero() {
+		return renderArticleHTML(a)
+	}
+	// El LRU no usa el contexto ni falla
+	key := renderKey(a)
+	if cached, ok, _ := h.Rendered.Get(context.Background(), key); ok {
+		return string(cached)
+	}
+	out := renderArticleHTML(a)
+	h.Rendered.Set(context.Background(), key, []byte(out), 0)
+	return out
+}
+
+// publicPresenter arma el presentador de las lecturas públicas según
+// ?content=html (por defecto, el HTML renderizado) o ?content=source (la
+// fuente tal como se escribió)
+func (h *Handlers) publicPresenter(r *http.Request) (func(*Article), error) {
+	mode := r.URL.Query().Get("content")
+	switch mode {
+	case "", "html", "source":
+	default:
+		return nil, errors.New("content must be html or source")
+	}
+	return func(a *Article) {
+		presentPublic(a)
+		format := contentFormat(a)
+		a.ContentFormat = format
+		body := a.Content
+		if format == FormatMarkdown {
+			body = h.renderedHTML(a)
+		}
+		deriveText(a, body)
+		if mode != "source" {
+			a.Content = body
+		}
+	}, nil
+}
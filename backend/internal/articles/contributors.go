@@ -0,0 +1,392 @@
+package articles
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"pittsix/internal/users"
+	"pittsix/pkg/events"
+	"pittsix/pkg/middleware"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 👥 Coautoría: además del autor (owner) un artículo puede tener colaboradores
+// que lo editan (editor) o solo lo leen mientras es borrador (viewer).
+// org_admin, superadmin y los permisos articles:edit / articles:delete pasan
+// por encima de los roles del artículo.
+
+const (
+	RoleOwner  = "owner"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+var (
+	errContributorRole = errors.New("role must be editor or viewer")
+	errContributorUser = errors.New("User not found in the article's organization")
+	errAlreadyOwner    = errors.New("User already owns the article")
+)
+
+// Contributor es un coautor del artículo
+type Contributor struct {
+	UserID  primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Role    string             `bson:"role" json:"role"`
+	AddedBy primitive.ObjectID `bson:"added_by,omitempty" json:"added_by,omitempty"`
+	AddedAt time.Time          `bson:"added_at" json:"added_at"`
+}
+
+// articleRole devuelve el rol del usuario en el artículo ("" si no participa)
+func articleRole(a *Article, userID primitive.ObjectID) string {
+	if userID.IsZero() {
+		return ""
+	}
+	if a.AuthorID == userID {
+		return RoleOwner
+	}
+	for _, c := range a.Contributors {
+		if c.UserID == userID {
+			return c.Role
+		}
+	}
+	return ""
+}
+
+func isAdmin(ctx context.Context) bool {
+	return middleware.HasRole(ctx, "superadmin", "org_admin")
+}
+
+// canEdit permite modificar el artículo al owner, a los editores y a quien
+// tenga articles:edit
+func canEdit(ctx context.Context, a *Article, userID primitive.ObjectID) bool {
+	if isAdmin(ctx) || middleware.HasPermission(ctx, "articles:edit") {
+		return true
+	}
+	role := articleRole(a, userID)
+	return role == RoleOwner || role == RoleEditor
+}
+
+// canDelete reserva el borrado al owner y a quien tenga articles:delete
+func canDelete(ctx context.Context, a *Article, userID primitive.ObjectID) bool {
+	if isAdmin(ctx) || middleware.HasPermission(ctx, "articles:delete") {
+		return true
+	}
+	return articleRole(a, userID) == RoleOwner
+}
+
+// canManageContributors: el owner y los admins eligen colaboradores y traspasan el artículo
+func canManageContributors(ctx context.Context, a *Article, userID primitive.ObjectID) bool {
+	return isAdmin(ctx) || articleRole(a, userID) == RoleOwner
+}
+
+// accessCheck decide si el usuario puede operar sobre el artículo
+type accessCheck func(ctx context.Context, a *Article, userID primitive.ObjectID) bool
+
+// findArticleFor busca el artículo del path en la organización del JWT y
+// verifica el acceso; sin acceso responde igual que si no existiera
+func (h *Handlers) findArticleFor(ctx context.Context, r *http.Request, allowed accessCheck) (*Article, primitive.ObjectID, int, error) {
+	objID, err := primitive.ObjectIDFromHex(r.PathValue("id"))
+	if err != nil {
+		return nil, primitive.NilObjectID, http.StatusBadRequest, errors.New("Invalid ID")
+	}
+	userIDStr, ok := ctx.Value("user_id").(string)
+	if !ok {
+		return nil, primitive.NilObjectID, http.StatusUnauthorized, errors.New("Unauthorized")
+	}
+	userID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		return nil, primitive.NilObjectID, http.StatusBadRequest, errors.New("Invalid user id")
+	}
+	tenant, err := tenantFromRequest(r)
+	if err != nil {
+		return nil, primitive.NilObjectID, http.StatusForbidden, err
+	}
+	article, err := h.Repo.FindArticle(ctx, tenant.scope(Filter{ID: objID}))
+	if err != nil || !allowed(ctx, article, userID) {
+		return nil, primitive.NilObjectID, http.StatusForbidden, errors.New("Not authorized or not found")
+	}
+	return article, userID, http.StatusOK, nil
+}
+
+// orgMember devuelve el usuario si existe y pertenece a la organización
+func (h *Handlers) orgMember(id, orgID primitive.ObjectID) (*users.User, error) {
+	user, err := h.Users.GetUserByID(id)
+	if err != nil || user == nil || user.OrganizationID != orgID {
+		return nil, errContributorUser
+	}
+	return user, nil
+}
+
+// withoutContributor quita al usuario de la lista de colaboradores
+func withoutContributor(list []Contributor, userID primitive.ObjectID) []Contributor {
+	kept := []Contributor{}
+	for _, c := range list {
+		if c.UserID != userID {
+			kept = append(kept, c)
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return kept
+}
+
+func contributorErrorStatus(err error) int {
+	switch err {
+	case errContributorRole:
+		return http.StatusBadRequest
+	case errContributorUser:
+		return http.StatusUnprocessableEntity
+	case errAlreadyOwner:
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// saveMembership guarda los cambios de autor o colaboradores si nadie más
+// cambió el owner antes. Solo toca esos campos: una edición del contenido que
+// entre en el medio se conserva, y updated queda con el artículo guardado.
+func (h *Handlers) saveMembership(ctx context.Context, current, updated *Article) error {
+	now := time.Now()
+	patch := Patch{Contributors: &updated.Contributors, UpdatedAt: &now}
+	if author := byline(updated); author != byline(current) {
+		patch.Author = &author
+	}
+	filter := Filter{ID: current.ID, OrganizationID: current.OrganizationID, AuthorID: current.AuthorID}
+	saved, err := h.Repo.SetFields(ctx, filter, patch)
+	if err != nil {
+		return err
+	}
+	*updated = *saved
+	h.publish(ctx, events.ArticleUpdated, updated)
+	return nil
+}
+
+// writeMembershipError distingue un cambio concurrente de un error de la base
+func writeMembershipError(w http.ResponseWriter, err error) {
+	if err == ErrNotFound {
+		http.Error(w, "Article changed, try again", http.StatusConflict)
+		return
+	}
+	log.Println(err.Error())
+	http.Error(w, "DB error", http.StatusInternalServerError)
+}
+
+// 👥 Listar autor y colaboradores (requiere JWT y participar del artículo)
+func (h *Handlers) ListContributors(w http.ResponseWriter, r *http.Request) {
+	article, _, code, err := h.findArticleFor(r.Context(), r, canReview)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	list := append([]Contributor{{UserID: article.AuthorID, Role: RoleOwner, AddedAt: article.CreatedAt}}, article.Contributors...)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// 👥 Agregar o cambiar un colaborador: PUT /articles/{id}/contributors/{user}
+// con {"role": "editor" | "viewer"} (owner o admin)
+func (h *Handlers) PutContributor(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	article, userID, code, err := h.findArticleFor(ctx, r, canManageContributors)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	memberID, err := primitive.ObjectIDFromHex(r.PathValue("user"))
+	if err != nil {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		return
+	}
+	var input struct {
+		Role string `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid body", http.StatusBadRequest)
+		return
+	}
+	if input.Role != RoleEditor && input.Role != RoleViewer {
+		http.Error(w, errContributorRole.Error(), http.StatusBadRequest)
+		return
+	}
+	if memberID == article.AuthorID {
+		http.Error(w, errAlreadyOwner.Error(), http.StatusConflict)
+		return
+	}
+	if _, err := h.orgMember(memberID, article.OrganizationID); err != nil {
+		http.Error(w, err.Error(), contributorErrorStatus(err))
+		return
+	}
+
+	// Cambiar el rol conserva quién y cuándo lo sumó
+	member := Contributor{UserID: memberID, AddedBy: userID, AddedAt: time.Now()}
+	for _, c := range article.Contributors {
+		if c.UserID == memberID {
+			member = c
+		}
+	}
+	member.Role = input.Role
+	updated := *article
+	updated.Contributors = append(withoutContributor(article.Contributors, memberID), member)
+	if err := h.saveMembership(ctx, article, &updated); err != nil {
+		writeMembershipError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated.Contributors)
+}
+
+// 👥 Quitar un colaborador (owner o admin; cualquiera puede quitarse a sí mismo)
+func (h *Handlers) DeleteContributor(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	memberID, err := primitive.ObjectIDFromHex(r.PathValue("user"))
+	if err != nil {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		return
+	}
+	article, _, code, err := h.findArticleFor(ctx, r, func(ctx context.Context, a *Article, userID primitive.ObjectID) bool {
+		return userID == memberID || canManageContributors(ctx, a, userID)
+	})
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	if memberID == article.AuthorID {
+		http.Error(w, "The owner can't be removed; transfer the article first", http.StatusConflict)
+		return
+	}
+	if articleRole(article, memberID) == "" {
+		http.Error(w, "Contributor not found", http.StatusNotFound)
+		return
+	}
+	updated := *article
+	updated.Contributors = withoutContributor(article.Contributors, memberID)
+	if err := h.saveMembership(ctx, article, &updated); err != nil {
+		writeMembershipError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "removed"})
+}
+
+// 🔑 Traspasar el artículo a otro usuario de la organización (owner o admin).
+// Body: {"user_id": "...", "keep_as": "editor" | "viewer" | "none"}; por
+// defecto el owner anterior queda como editor.
+func (h *Handlers) TransferOwnership(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	article, _, code, err := h.findArticleFor(ctx, r, canManageContributors)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	var input struct {
+		UserID primitive.ObjectID `json:"user_id"`
+		KeepAs string             `json:"keep_as"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil || input.UserID.IsZero() {
+		http.Error(w, "Invalid body", http.StatusBadRequest)
+		return
+	}
+	if input.KeepAs == "" {
+		input.KeepAs = RoleEditor
+	}
+	if input.KeepAs != RoleEditor && input.KeepAs != RoleViewer && input.KeepAs != "none" {
+		http.Error(w, "keep_as must be editor, viewer or none", http.StatusBadRequest)
+		return
+	}
+	if input.UserID == article.AuthorID {
+		http.Error(w, errAlreadyOwner.Error(), http.StatusConflict)
+		return
+	}
+	owner, err := h.orgMember(input.UserID, article.OrganizationID)
+	if err != nil {
+		http.Error(w, err.Error(), contributorErrorStatus(err))
+		return
+	}
+
+	updated := *article
+	transferTo(&updated, owner)
+	if input.KeepAs != "none" {
+		updated.Contributors = append(updated.Contributors, Contributor{
+			UserID:  article.AuthorID,
+			Role:    input.KeepAs,
+			AddedBy: owner.ID,
+			AddedAt: time.Now(),
+		})
+	}
+	if err := h.saveMembership(ctx, article, &updated); err != nil {
+		writeMembershipError(w, err)
+		return
+	}
+	h.reindex(ctx, article.ID)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+// transferTo deja al usuario como owner y lo saca de los colaboradores
+func transferTo(a *Article, owner *users.User) {
+	a.AuthorID = owner.ID
+	setByline(a, authorOf(owner))
+	a.Contributors = withoutContributor(a.Contributors, owner.ID)
+}
+
+// ReassignAuthor se engancha en el borrado de usuarios: saca al usuario de los
+// colaboradores y traspasa sus artículos al sucesor indicado o, si no hay, al
+// primer editor. Sin sucesor ni editores el artículo conserva la firma y
+// queda a cargo de los admins de la organización. Los usuarios sin
+// organización (los de /auth/register) no pueden tener artículos.
+func (h *Handlers) ReassignAuthor(ctx context.Context, deleted *users.User, successor primitive.ObjectID) error {
+	if deleted.OrganizationID.IsZero() {
+		return nil
+	}
+	var heir *users.User
+	if !successor.IsZero() {
+		u, err := h.orgMember(successor, deleted.OrganizationID)
+		if err != nil {
+			return err
+		}
+		heir = u
+	}
+	found, err := h.Repo.FindArticles(ctx, Filter{OrganizationID: deleted.OrganizationID, MemberID: deleted.ID})
+	if err != nil {
+		return err
+	}
+	for i := range found {
+		current := &found[i]
+		updated := *current
+		updated.Contributors = withoutContributor(current.Contributors, deleted.ID)
+		if current.AuthorID == deleted.ID {
+			if next := h.nextOwner(&updated, heir); next != nil {
+				transferTo(&updated, next)
+			}
+		}
+		if err := h.saveMembership(ctx, current, &updated); err != nil {
+			return err
+		}
+		h.reindex(ctx, current.ID)
+	}
+	if len(found) > 0 {
+		log.Printf("👥 %d artículos reasignados tras borrar al usuario %s", len(found), deleted.ID.Hex())
+	}
+	return nil
+}
+
+// nextOwner elige quién hereda un artículo cuyo owner se borra
+func (h *Handlers) nextOwner(a *Article, heir *users.User) *users.User {
+	if heir != nil {
+		return heir
+	}
+	for _, c := range a.Contributors {
+		if c.Role != RoleEditor {
+			continue
+		}
+		if u, err := h.orgMember(c.UserID, a.OrganizationID); err == nil {
+			return u
+		}
+	}
+	return nil
+}
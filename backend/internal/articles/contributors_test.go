@@ -0,0 +1,233 @@
+package articles
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"pittsix/internal/organizations"
+	"pittsix/internal/users"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (e *testEnv) addContributor(t *testing.T, a Article, by, member primitive.ObjectID, role string) {
+	t.Helper()
+	r := request("PUT", "/articles/"+a.ID.Hex()+"/contributors/"+member.Hex(), `{"role":"`+role+`"}`, by, e.orgA)
+	if w := serve(e.h.PutContributor, r, "id", a.ID.Hex(), "user", member.Hex()); w.Code != http.StatusOK {
+		t.Fatalf("add contributor: expected 200, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func (e *testEnv) edit(a Article, user primitive.ObjectID, org *organizations.Organization, roles ...string) int {
+	r := request("PUT", "/articles/"+a.ID.Hex(), `{"title":"`+a.Title+`","content":"editado"}`, user, org, roles...)
+	return serve(e.h.UpdateArticle, r, "id", a.ID.Hex()).Code
+}
+
+func withPermissions(r *http.Request, perms ...string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), "permissions", perms))
+}
+
+func TestHandlers_ContributorRoles(t *testing.T) {
+	e := newTestEnv()
+	owner, editor, viewer, stranger := e.newUser(e.orgA), e.newUser(e.orgA), e.newUser(e.orgA), e.newUser(e.orgA)
+	a := e.create(t, owner, e.orgA, "Entre varios")
+	e.addContributor(t, a, owner, editor, RoleEditor)
+	e.addContributor(t, a, owner, viewer, RoleViewer)
+
+	r := request("PUT", "/articles/"+a.ID.Hex()+"/contributors/"+stranger.Hex(), `{"role":"editor"}`, editor, e.orgA)
+	if w := serve(e.h.PutContributor, r, "id", a.ID.Hex(), "user", stranger.Hex()); w.Code != http.StatusForbidden {
+		t.Errorf("editors can't add contributors, got %d", w.Code)
+	}
+	r = request("PUT", "/articles/"+a.ID.Hex()+"/contributors/"+owner.Hex(), `{"role":"viewer"}`, owner, e.orgA)
+	if w := serve(e.h.PutContributor, r, "id", a.ID.Hex(), "user", owner.Hex()); w.Code != http.StatusConflict {
+		t.Errorf("the owner can't become a contributor, got %d", w.Code)
+	}
+	outsider := e.newUser(e.orgB)
+	r = request("PUT", "/articles/"+a.ID.Hex()+"/contributors/"+outsider.Hex(), `{"role":"viewer"}`, owner, e.orgA)
+	if w := serve(e.h.PutContributor, r, "id", a.ID.Hex(), "user", outsider.Hex()); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("contributors must belong to the organization, got %d", w.Code)
+	}
+
+	if code := e.edit(a, editor, e.orgA); code != http.StatusOK {
+		t.Errorf("editors can edit, got %d", code)
+	}
+	for name, user := range map[string]primitive.ObjectID{"viewer": viewer, "stranger": stranger} {
+		if code := e.edit(a, user, e.orgA); code != http.StatusForbidden {
+			t.Errorf("%s must not edit, got %d", name, code)
+		}
+	}
+	if w := serve(e.h.ListRevisions, request("GET", "/", "", viewer, e.orgA), "id", a.ID.Hex()); w.Code != http.StatusOK {
+		t.Errorf("viewers can read the history, got %d", w.Code)
+	}
+	r = withPermissions(request("PUT", "/articles/"+a.ID.Hex(), `{"title":"Entre varios","content":"x"}`, stranger, e.orgA), "articles:edit")
+	if w := serve(e.h.UpdateArticle, r, "id", a.ID.Hex()); w.Code != http.StatusOK {
+		t.Errorf("articles:edit overrides the article roles, got %d", w.Code)
+	}
+
+	w := serve(e.h.GetMyArticles, request("GET", "/my-articles", "", viewer, e.orgA))
+	var page struct {
+		Items []Article `json:"items"`
+	}
+	json.NewDecoder(w.Body).Decode(&page)
+	if len(page.Items) != 1 || page.Items[0].ID != a.ID {
+		t.Errorf("co-authored articles belong in /my-articles: %s", w.Body.String())
+	}
+
+	del := func(user primitive.ObjectID, roles ...string) int {
+		r := request("DELETE", "/articles/"+a.ID.Hex(), "", user, e.orgA, roles...)
+		return serve(e.h.DeleteArticle, r, "id", a.ID.Hex()).Code
+	}
+	if code := del(editor); code != http.StatusForbidden {
+		t.Errorf("only the owner deletes, got %d", code)
+	}
+	if code := del(stranger, "org_admin"); code != http.StatusOK {
+		t.Errorf("org_admin overrides the article roles, got %d", code)
+	}
+}
+
+func TestHandlers_RemoveContributor(t *testing.T) {
+	e := newTestEnv()
+	owner, editor, viewer := e.newUser(e.orgA), e.newUser(e.orgA), e.newUser(e.orgA)
+	a := e.create(t, owner, e.orgA, "Salida")
+	e.addContributor(t, a, owner, editor, RoleEditor)
+	e.addContributor(t, a, owner, viewer, RoleViewer)
+
+	remove := func(by, member primitive.ObjectID) int {
+		r := request("DELETE", "/", "", by, e.orgA)
+		return serve(e.h.DeleteContributor, r, "id", a.ID.Hex(), "user", member.Hex()).Code
+	}
+	if code := remove(viewer, editor); code != http.StatusForbidden {
+		t.Errorf("viewers can't remove others, got %d", code)
+	}
+	if code := remove(viewer, viewer); code != http.StatusOK {
+		t.Errorf("anyone can leave, got %d", code)
+	}
+	if code := remove(owner, owner); code != http.StatusConflict {
+		t.Errorf("the owner can't leave without a transfer, got %d", code)
+	}
+
+	w := serve(e.h.ListContributors, request("GET", "/", "", editor, e.orgA), "id", a.ID.Hex())
+	var list []Contributor
+	json.NewDecoder(w.Body).Decode(&list)
+	if len(list) != 2 || list[0].UserID != owner || list[0].Role != RoleOwner || list[1].UserID != editor {
+		t.Errorf("unexpected contributors: %+v", list)
+	}
+}
+
+func TestHandlers_TransferOwnership(t *testing.T) {
+	e := newTestEnv()
+	owner, editor := e.newUser(e.orgA), e.newUser(e.orgA)
+	e.users.byID[editor].FirstName = "Beto"
+	a := e.create(t, owner, e.orgA, "Traspaso")
+	e.addContributor(t, a, owner, editor, RoleEditor)
+
+	transfer := func(by primitive.ObjectID, body string) (int, Article) {
+		r := request("POST", "/articles/"+a.ID.Hex()+"/transfer", body, by, e.orgA)
+		w := serve(e.h.TransferOwnership, r, "id", a.ID.Hex())
+		var got Article
+		json.NewDecoder(w.Body).Decode(&got)
+		return w.Code, got
+	}
+	if code, _ := transfer(editor, `{"user_id":"`+editor.Hex()+`"}`); code != http.StatusForbidden {
+		t.Errorf("editors can't take the article, got %d", code)
+	}
+	if code, _ := transfer(owner, `{"user_id":"`+e.newUser(e.orgB).Hex()+`"}`); code != http.StatusUnprocessableEntity {
+		t.Errorf("the new owner must belong to the organization, got %d", code)
+	}
+	code, got := transfer(owner, `{"user_id":"`+editor.Hex()+`","keep_as":"viewer"}`)
+	if code != http.StatusOK {
+		t.Fatalf("transfer: expected 200, got %d", code)
+	}
+	if got.AuthorID != editor || got.AuthorName != "Beto Pérez" {
+		t.Errorf("unexpected owner: %s %q", got.AuthorID.Hex(), got.AuthorName)
+	}
+	if len(got.Contributors) != 1 || got.Contributors[0].UserID != owner || got.Contributors[0].Role != RoleViewer {
+		t.Errorf("the previous owner should stay as viewer: %+v", got.Contributors)
+	}
+	if code := e.edit(a, owner, e.orgA); code != http.StatusForbidden {
+		t.Errorf("the previous owner is now a viewer, got %d", code)
+	}
+}
+
+func TestReassignAuthor(t *testing.T) {
+	e := newTestEnv()
+	ctx := context.Background()
+	gone, editor, viewer, heir := e.newUser(e.orgA), e.newUser(e.orgA), e.newUser(e.orgA), e.newUser(e.orgA)
+	withEditor := e.create(t, gone, e.orgA, "Con editor")
+	e.addContributor(t, withEditor, gone, viewer, RoleViewer)
+	e.addContributor(t, withEditor, gone, editor, RoleEditor)
+	alone := e.create(t, gone, e.orgA, "Sin editor")
+	helped := e.create(t, editor, e.orgA, "Ajeno")
+	e.addContributor(t, helped, editor, gone, RoleEditor)
+
+	if err := e.h.ReassignAuthor(ctx, e.users.byID[gone], primitive.NilObjectID); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := e.h.Repo.FindArticle(ctx, Filter{ID: withEditor.ID, System: true})
+	if got.AuthorID != editor || len(got.Contributors) != 1 || got.Contributors[0].UserID != viewer {
+		t.Errorf("the first editor should inherit the article: %+v", got)
+	}
+	got, _ = e.h.Repo.FindArticle(ctx, Filter{ID: alone.ID, System: true})
+	if got.AuthorID != gone || got.AuthorName != "Ana Pérez" {
+		t.Errorf("without editors the byline is kept: %+v", got)
+	}
+	got, _ = e.h.Repo.FindArticle(ctx, Filter{ID: helped.ID, System: true})
+	if got.AuthorID != editor || len(got.Contributors) != 0 {
+		t.Errorf("the deleted user must leave other articles: %+v", got)
+	}
+
+	if err := e.h.ReassignAuthor(ctx, e.users.byID[gone], heir); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ = e.h.Repo.FindArticle(ctx, Filter{ID: alone.ID, System: true}); got.AuthorID != heir {
+		t.Errorf("an explicit successor takes the remaining articles: %+v", got)
+	}
+	if err := e.h.ReassignAuthor(ctx, e.users.byID[gone], e.newUser(e.orgB)); err != errContributorUser {
+		t.Errorf("successors from another organization are rejected, got %v", err)
+	}
+}
+
+func TestSaveMembership_KeepsConcurrentEdit(t *testing.T) {
+	e := newTestEnv()
+	ctx := context.Background()
+	owner, editor := e.newUser(e.orgA), e.newUser(e.orgA)
+	a := e.create(t, owner, e.orgA, "En paralelo")
+	stale, _ := e.h.Repo.FindArticle(ctx, Filter{ID: a.ID, System: true})
+
+	if code := e.edit(a, owner, e.orgA); code != http.StatusOK {
+		t.Fatalf("edit: expected 200, got %d", code)
+	}
+	// El colaborador se suma a partir de una lectura anterior a la edición
+	updated := *stale
+	updated.Contributors = []Contributor{{UserID: editor, Role: RoleEditor, AddedBy: owner}}
+	if err := e.h.saveMembership(ctx, stale, &updated); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := e.h.Repo.FindArticle(ctx, Filter{ID: a.ID, System: true})
+	if got.Content != "editado" || got.Revision != 2 || articleRole(got, editor) != RoleEditor {
+		t.Errorf("adding a contributor must not revert the edit: %+v", got)
+	}
+	if updated.Content != "editado" {
+		t.Errorf("the caller gets the saved article: %+v", updated)
+	}
+}
+
+func TestDeleteUser_WithoutOrganization(t *testing.T) {
+	e := newTestEnv()
+	owner := e.newUser(e.orgA)
+	e.create(t, owner, e.orgA, "De la organización")
+	// Los que se registran solos no tienen organización
+	loner := primitive.NewObjectID()
+	e.users.byID[loner] = &users.User{ID: loner, FirstName: "Sin", LastName: "Org"}
+
+	uh := &users.Handlers{Repo: e.users, OnDelete: []users.DeleteHook{e.h.ReassignAuthor}}
+	w := serve(uh.DeleteUser, request("DELETE", "/users/"+loner.Hex(), "", owner, e.orgA, "superadmin"))
+	if w.Code != http.StatusOK {
+		t.Fatalf("a user without organization can be deleted, got %d %s", w.Code, w.Body.String())
+	}
+	if _, ok := e.users.byID[loner]; ok {
+		t.Error("the user should be gone")
+	}
+}
@@ -0,0 +1,153 @@
+package articles
+
+import "strings"
+
+// FieldChange describe un campo escalar que cambió entre dos revisiones
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// LineOp es una línea del diff de contenido: "equal", "insert" o "delete"
+type LineOp struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// RevisionDiff es la respuesta del endpoint de diff
+type RevisionDiff struct {
+	From    int           `json:"from"`
+	To      int           `json:"to"`
+	Fields  []FieldChange `json:"fields"`
+	Content []LineOp      `json:"content"`
+}
+
+// DiffRevisions compara dos revisiones campo a campo y el contenido línea a línea
+func DiffRevisions(from, to *Revision) RevisionDiff {
+	diff := RevisionDiff{From: from.Number, To: to.Number, Fields: []FieldChange{}}
+	fields := []struct {
+		name     string
+		old, new string
+	}{
+		{"title", from.Title, to.Title},
+		{"image", from.Image, to.Image},
+		{"slug", from.Slug, to.Slug},
+		{"status", from.Status, to.Status},
+	}
+	for _, f := range fields {
+		if f.old != f.new {
+			diff.Fields = append(diff.Fields, FieldChange{Field: f.name, From: f.old, To: f.new})
+		}
+	}
+	diff.Content = diffLines(from.Content, to.Content)
+	return diff
+}
+
+// maxDiffCells acota el trabajo del diff (líneas distintas de un lado por las
+// del otro); más allá, el tramo que cambió se muestra borrado e insertado entero
+const maxDiffCells = 25_000_000
+
+// diffLines calcula un diff por líneas con la subsecuencia común más larga.
+// Usa Hirschberg, que necesita memoria lineal: el contenido no tiene tope.
+func diffLines(a, b string) []LineOp {
+	x := splitLines(a)
+	y := splitLines(b)
+	ops := []LineOp{}
+
+	// Lo común al principio y al final no entra en la cuenta
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix && x[len(x)-1-suffix] == y[len(y)-1-suffix] {
+		suffix++
+	}
+	for _, line := range x[:prefix] {
+		ops = append(ops, LineOp{Op: "equal", Text: line})
+	}
+	mx, my := x[prefix:len(x)-suffix], y[prefix:len(y)-suffix]
+	if len(mx)*len(my) > maxDiffCells {
+		ops = appendOps(ops, "delete", mx)
+		ops = appendOps(ops, "insert", my)
+	} else {
+		ops = hirschberg(ops, mx, my)
+	}
+	for _, line := range x[len(x)-suffix:] {
+		ops = append(ops, LineOp{Op: "equal", Text: line})
+	}
+	return ops
+}
+
+func appendOps(ops []LineOp, op string, lines []string) []LineOp {
+	for _, line := range lines {
+		ops = append(ops, LineOp{Op: op, Text: line})
+	}
+	return ops
+}
+
+// hirschberg parte x por la mitad, busca en y el corte que maximiza la LCS de
+// las dos mitades y resuelve cada lado por separado
+func hirschberg(ops []LineOp, x, y []string) []LineOp {
+	switch {
+	case len(x) == 0:
+		return appendOps(ops, "insert", y)
+	case len(y) == 0:
+		return appendOps(ops, "delete", x)
+	case len(x) == 1:
+		for j := range y {
+			if y[j] == x[0] {
+				ops = appendOps(ops, "insert", y[:j])
+				ops = append(ops, LineOp{Op: "equal", Text: x[0]})
+				return appendOps(ops, "insert", y[j+1:])
+			}
+		}
+		ops = append(ops, LineOp{Op: "delete", Text: x[0]})
+		return appendOps(ops, "insert", y)
+	}
+	mid := len(x) / 2
+	left := lcsRow(x[:mid], y, false)
+	right := lcsRow(x[mid:], y, true)
+	split, best := 0, -1
+	for k := 0; k <= len(y); k++ {
+		if n := left[k] + right[len(y)-k]; n > best {
+			split, best = k, n
+		}
+	}
+	ops = hirschberg(ops, x[:mid], y[:split])
+	return hirschberg(ops, x[mid:], y[split:])
+}
+
+// lcsRow devuelve en row[k] la longitud de la LCS entre x y los primeros k
+// elementos de y; con reverse, entre x y los últimos k
+func lcsRow(x, y []string, reverse bool) []int {
+	at := func(s []string, i int) string {
+		if reverse {
+			return s[len(s)-1-i]
+		}
+		return s[i]
+	}
+	prev := make([]int, len(y)+1)
+	cur := make([]int, len(y)+1)
+	for i := range x {
+		line := at(x, i)
+		for j := 1; j <= len(y); j++ {
+			if line == at(y, j-1) {
+				cur[j] = prev[j-1] + 1
+			} else {
+				cur[j] = max(prev[j], cur[j-1])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.Split(s, "\n")
+}
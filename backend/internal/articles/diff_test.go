@@ -0,0 +1,112 @@
+package articles
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	got := diffLines("a\nb\nc", "a\nx\nc\nd")
+	want := []LineOp{
+		{"equal", "a"},
+		{"delete", "b"},
+		{"insert", "x"},
+		{"equal", "c"},
+		{"insert", "d"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestDiffLines_Empty(t *testing.T) {
+	if got := diffLines("", ""); len(got) != 0 {
+		t.Errorf("expected no ops, got %+v", got)
+	}
+	got := diffLines("", "hola")
+	if len(got) != 1 || got[0].Op != "insert" {
+		t.Errorf("expected single insert, got %+v", got)
+	}
+}
+
+func TestDiffRevisions_Fields(t *testing.T) {
+	from := &Revision{Number: 1, Title: "Viejo", Slug: "viejo", Status: "draft", Content: "uno"}
+	to := &Revision{Number: 2, Title: "Nuevo", Slug: "nuevo", Status: "draft", Content: "uno"}
+	d := DiffRevisions(from, to)
+	if d.From != 1 || d.To != 2 {
+		t.Errorf("unexpected numbers: %+v", d)
+	}
+	if len(d.Fields) != 2 || d.Fields[0].Field != "title" || d.Fields[1].Field != "slug" {
+		t.Errorf("unexpected field changes: %+v", d.Fields)
+	}
+	if len(d.Content) != 1 || d.Content[0].Op != "equal" {
+		t.Errorf("unexpected content diff: %+v", d.Content)
+	}
+}
+
+// lcsLen es la LCS de referencia con la tabla completa
+func lcsLen(x, y []string) int {
+	t := make([][]int, len(x)+1)
+	for i := range t {
+		t[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				t[i][j] = t[i+1][j+1] + 1
+			} else {
+				t[i][j] = max(t[i+1][j], t[i][j+1])
+			}
+		}
+	}
+	return t[0][0]
+}
+
+func TestDiffLines_Minimal(t *testing.T) {
+	seed := uint32(1)
+	random := func(n int) string {
+		lines := make([]string, n)
+		for i := range lines {
+			seed = seed*1664525 + 1013904223
+			lines[i] = string(rune('a' + seed>>29))
+		}
+		return strings.Join(lines, "\n")
+	}
+	for n := 0; n < 200; n++ {
+		a, b := random(n%23), random(n%17)
+		ops := diffLines(a, b)
+		var from, to []string
+		equal := 0
+		for _, op := range ops {
+			if op.Op != "insert" {
+				from = append(from, op.Text)
+			}
+			if op.Op != "delete" {
+				to = append(to, op.Text)
+			}
+			if op.Op == "equal" {
+				equal++
+			}
+		}
+		if strings.Join(from, "\n") != a || strings.Join(to, "\n") != b {
+			t.Fatalf("the diff must rebuild both sides: %q %q %+v", a, b, ops)
+		}
+		if want := lcsLen(splitLines(a), splitLines(b)); equal != want {
+			t.Fatalf("%q → %q: %d equal lines, want %d", a, b, equal, want)
+		}
+	}
+}
+
+func TestDiffLines_Huge(t *testing.T) {
+	var a, b strings.Builder
+	for i := 0; i < 20000; i++ {
+		fmt.Fprintf(&a, "viejo %d\n", i)
+		fmt.Fprintf(&b, "nuevo %d\n", i)
+	}
+	ops := diffLines("título\n"+a.String()+"fin", "título\n"+b.String()+"fin")
+	if len(ops) != 40002 || ops[0].Op != "equal" || ops[1].Op != "delete" || ops[20001].Op != "insert" || ops[40001].Op != "equal" {
+		t.Errorf("past the limit the changed stretch is replaced whole: %d ops", len(ops))
+	}
+}
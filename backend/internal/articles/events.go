@@ -0,0 +1,68 @@
+package articles
+
+import (
+	"context"
+	"time"
+
+	"pittsix/pkg/events"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ArticleSummary es lo que viaja en los eventos de artículos: lo justo para
+// que el dashboard actualice la fila sin volver a pedir el artículo
+type ArticleSummary struct {
+	ID         primitive.ObjectID `json:"id"`
+	Title      string             `json:"title"`
+	Slug       string             `json:"slug"`
+	Status     string             `json:"status"`
+	FromStatus string             `json:"from_status,omitempty"`
+	Revision   int                `json:"revision"`
+	AuthorID   primitive.ObjectID `json:"author_id"`
+	Locale     string             `json:"locale,omitempty"`
+	UpdatedAt  time.Time          `json:"updated_at"`
+}
+
+// articleAudience: un artículo público lo ve toda la organización; si no,
+// quienes participan de él y quienes revisan o publican
+func articleAudience(a *Article) events.Audience {
+	if IsPubliclyVisible(a, time.Now()) {
+		return events.Audience{}
+	}
+	ids := []string{a.AuthorID.Hex()}
+	for _, c := range a.Contributors {
+		ids = append(ids, c.UserID.Hex())
+	}
+	return events.Audience{UserIDs: ids, Permissions: []string{"articles:review", "articles:publish"}}
+}
+
+// publishArticle publica un evento del artículo; from es el estado anterior en los cambios de estado
+func publishArticle(bus *events.Bus, kind string, a *Article, actorID, from string) {
+	if bus == nil {
+		return
+	}
+	bus.Publish(events.Event{
+		Type:           kind,
+		OrganizationID: a.OrganizationID.Hex(),
+		ActorID:        actorID,
+		SubjectID:      a.ID.Hex(),
+		Audience:       articleAudience(a),
+		Data: ArticleSummary{
+			ID:         a.ID,
+			Title:      a.Title,
+			Slug:       a.Slug,
+			Status:     a.Status,
+			FromStatus: from,
+			Revision:   currentRevision(a),
+			AuthorID:   a.AuthorID,
+			Locale:     a.Locale,
+			UpdatedAt:  a.UpdatedAt,
+		},
+	})
+}
+
+// publish publica un evento del artículo con el usuario de la petición como actor
+func (h *Handlers) publish(ctx context.Context, kind string, a *Article) {
+	actor, _ := ctx.Value("user_id").(string)
+	publishArticle(h.Events, kind, a, actor, "")
+}
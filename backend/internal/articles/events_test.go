@@ -0,0 +1,62 @@
+package articles
+
+import (
+	"net/http"
+	"testing"
+
+	"pittsix/pkg/events"
+)
+
+func TestHandlers_PublishEvents(t *testing.T) {
+	e := newTestEnv()
+	e.h.Events = events.NewBus(0)
+	sub, _, _ := e.h.Events.Subscribe(0, func(events.Event) bool { return true })
+	defer sub.Close()
+	next := func() events.Event {
+		select {
+		case ev := <-sub.C:
+			return ev
+		default:
+			t.Fatal("expected an event")
+			return events.Event{}
+		}
+	}
+
+	author := e.newUser(e.orgA)
+	a := e.create(t, author, e.orgA, "Con eventos")
+	ev := next()
+	if ev.Type != events.ArticleCreated || ev.OrganizationID != e.orgA.ID.Hex() || ev.ActorID != author.Hex() || ev.SubjectID != a.ID.Hex() {
+		t.Errorf("created: %+v", ev)
+	}
+	// Un borrador lo ven sus autores y quienes revisan
+	if len(ev.Audience.UserIDs) != 1 || ev.Audience.UserIDs[0] != author.Hex() || len(ev.Audience.Permissions) == 0 {
+		t.Errorf("draft audience: %+v", ev.Audience)
+	}
+
+	w := serve(e.h.UpdateArticle, request("PUT", "/articles/"+a.ID.Hex(), `{"title":"Con eventos","content":"otra"}`, author, e.orgA), "id", a.ID.Hex())
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: %d", w.Code)
+	}
+	if ev := next(); ev.Type != events.ArticleUpdated || ev.Data.(ArticleSummary).Revision != 2 {
+		t.Errorf("updated: %+v", ev)
+	}
+
+	for _, name := range []string{"submit", "approve", "publish"} {
+		e.transition(t, a.ID, name, author, e.orgA)
+	}
+	next()
+	next()
+	ev = next()
+	s := ev.Data.(ArticleSummary)
+	if ev.Type != events.ArticleStatusChanged || s.Status != StatusPublished || s.FromStatus != StatusApproved {
+		t.Errorf("published: %+v", ev)
+	}
+	if len(ev.Audience.UserIDs) != 0 || len(ev.Audience.Permissions) != 0 {
+		t.Errorf("published articles are visible to the whole organization: %+v", ev.Audience)
+	}
+
+	serve(e.h.DeleteArticle, request("DELETE", "/articles/"+a.ID.Hex(), "", author, e.orgA, "org_admin"), "id", a.ID.Hex())
+	if ev := next(); ev.Type != events.ArticleDeleted || ev.SubjectID != a.ID.Hex() {
+		t.Errorf("deleted: %+v", ev)
+	}
+}
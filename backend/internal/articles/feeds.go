@@ -0,0 +1,524 @@
+package articles
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"pittsix/internal/organizations"
+)
+
+// 📡 Feeds de sindicación (RSS 2.0, Atom y JSON Feed 1.1) con los artículos
+// publicados de una organización, opcionalmente de un autor (?author=), una
+// etiqueta (?tag=) o una categoría y sus hijas (?category=). Las URLs salen
+// absolutas del sitio de la organización (ver siteBase) y las respuestas
+// llevan los mismos ETag/Last-Modified que el resto de las lecturas públicas.
+
+const (
+	FeedRSS  = "rss"
+	FeedAtom = "atom"
+	FeedJSON = "json"
+
+	// DefaultArticlePath es la ruta del frontend que muestra un artículo
+	DefaultArticlePath = "/article/{id}"
+
+	feedSummaryLength = 300
+	feedGenerator     = "Pittsix"
+)
+
+var feedContentTypes = map[string]string{
+	FeedRSS:  "application/rss+xml; charset=utf-8",
+	FeedAtom: "application/atom+xml; charset=utf-8",
+	FeedJSON: "application/feed+json; charset=utf-8",
+}
+
+// feed es lo común a los tres formatos antes de codificarlo
+type feed struct {
+	Title       string
+	Description string
+	Language    string
+	HomeURL     string
+	FeedURL     string
+	Updated     time.Time
+	Items       []feedItem
+}
+
+type feedItem struct {
+	ID         string
+	URL        string
+	Title      string
+	Summary    string
+	HTML       string
+	AuthorName string
+	Avatar     string
+	Tags       []string
+	Language   string
+	Image      string
+	ImageType  string
+	Published  time.Time
+	Updated    time.Time
+}
+
+// Feed devuelve el handler de un formato (rss, atom o json)
+func (h *Handlers) Feed(format string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h.serveFeed(w, r, format)
+	}
+}
+
+func (h *Handlers) serveFeed(w http.ResponseWriter, r *http.Request, format string) {
+	ctx := r.Context()
+	tenant, err := h.publicTenant(r)
+	if err != nil {
+		http.Error(w, err.Error(), tenantErrorStatus(err))
+		return
+	}
+	org, err := h.Orgs.GetByID(tenant.OrgID)
+	if err != nil || org == nil {
+		http.Error(w, "Organization not found", http.StatusNotFound)
+		return
+	}
+	params := r.URL.Query()
+	q, err := ParseListQuery(params)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// Un feed siempre muestra lo último publicado
+	q.Status = nil
+	q.Sort = "published_at"
+	q.Desc = true
+	q.From, q.To, q.After = nil, nil, nil
+
+	title := org.Name
+	if q.Tag != "" {
+		t, err := h.Taxonomy.GetTagBySlug(ctx, tenant.OrgID, q.Tag)
+		if err != nil {
+			http.Error(w, "Tag not found", taxonomyErrorStatus(err))
+			return
+		}
+		title += " · #" + t.Name
+	}
+	if slug := params.Get("category"); slug != "" {
+		c, err := h.Taxonomy.GetCategoryBySlug(ctx, tenant.OrgID, slug)
+		if err != nil {
+			http.Error(w, "Category not found", taxonomyErrorStatus(err))
+			return
+		}
+		all, err := h.Taxonomy.ListCategories(ctx, tenant.OrgID)
+		if err != nil {
+			http.Error(w, "DB error", http.StatusInternalServerError)
+			return
+		}
+		q.CategoryIDs = descendantIDs(all, c.ID)
+		title += " · " + c.Name
+	}
+
+	present, err := h.publicPresenter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	now := time.Now()
+	page, err := listArticles(ctx, h.Repo, tenant.scope(Filter{VisibleAt: &now}), q, present)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	if q.AuthorID != nil {
+		if user, err := h.Users.GetUserByID(*q.AuthorID); err == nil && user != nil && user.OrganizationID == tenant.OrgID {
+			title += " · " + authorOf(user).Name
+		} else if len(page.Items) > 0 {
+			title += " · " + page.Items[0].AuthorName
+		} else {
+			http.Error(w, "Author not found", http.StatusNotFound)
+			return
+		}
+	}
+
+	base := h.siteBase(r, org)
+	modified, keys := pageMeta(tenant.OrgID, page)
+	if q.AuthorID != nil {
+		keys = append(keys, authorKey(*q.AuthorID))
+	}
+	etag := pageETag(r, page, format+"|"+base+"|"+title)
+	if h.cacheHeaders(w, r, RouteFeeds, etag, modified, keys) {
+		return
+	}
+
+	f := feed{
+		Title:       title,
+		Description: "Artículos de " + title,
+		Language:    org.PrimaryLocale(),
+		HomeURL:     base + "/",
+		FeedURL:     requestURL(r),
+		Updated:     modified,
+		Items:       make([]feedItem, 0, len(page.Items)),
+	}
+	if f.Updated.IsZero() {
+		f.Updated = now
+	}
+	for i := range page.Items {
+		f.Items = append(f.Items, h.feedItem(base, &page.Items[i], f.Language))
+	}
+
+	w.Header().Set("Content-Type", feedContentTypes[format])
+	var encodeErr error
+	switch format {
+	case FeedRSS:
+		w.Write([]byte(xml.Header))
+		encodeErr = xml.NewEncoder(w).Encode(rssOf(f))
+	case FeedAtom:
+		w.Write([]byte(xml.Header))
+		encodeErr = xml.NewEncoder(w).Encode(atomOf(f))
+	default:
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		encodeErr = enc.Encode(jsonFeedOf(f))
+	}
+	if encodeErr != nil {
+		log.Printf("❌ Error escribiendo el feed %s: %v", format, encodeErr)
+	}
+}
+
+func (h *Handlers) feedItem(base string, a *Article, defaultLocale string) feedItem {
+	item := feedItem{
+		ID:         a.ID.Hex(),
+		URL:        h.articleURL(base, a),
+		Title:      a.Title,
+		Summary:    summarize(a.Content, feedSummaryLength),
+		HTML:       a.Content,
+		AuthorName: a.AuthorName,
+		Avatar:     absoluteURL(base, a.AuthorAvatar),
+		Tags:       a.Tags,
+		Language:   a.Locale,
+		Published:  a.CreatedAt,
+		Updated:    a.UpdatedAt,
+	}
+	if a.PublishedAt != nil {
+		item.Published = *a.PublishedAt
+	}
+	if item.Language == "" {
+		item.Language = defaultLocale
+	}
+	if a.Image != "" {
+		item.Image = absoluteURL(base, a.Image)
+		item.ImageType = imageType(a.Image)
+	}
+	return item
+}
+
+// siteBase es la raíz pública del sitio de la organización: su primer host
+// con el esquema de SiteURL, SiteURL si no tiene hosts, o el host de la
+// petición si no hay nada configurado. No termina en "/".
+func (h *Handlers) siteBase(r *http.Request, org *organizations.Organization) string {
+	scheme := "https"
+	if u, err := url.Parse(h.SiteURL); err == nil && u.Scheme != "" {
+		scheme = u.Scheme
+	}
+	if org != nil && len(org.Hosts) > 0 {
+		return scheme + "://" + org.Hosts[0]
+	}
+	if h.SiteURL != "" {
+		return strings.TrimSuffix(h.SiteURL, "/")
+	}
+	return requestScheme(r) + "://" + r.Host
+}
+
+// articleURL arma el enlace público de un artículo con ArticlePath
+// ({id} y {slug} se reemplazan)
+func (h *Handlers) articleURL(base string, a *Article) string {
+	p := h.ArticlePath
+	if p == "" {
+		p = DefaultArticlePath
+	}
+	p = strings.NewReplacer("{id}", a.ID.Hex(), "{slug}", url.PathEscape(a.Slug)).Replace(p)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return base + p
+}
+
+// absoluteURL resuelve contra la base las URLs relativas (imágenes, avatares)
+func absoluteURL(base, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	u, err := url.Parse(ref)
+	if err != nil || u.IsAbs() {
+		return ref
+	}
+	b, err := url.Parse(base + "/")
+	if err != nil {
+		return ref
+	}
+	return b.ResolveReference(u).String()
+}
+
+func requestScheme(r *http.Request) string {
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		return strings.TrimSpace(strings.Split(proto, ",")[0])
+	}
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// requestURL es la URL absoluta con la que se pidió el feed (enlace "self")
+func requestURL(r *http.Request) string {
+	return requestScheme(r) + "://" + r.Host + r.URL.RequestURI()
+}
+
+// imageType adivina el tipo MIME de la imagen por su extensión
+func imageType(ref string) string {
+	if u, err := url.Parse(ref); err == nil {
+		ref = u.Path
+	}
+	if t := mime.TypeByExtension(strings.ToLower(path.Ext(ref))); strings.HasPrefix(t, "image/") {
+		return t
+	}
+	return "image/jpeg"
+}
+
+// ---- RSS 2.0 ----
+
+type rssDoc struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	ContentNS string     `xml:"xmlns:content,attr"`
+	DCNS      string     `xml:"xmlns:dc,attr"`
+	AtomNS    string     `xml:"xmlns:atom,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language,omitempty"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Generator     string    `xml:"generator"`
+	Self          atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	GUID        rssGUID       `xml:"guid"`
+	PubDate     string        `xml:"pubDate"`
+	Creator     string        `xml:"dc:creator,omitempty"`
+	Categories  []string      `xml:"category"`
+	Description string        `xml:"description"`
+	Content     string        `xml:"content:encoded"`
+	Enclosure   *rssEnclosure `xml:"enclosure"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// rssEnclosure: RSS exige length; sin conocer el tamaño se manda 0
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+func rssOf(f feed) rssDoc {
+	doc := rssDoc{
+		Version:   "2.0",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		DCNS:      "http://purl.org/dc/elements/1.1/",
+		AtomNS:    "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:         f.Title,
+			Link:          f.HomeURL,
+			Description:   f.Description,
+			Language:      f.Language,
+			LastBuildDate: f.Updated.UTC().Format(time.RFC1123Z),
+			Generator:     feedGenerator,
+			Self:          atomLink{Href: f.FeedURL, Rel: "self", Type: feedContentTypes[FeedRSS]},
+		},
+	}
+	for _, it := range f.Items {
+		item := rssItem{
+			Title:       it.Title,
+			Link:        it.URL,
+			GUID:        rssGUID{IsPermaLink: true, Value: it.URL},
+			PubDate:     it.Published.UTC().Format(time.RFC1123Z),
+			Creator:     it.AuthorName,
+			Categories:  it.Tags,
+			Description: it.Summary,
+			Content:     it.HTML,
+		}
+		if it.Image != "" {
+			item.Enclosure = &rssEnclosure{URL: it.Image, Type: it.ImageType}
+		}
+		doc.Channel.Items = append(doc.Channel.Items, item)
+	}
+	return doc
+}
+
+// ---- Atom ----
+
+type atomDoc struct {
+	XMLName   xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Lang      string      `xml:"xml:lang,attr,omitempty"`
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Subtitle  string      `xml:"subtitle,omitempty"`
+	Updated   string      `xml:"updated"`
+	Links     []atomLink  `xml:"link"`
+	Generator string      `xml:"generator"`
+	Entries   []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr,omitempty"`
+	Type   string `xml:"type,attr,omitempty"`
+	Length int64  `xml:"length,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published"`
+	Links      []atomLink     `xml:"link"`
+	Author     *atomPerson    `xml:"author"`
+	Categories []atomCategory `xml:"category"`
+	Summary    string         `xml:"summary,omitempty"`
+	Content    atomText       `xml:"content"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+func atomOf(f feed) atomDoc {
+	doc := atomDoc{
+		Lang:     f.Language,
+		ID:       f.FeedURL,
+		Title:    f.Title,
+		Subtitle: f.Description,
+		Updated:  f.Updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: f.HomeURL, Rel: "alternate", Type: "text/html"},
+			{Href: f.FeedURL, Rel: "self", Type: feedContentTypes[FeedAtom]},
+		},
+		Generator: feedGenerator,
+	}
+	for _, it := range f.Items {
+		entry := atomEntry{
+			ID:        it.URL,
+			Title:     it.Title,
+			Updated:   it.Updated.UTC().Format(time.RFC3339),
+			Published: it.Published.UTC().Format(time.RFC3339),
+			Links:     []atomLink{{Href: it.URL, Rel: "alternate", Type: "text/html"}},
+			Summary:   it.Summary,
+			Content:   atomText{Type: "html", Body: it.HTML},
+		}
+		if it.AuthorName != "" {
+			entry.Author = &atomPerson{Name: it.AuthorName}
+		}
+		for _, tag := range it.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+		}
+		if it.Image != "" {
+			entry.Links = append(entry.Links, atomLink{Href: it.Image, Rel: "enclosure", Type: it.ImageType})
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+	return doc
+}
+
+// ---- JSON Feed 1.1 ----
+
+type jsonFeedDoc struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description,omitempty"`
+	Language    string         `json:"language,omitempty"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string               `json:"id"`
+	URL           string               `json:"url"`
+	Title         string               `json:"title"`
+	ContentHTML   string               `json:"content_html"`
+	Summary       string               `json:"summary,omitempty"`
+	Image         string               `json:"image,omitempty"`
+	DatePublished string               `json:"date_published"`
+	DateModified  string               `json:"date_modified"`
+	Authors       []jsonFeedAuthor     `json:"authors,omitempty"`
+	Tags          []string             `json:"tags,omitempty"`
+	Language      string               `json:"language,omitempty"`
+	Attachments   []jsonFeedAttachment `json:"attachments,omitempty"`
+}
+
+type jsonFeedAuthor struct {
+	Name   string `json:"name"`
+	Avatar string `json:"avatar,omitempty"`
+}
+
+type jsonFeedAttachment struct {
+	URL      string `json:"url"`
+	MimeType string `json:"mime_type"`
+}
+
+func jsonFeedOf(f feed) jsonFeedDoc {
+	doc := jsonFeedDoc{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageURL: f.HomeURL,
+		FeedURL:     f.FeedURL,
+		Description: f.Description,
+		Language:    f.Language,
+		Items:       []jsonFeedItem{},
+	}
+	for _, it := range f.Items {
+		item := jsonFeedItem{
+			ID:            it.ID,
+			URL:           it.URL,
+			Title:         it.Title,
+			ContentHTML:   it.HTML,
+			Summary:       it.Summary,
+			Image:         it.Image,
+			DatePublished: it.Published.UTC().Format(time.RFC3339),
+			DateModified:  it.Updated.UTC().Format(time.RFC3339),
+			Tags:          it.Tags,
+			Language:      it.Language,
+		}
+		if it.AuthorName != "" {
+			item.Authors = []jsonFeedAuthor{{Name: it.AuthorName, Avatar: it.Avatar}}
+		}
+		if it.Image != "" {
+			item.Attachments = []jsonFeedAttachment{{URL: it.Image, MimeType: it.ImageType}}
+		}
+		doc.Items = append(doc.Items, item)
+	}
+	return doc
+}
@@ -0,0 +1,208 @@
+package articles
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// publishedWith crea y publica en org A un artículo con el cuerpo JSON dado
+func (e *testEnv) publishedWith(t *testing.T, author primitive.ObjectID, body string) Article {
+	t.Helper()
+	w := serve(e.h.CreateArticle, request("POST", "/articles", body, author, e.orgA))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d %s", w.Code, w.Body.String())
+	}
+	var a Article
+	json.NewDecoder(w.Body).Decode(&a)
+	for _, name := range []string{"submit", "approve", "publish"} {
+		e.transition(t, a.ID, name, author, e.orgA)
+	}
+	return a
+}
+
+func getFeed(e *testEnv, format, target string, headers ...string) *httptest.ResponseRecorder {
+	r := request("GET", target, "", primitive.NilObjectID, nil)
+	for i := 0; i+1 < len(headers); i += 2 {
+		r.Header.Set(headers[i], headers[i+1])
+	}
+	return serve(e.h.Feed(format), r)
+}
+
+func TestFeeds_RSS(t *testing.T) {
+	e := newTestEnv()
+	author := e.newUser(e.orgA)
+	a := e.publishedWith(t, author, `{"title":"Con foto","content":"<p>hola <b>mundo</b></p>","image":"/media/foto.png","tags":["Go"]}`)
+	e.create(t, author, e.orgA, "Borrador")
+
+	w := getFeed(e, FeedRSS, "/feeds/articles.rss")
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "application/rss+xml") {
+		t.Fatalf("expected rss, got %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	var doc struct {
+		Channel struct {
+			Title string `xml:"title"`
+			Items []struct {
+				Title     string `xml:"title"`
+				Link      string `xml:"link"`
+				Creator   string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+				Content   string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+				Desc      string `xml:"description"`
+				Enclosure struct {
+					URL  string `xml:"url,attr"`
+					Type string `xml:"type,attr"`
+				} `xml:"enclosure"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err := xml.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid rss: %v\n%s", err, w.Body.String())
+	}
+	if doc.Channel.Title != "A" || !strings.Contains(w.Body.String(), "<link>https://a.example.com/</link>") {
+		t.Errorf("unexpected channel: %s", w.Body.String())
+	}
+	if len(doc.Channel.Items) != 1 {
+		t.Fatalf("only published articles belong in the feed, got %d", len(doc.Channel.Items))
+	}
+	item := doc.Channel.Items[0]
+	if item.Link != "https://a.example.com/article/"+a.ID.Hex() || item.Creator != "Ana Pérez" {
+		t.Errorf("unexpected item: %+v", item)
+	}
+	if item.Enclosure.URL != "https://a.example.com/media/foto.png" || item.Enclosure.Type != "image/png" {
+		t.Errorf("image must be an absolute enclosure, got %+v", item.Enclosure)
+	}
+	if item.Desc != "hola mundo" || !strings.Contains(item.Content, "<b>mundo</b>") {
+		t.Errorf("expected summary and full html, got %q / %q", item.Desc, item.Content)
+	}
+}
+
+func TestFeeds_AtomAndJSON(t *testing.T) {
+	e := newTestEnv()
+	e.h.ArticlePath = "/notas/{slug}"
+	author := e.newUser(e.orgA)
+	e.publishedWith(t, author, `{"title":"Nota Uno","content":"<p>texto</p>","image":"https://cdn.example.com/x.jpg"}`)
+
+	w := getFeed(e, FeedAtom, "/feeds/articles.atom")
+	var atom struct {
+		Entries []struct {
+			ID     string `xml:"id"`
+			Author string `xml:"author>name"`
+			Links  []struct {
+				Href string `xml:"href,attr"`
+				Rel  string `xml:"rel,attr"`
+			} `xml:"link"`
+		} `xml:"http://www.w3.org/2005/Atom entry"`
+	}
+	if err := xml.Unmarshal(w.Body.Bytes(), &atom); err != nil || len(atom.Entries) != 1 {
+		t.Fatalf("invalid atom (%v): %s", err, w.Body.String())
+	}
+	entry := atom.Entries[0]
+	if entry.ID != "https://a.example.com/notas/nota-uno" || entry.Author != "Ana Pérez" {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+	if len(entry.Links) != 2 || entry.Links[1].Rel != "enclosure" || entry.Links[1].Href != "https://cdn.example.com/x.jpg" {
+		t.Errorf("expected alternate and enclosure links, got %+v", entry.Links)
+	}
+
+	w = getFeed(e, FeedJSON, "/feeds/articles.json")
+	var doc jsonFeedDoc
+	if err := json.NewDecoder(w.Body).Decode(&doc); err != nil {
+		t.Fatalf("invalid json feed: %v", err)
+	}
+	if doc.Version != "https://jsonfeed.org/version/1.1" || len(doc.Items) != 1 {
+		t.Fatalf("unexpected json feed: %+v", doc)
+	}
+	item := doc.Items[0]
+	if item.Image != "https://cdn.example.com/x.jpg" || len(item.Attachments) != 1 || item.Attachments[0].MimeType != "image/jpeg" {
+		t.Errorf("unexpected image/attachments: %+v", item)
+	}
+	if len(item.Authors) != 1 || item.Authors[0].Name != "Ana Pérez" {
+		t.Errorf("unexpected authors: %+v", item.Authors)
+	}
+}
+
+func TestFeeds_Filters(t *testing.T) {
+	e := newTestEnv()
+	ana := e.newUser(e.orgA)
+	other := e.newUser(e.orgA)
+	e.publishedWith(t, ana, `{"title":"De Ana","content":"x","tags":["Go"]}`)
+	e.publishedWith(t, other, `{"title":"De otro","content":"x"}`)
+
+	count := func(target string) int {
+		t.Helper()
+		w := getFeed(e, FeedJSON, target)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d %s", target, w.Code, w.Body.String())
+		}
+		var doc jsonFeedDoc
+		json.NewDecoder(w.Body).Decode(&doc)
+		return len(doc.Items)
+	}
+	if n := count("/feeds/articles.json"); n != 2 {
+		t.Errorf("expected the whole organization, got %d", n)
+	}
+	if n := count("/feeds/articles.json?author=" + ana.Hex()); n != 1 {
+		t.Errorf("expected one article by author, got %d", n)
+	}
+	if n := count("/feeds/articles.json?tag=go"); n != 1 {
+		t.Errorf("expected one tagged article, got %d", n)
+	}
+	if n := count("/feeds/articles.json?org=org-b"); n != 0 {
+		t.Errorf("feeds must be scoped to the organization, got %d", n)
+	}
+	if w := getFeed(e, FeedJSON, "/feeds/articles.json?tag=nope"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown tag: expected 404, got %d", w.Code)
+	}
+	if w := getFeed(e, FeedJSON, "/feeds/articles.json?category=nope"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown category: expected 404, got %d", w.Code)
+	}
+}
+
+func TestFeeds_ConditionalGet(t *testing.T) {
+	e := newTestEnv()
+	author := e.newUser(e.orgA)
+	e.published(t, author, "Primero")
+
+	w := getFeed(e, FeedRSS, "/feeds/articles.rss")
+	etag, modified := w.Header().Get("ETag"), w.Header().Get("Last-Modified")
+	if etag == "" || modified == "" || w.Header().Get("Cache-Control") != DefaultCacheControl[RouteFeeds] {
+		t.Fatalf("expected cache headers, got %v", w.Header())
+	}
+	if w := getFeed(e, FeedRSS, "/feeds/articles.rss", "If-None-Match", etag); w.Code != http.StatusNotModified {
+		t.Errorf("expected 304 for a matching etag, got %d", w.Code)
+	}
+	if w := getFeed(e, FeedRSS, "/feeds/articles.rss", "If-Modified-Since", modified); w.Code != http.StatusNotModified {
+		t.Errorf("expected 304 for If-Modified-Since, got %d", w.Code)
+	}
+	if w := getFeed(e, FeedAtom, "/feeds/articles.atom", "If-None-Match", etag); w.Code != http.StatusOK {
+		t.Errorf("each format has its own etag, got %d", w.Code)
+	}
+
+	e.published(t, author, "Segundo")
+	if w := getFeed(e, FeedRSS, "/feeds/articles.rss", "If-None-Match", etag); w.Code != http.StatusOK {
+		t.Errorf("a new article must change the feed, got %d", w.Code)
+	}
+}
+
+func TestSiteBase(t *testing.T) {
+	e := newTestEnv()
+	r := httptest.NewRequest("GET", "/feeds/articles.rss", nil)
+	if got := e.h.siteBase(r, e.orgB); got != "http://example.com" {
+		t.Errorf("without SiteURL the request host is used, got %s", got)
+	}
+	e.h.SiteURL = "http://sitio.test/"
+	if got := e.h.siteBase(r, e.orgB); got != "http://sitio.test" {
+		t.Errorf("unexpected base: %s", got)
+	}
+	if got := e.h.siteBase(r, e.orgA); got != "http://a.example.com" {
+		t.Errorf("organizations with hosts use their own, got %s", got)
+	}
+	if got := absoluteURL("http://sitio.test", "img/a.png"); got != "http://sitio.test/img/a.png" {
+		t.Errorf("unexpected absolute url: %s", got)
+	}
+}
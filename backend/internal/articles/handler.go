@@ -1,63 +1,185 @@
 package articles
 
 import (
-	"context"
 	"encoding/json"
+	"errors"
 	"log"
+	"maps"
 	"net/http"
 	"regexp"
 	"strings"
 	"time"
 
+	"pittsix/internal/organizations"
 	"pittsix/internal/users"
+	"pittsix/pkg/cache"
+	"pittsix/pkg/events"
+	"pittsix/pkg/middleware"
 
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-// 🌱 Mongo Collection
-var Collection *mongo.Collection
-var userRepo users.Repository
+// Handlers agrupa las dependencias de la API de artículos
+type Handlers struct {
+	Repo     Repository
+	Taxonomy TaxonomyRepository
+	Users    users.Repository
+	Orgs     organizations.Repository
+	Search   SearchIndex
+	// Rendered cachea el HTML de los artículos en Markdown por revisión
+	Rendered *cache.LRU
+	// PreviewSecret firma los enlaces de vista previa
+	PreviewSecret []byte
+	// DefaultOrgSlug es la organización de las lecturas públicas sin ?org= ni host conocido
+	DefaultOrgSlug string
+	// Events recibe los cambios de artículos (nil: no se publican)
+	Events *events.Bus
+	// CacheControl es el Cache-Control de cada lectura pública (ver httpcache.go)
+	CacheControl map[string]string
+	// Purge invalida la CDN cuando cambia algo que no pasa por el bus, como la firma
+	Purge *Purger
+	// SiteURL es la raíz pública del sitio para las URLs absolutas de los feeds;
+	// las organizaciones con Hosts usan su primer host
+	SiteURL string
+	// ArticlePath es la ruta pública de un artículo ({id} o {slug}); por defecto DefaultArticlePath
+	ArticlePath string
+	// RobotsRules es el cuerpo de robots.txt sin la línea Sitemap; vacío usa DefaultRobots
+	RobotsRules string
+}
 
-// 🛠️ Inicializar desde main.go
-func Init(collection *mongo.Collection, uRepo users.Repository) {
-	Collection = collection
-	userRepo = uRepo
+func NewHandlers(repo Repository, taxonomy TaxonomyRepository, usersRepo users.Repository, orgRepo organizations.Repository, defaultOrgSlug string) *Handlers {
+	return &Handlers{
+		Repo:           repo,
+		Taxonomy:       taxonomy,
+		Users:          usersRepo,
+		Orgs:           orgRepo,
+		Search:         NewMemoryIndex(),
+		Rendered:       cache.NewLRU(renderCacheSize),
+		PreviewSecret:  newPreviewSecret(),
+		DefaultOrgSlug: defaultOrgSlug,
+		CacheControl:   maps.Clone(DefaultCacheControl),
+		ArticlePath:    DefaultArticlePath,
+	}
 }
 
 // 🧱 Modelo de artículo
 type Article struct {
-	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	Title      string             `bson:"title" json:"title"`
-	Content    string             `bson:"content" json:"content"`
-	Image      string             `bson:"image,omitempty" json:"image,omitempty"`
-	Slug       string             `bson:"slug" json:"slug"`
-	AuthorID   primitive.ObjectID `bson:"author_id" json:"author_id"`
-	AuthorName string             `bson:"author_name" json:"author_name"`
-	Status     string             `bson:"status" json:"status"`
-	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt  time.Time          `bson:"updated_at" json:"updated_at"`
+	ID      primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Title   string             `bson:"title" json:"title"`
+	Content string             `bson:"content" json:"content"`
+	// Blocks es el cuerpo estructurado; si está, Content es su render HTML
+	Blocks []Block `bson:"blocks,omitempty" json:"blocks,omitempty"`
+	// ContentFormat dice cómo leer Content: html, markdown o blocks (ver content.go)
+	ContentFormat string             `bson:"content_format,omitempty" json:"content_format,omitempty"`
+	Image         string             `bson:"image,omitempty" json:"image,omitempty"`
+	Slug          string             `bson:"slug" json:"slug"`
+	AuthorID      primitive.ObjectID `bson:"author_id" json:"author_id"`
+	AuthorName    string             `bson:"author_name" json:"author_name"`
+	// Avatar y bio del autor copiados del perfil; SyncAuthor los mantiene al día
+	AuthorAvatar string `bson:"author_avatar,omitempty" json:"author_avatar,omitempty"`
+	AuthorBio    string `bson:"author_bio,omitempty" json:"author_bio,omitempty"`
+	// Contributors son los coautores con rol editor o viewer; el owner es AuthorID
+	Contributors []Contributor `bson:"contributors,omitempty" json:"contributors,omitempty"`
+	// OrganizationID es el tenant dueño del artículo (la organización del autor)
+	OrganizationID primitive.ObjectID `bson:"organization_id,omitempty" json:"organization_id,omitempty"`
+	Status         string             `bson:"status" json:"status"`
+	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt      time.Time          `bson:"updated_at" json:"updated_at"`
+	// Revision es el número de la revisión actual; las anteriores se guardan como Revision
+	Revision     int                `bson:"revision" json:"revision"`
+	LastEditedBy primitive.ObjectID `bson:"last_edited_by,omitempty" json:"last_edited_by,omitempty"`
+	PublishedAt  *time.Time         `bson:"published_at,omitempty" json:"published_at,omitempty"`
+	// Ventana de publicación programada (la aplica el Scheduler)
+	PublishAt   *time.Time `bson:"publish_at,omitempty" json:"publish_at,omitempty"`
+	UnpublishAt *time.Time `bson:"unpublish_at,omitempty" json:"unpublish_at,omitempty"`
+	// Tags guarda slugs de Tag; CategoryIDs referencia Category de la misma organización
+	Tags        []string             `bson:"tags,omitempty" json:"tags,omitempty"`
+	CategoryIDs []primitive.ObjectID `bson:"category_ids,omitempty" json:"category_ids,omitempty"`
+	// SlugHistory guarda los slugs que tuvo después de publicarse, para redirigir enlaces viejos
+	SlugHistory []string `bson:"slug_history,omitempty" json:"slug_history,omitempty"`
+	// Locale es el idioma del artículo; vacío equivale al idioma por defecto de la organización
+	Locale string `bson:"locale,omitempty" json:"locale,omitempty"`
+	// TranslationGroup es el ID del artículo original del que todos son traducción
+	// (vacío si nunca se tradujo); SourceRevision, la revisión del original que se tradujo
+	TranslationGroup primitive.ObjectID `bson:"translation_group,omitempty" json:"translation_group,omitempty"`
+	SourceRevision   int                `bson:"source_revision,omitempty" json:"source_revision,omitempty"`
+	// Translations lista las variantes públicas en otros idiomas (solo en lecturas públicas)
+	Translations []TranslationLink `bson:"-" json:"translations,omitempty"`
+	// SEO pisa los metadatos derivados (ver seo.go)
+	SEO *SEO `bson:"seo,omitempty" json:"seo,omitempty"`
+	// Excerpt, WordCount y ReadingTime (minutos) se derivan del contenido en las lecturas públicas
+	Excerpt     string `bson:"-" json:"excerpt,omitempty"`
+	WordCount   int    `bson:"-" json:"word_count,omitempty"`
+	ReadingTime int    `bson:"-" json:"reading_time,omitempty"`
+}
+
+// maxBodyBytes acota el cuerpo de las escrituras: el contenido no tiene otro tope
+const maxBodyBytes = 4 << 20
+
+// limitBody corta los cuerpos de más de maxBodyBytes
+func limitBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeBodyError responde 413 si el cuerpo pasó el tope y 400 si no se entiende
+func writeBodyError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		http.Error(w, "Body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	http.Error(w, "Invalid body", http.StatusBadRequest)
 }
 
-// GenerateSlug genera un slug amigable a partir del título
+var (
+	slugInvalidChars = regexp.MustCompile(`[^a-z0-9-]+`)
+	slugDashes       = regexp.MustCompile(`-{2,}`)
+)
+
+// GenerateSlug genera un slug amigable a partir del título ("Acción rápida" → "accion-rapida")
 func GenerateSlug(title string) string {
-	slug := strings.ToLower(title)
+	slug := strings.ToLower(foldAccents(title))
 	slug = strings.ReplaceAll(slug, " ", "-")
 	slug = strings.ReplaceAll(slug, "_", "-")
-	slug = regexp.MustCompile(`[^a-z0-9-]+`).ReplaceAllString(slug, "")
+	slug = slugInvalidChars.ReplaceAllString(slug, "")
+	slug = slugDashes.ReplaceAllString(slug, "-")
 	slug = strings.Trim(slug, "-")
 	return slug
 }
 
-// 📥 Crear artículo (requiere JWT)
-func CreateArticleHandler(w http.ResponseWriter, r *http.Request) {
+// 📥 Crear artículo (requiere JWT). Se crea en la organización del JWT; un
+// superadmin con ?all_orgs=true la indica en organization_id.
+func (h *Handlers) CreateArticle(w http.ResponseWriter, r *http.Request) {
 	log.Printf("📩 Nuevo artículo recibido: %+v", r)
 
 	var article Article
 	if err := json.NewDecoder(r.Body).Decode(&article); err != nil {
 		log.Println(err.Error())
-		http.Error(w, "Invalid body", http.StatusBadRequest)
+		writeBodyError(w, err)
+		return
+	}
+	// Las traducciones se crean con POST /articles/{id}/translations
+	article.TranslationGroup = primitive.NilObjectID
+	article.SourceRevision = 0
+	h.createArticle(w, r, &article)
+}
+
+// createArticle completa un artículo nuevo con el autor y la organización del
+// JWT y lo guarda como borrador
+func (h *Handlers) createArticle(w http.ResponseWriter, r *http.Request, article *Article) {
+	if !validateSchedule(article.PublishAt, article.UnpublishAt) {
+		http.Error(w, "unpublish_at must be after publish_at", http.StatusBadRequest)
+		return
+	}
+	if err := prepareContent(article); err != nil {
+		writeContentError(w, err)
+		return
+	}
+	if err := validateSEO(article.SEO); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -71,57 +193,130 @@ func CreateArticleHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid user id", http.StatusBadRequest)
 		return
 	}
+	tenant, err := tenantFromRequest(r)
+	if err != nil {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	// Con ?all_orgs=true el superadmin elige la organización en organization_id
+	if tenant.All {
+		if article.OrganizationID.IsZero() {
+			http.Error(w, "organization_id required with all_orgs", http.StatusBadRequest)
+			return
+		}
+		if h.organization(article.OrganizationID) == nil {
+			http.Error(w, errUnknownTenant.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+	} else {
+		article.OrganizationID = tenant.OrgID
+	}
 	article.AuthorID = userObjID
+	if (article.PublishAt != nil || article.UnpublishAt != nil) && !canSchedule(r.Context(), article, userObjID) {
+		http.Error(w, errSchedulePermission.Error(), http.StatusForbidden)
+		return
+	}
+	// Los colaboradores se suman después con PUT /articles/{id}/contributors/{user}
+	article.Contributors = nil
+	article.LastEditedBy = userObjID
+	article.Revision = 1
 
-	// Buscar nombre del autor usando el repo global
-	user, err := userRepo.GetUserByID(userObjID)
+	// Buscar nombre del autor
+	user, err := h.Users.GetUserByID(userObjID)
 	if err == nil && user != nil {
-		article.AuthorName = user.FirstName + " " + user.LastName
+		setByline(article, authorOf(user))
+		if !user.OrganizationID.IsZero() && !tenant.All {
+			article.OrganizationID = user.OrganizationID
+		}
 	}
+	locale, ok := h.resolveLocale(article.OrganizationID, article.Locale)
+	if !ok {
+		http.Error(w, errLocale.Error(), http.StatusBadRequest)
+		return
+	}
+	article.Locale = locale
 
 	article.CreatedAt = time.Now()
 	article.UpdatedAt = time.Now()
-	article.Slug = GenerateSlug(article.Title)
-	if article.Status == "" {
-		article.Status = "draft"
+	base := GenerateSlug(article.Slug)
+	if base == "" {
+		base = GenerateSlug(article.Title)
 	}
+	article.Slug = ""
+	article.SlugHistory = nil
+	if err := h.classify(r.Context(), article, article.Tags, article.CategoryIDs); err != nil {
+		http.Error(w, err.Error(), taxonomyErrorStatus(err))
+		return
+	}
+	// El estado solo cambia mediante POST /articles/{id}/transitions
+	article.Status = StatusDraft
+	article.PublishedAt = nil
 
-	res, err := Collection.InsertOne(context.Background(), article)
+	err = h.assignSlug(r.Context(), article, base, func() error {
+		return h.Repo.CreateArticle(r.Context(), article)
+	})
+	if err == errSlugTaken {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
 	if err != nil {
 		log.Println(err.Error())
 		http.Error(w, "DB error", http.StatusInternalServerError)
 		return
 	}
 
-	article.ID = res.InsertedID.(primitive.ObjectID)
+	if err := h.Search.Index(*article); err != nil {
+		log.Printf("⚠️ No se pudo indexar %s: %v", article.ID.Hex(), err)
+	}
+	h.publish(r.Context(), events.ArticleCreated, article)
+	w.Header().Set("ETag", versionETag(article))
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(article)
 }
 
-// 📤 Listar todos (público)
-func ListArticlesHandler(w http.ResponseWriter, r *http.Request) {
-	cursor, err := Collection.Find(context.Background(), bson.M{})
+// 📤 Listar publicados (público, paginado)
+func (h *Handlers) ListArticles(w http.ResponseWriter, r *http.Request) {
+	q, err := ParseListQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// El público solo ve lo publicado, sin importar ?status=
+	q.Status = nil
+	tenant, err := h.publicTenant(r)
+	if err != nil {
+		http.Error(w, err.Error(), tenantErrorStatus(err))
+		return
+	}
+
+	h.writePublicPage(w, r, tenant, q)
+}
+
+// writePublicPage lista lo publicado del tenant con el query dado
+func (h *Handlers) writePublicPage(w http.ResponseWriter, r *http.Request, tenant Tenant, q ListQuery) {
+	present, err := h.publicPresenter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	now := time.Now()
+	page, err := listArticles(r.Context(), h.Repo, tenant.scope(Filter{VisibleAt: &now}), q, present)
 	if err != nil {
 		log.Println(err.Error())
 		http.Error(w, "DB error", http.StatusInternalServerError)
 		return
 	}
-	defer cursor.Close(context.Background())
-
-	var articles []Article
-	for cursor.Next(context.Background()) {
-		var art Article
-		if err := cursor.Decode(&art); err == nil {
-			articles = append(articles, art)
-		}
+	modified, keys := pageMeta(tenant.OrgID, page)
+	if h.cacheHeaders(w, r, RouteList, pageETag(r, page, ""), modified, keys) {
+		return
 	}
-
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(articles)
+	json.NewEncoder(w).Encode(page)
 }
 
-// 🙋 Listar solo artículos del usuario (requiere JWT)
-func GetMyArticlesHandler(w http.ResponseWriter, r *http.Request) {
+// 🙋 Listar los artículos del usuario, propios o como colaborador (requiere JWT, paginado).
+// Con ?scope=organization, revisores y admins ven los de toda la organización en cualquier estado.
+func (h *Handlers) GetMyArticles(w http.ResponseWriter, r *http.Request) {
 	userIDStr, ok := r.Context().Value("user_id").(string)
 	if !ok {
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
@@ -132,28 +327,46 @@ func GetMyArticlesHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid user id", http.StatusBadRequest)
 		return
 	}
-	cursor, err := Collection.Find(context.Background(), bson.M{"author_id": userObjID})
+	q, err := ParseListQuery(r.URL.Query())
 	if err != nil {
-		log.Println(err.Error())
-		http.Error(w, "DB error", http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	defer cursor.Close(context.Background())
-
-	var articles []Article
-	for cursor.Next(context.Background()) {
-		var art Article
-		if err := cursor.Decode(&art); err == nil {
-			articles = append(articles, art)
+	filter := Filter{MemberID: userObjID}
+	switch r.URL.Query().Get("scope") {
+	case "":
+		q.AuthorID = nil
+	case "organization":
+		if !reviewsAll(r.Context()) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
 		}
+		filter = Filter{}
+	default:
+		http.Error(w, "Invalid scope", http.StatusBadRequest)
+		return
+	}
+	tenant, err := tenantFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	page, err := listArticles(r.Context(), h.Repo, tenant.scope(filter), q, nil)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(articles)
+	json.NewEncoder(w).Encode(page)
 }
 
-// ✏️ Editar (requiere JWT y autoría)
-func UpdateArticleHandler(w http.ResponseWriter, r *http.Request) {
+// ✏️ Editar (requiere JWT; owner, editor o admin). Con If-Match solo guarda
+// si la revisión sigue siendo esa; si no, 412. Cambiar publish_at o
+// unpublish_at pide el permiso de publicar; si no vienen, no cambian.
+func (h *Handlers) UpdateArticle(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 	objID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -174,35 +387,113 @@ func UpdateArticleHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var payload Article
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+	setPublishAt, setUnpublishAt, err := decodeScheduled(r.Body, &payload)
+	if err != nil {
 		log.Println(err.Error())
-		http.Error(w, "Invalid body", http.StatusBadRequest)
+		writeBodyError(w, err)
 		return
 	}
+	tenant, err := tenantFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	ctx := r.Context()
+	filter := tenant.scope(Filter{ID: objID})
 
-	newSlug := GenerateSlug(payload.Title)
-
-	filter := bson.M{"_id": objID, "author_id": userObjID}
-	update := bson.M{"$set": bson.M{
-		"title":      payload.Title,
-		"content":    payload.Content,
-		"image":      payload.Image,
-		"slug":       newSlug,
-		"status":     payload.Status,
-		"updated_at": time.Now(),
-	}}
-
-	res, err := Collection.UpdateOne(context.Background(), filter, update)
-	if err != nil || res.MatchedCount == 0 {
+	// La versión previa se guarda como revisión una vez sobrescrita
+	current, err := h.Repo.FindArticle(ctx, filter)
+	if err != nil || !canEdit(ctx, current, userObjID) {
 		http.Error(w, "Not authorized or not found", http.StatusForbidden)
 		return
 	}
+	if !ifMatch(r, current) {
+		writePreconditionFailed(w, current)
+		return
+	}
+	// La ventana de publicación que no viene en el cuerpo se conserva
+	publishAt, unpublishAt := current.PublishAt, current.UnpublishAt
+	if setPublishAt {
+		publishAt = payload.PublishAt
+	}
+	if setUnpublishAt {
+		unpublishAt = payload.UnpublishAt
+	}
+	if !validateSchedule(publishAt, unpublishAt) {
+		http.Error(w, "unpublish_at must be after publish_at", http.StatusBadRequest)
+		return
+	}
+	scheduleChanged := !sameTime(publishAt, current.PublishAt) || !sameTime(unpublishAt, current.UnpublishAt)
+	if scheduleChanged && !canSchedule(ctx, current, userObjID) {
+		http.Error(w, errSchedulePermission.Error(), http.StatusForbidden)
+		return
+	}
+	// El guardado exige la revisión leída: si otro guardó en el medio, 412
+	filter.Revision = currentRevision(current)
+	// Sin formato ni bloques, un artículo en Markdown sigue en Markdown
+	if payload.ContentFormat == "" && len(payload.Blocks) == 0 && current.ContentFormat == FormatMarkdown {
+		payload.ContentFormat = FormatMarkdown
+	}
+	if err := prepareContent(&payload); err != nil {
+		writeContentError(w, err)
+		return
+	}
+	if err := validateSEO(payload.SEO); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	updated := *current
+	if err := h.classify(ctx, &updated, payload.Tags, payload.CategoryIDs); err != nil {
+		http.Error(w, err.Error(), taxonomyErrorStatus(err))
+		return
+	}
+	if err := h.applyTranslationUpdate(ctx, &payload, &updated); err != nil {
+		http.Error(w, err.Error(), translationErrorStatus(err))
+		return
+	}
+	slugBase, err := planSlug(current, payload.Slug, payload.Title, middleware.HasRole(ctx, "superadmin", "org_admin"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	updated.Title = payload.Title
+	updated.Content = payload.Content
+	updated.Blocks = payload.Blocks
+	updated.ContentFormat = payload.ContentFormat
+	updated.Image = payload.Image
+	updated.SEO = payload.SEO
+	updated.Revision = currentRevision(current) + 1
+	updated.LastEditedBy = userObjID
+	updated.PublishAt = publishAt
+	updated.UnpublishAt = unpublishAt
+	updated.UpdatedAt = time.Now()
+
+	err = h.assignSlug(ctx, &updated, slugBase, func() error {
+		return h.Repo.UpdateArticle(ctx, filter, &updated)
+	})
+	if err == errSlugTaken {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err == ErrNotFound {
+		writePreconditionFailed(w, nil)
+		return
+	}
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	keepSnapshot(ctx, h.Repo, current)
+	h.reindex(ctx, objID)
+	h.publish(ctx, events.ArticleUpdated, &updated)
 
+	w.Header().Set("ETag", versionETag(&updated))
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"status": "updated"})
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "updated", "slug": updated.Slug, "revision": updated.Revision})
 }
 
-func DeleteArticleHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) DeleteArticle(w http.ResponseWriter, r *http.Request) {
 	id := strings.TrimSpace(r.PathValue("id"))
 	log.Println("🔍 Recibido ID para borrar:", id)
 
@@ -223,29 +514,44 @@ func DeleteArticleHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid user id", http.StatusBadRequest)
 		return
 	}
-	res, err := Collection.DeleteOne(context.Background(), bson.M{
-		"_id":       objID,
-		"author_id": userObjID,
-	})
-
+	tenant, err := tenantFromRequest(r)
 	if err != nil {
-		log.Println("❌ Error al borrar:", err)
-		http.Error(w, "Delete failed", http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusForbidden)
 		return
 	}
-
-	if res.DeletedCount == 0 {
+	filter := tenant.scope(Filter{ID: objID})
+	current, err := h.Repo.FindArticle(r.Context(), filter)
+	if err == nil && !canDelete(r.Context(), current, userObjID) {
+		err = ErrNotFound
+	}
+	if err == nil {
+		err = h.Repo.DeleteArticle(r.Context(), filter)
+	}
+	if err == ErrNotFound {
 		log.Println("⚠️ No se encontró o no es tuyo")
 		http.Error(w, "Not authorized or not found", http.StatusForbidden)
 		return
 	}
+	if err != nil {
+		log.Println("❌ Error al borrar:", err)
+		http.Error(w, "Delete failed", http.StatusInternalServerError)
+		return
+	}
 
+	// El bloqueo de edición ya no tiene sentido
+	if err := h.Repo.ReleaseLock(r.Context(), objID, primitive.NilObjectID); err != nil && err != ErrNotFound {
+		log.Printf("⚠️ No se pudo soltar el bloqueo de %s: %v", objID.Hex(), err)
+	}
+	h.publish(r.Context(), events.ArticleDeleted, current)
+	if err := h.Search.Remove(objID); err != nil {
+		log.Printf("⚠️ No se pudo quitar %s del índice: %v", objID.Hex(), err)
+	}
 	log.Println("✅ Artículo borrado correctamente")
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"status": "deleted"})
 }
 
-func GetArticleByIDHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) GetArticleByID(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 	log.Println("🔎 Buscando artículo con ID:", id)
 
@@ -256,27 +562,86 @@ func GetArticleByIDHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var article bson.M
-	err = Collection.FindOne(context.Background(), bson.M{"_id": objID}).Decode(&article)
+	tenant, err := h.publicTenant(r)
+	if err != nil {
+		http.Error(w, err.Error(), tenantErrorStatus(err))
+		return
+	}
+	present, err := h.publicPresenter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Los borradores solo se comparten con enlaces de vista previa
+	now := time.Now()
+	article, err := h.Repo.FindArticle(r.Context(), tenant.scope(Filter{ID: objID, VisibleAt: &now}))
 	if err != nil {
 		log.Println("❌ Artículo no encontrado:", err)
 		http.Error(w, "Article not found", http.StatusNotFound)
 		return
 	}
+	present(article)
+	if h.cacheHeaders(w, r, RouteByID, readETag(r, article), article.UpdatedAt, []string{articleKey(article.ID), authorKey(article.AuthorID)}) {
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(article)
 }
 
 // Handler para buscar artículo por slug
-func GetArticleBySlugHandler(w http.ResponseWriter, r *http.Request) {
-	slug := r.PathValue("slug")
-	var article Article
-	err := Collection.FindOne(context.Background(), bson.M{"slug": slug}).Decode(&article)
+func (h *Handlers) GetArticleBySlug(w http.ResponseWriter, r *http.Request) {
+	tenant, err := h.publicTenant(r)
 	if err != nil {
-		http.Error(w, "Article not found", http.StatusNotFound)
+		http.Error(w, err.Error(), tenantErrorStatus(err))
+		return
+	}
+	present, err := h.publicPresenter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	article, ok := h.publicBySlug(w, r, tenant, "")
+	if !ok {
+		return
+	}
+	present(article)
+	if h.cacheHeaders(w, r, RouteBySlug, readETag(r, article), article.UpdatedAt, []string{articleKey(article.ID), authorKey(article.AuthorID)}) {
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(article)
 }
+
+// publicBySlug busca el artículo visible con {slug} en la variante de idioma
+// pedida. Un slug anterior redirige al actual (más suffix) para no romper
+// enlaces publicados; si ya respondió devuelve false.
+func (h *Handlers) publicBySlug(w http.ResponseWriter, r *http.Request, tenant Tenant, suffix string) (*Article, bool) {
+	slug := r.PathValue("slug")
+	locales, err := requestedLocales(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	now := time.Now()
+	article, err := h.Repo.FindArticle(r.Context(), tenant.scope(Filter{Slug: slug, VisibleAt: &now}))
+	if err == ErrNotFound {
+		moved, err := h.Repo.FindArticle(r.Context(), tenant.scope(Filter{FormerSlug: slug, VisibleAt: &now}))
+		if err != nil {
+			http.Error(w, "Article not found", http.StatusNotFound)
+			return nil, false
+		}
+		target := "/articles/slug/" + moved.Slug + suffix
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+		return nil, false
+	}
+	if err != nil {
+		http.Error(w, "Article not found", http.StatusNotFound)
+		return nil, false
+	}
+	return h.localize(r.Context(), w, tenant, article, locales), true
+}
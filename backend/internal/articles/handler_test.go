@@ -0,0 +1,526 @@
+package articles
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pittsix/internal/organizations"
+	"pittsix/internal/users"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type fakeUsers struct {
+	users.Repository
+	byID map[primitive.ObjectID]*users.User
+}
+
+func (f *fakeUsers) GetUserByID(id primitive.ObjectID) (*users.User, error) {
+	if u, ok := f.byID[id]; ok {
+		return u, nil
+	}
+	return nil, ErrNotFound
+}
+
+func (f *fakeUsers) DeleteUser(id primitive.ObjectID) error {
+	delete(f.byID, id)
+	return nil
+}
+
+type fakeOrgs struct {
+	organizations.Repository
+	orgs []*organizations.Organization
+}
+
+func (f *fakeOrgs) GetBySlug(slug string) (*organizations.Organization, error) {
+	for _, o := range f.orgs {
+		if o.Slug == slug {
+			return o, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (f *fakeOrgs) GetByID(id primitive.ObjectID) (*organizations.Organization, error) {
+	for _, o := range f.orgs {
+		if o.ID == id {
+			return o, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (f *fakeOrgs) GetByHost(host string) (*organizations.Organization, error) {
+	for _, o := range f.orgs {
+		for _, h := range o.Hosts {
+			if h == host {
+				return o, nil
+			}
+		}
+	}
+	return nil, ErrNotFound
+}
+
+type testEnv struct {
+	h     *Handlers
+	users *fakeUsers
+	orgA  *organizations.Organization
+	orgB  *organizations.Organization
+}
+
+func newTestEnv() *testEnv {
+	orgA := &organizations.Organization{ID: primitive.NewObjectID(), Name: "A", Slug: "org-a", Hosts: []string{"a.example.com"}}
+	orgB := &organizations.Organization{ID: primitive.NewObjectID(), Name: "B", Slug: "org-b"}
+	u := &fakeUsers{byID: map[primitive.ObjectID]*users.User{}}
+	h := NewHandlers(NewMemoryRepository(), NewMemoryTaxonomyRepository(), u, &fakeOrgs{orgs: []*organizations.Organization{orgA, orgB}}, "org-a")
+	return &testEnv{h: h, users: u, orgA: orgA, orgB: orgB}
+}
+
+func (e *testEnv) newUser(org *organizations.Organization) primitive.ObjectID {
+	id := primitive.NewObjectID()
+	e.users.byID[id] = &users.User{ID: id, FirstName: "Ana", LastName: "Pérez", OrganizationID: org.ID}
+	return id
+}
+
+// request arma una petición autenticada como si hubiera pasado por JWTAuth
+func request(method, target, body string, userID primitive.ObjectID, org *organizations.Organization, roles ...string) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	if userID.IsZero() {
+		return r
+	}
+	ctx := context.WithValue(r.Context(), "user_id", userID.Hex())
+	ctx = context.WithValue(ctx, "organization_id", org.ID.Hex())
+	ctx = context.WithValue(ctx, "roles", roles)
+	return r.WithContext(ctx)
+}
+
+func serve(handler http.HandlerFunc, r *http.Request, pathValues ...string) *httptest.ResponseRecorder {
+	for i := 0; i+1 < len(pathValues); i += 2 {
+		r.SetPathValue(pathValues[i], pathValues[i+1])
+	}
+	w := httptest.NewRecorder()
+	handler(w, r)
+	return w
+}
+
+func (e *testEnv) create(t *testing.T, author primitive.ObjectID, org *organizations.Organization, title string) Article {
+	t.Helper()
+	w := serve(e.h.CreateArticle, request("POST", "/articles", `{"title":"`+title+`","content":"hola mundo"}`, author, org))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d %s", w.Code, w.Body.String())
+	}
+	var a Article
+	json.NewDecoder(w.Body).Decode(&a)
+	return a
+}
+
+func (e *testEnv) transition(t *testing.T, id primitive.ObjectID, name string, user primitive.ObjectID, org *organizations.Organization) int {
+	t.Helper()
+	r := request("POST", "/articles/"+id.Hex()+"/transitions", `{"transition":"`+name+`"}`, user, org, "org_admin")
+	return serve(e.h.TransitionArticle, r, "id", id.Hex()).Code
+}
+
+func TestHandlers_CreateStampsAuthorAndOrganization(t *testing.T) {
+	e := newTestEnv()
+	author := e.newUser(e.orgA)
+	a := e.create(t, author, e.orgA, "Mi Primer Artículo")
+
+	if a.ID.IsZero() || a.AuthorID != author || a.OrganizationID != e.orgA.ID {
+		t.Errorf("unexpected article: %+v", a)
+	}
+	if a.Status != StatusDraft || a.Revision != 1 || a.AuthorName != "Ana Pérez" || a.Slug != "mi-primer-articulo" {
+		t.Errorf("unexpected defaults: %+v", a)
+	}
+}
+
+func TestHandlers_PublicReadsOnlySeePublished(t *testing.T) {
+	e := newTestEnv()
+	author := e.newUser(e.orgA)
+	draft := e.create(t, author, e.orgA, "Borrador")
+	pub := e.create(t, author, e.orgA, "Publicado")
+	for _, step := range []string{"submit", "approve", "publish"} {
+		if code := e.transition(t, pub.ID, step, author, e.orgA); code != http.StatusCreated {
+			t.Fatalf("%s: expected 201, got %d", step, code)
+		}
+	}
+
+	w := serve(e.h.ListArticles, request("GET", "/articles", "", primitive.NilObjectID, nil))
+	var page ArticlePage
+	json.NewDecoder(w.Body).Decode(&page)
+	if page.Total != 1 || page.Items[0].ID != pub.ID || page.Items[0].PublishedAt == nil {
+		t.Errorf("expected only the published article, got %+v", page)
+	}
+
+	if w := serve(e.h.GetArticleBySlug, request("GET", "/articles/slug/borrador", "", primitive.NilObjectID, nil), "slug", draft.Slug); w.Code != http.StatusNotFound {
+		t.Errorf("draft should not be readable by slug, got %d", w.Code)
+	}
+	if w := serve(e.h.GetArticleBySlug, request("GET", "/articles/slug/publicado", "", primitive.NilObjectID, nil), "slug", pub.Slug); w.Code != http.StatusOK {
+		t.Errorf("published article should be readable by slug, got %d", w.Code)
+	}
+}
+
+func TestHandlers_TenantIsolation(t *testing.T) {
+	e := newTestEnv()
+	author := e.newUser(e.orgA)
+	intruder := e.newUser(e.orgB)
+	a := e.create(t, author, e.orgA, "Secreto")
+
+	r := request("GET", "/articles/"+a.ID.Hex()+"?org=org-b", "", primitive.NilObjectID, nil)
+	if w := serve(e.h.GetArticleByID, r, "id", a.ID.Hex()); w.Code != http.StatusNotFound {
+		t.Errorf("article must not leak to another organization, got %d", w.Code)
+	}
+	r = request("GET", "/articles/"+a.ID.Hex(), "", primitive.NilObjectID, nil)
+	r.Host = "a.example.com"
+	if w := serve(e.h.GetArticleByID, r, "id", a.ID.Hex()); w.Code != http.StatusNotFound {
+		t.Errorf("drafts must not be readable by ID, got %d", w.Code)
+	}
+	for _, name := range []string{"submit", "approve", "publish"} {
+		e.transition(t, a.ID, name, author, e.orgA)
+	}
+	if w := serve(e.h.GetArticleByID, r, "id", a.ID.Hex()); w.Code != http.StatusOK {
+		t.Errorf("article should resolve by host, got %d", w.Code)
+	}
+
+	if code := e.transition(t, a.ID, "submit", intruder, e.orgB); code != http.StatusNotFound {
+		t.Errorf("org_admin of another organization must not see the article, got %d", code)
+	}
+	r = request("DELETE", "/articles/"+a.ID.Hex(), "", intruder, e.orgB)
+	if w := serve(e.h.DeleteArticle, r, "id", a.ID.Hex()); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 deleting another organization's article, got %d", w.Code)
+	}
+}
+
+func TestHandlers_UpdateKeepsRevisionsAndRestores(t *testing.T) {
+	e := newTestEnv()
+	author := e.newUser(e.orgA)
+	a := e.create(t, author, e.orgA, "Versión uno")
+
+	r := request("PUT", "/articles/"+a.ID.Hex(), `{"title":"Versión dos","content":"nuevo"}`, author, e.orgA)
+	if w := serve(e.h.UpdateArticle, r, "id", a.ID.Hex()); w.Code != http.StatusOK {
+		t.Fatalf("update: expected 200, got %d %s", w.Code, w.Body.String())
+	}
+
+	w := serve(e.h.ListRevisions, request("GET", "/", "", author, e.orgA), "id", a.ID.Hex())
+	var revs []Revision
+	json.NewDecoder(w.Body).Decode(&revs)
+	if len(revs) != 2 || revs[0].Number != 2 || revs[1].Title != "Versión uno" {
+		t.Fatalf("unexpected revisions: %+v", revs)
+	}
+
+	w = serve(e.h.RestoreRevision, request("POST", "/", "", author, e.orgA), "id", a.ID.Hex(), "rev", "1")
+	var restored Article
+	json.NewDecoder(w.Body).Decode(&restored)
+	if w.Code != http.StatusOK || restored.Title != "Versión uno" || restored.Revision != 3 {
+		t.Errorf("unexpected restore: %d %+v", w.Code, restored)
+	}
+}
+
+// failingUpdate hace fallar el próximo UpdateArticle
+type failingUpdate struct {
+	Repository
+	err error
+}
+
+func (f *failingUpdate) UpdateArticle(ctx context.Context, filter Filter, a *Article) error {
+	if err := f.err; err != nil {
+		f.err = nil
+		return err
+	}
+	return f.Repository.UpdateArticle(ctx, filter, a)
+}
+
+func TestHandlers_FailedUpdateLeavesNoRevisionBehind(t *testing.T) {
+	e := newTestEnv()
+	repo := &failingUpdate{Repository: e.h.Repo}
+	e.h.Repo = repo
+	author := e.newUser(e.orgA)
+	a := e.create(t, author, e.orgA, "Versión uno")
+
+	for _, step := range []struct{ target, body string }{
+		{"/articles/" + a.ID.Hex(), `{"title":"Versión dos","content":"nuevo"}`},
+		{"/articles/" + a.ID.Hex() + "/revisions/1/restore", ""},
+	} {
+		repo.err = errors.New("boom")
+		handler, pathValues := e.h.UpdateArticle, []string{"id", a.ID.Hex()}
+		if step.body == "" {
+			handler, pathValues = e.h.RestoreRevision, []string{"id", a.ID.Hex(), "rev", "1"}
+		}
+		r := request("PUT", step.target, step.body, author, e.orgA)
+		if w := serve(handler, r, pathValues...); w.Code != http.StatusInternalServerError {
+			t.Fatalf("%s: expected 500, got %d", step.target, w.Code)
+		}
+		r = request("PUT", step.target, step.body, author, e.orgA)
+		if w := serve(handler, r, pathValues...); w.Code != http.StatusOK {
+			t.Fatalf("%s: the retry must succeed, got %d %s", step.target, w.Code, w.Body.String())
+		}
+	}
+	revs, _ := e.h.Repo.ListRevisions(context.Background(), a.ID)
+	if len(revs) != 2 || revs[0].Number != 2 || revs[1].Number != 1 {
+		t.Errorf("unexpected revisions: %+v", revs)
+	}
+}
+
+// interleavedUpdate corre before justo antes del próximo UpdateArticle, como
+// si otra petición guardara entre la lectura y la escritura
+type interleavedUpdate struct {
+	Repository
+	before func()
+}
+
+func (f *interleavedUpdate) UpdateArticle(ctx context.Context, filter Filter, a *Article) error {
+	if before := f.before; before != nil {
+		f.before = nil
+		before()
+	}
+	return f.Repository.UpdateArticle(ctx, filter, a)
+}
+
+func TestHandlers_ConcurrentUpdatesOfLegacyArticle(t *testing.T) {
+	e := newTestEnv()
+	repo := &interleavedUpdate{Repository: e.h.Repo}
+	e.h.Repo = repo
+	author := e.newUser(e.orgA)
+	a := e.create(t, author, e.orgA, "Artículo viejo")
+	// Los artículos anteriores a las revisiones no tienen número
+	legacy, _ := repo.FindArticle(context.Background(), Filter{ID: a.ID, System: true})
+	legacy.Revision = 0
+	if err := repo.Repository.UpdateArticle(context.Background(), Filter{ID: a.ID, System: true}, legacy); err != nil {
+		t.Fatal(err)
+	}
+
+	put := func(content string) *httptest.ResponseRecorder {
+		r := request("PUT", "/articles/"+a.ID.Hex(), `{"title":"Artículo viejo","content":"`+content+`"}`, author, e.orgA)
+		return serve(e.h.UpdateArticle, r, "id", a.ID.Hex())
+	}
+	var first *httptest.ResponseRecorder
+	repo.before = func() { first = put("primera") }
+	second := put("segunda")
+	if first.Code != http.StatusOK {
+		t.Fatalf("the first edit must be saved, got %d %s", first.Code, first.Body.String())
+	}
+	if second.Code != http.StatusPreconditionFailed {
+		t.Errorf("the second edit read revision 0 and must not overwrite the first, got %d", second.Code)
+	}
+	stored, _ := repo.FindArticle(context.Background(), Filter{ID: a.ID, System: true})
+	if stored.Content != "primera" || stored.Revision != 2 {
+		t.Errorf("expected the first edit at revision 2, got %q at %d", stored.Content, stored.Revision)
+	}
+}
+
+func TestHandlers_StaleTransitionKeepsConcurrentEdit(t *testing.T) {
+	e := newTestEnv()
+	author := e.newUser(e.orgA)
+	a := e.create(t, author, e.orgA, "Carrera")
+	stale, _ := e.h.Repo.FindArticle(context.Background(), Filter{ID: a.ID, System: true})
+
+	r := request("PUT", "/articles/"+a.ID.Hex(), `{"title":"Carrera","content":"editado"}`, author, e.orgA)
+	if w := serve(e.h.UpdateArticle, r, "id", a.ID.Hex()); w.Code != http.StatusOK {
+		t.Fatalf("update: expected 200, got %d %s", w.Code, w.Body.String())
+	}
+	// La transición parte de una lectura anterior al PUT
+	ctx := context.WithValue(context.Background(), "user_id", author.Hex())
+	if _, err := e.h.applyTransition(ctx, stale, Transitions["submit"], TransitionRecord{UserID: author}); err != nil {
+		t.Fatal(err)
+	}
+	stored, _ := e.h.Repo.FindArticle(context.Background(), Filter{ID: a.ID, System: true})
+	if stored.Status != StatusInReview || stored.Content != "editado" || stored.Revision != 2 {
+		t.Fatalf("the transition must not revert the edit: %+v", stored)
+	}
+	r = request("PUT", "/articles/"+a.ID.Hex(), `{"title":"Carrera","content":"otra vez"}`, author, e.orgA)
+	if w := serve(e.h.UpdateArticle, r, "id", a.ID.Hex()); w.Code != http.StatusOK {
+		t.Errorf("later edits keep working, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandlers_SearchScopedToTenant(t *testing.T) {
+	e := newTestEnv()
+	authorA := e.newUser(e.orgA)
+	authorB := e.newUser(e.orgB)
+	e.create(t, authorA, e.orgA, "Goroutines en A")
+	e.create(t, authorB, e.orgB, "Goroutines en B")
+
+	w := serve(e.h.SearchMyArticles, request("GET", "/my-articles/search?q=goroutines", "", authorB, e.orgB, "org_admin"))
+	var res SearchResult
+	json.NewDecoder(w.Body).Decode(&res)
+	if res.Total != 1 || res.Hits[0].Article.OrganizationID != e.orgB.ID {
+		t.Errorf("search leaked across organizations: %+v", res)
+	}
+}
+
+func TestHandlers_SearchPagesAndScopes(t *testing.T) {
+	e := newTestEnv()
+	author := e.newUser(e.orgA)
+	other := e.newUser(e.orgA)
+	for _, title := range []string{"Goroutines uno", "Goroutines dos", "Goroutines tres"} {
+		e.published(t, author, title)
+	}
+	draft := e.create(t, other, e.orgA, "Goroutines en borrador")
+
+	search := func(handler http.HandlerFunc, target string, userID primitive.ObjectID, roles ...string) SearchResult {
+		t.Helper()
+		var org *organizations.Organization
+		if !userID.IsZero() {
+			org = e.orgA
+		}
+		w := serve(handler, request("GET", target, "", userID, org, roles...))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: %d %s", target, w.Code, w.Body.String())
+		}
+		var res SearchResult
+		json.NewDecoder(w.Body).Decode(&res)
+		return res
+	}
+	res := search(e.h.SearchArticles, "/articles/search?q=goroutines&org=org-a&limit=1&offset=1", primitive.NilObjectID)
+	if res.Total != 3 || len(res.Hits) != 1 {
+		t.Errorf("expected 1 of 3 published hits, got %d of %d", len(res.Hits), res.Total)
+	}
+	if statuses := res.Facets["status"]; len(statuses) != 1 || statuses[0].Count != 3 {
+		t.Errorf("facets count every visible match, not only the page: %+v", res.Facets)
+	}
+
+	// Sin permisos de revisión solo aparecen los artículos propios
+	res = search(e.h.SearchMyArticles, "/my-articles/search?q=goroutines", other)
+	if res.Total != 1 || res.Hits[0].Article.ID != draft.ID {
+		t.Errorf("a writer only finds their own articles: %+v", res)
+	}
+	if res = search(e.h.SearchMyArticles, "/my-articles/search?q=goroutines", other, "org_admin"); res.Total != 4 {
+		t.Errorf("an org admin finds every article of the organization, got %d", res.Total)
+	}
+}
+
+func TestHandlers_MyArticlesOrganizationScope(t *testing.T) {
+	e := newTestEnv()
+	author := e.newUser(e.orgA)
+	admin := e.newUser(e.orgA)
+	e.create(t, author, e.orgA, "Borrador")
+	e.published(t, author, "Publicado")
+	e.create(t, e.newUser(e.orgB), e.orgB, "De otra organización")
+
+	list := func(target string, user primitive.ObjectID, roles ...string) (int, ArticlePage) {
+		w := serve(e.h.GetMyArticles, request("GET", target, "", user, e.orgA, roles...))
+		var page ArticlePage
+		json.NewDecoder(w.Body).Decode(&page)
+		return w.Code, page
+	}
+	if code, page := list("/my-articles", admin, "org_admin"); code != http.StatusOK || page.Total != 0 {
+		t.Errorf("without a scope an admin only lists their own articles, got %d %+v", code, page)
+	}
+	if code, _ := list("/my-articles?scope=organization", author); code != http.StatusForbidden {
+		t.Errorf("a writer must not list the whole organization, got %d", code)
+	}
+	code, page := list("/my-articles?scope=organization&limit=1", admin, "org_admin")
+	if code != http.StatusOK || page.Total != 2 || len(page.Items) != 1 || page.NextCursor == "" {
+		t.Fatalf("expected a first page of the 2 organization articles, got %d %+v", code, page)
+	}
+	_, next := list("/my-articles?scope=organization&limit=1&cursor="+page.NextCursor, admin, "org_admin")
+	if len(next.Items) != 1 || next.Items[0].ID == page.Items[0].ID || next.NextCursor != "" {
+		t.Errorf("the cursor must reach the draft too: %+v", next)
+	}
+	if code, _ := list("/my-articles?scope=todo", admin, "org_admin"); code != http.StatusBadRequest {
+		t.Errorf("unknown scopes are rejected, got %d", code)
+	}
+}
+
+func TestScheduler_RunOnceWithMemoryRepository(t *testing.T) {
+	e := newTestEnv()
+	author := e.newUser(e.orgA)
+	a := e.create(t, author, e.orgA, "Programado")
+	e.transition(t, a.ID, "submit", author, e.orgA)
+	e.transition(t, a.ID, "approve", author, e.orgA)
+
+	stored, _ := e.h.Repo.FindArticle(context.Background(), Filter{ID: a.ID, System: true})
+	past := time.Now().Add(-time.Minute)
+	stored.PublishAt = &past
+	e.h.Repo.UpdateArticle(context.Background(), Filter{System: true}, stored)
+
+	s := NewScheduler(e.h.Repo, e.h.Search, time.Minute)
+	published, unpublished, err := s.RunOnce(context.Background())
+	if err != nil || published != 1 || unpublished != 0 {
+		t.Fatalf("unexpected run: %d %d %v", published, unpublished, err)
+	}
+	got, _ := e.h.Repo.FindArticle(context.Background(), Filter{ID: a.ID, System: true})
+	if got.Status != StatusPublished || got.PublishedAt == nil {
+		t.Errorf("expected scheduled article to be published, got %+v", got)
+	}
+	history, _ := e.h.Repo.ListTransitions(context.Background(), a.ID)
+	if len(history) != 3 || history[2].UserName != "scheduler" {
+		t.Errorf("unexpected transition history: %+v", history)
+	}
+}
+
+func TestScheduler_ArticleWithoutOrganization(t *testing.T) {
+	e := newTestEnv()
+	ctx := context.Background()
+	past := time.Now().Add(-time.Minute)
+	// Un artículo viejo sin organización no frena al resto de la agenda
+	legacy := &Article{Title: "Viejo", Status: StatusApproved, PublishAt: &past}
+	e.h.Repo.CreateArticle(ctx, legacy)
+	author := e.newUser(e.orgA)
+	a := e.create(t, author, e.orgA, "Programado")
+	stored, _ := e.h.Repo.FindArticle(ctx, Filter{ID: a.ID, System: true})
+	stored.Status, stored.PublishAt = StatusApproved, &past
+	e.h.Repo.UpdateArticle(ctx, Filter{System: true}, stored)
+
+	published, _, err := NewScheduler(e.h.Repo, e.h.Search, time.Minute).RunOnce(ctx)
+	if err != nil || published != 2 {
+		t.Fatalf("both articles should be published: %d %v", published, err)
+	}
+	if got, _ := e.h.Repo.FindArticle(ctx, Filter{ID: legacy.ID, System: true}); got.Status != StatusPublished {
+		t.Errorf("legacy article: %+v", got)
+	}
+}
+
+func TestHandlers_ScheduleNeedsPublishPermission(t *testing.T) {
+	e := newTestEnv()
+	author := e.newUser(e.orgA)
+	soon := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	body := `{"title":"Programado","content":"x","publish_at":"` + soon + `"}`
+	if w := serve(e.h.CreateArticle, request("POST", "/articles", body, author, e.orgA)); w.Code != http.StatusForbidden {
+		t.Errorf("create with a schedule and no publish permission: expected 403, got %d", w.Code)
+	}
+	a := e.create(t, author, e.orgA, "Programado")
+	put := func(body string, roles ...string) *httptest.ResponseRecorder {
+		return serve(e.h.UpdateArticle, request("PUT", "/articles/"+a.ID.Hex(), body, author, e.orgA, roles...), "id", a.ID.Hex())
+	}
+	if w := put(body); w.Code != http.StatusForbidden {
+		t.Errorf("an owner can't schedule without articles:publish, got %d", w.Code)
+	}
+	if w := serve(e.h.UpdateArticle, withPermissions(request("PUT", "/articles/"+a.ID.Hex(), body, author, e.orgA), "articles:publish"), "id", a.ID.Hex()); w.Code != http.StatusOK {
+		t.Fatalf("with articles:publish: %d %s", w.Code, w.Body.String())
+	}
+
+	// Un PUT sin las fechas conserva la programación
+	if w := put(`{"title":"Programado","content":"otra cosa"}`); w.Code != http.StatusOK {
+		t.Fatalf("plain edit: %d %s", w.Code, w.Body.String())
+	}
+	got, _ := e.h.Repo.FindArticle(context.Background(), Filter{ID: a.ID, System: true})
+	if got.PublishAt == nil || got.PublishAt.UTC().Format(time.RFC3339) != soon {
+		t.Errorf("the schedule must be kept: %+v", got.PublishAt)
+	}
+	if w := put(`{"title":"Programado","content":"otra cosa","publish_at":null}`); w.Code != http.StatusForbidden {
+		t.Errorf("clearing the schedule is also a schedule change, got %d", w.Code)
+	}
+	if w := put(`{"title":"Programado","content":"otra cosa","publish_at":null}`, "org_admin"); w.Code != http.StatusOK {
+		t.Errorf("admins can clear it, got %d", w.Code)
+	}
+	if got, _ = e.h.Repo.FindArticle(context.Background(), Filter{ID: a.ID, System: true}); got.PublishAt != nil {
+		t.Errorf("null clears the schedule: %+v", got.PublishAt)
+	}
+}
+
+func TestHandlers_BodyLimit(t *testing.T) {
+	e := newTestEnv()
+	author := e.newUser(e.orgA)
+	body := `{"title":"Enorme","content":"` + strings.Repeat("a", maxBodyBytes) + `"}`
+	w := httptest.NewRecorder()
+	limitBody(http.HandlerFunc(e.h.CreateArticle)).ServeHTTP(w, request("POST", "/articles", body, author, e.orgA))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected 413, got %d", w.Code)
+	}
+}
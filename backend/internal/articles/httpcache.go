@@ -0,0 +1,237 @@
+package articles
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"pittsix/pkg/events"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 🌐 Caché HTTP de las lecturas públicas. Cada respuesta lleva un ETag fuerte
+// (revisión + huella de lo que cambia la representación), Last-Modified,
+// Cache-Control según la ruta y Surrogate-Key para que la CDN purgue por
+// artículo u organización. Los cambios de contenido disparan los PurgeHook.
+
+// Rutas con Cache-Control propio
+const (
+	RouteList    = "articles.list"
+	RouteByID    = "articles.id"
+	RouteBySlug  = "articles.slug"
+	RouteAuthors = "authors"
+	RouteFeeds   = "feeds"
+	RouteSitemap = "sitemap"
+	RouteRobots  = "robots"
+)
+
+// DefaultCacheControl: poco tiempo en el navegador, más en la CDN, que se
+// purga al cambiar el contenido
+var DefaultCacheControl = map[string]string{
+	RouteList:    "public, max-age=60, s-maxage=300, stale-while-revalidate=60",
+	RouteByID:    "public, max-age=300, s-maxage=3600, stale-while-revalidate=300",
+	RouteBySlug:  "public, max-age=300, s-maxage=3600, stale-while-revalidate=300",
+	RouteAuthors: "public, max-age=300, s-maxage=3600, stale-while-revalidate=300",
+	RouteFeeds:   "public, max-age=900, s-maxage=900, stale-while-revalidate=300",
+	RouteSitemap: "public, max-age=3600, s-maxage=3600, stale-while-revalidate=600",
+	RouteRobots:  "public, max-age=86400",
+}
+
+func articleKey(id primitive.ObjectID) string { return "article-" + id.Hex() }
+func listKey(orgID primitive.ObjectID) string { return "articles-" + orgID.Hex() }
+func authorKey(id primitive.ObjectID) string  { return "author-" + id.Hex() }
+
+// fingerprint suma a la huella todo lo que cambia la representación pública
+// de un artículo sin subir su revisión: la firma y las traducciones
+func fingerprint(buf *bytes.Buffer, a *Article) {
+	fmt.Fprintf(buf, "%s|%d|%d|%s|%s|%s|%s|%s|", a.ID.Hex(), currentRevision(a), a.UpdatedAt.UnixMilli(),
+		a.Status, a.Locale, a.AuthorName, a.AuthorAvatar, a.AuthorBio)
+	for _, t := range a.Translations {
+		fmt.Fprintf(buf, "%s:%s:%s;", t.ID.Hex(), t.Locale, t.Slug)
+	}
+	buf.WriteByte('\n')
+}
+
+func digest(buf *bytes.Buffer) string {
+	sum := sha256.Sum256(buf.Bytes())
+	return hex.EncodeToString(sum[:8])
+}
+
+// readETag es el ETag de la lectura pública de un artículo. Empieza con la
+// revisión ("r12-...") para que sirva de If-Match al editar; el resto separa
+// las variantes (?content=, idioma) y los cambios de firma o traducciones.
+func readETag(r *http.Request, a *Article) string {
+	var buf bytes.Buffer
+	buf.WriteString(r.URL.Query().Get("content") + "\n")
+	fingerprint(&buf, a)
+	return `"r` + strconv.Itoa(currentRevision(a)) + "-" + digest(&buf) + `"`
+}
+
+// pageETag es el ETag de una página de artículos; extra suma lo que la
+// respuesta muestre además de la página
+func pageETag(r *http.Request, page *ArticlePage, extra string) string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s\n%s\n%d|%s\n", r.URL.RawQuery, extra, page.Total, page.NextCursor)
+	for i := range page.Items {
+		fingerprint(&buf, &page.Items[i])
+	}
+	return `"l-` + digest(&buf) + `"`
+}
+
+// pageMeta devuelve la última modificación y las surrogate keys de una página
+func pageMeta(orgID primitive.ObjectID, page *ArticlePage) (time.Time, []string) {
+	var last time.Time
+	keys := []string{listKey(orgID)}
+	authors := map[primitive.ObjectID]bool{}
+	for i := range page.Items {
+		a := &page.Items[i]
+		if a.UpdatedAt.After(last) {
+			last = a.UpdatedAt
+		}
+		keys = append(keys, articleKey(a.ID))
+		if !authors[a.AuthorID] {
+			authors[a.AuthorID] = true
+			keys = append(keys, authorKey(a.AuthorID))
+		}
+	}
+	return last, keys
+}
+
+// cacheHeaders escribe los headers de caché de una lectura pública y responde
+// 304 si el cliente ya tiene esa versión. Devuelve true si ya respondió.
+func (h *Handlers) cacheHeaders(w http.ResponseWriter, r *http.Request, route, etag string, modified time.Time, keys []string) bool {
+	header := w.Header()
+	header.Set("ETag", etag)
+	if !modified.IsZero() {
+		header.Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+	if cc := h.CacheControl[route]; cc != "" {
+		header.Set("Cache-Control", cc)
+	}
+	if len(keys) > 0 {
+		header.Set("Surrogate-Key", strings.Join(keys, " "))
+	}
+	if notModified(r, etag, modified) {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
+// notModified aplica If-None-Match y, si no vino, If-Modified-Since (RFC 9110 §13.2.2)
+func notModified(r *http.Request, etag string, modified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+		return false
+	}
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" || modified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(ims)
+	return err == nil && !modified.Truncate(time.Second).After(since)
+}
+
+// PurgeHook invalida en la CDN las respuestas con alguna de esas surrogate keys
+type PurgeHook func(ctx context.Context, keys []string) error
+
+// Purger reparte las purgas entre los hooks configurados. Un Purger nil o
+// sin hooks no hace nada.
+type Purger struct {
+	Hooks []PurgeHook
+}
+
+// Purge llama a cada hook; los errores solo se loguean para no frenar la escritura
+func (p *Purger) Purge(ctx context.Context, keys ...string) {
+	if p == nil || len(keys) == 0 {
+		return
+	}
+	for _, hook := range p.Hooks {
+		if err := hook(ctx, keys); err != nil {
+			log.Printf("⚠️ No se pudo purgar %v: %v", keys, err)
+		}
+	}
+}
+
+// Listen purga el artículo y las listas de su organización con cada evento de
+// artículos del bus, incluidos los del scheduler, hasta que se cancele el contexto
+func (p *Purger) Listen(ctx context.Context, bus *events.Bus) {
+	var lastID uint64
+	for ctx.Err() == nil {
+		sub, replay, _ := bus.Subscribe(lastID, func(e events.Event) bool {
+			return strings.HasPrefix(e.Type, "article.")
+		})
+		for _, e := range replay {
+			p.purgeEvent(ctx, e)
+			lastID = e.ID
+		}
+	loop:
+		for {
+			select {
+			case <-ctx.Done():
+				break loop
+			case e, ok := <-sub.C:
+				if !ok {
+					break loop
+				}
+				p.purgeEvent(ctx, e)
+				lastID = e.ID
+			}
+		}
+		sub.Close()
+	}
+}
+
+func (p *Purger) purgeEvent(ctx context.Context, e events.Event) {
+	keys := []string{}
+	if id, err := primitive.ObjectIDFromHex(e.SubjectID); err == nil {
+		keys = append(keys, articleKey(id))
+	}
+	if org, err := primitive.ObjectIDFromHex(e.OrganizationID); err == nil {
+		keys = append(keys, listKey(org))
+	}
+	p.Purge(ctx, keys...)
+}
+
+// HTTPPurgeHook purga por surrogate key con un POST al endpoint de la CDN,
+// mandando las keys en el header Surrogate-Key (estilo Fastly)
+func HTTPPurgeHook(endpoint, token string, client *http.Client) PurgeHook {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return func(ctx context.Context, keys []string) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Surrogate-Key", strings.Join(keys, " "))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		res, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		res.Body.Close()
+		if res.StatusCode >= 300 {
+			return fmt.Errorf("purge answered %d", res.StatusCode)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,175 @@
+package articles
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"pittsix/pkg/events"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (e *testEnv) published(t *testing.T, author primitive.ObjectID, title string) Article {
+	t.Helper()
+	a := e.create(t, author, e.orgA, title)
+	for _, name := range []string{"submit", "approve", "publish"} {
+		e.transition(t, a.ID, name, author, e.orgA)
+	}
+	return a
+}
+
+func TestHandlers_PublicReadConditionalGet(t *testing.T) {
+	e := newTestEnv()
+	author := e.newUser(e.orgA)
+	a := e.published(t, author, "Cacheable")
+	id := a.ID.Hex()
+	get := func(target string, headers ...string) *httptest.ResponseRecorder {
+		r := request("GET", target, "", primitive.NilObjectID, nil)
+		for i := 0; i+1 < len(headers); i += 2 {
+			r.Header.Set(headers[i], headers[i+1])
+		}
+		return serve(e.h.GetArticleByID, r, "id", id)
+	}
+
+	w := get("/articles/" + id)
+	etag, modified := w.Header().Get("ETag"), w.Header().Get("Last-Modified")
+	if w.Code != http.StatusOK || !strings.HasPrefix(etag, `"r1-`) || modified == "" {
+		t.Fatalf("first read: %d %q %q", w.Code, etag, modified)
+	}
+	if cc := w.Header().Get("Cache-Control"); cc != DefaultCacheControl[RouteByID] {
+		t.Errorf("cache control: %q", cc)
+	}
+	if keys := w.Header().Get("Surrogate-Key"); keys != "article-"+id+" author-"+author.Hex() {
+		t.Errorf("surrogate keys: %q", keys)
+	}
+
+	if w := get("/articles/"+id, "If-None-Match", `"otro", `+etag); w.Code != http.StatusNotModified || w.Body.Len() != 0 || w.Header().Get("ETag") != etag {
+		t.Errorf("matching If-None-Match: %d", w.Code)
+	}
+	if w := get("/articles/"+id, "If-Modified-Since", modified); w.Code != http.StatusNotModified {
+		t.Errorf("If-Modified-Since at Last-Modified: %d", w.Code)
+	}
+	// If-None-Match manda sobre If-Modified-Since
+	if w := get("/articles/"+id, "If-None-Match", `"viejo"`, "If-Modified-Since", modified); w.Code != http.StatusOK {
+		t.Errorf("a stale ETag gets the body: %d", w.Code)
+	}
+	if w := get("/articles/"+id+"?content=source", "If-None-Match", etag); w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Errorf("each representation has its own ETag: %d", w.Code)
+	}
+
+	// El ETag de lectura sirve de If-Match para editar
+	put := request("PUT", "/articles/"+id, `{"title":"Cacheable","content":"nuevo"}`, author, e.orgA)
+	put.Header.Set("If-Match", etag)
+	if w := serve(e.h.UpdateArticle, put, "id", id); w.Code != http.StatusOK {
+		t.Fatalf("update with the read ETag: %d", w.Code)
+	}
+	if w := get("/articles/"+id, "If-None-Match", etag); w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("ETag"), `"r2-`) {
+		t.Errorf("an edit changes the ETag: %d %q", w.Code, w.Header().Get("ETag"))
+	}
+
+	// La firma no sube la revisión pero cambia la respuesta
+	w = get("/articles/" + id)
+	etag = w.Header().Get("ETag")
+	e.users.byID[author].FirstName = "Ana María"
+	e.h.SyncAuthor(context.Background(), e.users.byID[author])
+	if w := get("/articles/"+id, "If-None-Match", etag); w.Code != http.StatusOK {
+		t.Errorf("a byline change must invalidate the ETag, got %d", w.Code)
+	}
+}
+
+func TestHandlers_PublicListConditionalGet(t *testing.T) {
+	e := newTestEnv()
+	author := e.newUser(e.orgA)
+	e.published(t, author, "Uno")
+	e.h.CacheControl[RouteList] = "public, max-age=5"
+	list := func(inm string) *httptest.ResponseRecorder {
+		r := request("GET", "/articles", "", primitive.NilObjectID, nil)
+		if inm != "" {
+			r.Header.Set("If-None-Match", inm)
+		}
+		return serve(e.h.ListArticles, r)
+	}
+	w := list("")
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || !strings.HasPrefix(etag, `"l-`) || w.Header().Get("Cache-Control") != "public, max-age=5" {
+		t.Fatalf("list: %d %q %q", w.Code, etag, w.Header().Get("Cache-Control"))
+	}
+	if !strings.HasPrefix(w.Header().Get("Surrogate-Key"), "articles-"+e.orgA.ID.Hex()+" article-") {
+		t.Errorf("list surrogate keys: %q", w.Header().Get("Surrogate-Key"))
+	}
+	if w := list(etag); w.Code != http.StatusNotModified {
+		t.Errorf("unchanged list: %d", w.Code)
+	}
+	e.published(t, author, "Dos")
+	if w := list(etag); w.Code != http.StatusOK {
+		t.Errorf("a new article changes the list ETag: %d", w.Code)
+	}
+}
+
+func TestPurger(t *testing.T) {
+	var mu sync.Mutex
+	var purged []string
+	p := &Purger{Hooks: []PurgeHook{func(ctx context.Context, keys []string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		purged = append(purged, strings.Join(keys, " "))
+		return nil
+	}}}
+	bus := events.NewBus(0)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.Listen(ctx, bus)
+		close(done)
+	}()
+	article, org := primitive.NewObjectID(), primitive.NewObjectID()
+	want := "article-" + article.Hex() + " articles-" + org.Hex()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		bus.Publish(events.Event{Type: events.UserUpdated, OrganizationID: org.Hex()})
+		bus.Publish(events.Event{Type: events.ArticleUpdated, OrganizationID: org.Hex(), SubjectID: article.Hex()})
+		mu.Lock()
+		got := append([]string(nil), purged...)
+		mu.Unlock()
+		if len(got) > 0 {
+			if got[0] != want {
+				t.Errorf("purged %q, want %q", got[0], want)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("article events must trigger a purge")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	var nilPurger *Purger
+	nilPurger.Purge(context.Background(), "x")
+}
+
+func TestHTTPPurgeHook(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+	if err := HTTPPurgeHook(srv.URL, "tok", nil)(context.Background(), []string{"a", "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if got.Get("Surrogate-Key") != "a b" {
+		t.Errorf("keys travel in Surrogate-Key: %v", got)
+	}
+	if err := HTTPPurgeHook(srv.URL, "", nil)(context.Background(), []string{"a"}); err == nil {
+		t.Error("a rejected purge is an error")
+	}
+}
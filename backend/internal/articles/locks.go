@@ -0,0 +1,178 @@
+package articles
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 🔒 Edición concurrente. Cada guardado sube la revisión del artículo y la
+// revisión viaja como ETag: un PUT con If-Match de una revisión vieja recibe
+// 412 en lugar de pisar el trabajo de otro. Aparte, el bloqueo de edición es
+// solo un aviso para que la UI muestre quién está editando; no impide guardar.
+
+// lockTTL es cuánto dura un bloqueo sin heartbeat
+const lockTTL = 2 * time.Minute
+
+// EditLock dice quién está editando un artículo
+type EditLock struct {
+	ArticleID      primitive.ObjectID `bson:"_id" json:"article_id"`
+	OrganizationID primitive.ObjectID `bson:"organization_id" json:"organization_id"`
+	UserID         primitive.ObjectID `bson:"user_id" json:"user_id"`
+	UserName       string             `bson:"user_name" json:"user_name"`
+	AcquiredAt     time.Time          `bson:"acquired_at" json:"acquired_at"`
+	HeartbeatAt    time.Time          `bson:"heartbeat_at" json:"heartbeat_at"`
+	ExpiresAt      time.Time          `bson:"expires_at" json:"expires_at"`
+}
+
+// versionETag identifica la revisión actual del artículo
+func versionETag(a *Article) string {
+	return `"r` + strconv.Itoa(currentRevision(a)) + `"`
+}
+
+// ifMatch indica si la petición puede escribir sobre la revisión actual: sin
+// If-Match no se verifica, con "*" vale cualquiera. También acepta el ETag de
+// la lectura pública ("r12-<huella>"), que empieza con la revisión.
+func ifMatch(r *http.Request, a *Article) bool {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		return true
+	}
+	current := versionETag(a)
+	readPrefix := strings.TrimSuffix(current, `"`) + "-"
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == current || strings.HasPrefix(tag, readPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func writePreconditionFailed(w http.ResponseWriter, current *Article) {
+	if current != nil {
+		w.Header().Set("ETag", versionETag(current))
+	}
+	http.Error(w, "Article was modified by someone else; reload it and try again", http.StatusPreconditionFailed)
+}
+
+// lockView es el bloqueo junto con la revisión desde la que se empieza a editar
+type lockView struct {
+	EditLock
+	Revision int `json:"revision"`
+}
+
+func writeLock(w http.ResponseWriter, status int, l *EditLock, a *Article) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", versionETag(a))
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(lockView{EditLock: *l, Revision: currentRevision(a)})
+}
+
+// 🔒 Tomar el bloqueo de edición (requiere JWT y poder editar). Si lo tiene
+// otro usuario responde 423 con su bloqueo.
+func (h *Handlers) AcquireLock(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	article, userID, code, err := h.findArticleFor(ctx, r, canEdit)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	now := time.Now()
+	lock := EditLock{
+		ArticleID:      article.ID,
+		OrganizationID: article.OrganizationID,
+		UserID:         userID,
+		AcquiredAt:     now,
+		HeartbeatAt:    now,
+		ExpiresAt:      now.Add(lockTTL),
+	}
+	if user, err := h.Users.GetUserByID(userID); err == nil && user != nil {
+		lock.UserName = authorOf(user).Name
+	}
+	held, err := h.Repo.AcquireLock(ctx, &lock, now)
+	if err == errLocked {
+		writeLock(w, http.StatusLocked, held, article)
+		return
+	}
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	writeLock(w, http.StatusOK, held, article)
+}
+
+// 💓 Heartbeat: extiende el bloqueo propio. 409 si venció o alguien lo rompió.
+func (h *Handlers) RefreshLock(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	article, userID, code, err := h.findArticleFor(ctx, r, canEdit)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	now := time.Now()
+	lock, err := h.Repo.RefreshLock(ctx, article.ID, userID, now, now.Add(lockTTL))
+	if err == ErrNotFound {
+		http.Error(w, "Lock lost; acquire it again", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	writeLock(w, http.StatusOK, lock, article)
+}
+
+// 🔓 Soltar el bloqueo propio; org_admin y superadmin rompen el de cualquiera
+func (h *Handlers) ReleaseLock(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	article, userID, code, err := h.findArticleFor(ctx, r, canEdit)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	owner := userID
+	if isAdmin(ctx) {
+		owner = primitive.NilObjectID
+	}
+	err = h.Repo.ReleaseLock(ctx, article.ID, owner)
+	if err == ErrNotFound {
+		http.Error(w, "Lock not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "released"})
+}
+
+// 👀 Ver quién está editando (requiere JWT y participar del artículo); 404 si nadie
+func (h *Handlers) GetLock(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	article, _, code, err := h.findArticleFor(ctx, r, canReview)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	lock, err := h.Repo.GetLock(ctx, article.ID)
+	if err == ErrNotFound || (err == nil && !lock.ExpiresAt.After(time.Now())) {
+		http.Error(w, "Nobody is editing", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	writeLock(w, http.StatusOK, lock, article)
+}
@@ -0,0 +1,122 @@
+package articles
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestHandlers_UpdateIfMatch(t *testing.T) {
+	e := newTestEnv()
+	author := e.newUser(e.orgA)
+	w := serve(e.h.CreateArticle, request("POST", "/articles", `{"title":"Concurrente","content":"uno"}`, author, e.orgA))
+	etag := w.Header().Get("ETag")
+	if etag != `"r1"` {
+		t.Fatalf("unexpected ETag on create: %q", etag)
+	}
+	var a Article
+	json.NewDecoder(w.Body).Decode(&a)
+
+	put := func(ifMatch, content string) *http.Response {
+		r := request("PUT", "/articles/"+a.ID.Hex(), `{"title":"Concurrente","content":"`+content+`"}`, author, e.orgA)
+		if ifMatch != "" {
+			r.Header.Set("If-Match", ifMatch)
+		}
+		return serve(e.h.UpdateArticle, r, "id", a.ID.Hex()).Result()
+	}
+	res := put(etag, "dos")
+	if res.StatusCode != http.StatusOK || res.Header.Get("ETag") != `"r2"` {
+		t.Fatalf("update with the current ETag: %d %q", res.StatusCode, res.Header.Get("ETag"))
+	}
+	// Otro editor guarda con la revisión que leyó antes
+	res = put(etag, "tres")
+	if res.StatusCode != http.StatusPreconditionFailed || res.Header.Get("ETag") != `"r2"` {
+		t.Errorf("stale If-Match must fail with the current ETag: %d %q", res.StatusCode, res.Header.Get("ETag"))
+	}
+	if res := put(`W/"r2", "r9"`, "tres"); res.StatusCode != http.StatusOK {
+		t.Errorf("any listed ETag matches, got %d", res.StatusCode)
+	}
+	if res := put("", "cuatro"); res.StatusCode != http.StatusOK {
+		t.Errorf("without If-Match the save goes through, got %d", res.StatusCode)
+	}
+
+	r := request("POST", "/", "", author, e.orgA)
+	r.Header.Set("If-Match", `"r1"`)
+	if w := serve(e.h.RestoreRevision, r, "id", a.ID.Hex(), "rev", "1"); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("restore honors If-Match too, got %d", w.Code)
+	}
+}
+
+func TestRepository_UpdateRequiresRevision(t *testing.T) {
+	repo := NewMemoryRepository()
+	ctx := context.Background()
+	a := &Article{Title: "x", Revision: 3}
+	repo.CreateArticle(ctx, a)
+	a.Revision = 4
+	if err := repo.UpdateArticle(ctx, Filter{Revision: 2, System: true}, a); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound for a stale revision, got %v", err)
+	}
+	if err := repo.UpdateArticle(ctx, Filter{Revision: 3, System: true}, a); err != nil {
+		t.Errorf("update with the read revision: %v", err)
+	}
+}
+
+func TestHandlers_EditLocks(t *testing.T) {
+	e := newTestEnv()
+	owner, editor, viewer, admin := e.newUser(e.orgA), e.newUser(e.orgA), e.newUser(e.orgA), e.newUser(e.orgA)
+	e.users.byID[owner].FirstName = "Olga"
+	a := e.create(t, owner, e.orgA, "Bloqueado")
+	e.addContributor(t, a, owner, editor, RoleEditor)
+	e.addContributor(t, a, owner, viewer, RoleViewer)
+
+	call := func(handler http.HandlerFunc, method string, user primitive.ObjectID, roles ...string) (int, lockView) {
+		w := serve(handler, request(method, "/articles/"+a.ID.Hex()+"/lock", "", user, e.orgA, roles...), "id", a.ID.Hex())
+		var l lockView
+		json.NewDecoder(w.Body).Decode(&l)
+		return w.Code, l
+	}
+	if code, _ := call(e.h.GetLock, "GET", viewer); code != http.StatusNotFound {
+		t.Errorf("nobody is editing yet, got %d", code)
+	}
+	if code, _ := call(e.h.AcquireLock, "POST", viewer); code != http.StatusForbidden {
+		t.Errorf("viewers can't lock, got %d", code)
+	}
+	code, l := call(e.h.AcquireLock, "POST", owner)
+	if code != http.StatusOK || l.UserID != owner || l.UserName != "Olga Pérez" || l.Revision != 1 {
+		t.Fatalf("acquire: %d %+v", code, l)
+	}
+	code, l = call(e.h.AcquireLock, "POST", editor)
+	if code != http.StatusLocked || l.UserID != owner {
+		t.Errorf("a held lock answers 423 with its holder: %d %+v", code, l)
+	}
+	if code, _ := call(e.h.RefreshLock, "PUT", editor); code != http.StatusConflict {
+		t.Errorf("only the holder sends heartbeats, got %d", code)
+	}
+	if code, l := call(e.h.GetLock, "GET", viewer); code != http.StatusOK || l.UserID != owner {
+		t.Errorf("viewers see who is editing: %d %+v", code, l)
+	}
+	if code, _ := call(e.h.RefreshLock, "PUT", owner); code != http.StatusOK {
+		t.Errorf("heartbeat: %d", code)
+	}
+	if code, _ := call(e.h.ReleaseLock, "DELETE", editor); code != http.StatusNotFound {
+		t.Errorf("editors can't break someone else's lock, got %d", code)
+	}
+	if code, _ := call(e.h.ReleaseLock, "DELETE", admin, "org_admin"); code != http.StatusOK {
+		t.Errorf("org_admin breaks locks, got %d", code)
+	}
+	if code, _ := call(e.h.RefreshLock, "PUT", owner); code != http.StatusConflict {
+		t.Errorf("a broken lock can't be refreshed, got %d", code)
+	}
+
+	// Un bloqueo sin heartbeat vence y lo puede tomar otro
+	call(e.h.AcquireLock, "POST", owner)
+	later := time.Now().Add(lockTTL + time.Second)
+	taken, err := e.h.Repo.AcquireLock(context.Background(), &EditLock{ArticleID: a.ID, UserID: editor, ExpiresAt: later.Add(lockTTL)}, later)
+	if err != nil || taken.UserID != editor {
+		t.Errorf("expired locks can be taken: %v", err)
+	}
+}
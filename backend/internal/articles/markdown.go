@@ -0,0 +1,63 @@
+package articles
+
+import (
+	"bytes"
+
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// 📝 Markdown con goldmark y las extensiones de GFM que usamos: tablas, notas
+// al pie, tachado, listas de tareas y autolinks. Los bloques de código con
+// lenguaje conocido los resalta chroma con clases (<pre class="chroma">, los
+// tokens en <span class="…">) y el frontend pone los colores; los demás salen
+// escapados. El HTML crudo se admite y se sanea junto con el resultado.
+
+var markdown = goldmark.New(
+	goldmark.WithExtensions(
+		extension.NewTable(extension.WithTableCellAlignMethod(extension.TableCellAlignAttribute)),
+		extension.Strikethrough,
+		extension.Linkify,
+		extension.TaskList,
+		extension.NewFootnote(extension.WithFootnoteBacklinkHTML("↩")),
+		highlighting.NewHighlighting(highlighting.WithFormatOptions(chromahtml.WithClasses(true))),
+	),
+	goldmark.WithParserOptions(
+		parser.WithASTTransformers(util.Prioritized(taskItems{}, 100)),
+	),
+	goldmark.WithRendererOptions(
+		// El HTML del autor pasa tal cual: lo limpia sanitizeRendered
+		html.WithUnsafe(),
+	),
+)
+
+// RenderMarkdownHTML convierte Markdown a HTML saneado
+func RenderMarkdownHTML(src string) string {
+	var b bytes.Buffer
+	if err := markdown.Convert([]byte(src), &b); err != nil {
+		return SanitizeHTML(src)
+	}
+	return sanitizeRendered(b.String())
+}
+
+// taskItems marca con task-list-item los ítems que empiezan con una casilla
+type taskItems struct{}
+
+func (taskItems) Transform(doc *ast.Document, _ text.Reader, _ parser.Context) {
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if _, ok := n.(*east.TaskCheckBox); ok && entering {
+			if item := n.Parent().Parent(); item != nil && item.Kind() == ast.KindListItem {
+				item.SetAttributeString("class", []byte("task-list-item"))
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+}
@@ -0,0 +1,192 @@
+package articles
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/net/html"
+)
+
+func TestRenderMarkdownHTML(t *testing.T) {
+	src := "# Guía\n\n" +
+		"Texto con *énfasis*, **fuerte**, ~~tachado~~ y `código`. Ver https://go.dev.\n\n" +
+		"| Nombre | Total |\n|:--|--:|\n| a \\| b | 2 |\n\n" +
+		"Dato[^nota].\n\n[^nota]: La fuente.\n\n" +
+		"- [x] hecho\n- [ ] pendiente\n\n" +
+		"3. tres\n4. cuatro\n\n" +
+		"```go\nx := \"hola\" // saludo\n```\n\n" +
+		"<script>alert(1)</script>\n\n[malo](javascript:alert(1)) <span onclick=\"x()\">ok</span>\n"
+	got := RenderMarkdownHTML(src)
+	for _, want := range []string{
+		"<h1>Guía</h1>",
+		"<em>énfasis</em>, <strong>fuerte</strong>, <del>tachado</del> y <code>código</code>",
+		`<a href="https://go.dev" rel="nofollow noopener">https://go.dev</a>.`,
+		"<tr>\n<th align=\"left\">Nombre</th>\n<th align=\"right\">Total</th>\n</tr>",
+		`<td align="left">a | b</td>`,
+		`Dato<sup id="fnref:1"><a href="#fn:1" class="footnote-ref">1</a></sup>.`,
+		`<div class="footnotes">`,
+		"<li id=\"fn:1\">\n<p>La fuente.\u00a0<a href=\"#fnref:1\" class=\"footnote-backref\">↩</a></p>\n</li>",
+		`<li class="task-list-item"><input checked="" disabled="" type="checkbox"> hecho</li>`,
+		`<ol start="3">`,
+		`<pre class="chroma"><code><span class="line"><span class="cl"><span class="nx">x</span> <span class="o">:=</span> <span class="s">&#34;hola&#34;</span> <span class="c1">// saludo`,
+		`<p><a rel="nofollow noopener">malo</a> <span>ok</span></p>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+	for _, bad := range []string{"<script", "javascript:", "onclick"} {
+		if strings.Contains(got, bad) {
+			t.Errorf("rendered markdown must be sanitized, found %q in:\n%s", bad, got)
+		}
+	}
+}
+
+func TestMarkdownEmphasisAndLists(t *testing.T) {
+	cases := map[string]string{
+		"**a*b**":           "<p><strong>a*b</strong></p>\n",
+		"***x***":           "<p><em><strong>x</strong></em></p>\n",
+		"snake_case_name":   "<p>snake_case_name</p>\n",
+		"\\*literal\\*":     "<p>*literal*</p>\n",
+		"línea  \nsigue":    "<p>línea<br>\nsigue</p>\n",
+		"Título\n===":       "<h1>Título</h1>\n",
+		"- a\n  - b\n- c":   "<ul>\n<li>a\n<ul>\n<li>b</li>\n</ul>\n</li>\n<li>c</li>\n</ul>\n",
+		"1. uno\n2. dos":    "<ol>\n<li>uno</li>\n<li>dos</li>\n</ol>\n",
+		"<x@ejemplo.com>":   `<p><a href="mailto:x@ejemplo.com" rel="nofollow noopener">x@ejemplo.com</a></p>` + "\n",
+		"    <b>indentado":  "<pre><code>&lt;b&gt;indentado\n</code></pre>\n",
+		"![logo](/a.png)":   `<p><img src="/a.png" alt="logo"></p>` + "\n",
+		"[sin cerrar](/a":   "<p>[sin cerrar](/a</p>\n",
+		"a ~~b~ c":          "<p>a ~<del>b</del> c</p>\n",
+		"`` a ` b ``":       "<p><code>a ` b</code></p>\n",
+		"> cita\nperezosa":  "<blockquote>\n<p>cita\nperezosa</p>\n</blockquote>\n",
+		"---":               "<hr>\n",
+		"SELECT 1 -- nota":  "<p>SELECT 1 -- nota</p>\n",
+		"[^falta] sin nota": "<p>[^falta] sin nota</p>\n",
+	}
+	for in, want := range cases {
+		if got := RenderMarkdownHTML(in); got != want {
+			t.Errorf("RenderMarkdownHTML(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// FuzzRenderMarkdown busca entradas que dejen pasar HTML peligroso
+func FuzzRenderMarkdown(f *testing.F) {
+	for _, seed := range []string{
+		"# t\n\n*a* **b** ~~c~~ `d` https://go.dev",
+		"| a | b |\n|--|--|\n| 1 | 2 |",
+		"x[^1]\n\n[^1]: nota",
+		"- [x] a\n- [ ] b",
+		"```js\nalert(\"x\")\n```",
+		"<script>alert(1)</script>",
+		"[a](javascript:alert(1)) <img src=x onerror=alert(1)>",
+		"<a href=\"jav&#x09;ascript:x\">a</a>",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, src string) {
+		got := RenderMarkdownHTML(src)
+		nodes, err := parseFragment(got)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var check func(n *html.Node)
+		check = func(n *html.Node) {
+			if n.Type == html.ElementNode {
+				if droppedTags[n.DataAtom] {
+					t.Fatalf("<%s> survived in %q for %q", n.Data, got, src)
+				}
+				for _, a := range n.Attr {
+					if strings.HasPrefix(a.Key, "on") || (a.Key == "href" || a.Key == "src") && !safeLink(a.Val) {
+						t.Fatalf("unsafe %s=%q in %q for %q", a.Key, a.Val, got, src)
+					}
+				}
+			}
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				check(c)
+			}
+		}
+		for _, n := range nodes {
+			check(n)
+		}
+	})
+}
+
+func TestRenderMarkdownHTML_CodeBlocks(t *testing.T) {
+	got := RenderMarkdownHTML("```sql\nSELECT 'x' FROM t -- fin\n```\n")
+	for _, want := range []string{`<pre class="chroma">`, `<span class="k">SELECT</span>`, `<span class="s1">&#39;x&#39;</span>`, `<span class="c1">-- fin`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in %s", want, got)
+		}
+	}
+	if strings.Contains(got, "tabindex") {
+		t.Errorf("only the class survives the sanitizer: %s", got)
+	}
+	if got := RenderMarkdownHTML("```desconocido\n<b>\n```\n"); got != "<pre><code class=\"language-desconocido\">&lt;b&gt;\n</code></pre>\n" {
+		t.Errorf("unknown languages should only be escaped, got %q", got)
+	}
+}
+
+func TestHandlers_MarkdownArticles(t *testing.T) {
+	e := newTestEnv()
+	author := e.newUser(e.orgA)
+
+	body := `{"title":"Formato raro","content":"x","content_format":"rtf"}`
+	if w := serve(e.h.CreateArticle, request("POST", "/articles", body, author, e.orgA)); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for unknown content_format, got %d", w.Code)
+	}
+
+	body = `{"title":"En Markdown","content":"Hola **mundo** <https://go.dev>","content_format":"markdown"}`
+	w := serve(e.h.CreateArticle, request("POST", "/articles", body, author, e.orgA))
+	var a Article
+	json.NewDecoder(w.Body).Decode(&a)
+	if a.Content != "Hola **mundo** <https://go.dev>" || a.ContentFormat != FormatMarkdown {
+		t.Fatalf("markdown source should be stored as written: %+v", a)
+	}
+	for _, name := range []string{"submit", "approve", "publish"} {
+		e.transition(t, a.ID, name, author, e.orgA)
+	}
+
+	get := func(query string) (int, Article) {
+		r := request("GET", "/articles/slug/en-markdown?org=org-a"+query, "", primitive.NilObjectID, nil)
+		w := serve(e.h.GetArticleBySlug, r, "slug", "en-markdown")
+		var got Article
+		json.NewDecoder(w.Body).Decode(&got)
+		return w.Code, got
+	}
+	if code, got := get(""); code != http.StatusOK || !strings.Contains(got.Content, "<strong>mundo</strong>") {
+		t.Errorf("public read should return rendered html by default: %d %q", code, got.Content)
+	}
+	if e.h.Rendered.Stats().Entries != 1 {
+		t.Errorf("rendered html should be cached, len=%d", e.h.Rendered.Stats().Entries)
+	}
+	if _, got := get("&content=source"); got.Content != "Hola **mundo** <https://go.dev>" || got.ContentFormat != FormatMarkdown {
+		t.Errorf("?content=source should return the markdown: %+v", got)
+	}
+	if code, _ := get("&content=pdf"); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for unknown content mode, got %d", code)
+	}
+
+	// Editar sin formato mantiene el Markdown y genera una revisión nueva en caché
+	body = `{"title":"En Markdown","content":"Chau _mundo_"}`
+	r := request("PUT", "/articles/"+a.ID.Hex(), body, author, e.orgA)
+	if w := serve(e.h.UpdateArticle, r, "id", a.ID.Hex()); w.Code != http.StatusOK {
+		t.Fatalf("update: expected 200, got %d %s", w.Code, w.Body.String())
+	}
+	if _, got := get(""); got.Content != "<p>Chau <em>mundo</em></p>\n" {
+		t.Errorf("updated article should render the new revision, got %q", got.Content)
+	}
+
+	r = request("POST", "/articles/"+a.ID.Hex()+"/content/migrate", "", author, e.orgA)
+	if w := serve(e.h.MigrateArticleContent, r, "id", a.ID.Hex()); w.Code != http.StatusOK {
+		t.Fatalf("migrate: expected 200, got %d %s", w.Code, w.Body.String())
+	}
+	got, _ := e.h.Repo.FindArticle(context.Background(), Filter{ID: a.ID, System: true})
+	if got.ContentFormat != FormatBlocks || len(got.Blocks) != 1 || got.Blocks[0].Text != "Chau <em>mundo</em>" {
+		t.Errorf("markdown should migrate to blocks: %+v", got)
+	}
+}
@@ -0,0 +1,80 @@
+package articles
+
+import (
+	"context"
+	"html"
+	"regexp"
+	"strings"
+
+	"pittsix/internal/media"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 🗂️ Referencias a la biblioteca de medios: antes de borrar un archivo se
+// buscan los artículos que usan su URL (o la de alguna de sus versiones) como
+// imagen, en las imágenes SEO o dentro del contenido.
+
+// MediaReferences es el media.ReferenceFinder de los artículos
+func (h *Handlers) MediaReferences(ctx context.Context, orgID primitive.ObjectID, urls []string) ([]media.Reference, error) {
+	found, err := h.Repo.FindByMedia(ctx, orgID, urls)
+	if err != nil {
+		return nil, err
+	}
+	refs := []media.Reference{}
+	for i := range found {
+		for _, field := range mediaFields(&found[i], urls) {
+			refs = append(refs, media.Reference{Kind: "article", ID: found[i].ID.Hex(), Title: found[i].Title, Field: field})
+		}
+	}
+	return refs, nil
+}
+
+// mediaFields dice en qué campos del artículo aparece alguna de las URLs
+func mediaFields(a *Article, urls []string) []string {
+	var fields []string
+	if a.Image != "" && containsString(urls, a.Image) {
+		fields = append(fields, "image")
+	}
+	if a.SEO != nil && ((a.SEO.OGImage != "" && containsString(urls, a.SEO.OGImage)) || (a.SEO.TwitterImage != "" && containsString(urls, a.SEO.TwitterImage))) {
+		fields = append(fields, "seo")
+	}
+	for _, u := range urls {
+		if u == "" {
+			continue
+		}
+		inBlocks := false
+		for _, b := range a.Blocks {
+			inBlocks = inBlocks || b.URL == u
+		}
+		if inBlocks || strings.Contains(a.Content, u) || strings.Contains(a.Content, html.EscapeString(u)) {
+			return append(fields, "content")
+		}
+	}
+	return fields
+}
+
+// mediaCondition es la consulta de Mongo equivalente a mediaFields
+func mediaCondition(urls []string) bson.M {
+	var patterns []string
+	for _, u := range urls {
+		if u == "" {
+			continue
+		}
+		patterns = append(patterns, regexp.QuoteMeta(u))
+		if escaped := html.EscapeString(u); escaped != u {
+			patterns = append(patterns, regexp.QuoteMeta(escaped))
+		}
+	}
+	or := bson.A{
+		bson.M{"image": bson.M{"$in": urls}},
+		bson.M{"seo.og_image": bson.M{"$in": urls}},
+		bson.M{"seo.twitter_image": bson.M{"$in": urls}},
+		bson.M{"blocks.url": bson.M{"$in": urls}},
+	}
+	if len(patterns) > 0 {
+		or = append(or, bson.M{"content": bson.M{"$regex": strings.Join(patterns, "|")}})
+	}
+	return bson.M{"$or": or}
+}
@@ -0,0 +1,58 @@
+package articles
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMediaReferences(t *testing.T) {
+	e := newTestEnv()
+	ctx := context.Background()
+	const (
+		original = "http://minio/mybucket/upload_1_foto.jpg"
+		thumb    = "http://minio/mybucket/upload_1_foto_thumbnail.jpg?v=1&w=320"
+	)
+	add := func(org primitive.ObjectID, a Article) primitive.ObjectID {
+		a.OrganizationID = org
+		if err := e.h.Repo.CreateArticle(ctx, &a); err != nil {
+			t.Fatal(err)
+		}
+		return a.ID
+	}
+	cover := add(e.orgA.ID, Article{Title: "Portada", Slug: "portada", Image: original, SEO: &SEO{OGImage: thumb}})
+	add(e.orgA.ID, Article{Title: "En el texto", Slug: "texto", Content: `<p><img src="` + strings.ReplaceAll(thumb, "&", "&amp;") + `"></p>`})
+	add(e.orgA.ID, Article{Title: "En bloques", Slug: "bloques", Blocks: []Block{{Type: BlockImage, URL: original}}})
+	add(e.orgA.ID, Article{Title: "Otra", Slug: "otra", Image: "http://minio/mybucket/upload_2_otra.jpg"})
+	add(e.orgB.ID, Article{Title: "Ajena", Slug: "ajena", Image: original})
+
+	refs, err := e.h.MediaReferences(ctx, e.orgA.ID, []string{original, thumb})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range refs {
+		if r.Kind != "article" {
+			t.Errorf("unexpected kind %q", r.Kind)
+		}
+		got = append(got, r.Title+":"+r.Field)
+	}
+	want := map[string]bool{"Portada:image": true, "Portada:seo": true, "En el texto:content": true, "En bloques:content": true}
+	if len(got) != len(want) {
+		t.Fatalf("references: %v", got)
+	}
+	for _, g := range got {
+		if !want[g] {
+			t.Errorf("unexpected reference %s (all: %v)", g, got)
+		}
+	}
+	if refs[0].ID == "" || (refs[0].Title == "Portada" && refs[0].ID != cover.Hex()) {
+		t.Errorf("references carry the article id: %+v", refs[0])
+	}
+
+	if refs, _ := e.h.MediaReferences(ctx, e.orgA.ID, []string{"http://minio/mybucket/nada.jpg"}); len(refs) != 0 {
+		t.Errorf("unused media: %v", refs)
+	}
+}
@@ -0,0 +1,240 @@
+package articles
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 👀 Enlaces de vista previa: dan acceso de solo lectura a una revisión de un
+// artículo no publicado a quien no tiene cuenta. El token va firmado con HMAC
+// y dice qué enlace es y hasta cuándo vale; el enlace guardado permite
+// revocarlo antes de que venza.
+
+const (
+	defaultPreviewTTL = 7 * 24 * time.Hour
+	maxPreviewTTL     = 30 * 24 * time.Hour
+)
+
+var errPreviewToken = errors.New("Invalid or expired preview link")
+
+// PreviewLink es un enlace de vista previa emitido para una revisión
+type PreviewLink struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ArticleID      primitive.ObjectID `bson:"article_id" json:"article_id"`
+	OrganizationID primitive.ObjectID `bson:"organization_id" json:"organization_id"`
+	Revision       int                `bson:"revision" json:"revision"`
+	CreatedBy      primitive.ObjectID `bson:"created_by" json:"created_by"`
+	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
+	ExpiresAt      time.Time          `bson:"expires_at" json:"expires_at"`
+	RevokedAt      *time.Time         `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+}
+
+// Active indica si el enlace todavía da acceso
+func (l *PreviewLink) Active(now time.Time) bool {
+	return l.RevokedAt == nil && now.Before(l.ExpiresAt)
+}
+
+// newPreviewSecret genera una clave al azar para cuando no se configura
+// PREVIEW_SECRET; los enlaces emitidos dejan de valer al reiniciar
+func newPreviewSecret() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		log.Fatalf("❌ No se pudo generar la clave de vista previa: %v", err)
+	}
+	return key
+}
+
+func (h *Handlers) previewSignature(payload string) string {
+	mac := hmac.New(sha256.New, h.PreviewSecret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// previewToken firma "<id>.<vencimiento unix>"
+func (h *Handlers) previewToken(l *PreviewLink) string {
+	payload := l.ID.Hex() + "." + strconv.FormatInt(l.ExpiresAt.Unix(), 10)
+	return payload + "." + h.previewSignature(payload)
+}
+
+// parsePreviewToken verifica firma y vencimiento y devuelve el ID del enlace
+func (h *Handlers) parsePreviewToken(token string, now time.Time) (primitive.ObjectID, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return primitive.NilObjectID, errPreviewToken
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(h.previewSignature(payload))) {
+		return primitive.NilObjectID, errPreviewToken
+	}
+	exp, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || !now.Before(time.Unix(exp, 0)) {
+		return primitive.NilObjectID, errPreviewToken
+	}
+	id, err := primitive.ObjectIDFromHex(parts[0])
+	if err != nil {
+		return primitive.NilObjectID, errPreviewToken
+	}
+	return id, nil
+}
+
+// 👀 Crear un enlace de vista previa (requiere JWT; owner, editores o admins).
+// Body opcional: {"revision": n, "expires_in": "48h"}; por defecto la revisión
+// actual y 7 días.
+func (h *Handlers) CreatePreviewLink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	article, userID, code, err := h.findArticleFor(ctx, r, canEdit)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	var input struct {
+		Revision  int    `json:"revision"`
+		ExpiresIn string `json:"expires_in"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			http.Error(w, "Invalid body", http.StatusBadRequest)
+			return
+		}
+	}
+	ttl := defaultPreviewTTL
+	if input.ExpiresIn != "" {
+		ttl, err = time.ParseDuration(input.ExpiresIn)
+		if err != nil || ttl <= 0 || ttl > maxPreviewTTL {
+			http.Error(w, "expires_in must be a duration up to "+maxPreviewTTL.String(), http.StatusBadRequest)
+			return
+		}
+	}
+	if input.Revision == 0 {
+		input.Revision = currentRevision(article)
+	}
+	if _, err := findRevision(ctx, h.Repo, article, input.Revision); err != nil {
+		http.Error(w, "Revision not found", http.StatusNotFound)
+		return
+	}
+
+	now := time.Now()
+	link := PreviewLink{
+		ArticleID:      article.ID,
+		OrganizationID: article.OrganizationID,
+		Revision:       input.Revision,
+		CreatedBy:      userID,
+		CreatedAt:      now,
+		// Segundos exactos: el vencimiento viaja en el token como unix
+		ExpiresAt: now.Add(ttl).Truncate(time.Second),
+	}
+	if err := h.Repo.SavePreviewLink(ctx, &link); err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	token := h.previewToken(&link)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(struct {
+		PreviewLink
+		Token string `json:"token"`
+		URL   string `json:"url"`
+	}{link, token, "/preview/" + token})
+}
+
+// 👀 Listar los enlaces de vista previa del artículo (requiere JWT)
+func (h *Handlers) ListPreviewLinks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	article, _, code, err := h.findArticleFor(ctx, r, canReview)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	links, err := h.Repo.ListPreviewLinks(ctx, article.ID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(links)
+}
+
+// 👀 Revocar un enlace de vista previa (requiere JWT; owner, editores o admins)
+func (h *Handlers) RevokePreviewLink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	article, _, code, err := h.findArticleFor(ctx, r, canEdit)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	linkID, err := primitive.ObjectIDFromHex(r.PathValue("link"))
+	if err != nil {
+		http.Error(w, "Invalid link id", http.StatusBadRequest)
+		return
+	}
+	err = h.Repo.RevokePreviewLink(ctx, article.ID, linkID, time.Now())
+	if err == ErrNotFound {
+		http.Error(w, "Preview link not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "revoked"})
+}
+
+// 👀 Ver la revisión de un enlace de vista previa (público, con token)
+func (h *Handlers) GetPreview(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	// Una vista previa nunca se cachea ni se indexa
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Header().Set("X-Robots-Tag", "noindex, nofollow")
+	present, err := h.publicPresenter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	now := time.Now()
+	linkID, err := h.parsePreviewToken(r.PathValue("token"), now)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	link, err := h.Repo.GetPreviewLink(ctx, linkID)
+	if err != nil || !link.Active(now) {
+		http.Error(w, errPreviewToken.Error(), http.StatusNotFound)
+		return
+	}
+	article, err := h.Repo.FindArticle(ctx, Filter{ID: link.ArticleID, OrganizationID: link.OrganizationID})
+	if err != nil {
+		http.Error(w, "Article not found", http.StatusNotFound)
+		return
+	}
+	rev, err := findRevision(ctx, h.Repo, article, link.Revision)
+	if err != nil {
+		http.Error(w, "Revision not found", http.StatusNotFound)
+		return
+	}
+	preview := *article
+	preview.Title = rev.Title
+	preview.Content = rev.Content
+	preview.Blocks = rev.Blocks
+	preview.ContentFormat = rev.Format
+	preview.Image = rev.Image
+	preview.Revision = rev.Number
+	preview.SlugHistory = nil
+	present(&preview)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(preview)
+}
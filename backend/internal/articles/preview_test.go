@@ -0,0 +1,125 @@
+package articles
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPreviewToken(t *testing.T) {
+	h := &Handlers{PreviewSecret: []byte("clave")}
+	now := time.Now()
+	link := &PreviewLink{ID: primitive.NewObjectID(), ExpiresAt: now.Add(time.Hour).Truncate(time.Second)}
+	token := h.previewToken(link)
+
+	if id, err := h.parsePreviewToken(token, now); err != nil || id != link.ID {
+		t.Fatalf("valid token rejected: %v", err)
+	}
+	if _, err := h.parsePreviewToken(token, now.Add(2*time.Hour)); err == nil {
+		t.Error("expired token should be rejected")
+	}
+	parts := strings.Split(token, ".")
+	forged := parts[0] + "." + strconv.FormatInt(now.Add(48*time.Hour).Unix(), 10) + "." + parts[2]
+	if _, err := h.parsePreviewToken(forged, now); err == nil {
+		t.Error("token with a tampered expiry should be rejected")
+	}
+	other := &Handlers{PreviewSecret: []byte("otra")}
+	if _, err := other.parsePreviewToken(token, now); err == nil {
+		t.Error("token signed with another secret should be rejected")
+	}
+}
+
+func TestHandlers_PreviewLinks(t *testing.T) {
+	e := newTestEnv()
+	author := e.newUser(e.orgA)
+	stranger := e.newUser(e.orgA)
+	a := e.create(t, author, e.orgA, "Borrador")
+
+	r := request("GET", "/articles/"+a.ID.Hex(), "", primitive.NilObjectID, nil)
+	r.Host = "a.example.com"
+	if w := serve(e.h.GetArticleByID, r, "id", a.ID.Hex()); w.Code != http.StatusNotFound {
+		t.Errorf("drafts must be hidden from public reads, got %d", w.Code)
+	}
+
+	create := func(user primitive.ObjectID, body string) *http.Response {
+		r := request("POST", "/articles/"+a.ID.Hex()+"/preview-links", body, user, e.orgA)
+		return serve(e.h.CreatePreviewLink, r, "id", a.ID.Hex()).Result()
+	}
+	if res := create(stranger, ""); res.StatusCode != http.StatusForbidden {
+		t.Errorf("only reviewers can share drafts, got %d", res.StatusCode)
+	}
+	if res := create(author, `{"expires_in":"2000h"}`); res.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for a too long expiry, got %d", res.StatusCode)
+	}
+	res := create(author, `{"expires_in":"1h"}`)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("create preview link: expected 201, got %d", res.StatusCode)
+	}
+	var link struct {
+		PreviewLink
+		Token string `json:"token"`
+		URL   string `json:"url"`
+	}
+	json.NewDecoder(res.Body).Decode(&link)
+	if link.Revision != 1 || link.URL != "/preview/"+link.Token {
+		t.Errorf("unexpected preview link: %+v", link)
+	}
+
+	// La revisión compartida no cambia aunque el borrador siga editándose
+	body := `{"title":"Borrador editado","content":"<p>cambios</p>"}`
+	r = request("PUT", "/articles/"+a.ID.Hex(), body, author, e.orgA)
+	if w := serve(e.h.UpdateArticle, r, "id", a.ID.Hex()); w.Code != http.StatusOK {
+		t.Fatalf("update: %d %s", w.Code, w.Body.String())
+	}
+	preview := func(token string) (int, Article, http.Header) {
+		w := serve(e.h.GetPreview, request("GET", "/preview/"+token, "", primitive.NilObjectID, nil), "token", token)
+		var got Article
+		json.NewDecoder(w.Body).Decode(&got)
+		return w.Code, got, w.Header()
+	}
+	code, got, header := preview(link.Token)
+	if code != http.StatusOK || got.Title != "Borrador" || got.Content != "hola mundo" || got.Revision != 1 {
+		t.Errorf("preview should show the linked revision: %d %+v", code, got)
+	}
+	if header.Get("Cache-Control") != "private, no-store" || header.Get("X-Robots-Tag") == "" {
+		t.Errorf("previews must not be cached or indexed: %v", header)
+	}
+	if code, _, _ := preview(link.Token + "x"); code != http.StatusNotFound {
+		t.Errorf("expected 404 for a tampered token, got %d", code)
+	}
+
+	r = request("GET", "/articles/"+a.ID.Hex()+"/preview-links", "", author, e.orgA)
+	var links []PreviewLink
+	json.NewDecoder(serve(e.h.ListPreviewLinks, r, "id", a.ID.Hex()).Body).Decode(&links)
+	if len(links) != 1 || links[0].ID != link.ID {
+		t.Errorf("unexpected preview links: %+v", links)
+	}
+
+	// Un viewer ve los enlaces pero no los crea ni los revoca
+	viewer := e.newUser(e.orgA)
+	e.addContributor(t, a, author, viewer, RoleViewer)
+	if res := create(viewer, ""); res.StatusCode != http.StatusForbidden {
+		t.Errorf("viewers can't share drafts, got %d", res.StatusCode)
+	}
+	r = request("GET", "/articles/"+a.ID.Hex()+"/preview-links", "", viewer, e.orgA)
+	if w := serve(e.h.ListPreviewLinks, r, "id", a.ID.Hex()); w.Code != http.StatusOK {
+		t.Errorf("viewers can list the links, got %d", w.Code)
+	}
+	r = request("DELETE", "/articles/"+a.ID.Hex()+"/preview-links/"+link.ID.Hex(), "", viewer, e.orgA)
+	if w := serve(e.h.RevokePreviewLink, r, "id", a.ID.Hex(), "link", link.ID.Hex()); w.Code != http.StatusForbidden {
+		t.Errorf("viewers can't revoke links, got %d", w.Code)
+	}
+
+	r = request("DELETE", "/articles/"+a.ID.Hex()+"/preview-links/"+link.ID.Hex(), "", author, e.orgA)
+	if w := serve(e.h.RevokePreviewLink, r, "id", a.ID.Hex(), "link", link.ID.Hex()); w.Code != http.StatusOK {
+		t.Fatalf("revoke: expected 200, got %d", w.Code)
+	}
+	if code, _, _ := preview(link.Token); code != http.StatusNotFound {
+		t.Errorf("revoked links must stop working, got %d", code)
+	}
+}
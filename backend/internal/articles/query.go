@@ -0,0 +1,390 @@
+package articles
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+var sortFields = map[string]bool{"created_at": true, "updated_at": true, "published_at": true}
+
+// ListQuery describe filtros, orden y página de un listado de artículos
+type ListQuery struct {
+	Status         []string
+	AuthorID       *primitive.ObjectID
+	Tag            string
+	OrganizationID *primitive.ObjectID
+	// CategoryIDs lo completan /categories/{slug}/articles con la categoría y sus hijas
+	CategoryIDs []primitive.ObjectID
+	// From/To acotan el campo de orden (created_at por defecto)
+	From  *time.Time
+	To    *time.Time
+	Sort  string
+	Desc  bool
+	Limit int
+	After *pageCursor
+
+	// Lo que sigue no viene de la URL: lo usa el sitemap, que pagina por
+	// posición en orden de creación (Sort "_id") y lee solo algunos campos.
+	Skip int
+	// IDFrom/IDBefore acotan el _id: [IDFrom, IDBefore)
+	IDFrom   *primitive.ObjectID
+	IDBefore *primitive.ObjectID
+	// TranslationGroups deja los artículos traducidos de esos grupos
+	TranslationGroups []primitive.ObjectID
+	// Indexable deja afuera lo marcado noindex
+	Indexable bool
+	// Fields limita los campos leídos (siempre viene _id); vacío lee todo.
+	// El repositorio en memoria devuelve el artículo entero.
+	Fields []string
+}
+
+// pageCursor es la posición de la última fila devuelta; viaja opaco como next_cursor
+type pageCursor struct {
+	Sort  string             `json:"s"`
+	Value *time.Time         `json:"v,omitempty"`
+	ID    primitive.ObjectID `json:"id"`
+}
+
+// ArticlePage es el sobre de respuesta de los listados
+type ArticlePage struct {
+	Items      []Article `json:"items"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+	Total      int64     `json:"total"`
+	Limit      int       `json:"limit"`
+}
+
+// ParseListQuery lee ?status=&author=&tag=&organization=&from=&to=&sort=&order=&limit=&cursor=
+func ParseListQuery(v url.Values) (ListQuery, error) {
+	q := ListQuery{Sort: "created_at", Desc: true, Limit: defaultPageSize}
+
+	for _, s := range v["status"] {
+		for _, part := range strings.Split(s, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				q.Status = append(q.Status, part)
+			}
+		}
+	}
+	if s := v.Get("author"); s != "" {
+		id, err := primitive.ObjectIDFromHex(s)
+		if err != nil {
+			return q, errors.New("invalid author")
+		}
+		q.AuthorID = &id
+	}
+	if s := v.Get("organization"); s != "" {
+		id, err := primitive.ObjectIDFromHex(s)
+		if err != nil {
+			return q, errors.New("invalid organization")
+		}
+		q.OrganizationID = &id
+	}
+	q.Tag = GenerateSlug(strings.TrimSpace(v.Get("tag")))
+
+	var err error
+	if q.From, err = parseDateParam(v.Get("from")); err != nil {
+		return q, errors.New("invalid from date")
+	}
+	if q.To, err = parseDateParam(v.Get("to")); err != nil {
+		return q, errors.New("invalid to date")
+	}
+
+	if s := v.Get("sort"); s != "" {
+		if !sortFields[s] {
+			return q, errors.New("invalid sort")
+		}
+		q.Sort = s
+	}
+	switch v.Get("order") {
+	case "", "desc":
+	case "asc":
+		q.Desc = false
+	default:
+		return q, errors.New("invalid order")
+	}
+	if s := v.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			return q, errors.New("invalid limit")
+		}
+		q.Limit = min(n, maxPageSize)
+	}
+	if s := v.Get("cursor"); s != "" {
+		c, err := decodeCursor(s)
+		if err != nil || c.Sort != q.Sort {
+			return q, errors.New("invalid cursor")
+		}
+		q.After = c
+	}
+	return q, nil
+}
+
+// parseDateParam acepta RFC3339 o YYYY-MM-DD
+func parseDateParam(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func encodeCursor(c pageCursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (*pageCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	var c pageCursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+	if c.ID.IsZero() {
+		return nil, errors.New("empty cursor")
+	}
+	return &c, nil
+}
+
+// sortValue devuelve el valor del campo de orden de un artículo
+func sortValue(a *Article, field string) *time.Time {
+	switch field {
+	case "updated_at":
+		return &a.UpdatedAt
+	case "published_at":
+		return a.PublishedAt
+	case "_id":
+		// Sin valor: compareKeys desempata por _id
+		return nil
+	}
+	return &a.CreatedAt
+}
+
+// conditions arma los filtros del query
+func (q ListQuery) conditions() bson.A {
+	conds := bson.A{}
+	if len(q.Status) > 0 {
+		conds = append(conds, bson.M{"status": bson.M{"$in": q.Status}})
+	}
+	if q.AuthorID != nil {
+		conds = append(conds, bson.M{"author_id": *q.AuthorID})
+	}
+	if q.OrganizationID != nil {
+		conds = append(conds, bson.M{"organization_id": *q.OrganizationID})
+	}
+	if q.Tag != "" {
+		conds = append(conds, bson.M{"tags": q.Tag})
+	}
+	if len(q.CategoryIDs) > 0 {
+		conds = append(conds, bson.M{"category_ids": bson.M{"$in": q.CategoryIDs}})
+	}
+	if q.From != nil || q.To != nil {
+		rng := bson.M{}
+		if q.From != nil {
+			rng["$gte"] = *q.From
+		}
+		if q.To != nil {
+			rng["$lte"] = *q.To
+		}
+		conds = append(conds, bson.M{q.Sort: rng})
+	}
+	if q.IDFrom != nil || q.IDBefore != nil {
+		rng := bson.M{}
+		if q.IDFrom != nil {
+			rng["$gte"] = *q.IDFrom
+		}
+		if q.IDBefore != nil {
+			rng["$lt"] = *q.IDBefore
+		}
+		conds = append(conds, bson.M{"_id": rng})
+	}
+	if len(q.TranslationGroups) > 0 {
+		conds = append(conds, bson.M{"translation_group": bson.M{"$in": q.TranslationGroups}})
+	}
+	if q.Indexable {
+		conds = append(conds, bson.M{"seo.noindex": bson.M{"$ne": true}})
+	}
+	return conds
+}
+
+// cursorCondition pagina por keyset (campo de orden, _id). Los operadores de
+// comparación de Mongo no matchean null, así que los artículos sin valor (p. ej.
+// nunca publicados) se tratan aparte: van primero en asc y al final en desc.
+func (q ListQuery) cursorCondition() bson.M {
+	c := q.After
+	cmp, idCmp := "$gt", "$gt"
+	if q.Desc {
+		cmp, idCmp = "$lt", "$lt"
+	}
+	if c.Value == nil {
+		sameNull := bson.M{q.Sort: nil, "_id": bson.M{idCmp: c.ID}}
+		if q.Desc {
+			return sameNull
+		}
+		return bson.M{"$or": bson.A{sameNull, bson.M{q.Sort: bson.M{"$ne": nil}}}}
+	}
+	or := bson.A{
+		bson.M{q.Sort: bson.M{cmp: *c.Value}},
+		bson.M{q.Sort: *c.Value, "_id": bson.M{idCmp: c.ID}},
+	}
+	if q.Desc {
+		or = append(or, bson.M{q.Sort: nil})
+	}
+	return bson.M{"$or": or}
+}
+
+// matches es el equivalente en memoria de conditions
+func (q ListQuery) matches(a *Article) bool {
+	if len(q.Status) > 0 && !containsString(q.Status, a.Status) {
+		return false
+	}
+	if q.AuthorID != nil && a.AuthorID != *q.AuthorID {
+		return false
+	}
+	if q.OrganizationID != nil && a.OrganizationID != *q.OrganizationID {
+		return false
+	}
+	if q.Tag != "" && !containsString(a.Tags, q.Tag) {
+		return false
+	}
+	if len(q.CategoryIDs) > 0 && !containsAnyID(a.CategoryIDs, q.CategoryIDs) {
+		return false
+	}
+	v := sortValue(a, q.Sort)
+	if q.From != nil && (v == nil || v.Before(*q.From)) {
+		return false
+	}
+	if q.To != nil && (v == nil || v.After(*q.To)) {
+		return false
+	}
+	if q.IDFrom != nil && bytes.Compare(a.ID[:], q.IDFrom[:]) < 0 {
+		return false
+	}
+	if q.IDBefore != nil && bytes.Compare(a.ID[:], q.IDBefore[:]) >= 0 {
+		return false
+	}
+	if len(q.TranslationGroups) > 0 && !containsAnyID([]primitive.ObjectID{a.TranslationGroup}, q.TranslationGroups) {
+		return false
+	}
+	return !q.Indexable || a.SEO == nil || !a.SEO.NoIndex
+}
+
+// compareKeys ordena por (campo de orden, _id) como Mongo: null va primero
+func compareKeys(av *time.Time, aID primitive.ObjectID, bv *time.Time, bID primitive.ObjectID) int {
+	switch {
+	case av == nil && bv != nil:
+		return -1
+	case av != nil && bv == nil:
+		return 1
+	case av != nil && bv != nil && !av.Equal(*bv):
+		return av.Compare(*bv)
+	}
+	return bytes.Compare(aID[:], bID[:])
+}
+
+// less indica si a va antes que b en el orden del query
+func (q ListQuery) less(a, b *Article) bool {
+	c := compareKeys(sortValue(a, q.Sort), a.ID, sortValue(b, q.Sort), b.ID)
+	if q.Desc {
+		return c > 0
+	}
+	return c < 0
+}
+
+// afterCursor es el equivalente en memoria de cursorCondition
+func (q ListQuery) afterCursor(a *Article) bool {
+	if q.After == nil {
+		return true
+	}
+	c := compareKeys(sortValue(a, q.Sort), a.ID, q.After.Value, q.After.ID)
+	if q.Desc {
+		return c < 0
+	}
+	return c > 0
+}
+
+func (q ListQuery) findOptions() *options.FindOptions {
+	dir := 1
+	if q.Desc {
+		dir = -1
+	}
+	order := bson.D{{Key: q.Sort, Value: dir}, {Key: "_id", Value: dir}}
+	if q.Sort == "_id" {
+		order = order[1:]
+	}
+	opts := options.Find().SetSort(order).SetSkip(int64(q.Skip)).SetLimit(int64(q.Limit + 1))
+	if len(q.Fields) > 0 {
+		projection := bson.D{}
+		for _, f := range q.Fields {
+			projection = append(projection, bson.E{Key: f, Value: 1})
+		}
+		opts.SetProjection(projection)
+	}
+	return opts
+}
+
+func andFilter(conds bson.A) bson.M {
+	if len(conds) == 0 {
+		return bson.M{}
+	}
+	return bson.M{"$and": conds}
+}
+
+// listArticles ejecuta el query sobre f (tenant, visibilidad, autoría) y arma
+// la página. ?organization= solo acota algo con ?all_orgs=true.
+func listArticles(ctx context.Context, repo Repository, f Filter, q ListQuery, present func(*Article)) (*ArticlePage, error) {
+	items, total, err := repo.ListArticles(ctx, f, q)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &ArticlePage{Items: items, Total: total, Limit: q.Limit}
+	if page.Items == nil {
+		page.Items = []Article{}
+	}
+	if len(page.Items) > q.Limit {
+		page.Items = page.Items[:q.Limit]
+		last := &page.Items[q.Limit-1]
+		page.NextCursor = encodeCursor(pageCursor{Sort: q.Sort, Value: sortValue(last, q.Sort), ID: last.ID})
+	}
+	if present != nil {
+		for i := range page.Items {
+			present(&page.Items[i])
+		}
+	}
+	return page, nil
+}
+
+func containsAnyID(list, wanted []primitive.ObjectID) bool {
+	for _, id := range list {
+		for _, w := range wanted {
+			if id == w {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package articles
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseListQuery_Defaults(t *testing.T) {
+	q, err := ParseListQuery(url.Values{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if q.Sort != "created_at" || !q.Desc || q.Limit != defaultPageSize || q.After != nil {
+		t.Errorf("unexpected defaults: %+v", q)
+	}
+}
+
+func TestParseListQuery_Filters(t *testing.T) {
+	author := primitive.NewObjectID()
+	v := url.Values{
+		"status": {"draft,in_review"},
+		"author": {author.Hex()},
+		"tag":    {" Go "},
+		"from":   {"2024-01-01"},
+		"sort":   {"updated_at"},
+		"order":  {"asc"},
+		"limit":  {"500"},
+	}
+	q, err := ParseListQuery(v)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(q.Status, []string{"draft", "in_review"}) {
+		t.Errorf("unexpected status: %v", q.Status)
+	}
+	if q.AuthorID == nil || *q.AuthorID != author {
+		t.Errorf("unexpected author: %v", q.AuthorID)
+	}
+	if q.Tag != "go" || q.Sort != "updated_at" || q.Desc || q.Limit != maxPageSize {
+		t.Errorf("unexpected query: %+v", q)
+	}
+	if q.From == nil || !q.From.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected from: %v", q.From)
+	}
+}
+
+func TestParseListQuery_Invalid(t *testing.T) {
+	for _, v := range []url.Values{
+		{"author": {"nope"}},
+		{"sort": {"title"}},
+		{"order": {"sideways"}},
+		{"limit": {"0"}},
+		{"from": {"yesterday"}},
+		{"cursor": {"%%%"}},
+	} {
+		if _, err := ParseListQuery(v); err == nil {
+			t.Errorf("expected error for %v", v)
+		}
+	}
+}
+
+func TestCursor_RoundTrip(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	c := pageCursor{Sort: "created_at", Value: &now, ID: primitive.NewObjectID()}
+	q, err := ParseListQuery(url.Values{"cursor": {encodeCursor(c)}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if q.After.ID != c.ID || !q.After.Value.Equal(now) {
+		t.Errorf("cursor mismatch: %+v", q.After)
+	}
+
+	// Un cursor emitido para otro orden no sirve
+	if _, err := ParseListQuery(url.Values{"cursor": {encodeCursor(c)}, "sort": {"updated_at"}}); err == nil {
+		t.Errorf("expected error for cursor with different sort")
+	}
+}
+
+func TestCursorCondition_NullValues(t *testing.T) {
+	id := primitive.NewObjectID()
+	q := ListQuery{Sort: "published_at", Desc: true, After: &pageCursor{Sort: "published_at", ID: id}}
+	want := bson.M{"published_at": nil, "_id": bson.M{"$lt": id}}
+	if got := q.cursorCondition(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	now := time.Now()
+	q.After.Value = &now
+	or := q.cursorCondition()["$or"].(bson.A)
+	if len(or) != 3 {
+		t.Errorf("desc cursor should include null values, got %v", or)
+	}
+}
@@ -0,0 +1,208 @@
+package articles
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrNotFound          = errors.New("not found")
+	errLocked            = errors.New("Article is being edited by someone else")
+	errDuplicateRevision = errors.New("revision already exists")
+	errUnscoped          = errors.New("article filter without organization")
+)
+
+// Filter selecciona artículos; los campos vacíos no restringen
+type Filter struct {
+	ID             primitive.ObjectID
+	OrganizationID primitive.ObjectID
+	AuthorID       primitive.ObjectID
+	// MemberID busca los artículos del usuario: como autor o como colaborador
+	MemberID primitive.ObjectID
+	Slug     string
+	// FormerSlug busca por un slug que el artículo tuvo antes (SlugHistory)
+	FormerSlug string
+	Status     string
+	Tag        string
+	// Revision exige esa revisión (control de concurrencia optimista); 0 no
+	// restringe. Se compara como currentRevision: 1 también matchea los
+	// artículos viejos sin revisión
+	Revision int
+	// TranslationGroup trae el original y todas sus traducciones
+	TranslationGroup primitive.ObjectID
+	// NoOrganization busca artículos anteriores a la separación por tenant
+	NoOrganization bool
+	// VisibleAt deja solo lo visible para el público en ese momento
+	VisibleAt *time.Time
+	// PublishDue/UnpublishDue: publish_at / unpublish_at ya vencidos en ese momento
+	PublishDue   *time.Time
+	UnpublishDue *time.Time
+	// ScheduledAfter: con publish_at o unpublish_at todavía por llegar
+	ScheduledAfter *time.Time
+	// System permite filtrar sin organización. Solo lo usan las tareas del
+	// sistema (scheduler, backfills, índice, caché) y el superadmin con
+	// ?all_orgs=true; sin él el repositorio rechaza el filtro.
+	System bool
+}
+
+// scoped es la guarda de tenant del repositorio: un filtro sin organización
+// solo pasa si se marcó como del sistema
+func (f Filter) scoped() error {
+	if f.OrganizationID.IsZero() && !f.System {
+		return errUnscoped
+	}
+	return nil
+}
+
+// Patch son los campos que cambia SetFields; los nil quedan como estaban
+type Patch struct {
+	Status      *string
+	PublishedAt *time.Time
+	UpdatedAt   *time.Time
+	// Author cambia el owner junto con su firma
+	Author *Author
+	// Contributors reemplaza la lista entera; vacía la borra
+	Contributors     *[]Contributor
+	TranslationGroup *primitive.ObjectID
+}
+
+// apply vuelca el patch en un artículo en memoria
+func (p Patch) apply(a *Article) {
+	if p.Status != nil {
+		a.Status = *p.Status
+	}
+	if p.PublishedAt != nil {
+		t := *p.PublishedAt
+		a.PublishedAt = &t
+	}
+	if p.UpdatedAt != nil {
+		a.UpdatedAt = *p.UpdatedAt
+	}
+	if p.Author != nil {
+		a.AuthorID = p.Author.ID
+		setByline(a, *p.Author)
+	}
+	if p.Contributors != nil {
+		a.Contributors = nil
+		if len(*p.Contributors) > 0 {
+			a.Contributors = append([]Contributor(nil), *p.Contributors...)
+		}
+	}
+	if p.TranslationGroup != nil {
+		a.TranslationGroup = *p.TranslationGroup
+	}
+}
+
+// Repository persiste artículos, sus revisiones y su historial de transiciones
+type Repository interface {
+	// CreateArticle y UpdateArticle devuelven errSlugTaken si otro artículo de la
+	// organización ya tiene ese slug
+	CreateArticle(ctx context.Context, a *Article) error
+	FindArticle(ctx context.Context, f Filter) (*Article, error)
+	FindArticles(ctx context.Context, f Filter) ([]Article, error)
+	// ListArticles devuelve hasta q.Limit+1 artículos a partir del cursor de q,
+	// junto con el total que matchea sin paginar
+	ListArticles(ctx context.Context, f Filter, q ListQuery) ([]Article, int64, error)
+	// UpdateArticle reemplaza el artículo a.ID si además cumple f; si no, ErrNotFound.
+	// Reemplaza todo el documento: f tiene que exigir la Revision leída o se
+	// pisan las ediciones que entren en el medio.
+	UpdateArticle(ctx context.Context, f Filter, a *Article) error
+	// SetFields cambia solo los campos de p del artículo f.ID si además cumple f,
+	// de forma atómica y sin tocar el resto, y devuelve cómo quedó; si no, ErrNotFound
+	SetFields(ctx context.Context, f Filter, p Patch) (*Article, error)
+	DeleteArticle(ctx context.Context, f Filter) error
+	// ReplaceTag cambia el slug from por to en los artículos de la organización
+	// (to vacío solo lo quita) y devuelve cuántos artículos cambiaron
+	ReplaceTag(ctx context.Context, orgID primitive.ObjectID, from, to string) (int64, error)
+	// UpdateAuthor copia los datos del autor en todos sus artículos sin tocar
+	// updated_at y devuelve cuántos cambiaron
+	UpdateAuthor(ctx context.Context, author Author) (int64, error)
+	// RemoveCategory desasigna la categoría de todos los artículos de la organización
+	RemoveCategory(ctx context.Context, orgID, categoryID primitive.ObjectID) (int64, error)
+	// FindByMedia devuelve los artículos de la organización que usan alguna de
+	// las URLs como imagen, imagen SEO o dentro del contenido (ver media.go)
+	FindByMedia(ctx context.Context, orgID primitive.ObjectID, urls []string) ([]Article, error)
+	// FindArticlesIn devuelve, de los IDs pedidos, los que cumplen el filtro
+	FindArticlesIn(ctx context.Context, f Filter, ids []primitive.ObjectID) ([]Article, error)
+
+	SaveRevision(ctx context.Context, rev *Revision) error
+	GetRevision(ctx context.Context, articleID primitive.ObjectID, number int) (*Revision, error)
+	// ListRevisions devuelve las revisiones guardadas, de la más nueva a la más vieja
+	ListRevisions(ctx context.Context, articleID primitive.ObjectID) ([]Revision, error)
+
+	SaveTransition(ctx context.Context, rec *TransitionRecord) error
+	ListTransitions(ctx context.Context, articleID primitive.ObjectID) ([]TransitionRecord, error)
+
+	SavePreviewLink(ctx context.Context, l *PreviewLink) error
+	GetPreviewLink(ctx context.Context, id primitive.ObjectID) (*PreviewLink, error)
+	// ListPreviewLinks devuelve los enlaces del artículo, del más nuevo al más viejo
+	ListPreviewLinks(ctx context.Context, articleID primitive.ObjectID) ([]PreviewLink, error)
+	// RevokePreviewLink marca el enlace como revocado; ErrNotFound si no es del artículo
+	RevokePreviewLink(ctx context.Context, articleID, id primitive.ObjectID, at time.Time) error
+
+	// AcquireLock toma el bloqueo de edición si está libre, vencido en now o ya
+	// es del mismo usuario; si lo tiene otro devuelve ese bloqueo y errLocked
+	AcquireLock(ctx context.Context, l *EditLock, now time.Time) (*EditLock, error)
+	// RefreshLock extiende el bloqueo vigente del usuario; ErrNotFound si lo perdió
+	RefreshLock(ctx context.Context, articleID, userID primitive.ObjectID, now, expiresAt time.Time) (*EditLock, error)
+	// GetLock devuelve el bloqueo guardado, aunque esté vencido
+	GetLock(ctx context.Context, articleID primitive.ObjectID) (*EditLock, error)
+	// ReleaseLock suelta el bloqueo del usuario; con userID vacío lo rompe sea de quien sea
+	ReleaseLock(ctx context.Context, articleID, userID primitive.ObjectID) error
+}
+
+// Matches evalúa el filtro en memoria con la misma semántica que la consulta a Mongo
+func (f Filter) Matches(a *Article) bool {
+	if !f.ID.IsZero() && a.ID != f.ID {
+		return false
+	}
+	if !f.OrganizationID.IsZero() && a.OrganizationID != f.OrganizationID {
+		return false
+	}
+	if !f.AuthorID.IsZero() && a.AuthorID != f.AuthorID {
+		return false
+	}
+	if !f.MemberID.IsZero() && articleRole(a, f.MemberID) == "" {
+		return false
+	}
+	if f.Slug != "" && a.Slug != f.Slug {
+		return false
+	}
+	if f.FormerSlug != "" && !containsString(a.SlugHistory, f.FormerSlug) {
+		return false
+	}
+	if !f.TranslationGroup.IsZero() && a.ID != f.TranslationGroup && a.TranslationGroup != f.TranslationGroup {
+		return false
+	}
+	if f.Revision != 0 && currentRevision(a) != f.Revision {
+		return false
+	}
+	if f.Status != "" && a.Status != f.Status {
+		return false
+	}
+	if f.Tag != "" && !containsString(a.Tags, f.Tag) {
+		return false
+	}
+	if f.NoOrganization && !a.OrganizationID.IsZero() {
+		return false
+	}
+	if f.VisibleAt != nil && !IsPubliclyVisible(a, *f.VisibleAt) {
+		return false
+	}
+	if f.PublishDue != nil && (a.PublishAt == nil || a.PublishAt.After(*f.PublishDue)) {
+		return false
+	}
+	if f.UnpublishDue != nil && (a.UnpublishAt == nil || a.UnpublishAt.After(*f.UnpublishDue)) {
+		return false
+	}
+	if f.ScheduledAfter != nil {
+		t := *f.ScheduledAfter
+		if !(a.PublishAt != nil && a.PublishAt.After(t)) && !(a.UnpublishAt != nil && a.UnpublishAt.After(t)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,423 @@
+package articles
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryRepository guarda todo en memoria; sirve para tests y desarrollo local
+type MemoryRepository struct {
+	mu          sync.RWMutex
+	articles    map[primitive.ObjectID]Article
+	revisions   map[primitive.ObjectID][]Revision
+	transitions map[primitive.ObjectID][]TransitionRecord
+	previews    map[primitive.ObjectID]PreviewLink
+	locks       map[primitive.ObjectID]EditLock
+}
+
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		articles:    map[primitive.ObjectID]Article{},
+		revisions:   map[primitive.ObjectID][]Revision{},
+		transitions: map[primitive.ObjectID][]TransitionRecord{},
+		previews:    map[primitive.ObjectID]PreviewLink{},
+		locks:       map[primitive.ObjectID]EditLock{},
+	}
+}
+
+// cloneArticle evita que quien llama comparta slices con el repositorio
+func cloneArticle(a Article) Article {
+	if a.Tags != nil {
+		a.Tags = append([]string(nil), a.Tags...)
+	}
+	if a.SlugHistory != nil {
+		a.SlugHistory = append([]string(nil), a.SlugHistory...)
+	}
+	if a.Contributors != nil {
+		a.Contributors = append([]Contributor(nil), a.Contributors...)
+	}
+	if a.CategoryIDs != nil {
+		a.CategoryIDs = append([]primitive.ObjectID(nil), a.CategoryIDs...)
+	}
+	if a.SEO != nil {
+		seo := *a.SEO
+		a.SEO = &seo
+	}
+	// Como en Mongo, los campos bson:"-" no se guardan
+	a.Translations = nil
+	a.Excerpt, a.WordCount, a.ReadingTime = "", 0, 0
+	return a
+}
+
+// slugTaken replica el índice único (organization_id, slug) de Mongo
+func (r *MemoryRepository) slugTaken(a *Article) bool {
+	if a.OrganizationID.IsZero() {
+		return false
+	}
+	for id, other := range r.articles {
+		if id != a.ID && other.OrganizationID == a.OrganizationID && other.Slug == a.Slug {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *MemoryRepository) CreateArticle(ctx context.Context, a *Article) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if a.ID.IsZero() {
+		a.ID = primitive.NewObjectID()
+	}
+	if r.slugTaken(a) {
+		return errSlugTaken
+	}
+	r.articles[a.ID] = cloneArticle(*a)
+	return nil
+}
+
+func (r *MemoryRepository) FindArticle(ctx context.Context, f Filter) (*Article, error) {
+	if err := f.scoped(); err != nil {
+		return nil, err
+	}
+	found, err := r.FindArticles(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		return nil, ErrNotFound
+	}
+	return &found[0], nil
+}
+
+func (r *MemoryRepository) FindArticles(ctx context.Context, f Filter) ([]Article, error) {
+	if err := f.scoped(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := []Article{}
+	for _, a := range r.articles {
+		if f.Matches(&a) {
+			out = append(out, cloneArticle(a))
+		}
+	}
+	// Orden estable para que FindArticle sea determinista
+	sort.Slice(out, func(i, j int) bool { return out[i].ID.Hex() < out[j].ID.Hex() })
+	return out, nil
+}
+
+func (r *MemoryRepository) ListArticles(ctx context.Context, f Filter, q ListQuery) ([]Article, int64, error) {
+	if err := f.scoped(); err != nil {
+		return nil, 0, err
+	}
+	all, err := r.FindArticles(ctx, f)
+	if err != nil {
+		return nil, 0, err
+	}
+	matched := []Article{}
+	for i := range all {
+		if q.matches(&all[i]) {
+			matched = append(matched, all[i])
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return q.less(&matched[i], &matched[j]) })
+
+	page := []Article{}
+	skip := q.Skip
+	for i := range matched {
+		if len(page) > q.Limit {
+			break
+		}
+		if !q.afterCursor(&matched[i]) {
+			continue
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		page = append(page, matched[i])
+	}
+	return page, int64(len(matched)), nil
+}
+
+func (r *MemoryRepository) UpdateArticle(ctx context.Context, f Filter, a *Article) error {
+	if err := f.scoped(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.articles[a.ID]
+	if !ok || !f.Matches(&current) {
+		return ErrNotFound
+	}
+	if r.slugTaken(a) {
+		return errSlugTaken
+	}
+	r.articles[a.ID] = cloneArticle(*a)
+	return nil
+}
+
+func (r *MemoryRepository) SetFields(ctx context.Context, f Filter, p Patch) (*Article, error) {
+	if err := f.scoped(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.articles[f.ID]
+	if f.ID.IsZero() || !ok || !f.Matches(&current) {
+		return nil, ErrNotFound
+	}
+	p.apply(&current)
+	r.articles[current.ID] = cloneArticle(current)
+	return &current, nil
+}
+
+func (r *MemoryRepository) DeleteArticle(ctx context.Context, f Filter) error {
+	if err := f.scoped(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, a := range r.articles {
+		if f.Matches(&a) {
+			delete(r.articles, id)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func (r *MemoryRepository) ReplaceTag(ctx context.Context, orgID primitive.ObjectID, from, to string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var n int64
+	for id, a := range r.articles {
+		if a.OrganizationID != orgID || !containsString(a.Tags, from) {
+			continue
+		}
+		tags := []string{}
+		for _, t := range a.Tags {
+			if t == from {
+				t = to
+			}
+			if t != "" && !containsString(tags, t) {
+				tags = append(tags, t)
+			}
+		}
+		a.Tags = tags
+		r.articles[id] = a
+		n++
+	}
+	return n, nil
+}
+
+func (r *MemoryRepository) UpdateAuthor(ctx context.Context, author Author) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var n int64
+	for id, a := range r.articles {
+		if a.AuthorID != author.ID || byline(&a) == author {
+			continue
+		}
+		setByline(&a, author)
+		r.articles[id] = a
+		n++
+	}
+	return n, nil
+}
+
+func (r *MemoryRepository) FindByMedia(ctx context.Context, orgID primitive.ObjectID, urls []string) ([]Article, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := []Article{}
+	for _, a := range r.articles {
+		if a.OrganizationID == orgID && len(mediaFields(&a, urls)) > 0 {
+			out = append(out, cloneArticle(a))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID.Hex() < out[j].ID.Hex() })
+	return out, nil
+}
+
+func (r *MemoryRepository) FindArticlesIn(ctx context.Context, f Filter, ids []primitive.ObjectID) ([]Article, error) {
+	if err := f.scoped(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := []Article{}
+	for _, id := range ids {
+		if a, ok := r.articles[id]; ok && f.Matches(&a) {
+			out = append(out, cloneArticle(a))
+		}
+	}
+	return out, nil
+}
+
+func (r *MemoryRepository) RemoveCategory(ctx context.Context, orgID, categoryID primitive.ObjectID) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var n int64
+	for id, a := range r.articles {
+		if a.OrganizationID != orgID {
+			continue
+		}
+		kept := []primitive.ObjectID{}
+		for _, c := range a.CategoryIDs {
+			if c != categoryID {
+				kept = append(kept, c)
+			}
+		}
+		if len(kept) != len(a.CategoryIDs) {
+			a.CategoryIDs = kept
+			r.articles[id] = a
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *MemoryRepository) SaveRevision(ctx context.Context, rev *Revision) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.revisions[rev.ArticleID] {
+		if existing.Number == rev.Number {
+			return errDuplicateRevision
+		}
+	}
+	if rev.ID.IsZero() {
+		rev.ID = primitive.NewObjectID()
+	}
+	r.revisions[rev.ArticleID] = append(r.revisions[rev.ArticleID], *rev)
+	return nil
+}
+
+func (r *MemoryRepository) GetRevision(ctx context.Context, articleID primitive.ObjectID, number int) (*Revision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, rev := range r.revisions[articleID] {
+		if rev.Number == number {
+			return &rev, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryRepository) ListRevisions(ctx context.Context, articleID primitive.ObjectID) ([]Revision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := append([]Revision{}, r.revisions[articleID]...)
+	sort.Slice(out, func(i, j int) bool { return out[i].Number > out[j].Number })
+	return out, nil
+}
+
+func (r *MemoryRepository) SaveTransition(ctx context.Context, rec *TransitionRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if rec.ID.IsZero() {
+		rec.ID = primitive.NewObjectID()
+	}
+	r.transitions[rec.ArticleID] = append(r.transitions[rec.ArticleID], *rec)
+	return nil
+}
+
+func (r *MemoryRepository) ListTransitions(ctx context.Context, articleID primitive.ObjectID) ([]TransitionRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]TransitionRecord{}, r.transitions[articleID]...), nil
+}
+
+func (r *MemoryRepository) SavePreviewLink(ctx context.Context, l *PreviewLink) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if l.ID.IsZero() {
+		l.ID = primitive.NewObjectID()
+	}
+	r.previews[l.ID] = *l
+	return nil
+}
+
+func (r *MemoryRepository) GetPreviewLink(ctx context.Context, id primitive.ObjectID) (*PreviewLink, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	l, ok := r.previews[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &l, nil
+}
+
+func (r *MemoryRepository) ListPreviewLinks(ctx context.Context, articleID primitive.ObjectID) ([]PreviewLink, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := []PreviewLink{}
+	for _, l := range r.previews {
+		if l.ArticleID == articleID {
+			out = append(out, l)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (r *MemoryRepository) RevokePreviewLink(ctx context.Context, articleID, id primitive.ObjectID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	l, ok := r.previews[id]
+	if !ok || l.ArticleID != articleID {
+		return ErrNotFound
+	}
+	if l.RevokedAt == nil {
+		l.RevokedAt = &at
+		r.previews[id] = l
+	}
+	return nil
+}
+
+func (r *MemoryRepository) AcquireLock(ctx context.Context, l *EditLock, now time.Time) (*EditLock, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if held, ok := r.locks[l.ArticleID]; ok && held.UserID != l.UserID && held.ExpiresAt.After(now) {
+		return &held, errLocked
+	}
+	r.locks[l.ArticleID] = *l
+	return l, nil
+}
+
+func (r *MemoryRepository) RefreshLock(ctx context.Context, articleID, userID primitive.ObjectID, now, expiresAt time.Time) (*EditLock, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	l, ok := r.locks[articleID]
+	if !ok || l.UserID != userID || !l.ExpiresAt.After(now) {
+		return nil, ErrNotFound
+	}
+	l.HeartbeatAt = now
+	l.ExpiresAt = expiresAt
+	r.locks[articleID] = l
+	return &l, nil
+}
+
+func (r *MemoryRepository) GetLock(ctx context.Context, articleID primitive.ObjectID) (*EditLock, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	l, ok := r.locks[articleID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &l, nil
+}
+
+func (r *MemoryRepository) ReleaseLock(ctx context.Context, articleID, userID primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	l, ok := r.locks[articleID]
+	if !ok || (!userID.IsZero() && l.UserID != userID) {
+		return ErrNotFound
+	}
+	delete(r.locks, articleID)
+	return nil
+}
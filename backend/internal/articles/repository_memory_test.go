@@ -0,0 +1,68 @@
+package articles
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMemoryRepository_ListPaginatesWithCursor(t *testing.T) {
+	repo := NewMemoryRepository()
+	ctx := context.Background()
+	org := primitive.NewObjectID()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		repo.CreateArticle(ctx, &Article{OrganizationID: org, CreatedAt: base.Add(time.Duration(i) * time.Hour), Status: StatusDraft, Slug: "a-" + strconv.Itoa(i)})
+	}
+	repo.CreateArticle(ctx, &Article{OrganizationID: primitive.NewObjectID(), CreatedAt: base})
+
+	q := ListQuery{Sort: "created_at", Desc: true, Limit: 2}
+	seen := []time.Time{}
+	for page := 0; page < 5; page++ {
+		items, total, err := repo.ListArticles(ctx, Filter{OrganizationID: org}, q)
+		if err != nil || total != 5 {
+			t.Fatalf("unexpected list: total %d err %v", total, err)
+		}
+		n := min(len(items), q.Limit)
+		for _, a := range items[:n] {
+			seen = append(seen, a.CreatedAt)
+		}
+		if len(items) <= q.Limit {
+			break
+		}
+		last := items[n-1]
+		q.After = &pageCursor{Sort: q.Sort, Value: sortValue(&last, q.Sort), ID: last.ID}
+	}
+	if len(seen) != 5 {
+		t.Fatalf("expected 5 articles across pages, got %d", len(seen))
+	}
+	for i := 1; i < len(seen); i++ {
+		if !seen[i].Before(seen[i-1]) {
+			t.Errorf("pages out of order: %v", seen)
+		}
+	}
+}
+
+func TestMemoryRepository_UpdateIsConditional(t *testing.T) {
+	repo := NewMemoryRepository()
+	ctx := context.Background()
+	a := &Article{Status: StatusInReview}
+	repo.CreateArticle(ctx, a)
+
+	a.Status = StatusApproved
+	if err := repo.UpdateArticle(ctx, Filter{Status: StatusDraft, System: true}, a); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound when the guard does not match, got %v", err)
+	}
+	if err := repo.UpdateArticle(ctx, Filter{Status: StatusInReview, System: true}, a); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := repo.SaveRevision(ctx, &Revision{ArticleID: a.ID, Number: 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := repo.SaveRevision(ctx, &Revision{ArticleID: a.ID, Number: 1}); err != errDuplicateRevision {
+		t.Errorf("expected errDuplicateRevision, got %v", err)
+	}
+}
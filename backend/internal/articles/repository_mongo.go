@@ -0,0 +1,543 @@
+package articles
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoRepository struct {
+	collection  *mongo.Collection
+	revisions   *mongo.Collection
+	transitions *mongo.Collection
+	previews    *mongo.Collection
+	locks       *mongo.Collection
+}
+
+// NewMongoRepository usa la colección de artículos y, en la misma base,
+// article_revisions, article_transitions, article_preview_links y article_locks
+func NewMongoRepository(collection *mongo.Collection) *MongoRepository {
+	db := collection.Database()
+	r := &MongoRepository{
+		collection:  collection,
+		revisions:   db.Collection("article_revisions"),
+		transitions: db.Collection("article_transitions"),
+		previews:    db.Collection("article_preview_links"),
+		locks:       db.Collection("article_locks"),
+	}
+	r.ensureIndexes()
+	return r
+}
+
+func (r *MongoRepository) Collection() *mongo.Collection {
+	return r.collection
+}
+
+func (r *MongoRepository) ensureIndexes() {
+	_, err := r.revisions.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "article_id", Value: 1}, {Key: "number", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Printf("⚠️ No se pudo crear el índice de revisiones: %v", err)
+	}
+	_, err = r.collection.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: "organization_id", Value: 1}, {Key: "slug_history", Value: 1}},
+	})
+	if err != nil {
+		log.Printf("⚠️ No se pudo crear el índice de slugs anteriores: %v", err)
+	}
+	_, err = r.collection.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: "translation_group", Value: 1}, {Key: "locale", Value: 1}},
+	})
+	if err != nil {
+		log.Printf("⚠️ No se pudo crear el índice de traducciones: %v", err)
+	}
+	_, err = r.collection.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: "contributors.user_id", Value: 1}},
+	})
+	if err != nil {
+		log.Printf("⚠️ No se pudo crear el índice de colaboradores: %v", err)
+	}
+	_, err = r.collection.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: "author_id", Value: 1}, {Key: "organization_id", Value: 1}},
+	})
+	if err != nil {
+		log.Printf("⚠️ No se pudo crear el índice de autores: %v", err)
+	}
+	_, err = r.previews.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: "article_id", Value: 1}, {Key: "created_at", Value: -1}},
+	})
+	if err != nil {
+		log.Printf("⚠️ No se pudo crear el índice de enlaces de vista previa: %v", err)
+	}
+	// Mongo borra solo los bloqueos vencidos; hasta que pasa, AcquireLock los ignora
+	_, err = r.locks.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		log.Printf("⚠️ No se pudo crear el índice de bloqueos de edición: %v", err)
+	}
+}
+
+// EnsureSlugIndex crea el índice único (organization_id, slug). Falla si hay
+// slugs repetidos, por eso se llama después de Handlers.BackfillSlugs; los
+// artículos sin organización quedan afuera.
+func (r *MongoRepository) EnsureSlugIndex(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "organization_id", Value: 1}, {Key: "slug", Value: 1}},
+		Options: options.Index().SetUnique(true).
+			SetPartialFilterExpression(bson.M{"organization_id": bson.M{"$exists": true}}),
+	})
+	return err
+}
+
+// bson traduce el filtro a una consulta de Mongo
+func (f Filter) bson() bson.M {
+	conds := bson.A{}
+	if !f.ID.IsZero() {
+		conds = append(conds, bson.M{"_id": f.ID})
+	}
+	if !f.OrganizationID.IsZero() {
+		conds = append(conds, bson.M{"organization_id": f.OrganizationID})
+	}
+	if !f.AuthorID.IsZero() {
+		conds = append(conds, bson.M{"author_id": f.AuthorID})
+	}
+	if !f.MemberID.IsZero() {
+		conds = append(conds, bson.M{"$or": bson.A{
+			bson.M{"author_id": f.MemberID},
+			bson.M{"contributors.user_id": f.MemberID},
+		}})
+	}
+	if f.Slug != "" {
+		conds = append(conds, bson.M{"slug": f.Slug})
+	}
+	if f.FormerSlug != "" {
+		conds = append(conds, bson.M{"slug_history": f.FormerSlug})
+	}
+	if !f.TranslationGroup.IsZero() {
+		conds = append(conds, bson.M{"$or": bson.A{
+			bson.M{"_id": f.TranslationGroup},
+			bson.M{"translation_group": f.TranslationGroup},
+		}})
+	}
+	switch f.Revision {
+	case 0:
+	case 1:
+		// Los artículos anteriores a las revisiones tienen 0 o no tienen el campo
+		conds = append(conds, bson.M{"revision": bson.M{"$in": bson.A{1, 0, nil}}})
+	default:
+		conds = append(conds, bson.M{"revision": f.Revision})
+	}
+	if f.Status != "" {
+		conds = append(conds, bson.M{"status": f.Status})
+	}
+	if f.Tag != "" {
+		conds = append(conds, bson.M{"tags": f.Tag})
+	}
+	if f.NoOrganization {
+		conds = append(conds, bson.M{"organization_id": nil})
+	}
+	if f.VisibleAt != nil {
+		conds = append(conds, publicFilter(*f.VisibleAt))
+	}
+	if f.PublishDue != nil {
+		conds = append(conds, bson.M{"publish_at": bson.M{"$lte": *f.PublishDue}})
+	}
+	if f.UnpublishDue != nil {
+		conds = append(conds, bson.M{"unpublish_at": bson.M{"$lte": *f.UnpublishDue}})
+	}
+	if f.ScheduledAfter != nil {
+		conds = append(conds, bson.M{"$or": bson.A{
+			bson.M{"publish_at": bson.M{"$gt": *f.ScheduledAfter}},
+			bson.M{"unpublish_at": bson.M{"$gt": *f.ScheduledAfter}},
+		}})
+	}
+	return andFilter(conds)
+}
+
+func (r *MongoRepository) CreateArticle(ctx context.Context, a *Article) error {
+	if a.ID.IsZero() {
+		a.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(ctx, a)
+	if mongo.IsDuplicateKeyError(err) {
+		return errSlugTaken
+	}
+	return err
+}
+
+func (r *MongoRepository) FindArticle(ctx context.Context, f Filter) (*Article, error) {
+	if err := f.scoped(); err != nil {
+		return nil, err
+	}
+	var a Article
+	err := r.collection.FindOne(ctx, f.bson()).Decode(&a)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *MongoRepository) FindArticles(ctx context.Context, f Filter) ([]Article, error) {
+	if err := f.scoped(); err != nil {
+		return nil, err
+	}
+	cursor, err := r.collection.Find(ctx, f.bson())
+	if err != nil {
+		return nil, err
+	}
+	return decodeAll[Article](ctx, cursor)
+}
+
+func (r *MongoRepository) ListArticles(ctx context.Context, f Filter, q ListQuery) ([]Article, int64, error) {
+	if err := f.scoped(); err != nil {
+		return nil, 0, err
+	}
+	conds := append(bson.A{f.bson()}, q.conditions()...)
+	total, err := r.collection.CountDocuments(ctx, andFilter(conds))
+	if err != nil {
+		return nil, 0, err
+	}
+	if q.After != nil {
+		conds = append(conds, q.cursorCondition())
+	}
+	cursor, err := r.collection.Find(ctx, andFilter(conds), q.findOptions())
+	if err != nil {
+		return nil, 0, err
+	}
+	items, err := decodeAll[Article](ctx, cursor)
+	return items, total, err
+}
+
+func (r *MongoRepository) UpdateArticle(ctx context.Context, f Filter, a *Article) error {
+	if err := f.scoped(); err != nil {
+		return err
+	}
+	f.ID = a.ID
+	res, err := r.collection.ReplaceOne(ctx, f.bson(), a)
+	if mongo.IsDuplicateKeyError(err) {
+		return errSlugTaken
+	}
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *MongoRepository) SetFields(ctx context.Context, f Filter, p Patch) (*Article, error) {
+	if err := f.scoped(); err != nil {
+		return nil, err
+	}
+	if f.ID.IsZero() {
+		return nil, ErrNotFound
+	}
+	set, unset := bson.M{}, bson.M{}
+	if p.Status != nil {
+		set["status"] = *p.Status
+	}
+	if p.PublishedAt != nil {
+		set["published_at"] = *p.PublishedAt
+	}
+	if p.UpdatedAt != nil {
+		set["updated_at"] = *p.UpdatedAt
+	}
+	if p.Author != nil {
+		set["author_id"] = p.Author.ID
+		set["author_name"] = p.Author.Name
+		set["author_avatar"] = p.Author.Avatar
+		set["author_bio"] = p.Author.Bio
+	}
+	if p.Contributors != nil {
+		if len(*p.Contributors) > 0 {
+			set["contributors"] = *p.Contributors
+		} else {
+			unset["contributors"] = ""
+		}
+	}
+	if p.TranslationGroup != nil {
+		set["translation_group"] = *p.TranslationGroup
+	}
+	update := bson.M{}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	var a Article
+	err := r.collection.FindOneAndUpdate(ctx, f.bson(), update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&a)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *MongoRepository) DeleteArticle(ctx context.Context, f Filter) error {
+	if err := f.scoped(); err != nil {
+		return err
+	}
+	res, err := r.collection.DeleteOne(ctx, f.bson())
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *MongoRepository) ReplaceTag(ctx context.Context, orgID primitive.ObjectID, from, to string) (int64, error) {
+	filter := bson.M{"organization_id": orgID, "tags": from}
+	// $addToSet y $pull sobre el mismo campo no pueden ir en una sola operación
+	if to != "" {
+		if _, err := r.collection.UpdateMany(ctx, filter, bson.M{"$addToSet": bson.M{"tags": to}}); err != nil {
+			return 0, err
+		}
+	}
+	res, err := r.collection.UpdateMany(ctx, filter, bson.M{"$pull": bson.M{"tags": from}})
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+func (r *MongoRepository) UpdateAuthor(ctx context.Context, author Author) (int64, error) {
+	res, err := r.collection.UpdateMany(ctx,
+		bson.M{"author_id": author.ID},
+		bson.M{"$set": bson.M{
+			"author_name":   author.Name,
+			"author_avatar": author.Avatar,
+			"author_bio":    author.Bio,
+		}})
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+func (r *MongoRepository) FindByMedia(ctx context.Context, orgID primitive.ObjectID, urls []string) ([]Article, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"$and": bson.A{
+		bson.M{"organization_id": orgID},
+		mediaCondition(urls),
+	}})
+	if err != nil {
+		return nil, err
+	}
+	return decodeAll[Article](ctx, cursor)
+}
+
+func (r *MongoRepository) FindArticlesIn(ctx context.Context, f Filter, ids []primitive.ObjectID) ([]Article, error) {
+	if err := f.scoped(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return []Article{}, nil
+	}
+	cursor, err := r.collection.Find(ctx, bson.M{"$and": bson.A{
+		f.bson(),
+		bson.M{"_id": bson.M{"$in": ids}},
+	}})
+	if err != nil {
+		return nil, err
+	}
+	return decodeAll[Article](ctx, cursor)
+}
+
+func (r *MongoRepository) RemoveCategory(ctx context.Context, orgID, categoryID primitive.ObjectID) (int64, error) {
+	res, err := r.collection.UpdateMany(ctx,
+		bson.M{"organization_id": orgID, "category_ids": categoryID},
+		bson.M{"$pull": bson.M{"category_ids": categoryID}})
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+func (r *MongoRepository) SaveRevision(ctx context.Context, rev *Revision) error {
+	if rev.ID.IsZero() {
+		rev.ID = primitive.NewObjectID()
+	}
+	_, err := r.revisions.InsertOne(ctx, rev)
+	if mongo.IsDuplicateKeyError(err) {
+		return errDuplicateRevision
+	}
+	return err
+}
+
+func (r *MongoRepository) GetRevision(ctx context.Context, articleID primitive.ObjectID, number int) (*Revision, error) {
+	var rev Revision
+	err := r.revisions.FindOne(ctx, bson.M{"article_id": articleID, "number": number}).Decode(&rev)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+func (r *MongoRepository) ListRevisions(ctx context.Context, articleID primitive.ObjectID) ([]Revision, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "number", Value: -1}})
+	cursor, err := r.revisions.Find(ctx, bson.M{"article_id": articleID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	return decodeAll[Revision](ctx, cursor)
+}
+
+func (r *MongoRepository) SaveTransition(ctx context.Context, rec *TransitionRecord) error {
+	if rec.ID.IsZero() {
+		rec.ID = primitive.NewObjectID()
+	}
+	_, err := r.transitions.InsertOne(ctx, rec)
+	return err
+}
+
+func (r *MongoRepository) ListTransitions(ctx context.Context, articleID primitive.ObjectID) ([]TransitionRecord, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.transitions.Find(ctx, bson.M{"article_id": articleID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	return decodeAll[TransitionRecord](ctx, cursor)
+}
+
+func (r *MongoRepository) SavePreviewLink(ctx context.Context, l *PreviewLink) error {
+	if l.ID.IsZero() {
+		l.ID = primitive.NewObjectID()
+	}
+	_, err := r.previews.InsertOne(ctx, l)
+	return err
+}
+
+func (r *MongoRepository) GetPreviewLink(ctx context.Context, id primitive.ObjectID) (*PreviewLink, error) {
+	var l PreviewLink
+	err := r.previews.FindOne(ctx, bson.M{"_id": id}).Decode(&l)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *MongoRepository) ListPreviewLinks(ctx context.Context, articleID primitive.ObjectID) ([]PreviewLink, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := r.previews.Find(ctx, bson.M{"article_id": articleID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	return decodeAll[PreviewLink](ctx, cursor)
+}
+
+func (r *MongoRepository) RevokePreviewLink(ctx context.Context, articleID, id primitive.ObjectID, at time.Time) error {
+	res, err := r.previews.UpdateOne(ctx,
+		bson.M{"_id": id, "article_id": articleID},
+		bson.M{"$min": bson.M{"revoked_at": at}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *MongoRepository) AcquireLock(ctx context.Context, l *EditLock, now time.Time) (*EditLock, error) {
+	// El _id es el artículo: si lo tiene otro y no venció, el upsert choca con
+	// el documento existente y devuelve clave duplicada
+	filter := bson.M{"_id": l.ArticleID, "$or": bson.A{
+		bson.M{"expires_at": bson.M{"$lte": now}},
+		bson.M{"user_id": l.UserID},
+	}}
+	opts := options.FindOneAndReplace().SetUpsert(true).SetReturnDocument(options.After)
+	var saved EditLock
+	err := r.locks.FindOneAndReplace(ctx, filter, l, opts).Decode(&saved)
+	if mongo.IsDuplicateKeyError(err) {
+		held, err := r.GetLock(ctx, l.ArticleID)
+		if err != nil {
+			return nil, err
+		}
+		return held, errLocked
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+func (r *MongoRepository) RefreshLock(ctx context.Context, articleID, userID primitive.ObjectID, now, expiresAt time.Time) (*EditLock, error) {
+	var l EditLock
+	err := r.locks.FindOneAndUpdate(ctx,
+		bson.M{"_id": articleID, "user_id": userID, "expires_at": bson.M{"$gt": now}},
+		bson.M{"$set": bson.M{"heartbeat_at": now, "expires_at": expiresAt}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&l)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *MongoRepository) GetLock(ctx context.Context, articleID primitive.ObjectID) (*EditLock, error) {
+	var l EditLock
+	err := r.locks.FindOne(ctx, bson.M{"_id": articleID}).Decode(&l)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *MongoRepository) ReleaseLock(ctx context.Context, articleID, userID primitive.ObjectID) error {
+	filter := bson.M{"_id": articleID}
+	if !userID.IsZero() {
+		filter["user_id"] = userID
+	}
+	res, err := r.locks.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// decodeAll lee el cursor completo; los documentos que no decodifican se saltean
+func decodeAll[T any](ctx context.Context, cursor *mongo.Cursor) ([]T, error) {
+	defer cursor.Close(ctx)
+	out := []T{}
+	for cursor.Next(ctx) {
+		var v T
+		if err := cursor.Decode(&v); err == nil {
+			out = append(out, v)
+		}
+	}
+	return out, cursor.Err()
+}
@@ -0,0 +1,237 @@
+package articles
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"pittsix/pkg/events"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Revision es una foto inmutable de un artículo en un número de revisión dado
+type Revision struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ArticleID primitive.ObjectID `bson:"article_id" json:"article_id"`
+	Number    int                `bson:"number" json:"number"`
+	Title     string             `bson:"title" json:"title"`
+	Content   string             `bson:"content" json:"content"`
+	Blocks    []Block            `bson:"blocks,omitempty" json:"blocks,omitempty"`
+	Format    string             `bson:"content_format,omitempty" json:"content_format,omitempty"`
+	Image     string             `bson:"image,omitempty" json:"image,omitempty"`
+	Slug      string             `bson:"slug" json:"slug"`
+	Status    string             `bson:"status" json:"status"`
+	EditedBy  primitive.ObjectID `bson:"edited_by,omitempty" json:"edited_by,omitempty"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// currentRevision normaliza artículos creados antes de versionar (revision 0)
+func currentRevision(a *Article) int {
+	if a.Revision < 1 {
+		return 1
+	}
+	return a.Revision
+}
+
+// snapshotOf arma la revisión que representa el estado actual del artículo
+func snapshotOf(a *Article) Revision {
+	editedAt := a.UpdatedAt
+	if editedAt.IsZero() {
+		editedAt = a.CreatedAt
+	}
+	return Revision{
+		ArticleID: a.ID,
+		Number:    currentRevision(a),
+		Title:     a.Title,
+		Content:   a.Content,
+		Blocks:    a.Blocks,
+		Format:    a.ContentFormat,
+		Image:     a.Image,
+		Slug:      a.Slug,
+		Status:    a.Status,
+		EditedBy:  a.LastEditedBy,
+		CreatedAt: editedAt,
+	}
+}
+
+// saveSnapshot guarda el estado previo del artículo ya sobrescrito
+func saveSnapshot(ctx context.Context, repo Repository, a *Article) error {
+	rev := snapshotOf(a)
+	return repo.SaveRevision(ctx, &rev)
+}
+
+// keepSnapshot guarda la versión previa después de un guardado condicionado
+// que salió bien. Si se guardara antes y el guardado fallara, quedaría una
+// revisión con ese número que hace chocar todas las ediciones siguientes.
+func keepSnapshot(ctx context.Context, repo Repository, previous *Article) {
+	if err := saveSnapshot(ctx, repo, previous); err != nil {
+		log.Printf("⚠️ No se pudo guardar la revisión %d del artículo %s: %v", currentRevision(previous), previous.ID.Hex(), err)
+	}
+}
+
+// findRevision devuelve la revisión pedida; el número actual se sirve desde el propio artículo
+func findRevision(ctx context.Context, repo Repository, a *Article, number int) (*Revision, error) {
+	if number == currentRevision(a) {
+		rev := snapshotOf(a)
+		return &rev, nil
+	}
+	return repo.GetRevision(ctx, a.ID, number)
+}
+
+func parseRevisionNumber(s string, a *Article) (int, error) {
+	if s == "current" {
+		return currentRevision(a), nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, errors.New("invalid revision")
+	}
+	return n, nil
+}
+
+// 📜 Listar revisiones de un artículo (requiere JWT y participar del artículo)
+func (h *Handlers) ListRevisions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	article, _, code, err := h.findArticleFor(ctx, r, canReview)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	stored, err := h.Repo.ListRevisions(ctx, article.ID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	revisions := append([]Revision{snapshotOf(article)}, stored...)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(revisions)
+}
+
+// 🔎 Obtener una revisión concreta (requiere JWT y participar del artículo)
+func (h *Handlers) GetRevision(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	article, _, code, err := h.findArticleFor(ctx, r, canReview)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	number, err := parseRevisionNumber(r.PathValue("rev"), article)
+	if err != nil {
+		http.Error(w, "Invalid revision", http.StatusBadRequest)
+		return
+	}
+	rev, err := findRevision(ctx, h.Repo, article, number)
+	if err != nil {
+		http.Error(w, "Revision not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rev)
+}
+
+// 🧮 Comparar dos revisiones: GET /articles/{id}/revisions/diff?from=1&to=current
+func (h *Handlers) DiffRevisions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	article, _, code, err := h.findArticleFor(ctx, r, canReview)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	q := r.URL.Query()
+	if q.Get("to") == "" {
+		q.Set("to", "current")
+	}
+	from, err := parseRevisionNumber(q.Get("from"), article)
+	if err != nil {
+		http.Error(w, "Invalid from revision", http.StatusBadRequest)
+		return
+	}
+	to, err := parseRevisionNumber(q.Get("to"), article)
+	if err != nil {
+		http.Error(w, "Invalid to revision", http.StatusBadRequest)
+		return
+	}
+	fromRev, err := findRevision(ctx, h.Repo, article, from)
+	if err != nil {
+		http.Error(w, "Revision not found", http.StatusNotFound)
+		return
+	}
+	toRev, err := findRevision(ctx, h.Repo, article, to)
+	if err != nil {
+		http.Error(w, "Revision not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(DiffRevisions(fromRev, toRev))
+}
+
+// ♻️ Restaurar una revisión: crea una revisión nueva con el contenido anterior.
+// Respeta If-Match igual que el PUT.
+func (h *Handlers) RestoreRevision(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	article, userID, code, err := h.findArticleFor(ctx, r, canEdit)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	if !ifMatch(r, article) {
+		writePreconditionFailed(w, article)
+		return
+	}
+	number, err := parseRevisionNumber(r.PathValue("rev"), article)
+	if err != nil {
+		http.Error(w, "Invalid revision", http.StatusBadRequest)
+		return
+	}
+	if number == currentRevision(article) {
+		http.Error(w, "Revision is already current", http.StatusConflict)
+		return
+	}
+	rev, err := findRevision(ctx, h.Repo, article, number)
+	if err != nil {
+		http.Error(w, "Revision not found", http.StatusNotFound)
+		return
+	}
+
+	previous := *article
+	filter := Filter{ID: article.ID, OrganizationID: article.OrganizationID, Revision: currentRevision(article)}
+	// Publicado, el slug no vuelve al de la revisión
+	slugBase, _ := planSlug(article, "", rev.Title, false)
+	article.Title = rev.Title
+	article.Content = rev.Content
+	article.Blocks = rev.Blocks
+	article.ContentFormat = rev.Format
+	article.Image = rev.Image
+	article.Revision = currentRevision(article) + 1
+	article.LastEditedBy = userID
+	article.UpdatedAt = time.Now()
+
+	err = h.assignSlug(ctx, article, slugBase, func() error {
+		return h.Repo.UpdateArticle(ctx, filter, article)
+	})
+	if err == ErrNotFound {
+		writePreconditionFailed(w, nil)
+		return
+	}
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	keepSnapshot(ctx, h.Repo, &previous)
+
+	h.reindex(ctx, article.ID)
+	h.publish(ctx, events.ArticleUpdated, article)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", versionETag(article))
+	json.NewEncoder(w).Encode(article)
+}
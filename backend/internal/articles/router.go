@@ -5,12 +5,126 @@ import (
 	"pittsix/pkg/middleware"
 )
 
-func RegisterHandlers(mux *http.ServeMux) {
-	mux.HandleFunc("GET /articles", ListArticlesHandler)
-	mux.HandleFunc("GET /articles/{id}", GetArticleByIDHandler)
-	mux.Handle("POST /articles", middleware.JWTAuth(http.HandlerFunc(CreateArticleHandler)))
-	mux.Handle("GET /my-articles", middleware.JWTAuth(http.HandlerFunc(GetMyArticlesHandler)))
-	mux.Handle("PUT /articles/{id}", middleware.JWTAuth(http.HandlerFunc(UpdateArticleHandler)))
-	mux.Handle("DELETE /articles/{id}", middleware.JWTAuth(http.HandlerFunc(DeleteArticleHandler)))
-	mux.HandleFunc("GET /articles/slug/{slug}", GetArticleBySlugHandler)
+func RegisterHandlers(mux *http.ServeMux, h *Handlers) {
+	mux.HandleFunc("GET /articles", h.ListArticles)
+	mux.HandleFunc("GET /articles/{id}", h.GetArticleByID)
+	mux.Handle("POST /articles", middleware.JWTAuth(limitBody(http.HandlerFunc(h.CreateArticle))))
+	mux.Handle("GET /my-articles", middleware.JWTAuth(http.HandlerFunc(h.GetMyArticles)))
+	mux.Handle("PUT /articles/{id}", middleware.JWTAuth(limitBody(http.HandlerFunc(h.UpdateArticle))))
+	mux.Handle("DELETE /articles/{id}", middleware.JWTAuth(http.HandlerFunc(h.DeleteArticle)))
+
+	// GET /articles/slug/{slug} y GET /articles/{id}/<sub> se solapan en el mux,
+	// así que los resolvemos en un único patrón
+	mux.HandleFunc("GET /articles/{id}/{sub}", articleSubresourceHandler(h, map[string]http.Handler{
+		"revisions":     middleware.JWTAuth(http.HandlerFunc(h.ListRevisions)),
+		"transitions":   middleware.JWTAuth(http.HandlerFunc(h.ListTransitions)),
+		"translations":  middleware.JWTAuth(http.HandlerFunc(h.GetTranslations)),
+		"preview-links": middleware.JWTAuth(http.HandlerFunc(h.ListPreviewLinks)),
+		"contributors":  middleware.JWTAuth(http.HandlerFunc(h.ListContributors)),
+		"lock":          middleware.JWTAuth(http.HandlerFunc(h.GetLock)),
+	}))
+
+	// GET /articles/slug/{slug}/meta choca igual con /articles/{id}/revisions/{rev}
+	mux.HandleFunc("GET /articles/{id}/{sub}/{item}", articleItemHandler(h))
+
+	// Historial de revisiones
+	mux.Handle("GET /articles/{id}/revisions/diff", middleware.JWTAuth(http.HandlerFunc(h.DiffRevisions)))
+	mux.Handle("POST /articles/{id}/revisions/{rev}/restore", middleware.JWTAuth(http.HandlerFunc(h.RestoreRevision)))
+
+	// Flujo editorial
+	mux.Handle("POST /articles/{id}/transitions", middleware.JWTAuth(http.HandlerFunc(h.TransitionArticle)))
+
+	// Contenido por bloques
+	mux.HandleFunc("GET /content/schema", h.GetContentSchema)
+	mux.Handle("POST /content/render", middleware.JWTAuth(limitBody(http.HandlerFunc(h.RenderContent))))
+	mux.Handle("POST /content/convert", middleware.JWTAuth(limitBody(http.HandlerFunc(h.ConvertContent))))
+	mux.Handle("POST /articles/{id}/content/migrate", middleware.JWTAuth(http.HandlerFunc(h.MigrateArticleContent)))
+	mux.Handle("POST /articles/migrate-content", middleware.JWTAuth(http.HandlerFunc(h.MigrateContent)))
+
+	// Traducciones
+	mux.Handle("POST /articles/{id}/translations", middleware.JWTAuth(limitBody(http.HandlerFunc(h.CreateTranslation))))
+	mux.Handle("GET /articles/translations", middleware.JWTAuth(http.HandlerFunc(h.ListTranslationStatus)))
+
+	// Vista previa
+	mux.Handle("POST /articles/{id}/preview-links", middleware.JWTAuth(http.HandlerFunc(h.CreatePreviewLink)))
+	mux.Handle("DELETE /articles/{id}/preview-links/{link}", middleware.JWTAuth(http.HandlerFunc(h.RevokePreviewLink)))
+	mux.HandleFunc("GET /preview/{token}", h.GetPreview)
+
+	// Coautoría
+	mux.Handle("PUT /articles/{id}/contributors/{user}", middleware.JWTAuth(http.HandlerFunc(h.PutContributor)))
+	mux.Handle("DELETE /articles/{id}/contributors/{user}", middleware.JWTAuth(http.HandlerFunc(h.DeleteContributor)))
+	mux.Handle("POST /articles/{id}/transfer", middleware.JWTAuth(http.HandlerFunc(h.TransferOwnership)))
+
+	// Bloqueo de edición
+	mux.Handle("POST /articles/{id}/lock", middleware.JWTAuth(http.HandlerFunc(h.AcquireLock)))
+	mux.Handle("PUT /articles/{id}/lock", middleware.JWTAuth(http.HandlerFunc(h.RefreshLock)))
+	mux.Handle("DELETE /articles/{id}/lock", middleware.JWTAuth(http.HandlerFunc(h.ReleaseLock)))
+
+	// Publicación programada
+	mux.Handle("GET /articles/scheduled", middleware.JWTAuth(http.HandlerFunc(h.ListScheduled)))
+
+	// Taxonomía
+	mux.HandleFunc("GET /categories", h.ListCategories)
+	mux.HandleFunc("GET /categories/{slug}/articles", h.ListCategoryArticles)
+	mux.Handle("POST /categories", middleware.JWTAuth(http.HandlerFunc(h.CreateCategory)))
+	mux.Handle("PUT /categories/{id}", middleware.JWTAuth(http.HandlerFunc(h.UpdateCategory)))
+	mux.Handle("DELETE /categories/{id}", middleware.JWTAuth(http.HandlerFunc(h.DeleteCategory)))
+	mux.HandleFunc("GET /tags", h.ListTags)
+	mux.HandleFunc("GET /tags/autocomplete", h.AutocompleteTags)
+	mux.HandleFunc("GET /tags/{slug}/articles", h.ListTagArticles)
+	mux.Handle("POST /tags", middleware.JWTAuth(http.HandlerFunc(h.CreateTag)))
+	mux.Handle("PUT /tags/{id}", middleware.JWTAuth(http.HandlerFunc(h.UpdateTag)))
+	mux.Handle("DELETE /tags/{id}", middleware.JWTAuth(http.HandlerFunc(h.DeleteTag)))
+	mux.Handle("POST /tags/{id}/merge", middleware.JWTAuth(http.HandlerFunc(h.MergeTag)))
+
+	// Autores
+	mux.HandleFunc("GET /authors/{id}", h.GetAuthor)
+
+	// Feeds
+	mux.HandleFunc("GET /feeds/articles.rss", h.Feed(FeedRSS))
+	mux.HandleFunc("GET /feeds/articles.atom", h.Feed(FeedAtom))
+	mux.HandleFunc("GET /feeds/articles.json", h.Feed(FeedJSON))
+
+	// Sitemap y robots
+	mux.HandleFunc("GET /sitemap.xml", h.SitemapIndex)
+	mux.HandleFunc("GET /sitemaps/{file}", h.Sitemap)
+	mux.HandleFunc("GET /robots.txt", h.Robots)
+
+	// Búsqueda
+	mux.HandleFunc("GET /articles/search", h.SearchArticles)
+	mux.Handle("GET /my-articles/search", middleware.JWTAuth(http.HandlerFunc(h.SearchMyArticles)))
+}
+
+func articleSubresourceHandler(h *Handlers, subs map[string]http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") == "slug" {
+			r.SetPathValue("slug", r.PathValue("sub"))
+			h.GetArticleBySlug(w, r)
+			return
+		}
+		h, ok := subs[r.PathValue("sub")]
+		if !ok {
+			http.Error(w, "Not Found", http.StatusNotFound)
+			return
+		}
+		h.ServeHTTP(w, r)
+	}
+}
+
+// articleItemHandler resuelve GET /articles/slug/{slug}/meta y
+// GET /articles/{id}/revisions/{rev}
+func articleItemHandler(h *Handlers) http.HandlerFunc {
+	getRevision := middleware.JWTAuth(http.HandlerFunc(h.GetRevision))
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.PathValue("id") == "slug" && r.PathValue("item") == "meta":
+			r.SetPathValue("slug", r.PathValue("sub"))
+			h.GetArticleMeta(w, r)
+		case r.PathValue("sub") == "revisions":
+			r.SetPathValue("rev", r.PathValue("item"))
+			getRevision.ServeHTTP(w, r)
+		default:
+			http.Error(w, "Not Found", http.StatusNotFound)
+		}
+	}
 }
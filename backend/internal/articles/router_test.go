@@ -0,0 +1,62 @@
+package articles
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegisterHandlers_NoConflicts(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatalf("conflicting routes: %v", r)
+		}
+	}()
+	RegisterHandlers(http.NewServeMux(), NewHandlers(NewMemoryRepository(), NewMemoryTaxonomyRepository(), nil, nil, ""))
+}
+
+func TestArticleSubresourceHandler(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /articles/{id}/{sub}", articleSubresourceHandler(nil, map[string]http.Handler{
+		"revisions": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(r.PathValue("id")))
+		}),
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/articles/abc/revisions", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "abc" {
+		t.Fatalf("expected revisions handler, got %d %q", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/articles/abc/unknown", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestArticleItemHandler(t *testing.T) {
+	e := newTestEnv()
+	mux := http.NewServeMux()
+	RegisterHandlers(mux, e.h)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/articles/slug/no-existe/meta", nil))
+	if w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), "Article not found") {
+		t.Errorf("expected the meta handler, got %d %q", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/articles/abc/revisions/3", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("revisions must still require a token, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/articles/abc/otra/cosa", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
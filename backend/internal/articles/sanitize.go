@@ -0,0 +1,175 @@
+package articles
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Saneado de HTML por lista blanca: las etiquetas permitidas se conservan con
+// sus atributos permitidos, las desconocidas se reemplazan por su contenido y
+// las peligrosas se descartan enteras.
+
+var inlineTags = map[atom.Atom][]string{
+	atom.A:      {"href", "title"},
+	atom.Strong: nil,
+	atom.B:      nil,
+	atom.Em:     nil,
+	atom.I:      nil,
+	atom.U:      nil,
+	atom.S:      nil,
+	atom.Code:   {"class"},
+	atom.Br:     nil,
+	atom.Sub:    nil,
+	atom.Sup:    nil,
+	atom.Mark:   nil,
+}
+
+var blockTags = map[atom.Atom][]string{
+	atom.P: nil, atom.H1: nil, atom.H2: nil, atom.H3: nil, atom.H4: nil, atom.H5: nil, atom.H6: nil,
+	atom.Blockquote: nil, atom.Cite: nil, atom.Footer: nil, atom.Pre: nil, atom.Hr: nil,
+	atom.Ul: nil, atom.Ol: nil, atom.Li: nil,
+	atom.Figure: {"class"}, atom.Figcaption: nil, atom.Img: {"src", "alt", "title", "width", "height"},
+	atom.Table: nil, atom.Thead: nil, atom.Tbody: nil, atom.Tr: nil, atom.Th: {"align"}, atom.Td: {"align"},
+}
+
+// droppedTags se eliminan junto con todo su contenido
+var droppedTags = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Iframe: true, atom.Object: true, atom.Embed: true,
+	atom.Template: true, atom.Noscript: true, atom.Textarea: true, atom.Select: true, atom.Form: true,
+	atom.Svg: true, atom.Math: true, atom.Head: true, atom.Title: true,
+}
+
+// renderedTags amplía la lista blanca con lo que genera el renderer de
+// Markdown: resaltado, notas al pie y listas de tareas
+var renderedTags = map[atom.Atom][]string{
+	atom.Pre:   {"class"},
+	atom.Span:  {"class"},
+	atom.Sup:   {"id", "class"},
+	atom.Del:   nil,
+	atom.Div:   {"class"},
+	atom.Input: {"type", "checked", "disabled"},
+	atom.A:     {"id", "class"},
+	atom.Li:    {"id", "class"},
+	atom.Ol:    {"start"},
+}
+
+// SanitizeHTML deja solo el HTML de contenido seguro para servir tal cual
+func SanitizeHTML(s string) string {
+	return sanitize(s, func(a atom.Atom) ([]string, bool) {
+		if attrs, ok := inlineTags[a]; ok {
+			return attrs, true
+		}
+		attrs, ok := blockTags[a]
+		return attrs, ok
+	})
+}
+
+// sanitizeInline es SanitizeHTML restringido al formato inline de los bloques
+func sanitizeInline(s string) string {
+	return sanitize(s, func(a atom.Atom) ([]string, bool) {
+		attrs, ok := inlineTags[a]
+		return attrs, ok
+	})
+}
+
+// sanitizeRendered sanea la salida del renderer de Markdown, que incluye el HTML crudo del autor
+func sanitizeRendered(s string) string {
+	return sanitize(s, func(a atom.Atom) ([]string, bool) {
+		base, ok := inlineTags[a]
+		if !ok {
+			base, ok = blockTags[a]
+		}
+		extra, rendered := renderedTags[a]
+		return append(append([]string{}, base...), extra...), ok || rendered
+	})
+}
+
+func sanitize(s string, allowed func(atom.Atom) ([]string, bool)) string {
+	nodes, err := parseFragment(s)
+	if err != nil {
+		return html.EscapeString(s)
+	}
+	var b strings.Builder
+	for _, n := range nodes {
+		writeSanitized(&b, n, allowed)
+	}
+	return b.String()
+}
+
+// parseFragment interpreta s como el contenido de un <body>
+func parseFragment(s string) ([]*html.Node, error) {
+	return html.ParseFragment(strings.NewReader(s), &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+}
+
+func writeSanitized(b *strings.Builder, n *html.Node, allowed func(atom.Atom) ([]string, bool)) {
+	switch n.Type {
+	case html.TextNode:
+		b.WriteString(html.EscapeString(n.Data))
+		return
+	case html.ElementNode:
+	default:
+		// Comentarios y doctypes no se conservan
+		return
+	}
+	if droppedTags[n.DataAtom] {
+		return
+	}
+	attrs, ok := allowed(n.DataAtom)
+	if n.DataAtom == atom.Input && attr(n, "type") != "checkbox" {
+		return
+	}
+	if !ok {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			writeSanitized(b, c, allowed)
+		}
+		return
+	}
+	b.WriteString("<" + n.Data)
+	for _, a := range n.Attr {
+		if a.Namespace != "" || !containsString(attrs, a.Key) {
+			continue
+		}
+		if (a.Key == "href" || a.Key == "src") && !safeLink(a.Val) {
+			continue
+		}
+		b.WriteString(" " + a.Key + `="` + html.EscapeString(a.Val) + `"`)
+	}
+	if n.DataAtom == atom.A && !strings.HasPrefix(attr(n, "href"), "#") {
+		b.WriteString(` rel="nofollow noopener"`)
+	}
+	b.WriteString(">")
+	if isVoid(n.DataAtom) {
+		return
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		writeSanitized(b, c, allowed)
+	}
+	b.WriteString("</" + n.Data + ">")
+}
+
+// safeLink acepta lo mismo que safeURL más mailto: y anclas
+func safeLink(raw string) bool {
+	raw = strings.TrimSpace(raw)
+	return safeURL(raw, false) || strings.HasPrefix(strings.ToLower(raw), "mailto:") || strings.HasPrefix(raw, "#")
+}
+
+func isVoid(a atom.Atom) bool {
+	return a == atom.Br || a == atom.Img || a == atom.Hr || a == atom.Input
+}
+
+// nodeText concatena el texto de un nodo y sus descendientes
+func nodeText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && droppedTags[c.DataAtom] {
+			continue
+		}
+		b.WriteString(nodeText(c))
+	}
+	return b.String()
+}
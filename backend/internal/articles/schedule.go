@@ -0,0 +1,269 @@
+package articles
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"pittsix/pkg/events"
+	"pittsix/pkg/middleware"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// publicFilter devuelve solo artículos visibles ahora: publicados, o aprobados
+// cuyo publish_at ya pasó aunque el scheduler todavía no los haya movido, y
+// siempre que no haya llegado su unpublish_at.
+func publicFilter(now time.Time) bson.M {
+	return bson.M{"$and": bson.A{
+		bson.M{"$or": bson.A{
+			bson.M{"status": StatusPublished},
+			bson.M{"status": StatusApproved, "publish_at": bson.M{"$lte": now}},
+		}},
+		bson.M{"$or": bson.A{
+			bson.M{"unpublish_at": nil},
+			bson.M{"unpublish_at": bson.M{"$gt": now}},
+		}},
+	}}
+}
+
+// IsPubliclyVisible es el equivalente en memoria de publicFilter
+func IsPubliclyVisible(a *Article, now time.Time) bool {
+	if a.UnpublishAt != nil && !a.UnpublishAt.After(now) {
+		return false
+	}
+	switch a.Status {
+	case StatusPublished:
+		return true
+	case StatusApproved:
+		return a.PublishAt != nil && !a.PublishAt.After(now)
+	}
+	return false
+}
+
+// presentPublic ajusta el estado de un artículo visible que el scheduler aún no procesó
+func presentPublic(a *Article) {
+	// El HTML heredado puede ser anterior al saneado en escritura
+	if contentFormat(a) == FormatHTML {
+		a.Content = SanitizeHTML(a.Content)
+	}
+	if a.Status == StatusApproved && a.PublishAt != nil {
+		a.Status = StatusPublished
+		if a.PublishedAt == nil {
+			a.PublishedAt = a.PublishAt
+		}
+	}
+}
+
+// validateSchedule comprueba que la ventana de publicación tenga sentido
+func validateSchedule(publishAt, unpublishAt *time.Time) bool {
+	return publishAt == nil || unpublishAt == nil || unpublishAt.After(*publishAt)
+}
+
+// errSchedulePermission: programar la ventana publica sin pasar por la
+// transición, así que pide lo mismo que publish
+var errSchedulePermission = errors.New("Scheduling requires the articles:publish permission")
+
+// canSchedule indica si el usuario puede fijar o cambiar publish_at y unpublish_at
+func canSchedule(ctx context.Context, a *Article, userID primitive.ObjectID) bool {
+	return canPerform(ctx, Transitions["publish"], a, userID)
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// decodeScheduled decodifica el cuerpo de un PUT en payload e indica qué fechas
+// de la ventana trae: las que no vienen no cambian y null las borra
+func decodeScheduled(body io.Reader, payload *Article) (publishAt, unpublishAt bool, err error) {
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		return false, false, err
+	}
+	if err := json.Unmarshal(raw, payload); err != nil {
+		return false, false, err
+	}
+	var fields map[string]json.RawMessage
+	json.Unmarshal(raw, &fields)
+	_, publishAt = fields["publish_at"]
+	_, unpublishAt = fields["unpublish_at"]
+	return publishAt, unpublishAt, nil
+}
+
+// ⏰ Scheduler publica y despublica artículos según publish_at / unpublish_at
+type Scheduler struct {
+	Repo     Repository
+	Search   SearchIndex
+	Interval time.Duration
+	Now      func() time.Time
+	// Events recibe los cambios de estado programados (nil: no se publican)
+	Events *events.Bus
+}
+
+func NewScheduler(repo Repository, search SearchIndex, interval time.Duration) *Scheduler {
+	return &Scheduler{Repo: repo, Search: search, Interval: interval, Now: time.Now}
+}
+
+// Run procesa la agenda cada Interval hasta que se cancele el contexto
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	log.Printf("⏰ Scheduler de publicación activo (cada %s)", s.Interval)
+	for {
+		if _, _, err := s.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("❌ Error en scheduler: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			log.Println("⏰ Scheduler detenido")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce ejecuta todos los cambios vencidos de todas las organizaciones. Cada
+// artículo se reclama con un SetFields condicionado a su estado, así que si
+// dos réplicas corren a la vez solo una consigue moverlo y registrar la transición.
+func (s *Scheduler) RunOnce(ctx context.Context) (published, unpublished int, err error) {
+	now := s.Now()
+	for {
+		ok, err := s.claim(ctx, Transitions["publish"], Filter{Status: StatusApproved, PublishDue: &now, System: true}, now)
+		if err != nil {
+			return published, unpublished, err
+		}
+		if !ok {
+			break
+		}
+		published++
+	}
+	for {
+		ok, err := s.claim(ctx, Transitions["archive"], Filter{Status: StatusPublished, UnpublishDue: &now, System: true}, now)
+		if err != nil {
+			return published, unpublished, err
+		}
+		if !ok {
+			break
+		}
+		unpublished++
+	}
+	return published, unpublished, nil
+}
+
+// claim mueve un artículo vencido. Si otra réplica lo movió primero vuelve a
+// buscar: el que ganó ya no matchea el filtro.
+func (s *Scheduler) claim(ctx context.Context, t Transition, filter Filter, now time.Time) (bool, error) {
+	for {
+		before, err := s.Repo.FindArticle(ctx, filter)
+		if err == ErrNotFound {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		// Solo cambian estado y fechas: una edición que entre en el medio se conserva
+		patch := Patch{Status: &t.To, UpdatedAt: &now}
+		if t.To == StatusPublished && (before.PublishedAt == nil || before.PublishedAt.After(now)) {
+			patch.PublishedAt = &now
+		}
+		// El ID ya fija el documento; los artículos viejos pueden no tener organización
+		after, err := s.Repo.SetFields(ctx, Filter{ID: before.ID, Status: before.Status, System: true}, patch)
+		if err == ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+
+		err = s.Repo.SaveTransition(ctx, &TransitionRecord{
+			ArticleID:  before.ID,
+			Transition: t.Name,
+			From:       before.Status,
+			To:         t.To,
+			Comment:    "scheduled",
+			UserName:   "scheduler",
+			CreatedAt:  now,
+		})
+		if err != nil {
+			log.Printf("⚠️ No se pudo registrar la transición programada de %s: %v", before.ID.Hex(), err)
+		}
+		syncIndex(ctx, s.Repo, s.Search, before.ID)
+		publishArticle(s.Events, events.ArticleStatusChanged, after, "", before.Status)
+		log.Printf("⏰ Artículo %s: %s → %s", before.ID.Hex(), before.Status, t.To)
+		return true, nil
+	}
+}
+
+// ScheduledChange es un cambio de estado pendiente en la agenda
+type ScheduledChange struct {
+	ArticleID primitive.ObjectID `json:"article_id"`
+	Title     string             `json:"title"`
+	Slug      string             `json:"slug"`
+	Status    string             `json:"status"`
+	Action    string             `json:"action"`
+	At        time.Time          `json:"at"`
+}
+
+// 🗓️ Próximas publicaciones/despublicaciones (requiere JWT)
+func (h *Handlers) ListScheduled(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userIDStr, ok := ctx.Value("user_id").(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	userObjID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	tenant, err := tenantFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	now := time.Now()
+	filter := Filter{ScheduledAfter: &now}
+	// Editores y admins ven toda la agenda; el resto solo la propia
+	if !middleware.HasRole(ctx, "superadmin", "org_admin") && !middleware.HasPermission(ctx, "articles:publish") {
+		filter.MemberID = userObjID
+	}
+
+	found, err := h.Repo.FindArticles(ctx, tenant.scope(filter))
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+
+	changes := []ScheduledChange{}
+	for i := range found {
+		changes = append(changes, upcomingChanges(&found[i], now)...)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].At.Before(changes[j].At) })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(changes)
+}
+
+func upcomingChanges(a *Article, now time.Time) []ScheduledChange {
+	var changes []ScheduledChange
+	if a.PublishAt != nil && a.PublishAt.After(now) {
+		changes = append(changes, ScheduledChange{a.ID, a.Title, a.Slug, a.Status, "publish", *a.PublishAt})
+	}
+	if a.UnpublishAt != nil && a.UnpublishAt.After(now) {
+		changes = append(changes, ScheduledChange{a.ID, a.Title, a.Slug, a.Status, "unpublish", *a.UnpublishAt})
+	}
+	return changes
+}
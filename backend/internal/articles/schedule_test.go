@@ -0,0 +1,62 @@
+package articles
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsPubliclyVisible(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+	cases := []struct {
+		name    string
+		article Article
+		want    bool
+	}{
+		{"draft", Article{Status: StatusDraft}, false},
+		{"published", Article{Status: StatusPublished}, true},
+		{"approved without schedule", Article{Status: StatusApproved}, false},
+		{"approved due", Article{Status: StatusApproved, PublishAt: &past}, true},
+		{"approved not yet due", Article{Status: StatusApproved, PublishAt: &future}, false},
+		{"published past unpublish", Article{Status: StatusPublished, UnpublishAt: &past}, false},
+		{"published before unpublish", Article{Status: StatusPublished, UnpublishAt: &future}, true},
+		{"draft due", Article{Status: StatusDraft, PublishAt: &past}, false},
+	}
+	for _, c := range cases {
+		if got := IsPubliclyVisible(&c.article, now); got != c.want {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestPresentPublic(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	a := Article{Status: StatusApproved, PublishAt: &past}
+	presentPublic(&a)
+	if a.Status != StatusPublished || a.PublishedAt == nil || !a.PublishedAt.Equal(past) {
+		t.Errorf("expected approved article to be presented as published, got %+v", a)
+	}
+}
+
+func TestValidateSchedule(t *testing.T) {
+	now := time.Now()
+	later := now.Add(time.Hour)
+	if !validateSchedule(nil, nil) || !validateSchedule(&now, nil) || !validateSchedule(&now, &later) {
+		t.Errorf("expected valid schedules")
+	}
+	if validateSchedule(&later, &now) {
+		t.Errorf("unpublish before publish should be invalid")
+	}
+}
+
+func TestUpcomingChanges(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+	a := Article{Title: "t", PublishAt: &past, UnpublishAt: &future}
+	changes := upcomingChanges(&a, now)
+	if len(changes) != 1 || changes[0].Action != "unpublish" {
+		t.Errorf("expected only the unpublish change, got %+v", changes)
+	}
+}
@@ -0,0 +1,191 @@
+package articles
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SearchIndex abstrae el motor de búsqueda. MongoIndex usa el índice de texto
+// de la colección, que comparten todas las réplicas; MemoryIndex sirve para un
+// solo proceso (tests y desarrollo). Index y Remove se llaman tras cada
+// escritura; los motores que se mantienen solos los ignoran.
+type SearchIndex interface {
+	Index(a Article) error
+	Remove(id primitive.ObjectID) error
+	Search(q SearchQuery) (*SearchResult, error)
+}
+
+// SearchQuery combina el texto a buscar con filtros por faceta y visibilidad
+type SearchQuery struct {
+	// Text admite palabras sueltas (todas requeridas) y "frases entre comillas"
+	Text     string
+	Status   string
+	AuthorID *primitive.ObjectID
+	Tag      string
+	// Scope acota la búsqueda a lo que quien busca puede ver (tenant,
+	// visibilidad, autoría) con la misma semántica que en el repositorio
+	Scope  Filter
+	Limit  int
+	Offset int
+}
+
+type SearchHit struct {
+	Article    Article           `json:"article"`
+	Score      float64           `json:"score"`
+	Highlights map[string]string `json:"highlights"`
+}
+
+type FacetCount struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"`
+	Count int    `json:"count"`
+}
+
+type SearchResult struct {
+	Total  int                     `json:"total"`
+	Hits   []SearchHit             `json:"hits"`
+	Facets map[string][]FacetCount `json:"facets"`
+}
+
+var ErrEmptyQuery = errors.New("empty query")
+
+// accepts aplica el alcance y los filtros por faceta a un artículo
+func (q SearchQuery) accepts(a *Article) bool {
+	if !q.Scope.Matches(a) {
+		return false
+	}
+	if q.Status != "" && a.Status != q.Status {
+		return false
+	}
+	if q.AuthorID != nil && a.AuthorID != *q.AuthorID {
+		return false
+	}
+	return q.Tag == "" || containsString(a.Tags, strings.ToLower(q.Tag))
+}
+
+func (h *Handlers) reindex(ctx context.Context, id primitive.ObjectID) {
+	syncIndex(ctx, h.Repo, h.Search, id)
+}
+
+// syncIndex sincroniza un artículo con el índice tras un cambio en el repositorio
+func syncIndex(ctx context.Context, repo Repository, index SearchIndex, id primitive.ObjectID) {
+	art, err := repo.FindArticle(ctx, Filter{ID: id, System: true})
+	if err == ErrNotFound {
+		err = index.Remove(id)
+	} else if err == nil {
+		err = index.Index(*art)
+	}
+	if err != nil {
+		log.Printf("⚠️ No se pudo reindexar %s: %v", id.Hex(), err)
+	}
+}
+
+func parseSearchQuery(r *http.Request) (SearchQuery, error) {
+	v := r.URL.Query()
+	q := SearchQuery{Text: v.Get("q"), Status: v.Get("status"), Tag: v.Get("tag"), Limit: defaultPageSize}
+	if s := v.Get("author"); s != "" {
+		id, err := primitive.ObjectIDFromHex(s)
+		if err != nil {
+			return q, errors.New("invalid author")
+		}
+		q.AuthorID = &id
+	}
+	if s := v.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			return q, errors.New("invalid limit")
+		}
+		q.Limit = min(n, maxPageSize)
+	}
+	if s := v.Get("offset"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return q, errors.New("invalid offset")
+		}
+		q.Offset = n
+	}
+	return q, nil
+}
+
+// writeSearchResult busca en el índice, que ya devuelve solo la página pedida
+func (h *Handlers) writeSearchResult(w http.ResponseWriter, q SearchQuery, present func(*Article)) {
+	res, err := h.Search.Search(q)
+	if err == ErrEmptyQuery {
+		http.Error(w, "Query required", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "Search error", http.StatusInternalServerError)
+		return
+	}
+	if present != nil {
+		for i := range res.Hits {
+			present(&res.Hits[i].Article)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// 🔍 Buscar entre artículos publicados (público): GET /articles/search?q=
+func (h *Handlers) SearchArticles(w http.ResponseWriter, r *http.Request) {
+	q, err := parseSearchQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	tenant, err := h.publicTenant(r)
+	if err != nil {
+		http.Error(w, err.Error(), tenantErrorStatus(err))
+		return
+	}
+	present, err := h.publicPresenter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	now := time.Now()
+	q.Scope = tenant.scope(Filter{VisibleAt: &now})
+	h.writeSearchResult(w, q, present)
+}
+
+// 🔍 Buscar entre los artículos propios, en cualquier estado (requiere JWT).
+// Revisores y admins buscan sobre todo el contenido.
+func (h *Handlers) SearchMyArticles(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userIDStr, ok := ctx.Value("user_id").(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	userObjID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		return
+	}
+	q, err := parseSearchQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	tenant, err := tenantFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	// Como en canReview: sin permisos de revisión, solo los artículos propios
+	q.Scope = tenant.scope(Filter{})
+	if !reviewsAll(ctx) {
+		q.Scope.MemberID = userObjID
+	}
+	h.writeSearchResult(w, q, nil)
+}
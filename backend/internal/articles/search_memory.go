@@ -0,0 +1,391 @@
+package articles
+
+import (
+	"html"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DefaultBoosts pondera cada campo en el ranking: el título pesa más que el cuerpo
+var DefaultBoosts = map[string]float64{"title": 3, "tags": 2, "content": 1}
+
+const snippetRadius = 12
+
+type indexedDoc struct {
+	article Article
+	texts   map[string]string
+	tokens  map[string][]token
+}
+
+// MemoryIndex es un índice invertido en memoria con posiciones por campo. Solo
+// ve las escrituras de su proceso, así que no sirve con varias réplicas: ahí va
+// MongoIndex.
+type MemoryIndex struct {
+	mu       sync.RWMutex
+	docs     map[primitive.ObjectID]*indexedDoc
+	postings map[string]map[primitive.ObjectID]bool
+	Boosts   map[string]float64
+}
+
+func NewMemoryIndex() *MemoryIndex {
+	return &MemoryIndex{
+		docs:     map[primitive.ObjectID]*indexedDoc{},
+		postings: map[string]map[primitive.ObjectID]bool{},
+		Boosts:   DefaultBoosts,
+	}
+}
+
+func (ix *MemoryIndex) Index(a Article) error {
+	doc := &indexedDoc{article: a, texts: searchTexts(&a), tokens: map[string][]token{}}
+	for field, text := range doc.texts {
+		doc.tokens[field] = tokenize(text)
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.removeLocked(a.ID)
+	ix.docs[a.ID] = doc
+	for _, toks := range doc.tokens {
+		for _, t := range toks {
+			if t.Term == "" {
+				continue
+			}
+			if ix.postings[t.Term] == nil {
+				ix.postings[t.Term] = map[primitive.ObjectID]bool{}
+			}
+			ix.postings[t.Term][a.ID] = true
+		}
+	}
+	return nil
+}
+
+func (ix *MemoryIndex) Remove(id primitive.ObjectID) error {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.removeLocked(id)
+	return nil
+}
+
+// searchTexts es el texto plano de cada campo buscable
+func searchTexts(a *Article) map[string]string {
+	return map[string]string{
+		"title":   a.Title,
+		"content": html.UnescapeString(stripTags(renderArticleHTML(a))),
+		"tags":    strings.Join(a.Tags, " "),
+	}
+}
+
+func (ix *MemoryIndex) removeLocked(id primitive.ObjectID) {
+	doc, ok := ix.docs[id]
+	if !ok {
+		return
+	}
+	for _, toks := range doc.tokens {
+		for _, t := range toks {
+			if docs := ix.postings[t.Term]; docs != nil {
+				delete(docs, id)
+				if len(docs) == 0 {
+					delete(ix.postings, t.Term)
+				}
+			}
+		}
+	}
+	delete(ix.docs, id)
+}
+
+// phraseTerm es un término de una frase con su distancia al primero
+type phraseTerm struct {
+	term   string
+	offset int
+}
+
+type parsedQuery struct {
+	terms   []string
+	phrases [][]phraseTerm
+}
+
+var phraseRegex = regexp.MustCompile(`"([^"]*)"`)
+
+func parseQueryText(text string) parsedQuery {
+	var pq parsedQuery
+	seen := map[string]bool{}
+	addTerm := func(t string) {
+		if t != "" && !seen[t] {
+			seen[t] = true
+			pq.terms = append(pq.terms, t)
+		}
+	}
+	for _, m := range phraseRegex.FindAllStringSubmatch(text, -1) {
+		var phrase []phraseTerm
+		base := -1
+		for _, t := range tokenize(m[1]) {
+			if t.Term == "" {
+				continue
+			}
+			if base < 0 {
+				base = t.Pos
+			}
+			phrase = append(phrase, phraseTerm{t.Term, t.Pos - base})
+			addTerm(t.Term)
+		}
+		if len(phrase) > 1 {
+			pq.phrases = append(pq.phrases, phrase)
+		}
+	}
+	for _, t := range tokenize(phraseRegex.ReplaceAllString(text, " ")) {
+		addTerm(t.Term)
+	}
+	return pq
+}
+
+// phraseInField indica si la frase aparece con sus distancias originales en el campo
+func phraseInField(toks []token, phrase []phraseTerm) bool {
+	positions := map[string]map[int]bool{}
+	for _, t := range toks {
+		if positions[t.Term] == nil {
+			positions[t.Term] = map[int]bool{}
+		}
+		positions[t.Term][t.Pos] = true
+	}
+	for start := range positions[phrase[0].term] {
+		ok := true
+		for _, pt := range phrase[1:] {
+			if !positions[pt.term][start+pt.offset] {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (ix *MemoryIndex) Search(q SearchQuery) (*SearchResult, error) {
+	pq := parseQueryText(q.Text)
+	if len(pq.terms) == 0 {
+		return nil, ErrEmptyQuery
+	}
+	if err := q.Scope.scoped(); err != nil {
+		return nil, err
+	}
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	// Candidatos: documentos que contienen todos los términos
+	var candidates []primitive.ObjectID
+	for id := range ix.postings[pq.terms[0]] {
+		candidates = append(candidates, id)
+	}
+	for _, term := range pq.terms[1:] {
+		docs := ix.postings[term]
+		filtered := candidates[:0]
+		for _, id := range candidates {
+			if docs[id] {
+				filtered = append(filtered, id)
+			}
+		}
+		candidates = filtered
+	}
+
+	n := float64(len(ix.docs))
+	idf := map[string]float64{}
+	for _, term := range pq.terms {
+		idf[term] = math.Log(1 + n/float64(len(ix.postings[term])))
+	}
+	hits := []SearchHit{}
+	facets := newFacetCounter()
+	for _, id := range candidates {
+		doc := ix.docs[id]
+		a := &doc.article
+		if !q.accepts(a) {
+			continue
+		}
+
+		score, ok := ix.score(doc, pq, idf)
+		if !ok {
+			continue
+		}
+		facets.add(a)
+		hits = append(hits, SearchHit{
+			Article:    *a,
+			Score:      math.Round(score*1000) / 1000,
+			Highlights: highlights(doc.texts, doc.tokens, pq.terms),
+		})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Article.UpdatedAt.After(hits[j].Article.UpdatedAt)
+	})
+
+	res := &SearchResult{Total: len(hits), Facets: facets.result()}
+	if q.Offset < len(hits) {
+		hits = hits[q.Offset:]
+	} else {
+		hits = nil
+	}
+	if q.Limit > 0 && len(hits) > q.Limit {
+		hits = hits[:q.Limit]
+	}
+	res.Hits = append([]SearchHit{}, hits...)
+	return res, nil
+}
+
+// score calcula tf-idf por campo con su boost; falla si alguna frase no aparece
+func (ix *MemoryIndex) score(doc *indexedDoc, pq parsedQuery, idf map[string]float64) (float64, bool) {
+	score := 0.0
+	for field, toks := range doc.tokens {
+		boost := ix.Boosts[field]
+		tf := map[string]int{}
+		for _, t := range toks {
+			tf[t.Term]++
+		}
+		for _, term := range pq.terms {
+			if tf[term] > 0 {
+				score += boost * (1 + math.Log(float64(tf[term]))) * idf[term]
+			}
+		}
+	}
+	for _, phrase := range pq.phrases {
+		matched := false
+		for field, toks := range doc.tokens {
+			if phraseInField(toks, phrase) {
+				matched = true
+				for _, pt := range phrase {
+					score += ix.Boosts[field] * idf[pt.term]
+				}
+			}
+		}
+		if !matched {
+			return 0, false
+		}
+	}
+	return score, true
+}
+
+// highlights marca los términos en el título y en un fragmento del contenido
+func highlights(texts map[string]string, tokens map[string][]token, terms []string) map[string]string {
+	termSet := map[string]bool{}
+	for _, term := range terms {
+		termSet[term] = true
+	}
+	return map[string]string{
+		"title":   highlight(texts["title"], tokens["title"], termSet, false),
+		"content": highlight(texts["content"], tokens["content"], termSet, true),
+	}
+}
+
+// highlight envuelve las coincidencias en <mark>; con snippet recorta alrededor de la primera
+func highlight(text string, toks []token, terms map[string]bool, snippet bool) string {
+	if len(toks) == 0 {
+		return html.EscapeString(text)
+	}
+	from, to := 0, len(toks)
+	if snippet {
+		first := 0
+		for i, t := range toks {
+			if terms[t.Term] {
+				first = i
+				break
+			}
+		}
+		from = max(0, first-snippetRadius)
+		to = min(len(toks), first+snippetRadius*2)
+	}
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("… ")
+	}
+	cursor := toks[from].Start
+	if from == 0 {
+		cursor = 0
+	}
+	for _, t := range toks[from:to] {
+		b.WriteString(html.EscapeString(text[cursor:t.Start]))
+		word := html.EscapeString(text[t.Start:t.End])
+		if terms[t.Term] {
+			b.WriteString("<mark>" + word + "</mark>")
+		} else {
+			b.WriteString(word)
+		}
+		cursor = t.End
+	}
+	if to < len(toks) {
+		b.WriteString(" …")
+	} else {
+		b.WriteString(html.EscapeString(text[cursor:]))
+	}
+	return strings.TrimSpace(b.String())
+}
+
+type facetCounter struct {
+	counts map[string]map[string]int
+	labels map[string]string
+}
+
+func newFacetCounter() *facetCounter {
+	return &facetCounter{
+		counts: map[string]map[string]int{"status": {}, "author": {}, "tag": {}},
+		labels: map[string]string{},
+	}
+}
+
+func (f *facetCounter) add(a *Article) {
+	f.counts["status"][a.Status]++
+	author := a.AuthorID.Hex()
+	f.counts["author"][author]++
+	f.labels[author] = a.AuthorName
+	for _, t := range a.Tags {
+		f.counts["tag"][t]++
+	}
+}
+
+// addCount suma un conteo ya agregado (ver MongoIndex)
+func (f *facetCounter) addCount(facet, value, label string, n int) {
+	f.counts[facet][value] += n
+	if label != "" {
+		f.labels[value] = label
+	}
+}
+
+func (f *facetCounter) result() map[string][]FacetCount {
+	out := map[string][]FacetCount{}
+	for facet, counts := range f.counts {
+		list := []FacetCount{}
+		for value, count := range counts {
+			fc := FacetCount{Value: value, Count: count}
+			if facet == "author" {
+				fc.Label = f.labels[value]
+			}
+			list = append(list, fc)
+		}
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Count != list[j].Count {
+				return list[i].Count > list[j].Count
+			}
+			return list[i].Value < list[j].Value
+		})
+		out[facet] = list
+	}
+	return out
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,165 @@
+package articles
+
+import (
+	"context"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const searchTimeout = 5 * time.Second
+
+// MongoIndex busca con el índice de texto de la colección de artículos. Mongo
+// lo actualiza con cada escritura, venga de la réplica que venga, así que Index
+// y Remove no hacen nada y no hay que reconstruir nada al arrancar.
+type MongoIndex struct {
+	collection *mongo.Collection
+}
+
+// NewMongoIndex crea, si falta, el índice de texto sobre título, tags y
+// contenido con los mismos pesos que DefaultBoosts. Sin idioma: los artículos
+// mezclan español e inglés y las stopwords de uno cortarían frases del otro.
+func NewMongoIndex(collection *mongo.Collection) *MongoIndex {
+	weights := bson.D{}
+	for _, field := range []string{"title", "tags", "content"} {
+		weights = append(weights, bson.E{Key: field, Value: int32(DefaultBoosts[field])})
+	}
+	_, err := collection.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "title", Value: "text"}, {Key: "tags", Value: "text"}, {Key: "content", Value: "text"}},
+		Options: options.Index().SetName("article_search").SetWeights(weights).SetDefaultLanguage("none"),
+	})
+	if err != nil {
+		log.Printf("⚠️ No se pudo crear el índice de búsqueda: %v", err)
+	}
+	return &MongoIndex{collection: collection}
+}
+
+func (ix *MongoIndex) Index(Article) error { return nil }
+
+func (ix *MongoIndex) Remove(primitive.ObjectID) error { return nil }
+
+// mongoSearchRow es un resultado con su puntaje de $text
+type mongoSearchRow struct {
+	Article `bson:",inline"`
+	Score   float64 `bson:"_score"`
+}
+
+type mongoFacetRow struct {
+	Value interface{} `bson:"_id"`
+	Label string      `bson:"label"`
+	Count int         `bson:"count"`
+}
+
+// Search arma un $text con cada término y cada frase entre comillas: así Mongo
+// los exige todos, como MemoryIndex. Un solo aggregate trae la página, el total
+// y las facetas; solo se leen los documentos de la página.
+func (ix *MongoIndex) Search(q SearchQuery) (*SearchResult, error) {
+	pq := parseQueryText(q.Text)
+	if len(pq.terms) == 0 {
+		return nil, ErrEmptyQuery
+	}
+	if err := q.Scope.scoped(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), searchTimeout)
+	defer cancel()
+
+	conds := bson.A{bson.M{"$text": bson.M{"$search": textSearch(q.Text)}}, q.Scope.bson()}
+	if q.Status != "" {
+		conds = append(conds, bson.M{"status": q.Status})
+	}
+	if q.AuthorID != nil {
+		conds = append(conds, bson.M{"author_id": *q.AuthorID})
+	}
+	if q.Tag != "" {
+		conds = append(conds, bson.M{"tags": strings.ToLower(q.Tag)})
+	}
+	page := bson.A{
+		bson.M{"$sort": bson.D{{Key: "_score", Value: -1}, {Key: "updated_at", Value: -1}}},
+		bson.M{"$skip": q.Offset},
+	}
+	if q.Limit > 0 {
+		page = append(page, bson.M{"$limit": q.Limit})
+	}
+	pipeline := bson.A{
+		bson.M{"$match": andFilter(conds)},
+		bson.M{"$addFields": bson.M{"_score": bson.M{"$meta": "textScore"}}},
+		bson.M{"$facet": bson.M{
+			"hits":   page,
+			"total":  bson.A{bson.M{"$count": "count"}},
+			"status": bson.A{bson.M{"$group": bson.M{"_id": "$status", "count": bson.M{"$sum": 1}}}},
+			"author": bson.A{bson.M{"$group": bson.M{"_id": "$author_id", "label": bson.M{"$first": "$author_name"}, "count": bson.M{"$sum": 1}}}},
+			"tag":    bson.A{bson.M{"$unwind": "$tags"}, bson.M{"$group": bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}}},
+		}},
+	}
+	cursor, err := ix.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var out []struct {
+		Hits   []mongoSearchRow `bson:"hits"`
+		Total  []mongoFacetRow  `bson:"total"`
+		Status []mongoFacetRow  `bson:"status"`
+		Author []mongoFacetRow  `bson:"author"`
+		Tag    []mongoFacetRow  `bson:"tag"`
+	}
+	if err := cursor.All(ctx, &out); err != nil {
+		return nil, err
+	}
+
+	res := &SearchResult{Hits: []SearchHit{}, Facets: newFacetCounter().result()}
+	if len(out) == 0 {
+		return res, nil
+	}
+	facets := newFacetCounter()
+	for facet, rows := range map[string][]mongoFacetRow{"status": out[0].Status, "author": out[0].Author, "tag": out[0].Tag} {
+		for _, row := range rows {
+			value, _ := row.Value.(string)
+			if id, ok := row.Value.(primitive.ObjectID); ok {
+				value = id.Hex()
+			}
+			facets.addCount(facet, value, row.Label, row.Count)
+		}
+	}
+	res.Facets = facets.result()
+	if len(out[0].Total) > 0 {
+		res.Total = out[0].Total[0].Count
+	}
+	for _, row := range out[0].Hits {
+		texts := searchTexts(&row.Article)
+		tokens := map[string][]token{}
+		for field, text := range texts {
+			tokens[field] = tokenize(text)
+		}
+		res.Hits = append(res.Hits, SearchHit{
+			Article:    row.Article,
+			Score:      math.Round(row.Score*1000) / 1000,
+			Highlights: highlights(texts, tokens, pq.terms),
+		})
+	}
+	return res, nil
+}
+
+// textSearch pasa a $search cada frase y cada palabra (sin stopwords) entre
+// comillas: Mongo une con OR las palabras sueltas pero exige todas las frases
+func textSearch(text string) string {
+	var parts []string
+	for _, m := range phraseRegex.FindAllStringSubmatch(text, -1) {
+		if phrase := strings.Join(strings.Fields(m[1]), " "); phrase != "" {
+			parts = append(parts, `"`+phrase+`"`)
+		}
+	}
+	rest := phraseRegex.ReplaceAllString(text, " ")
+	for _, t := range tokenize(rest) {
+		if t.Term != "" {
+			parts = append(parts, `"`+rest[t.Start:t.End]+`"`)
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,153 @@
+package articles
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestStem_SpanishAndEnglishVariants(t *testing.T) {
+	groups := [][]string{
+		{"artículo", "artículos", "articulo"},
+		{"article", "articles"},
+		{"publicación", "publicar", "publicado"},
+		{"acción", "acciones"},
+		{"publishing", "published", "publish"},
+	}
+	for _, g := range groups {
+		want := analyzeWord(g[0])
+		for _, w := range g[1:] {
+			if got := analyzeWord(w); got != want {
+				t.Errorf("%q -> %q, expected same stem as %q (%q)", w, got, g[0], want)
+			}
+		}
+	}
+}
+
+func TestTokenize_StopwordsKeepPositions(t *testing.T) {
+	toks := tokenize("La guerra de las galaxias")
+	if len(toks) != 5 {
+		t.Fatalf("expected 5 tokens, got %d", len(toks))
+	}
+	if toks[0].Term != "" || toks[2].Term != "" || toks[4].Pos != 4 {
+		t.Errorf("unexpected tokens: %+v", toks)
+	}
+}
+
+// everything es el alcance de las tareas del sistema: sin tenant
+var everything = Filter{System: true}
+
+func newTestIndex() (*MemoryIndex, []Article) {
+	ix := NewMemoryIndex()
+	alice := primitive.NewObjectID()
+	bob := primitive.NewObjectID()
+	docs := []Article{
+		{ID: primitive.NewObjectID(), Title: "Guía de Go", Content: "<p>Concurrencia con goroutines y canales.</p>", Status: StatusPublished, AuthorID: alice, AuthorName: "Alice", Tags: []string{"go"}},
+		{ID: primitive.NewObjectID(), Title: "Canales de noticias", Content: "Cómo publicar en varios canales a la vez con Go.", Status: StatusDraft, AuthorID: bob, AuthorName: "Bob", Tags: []string{"media"}},
+		{ID: primitive.NewObjectID(), Title: "Recetas", Content: "La guerra de las galaxias y otras sagas.", Status: StatusPublished, AuthorID: bob, AuthorName: "Bob"},
+	}
+	for _, d := range docs {
+		ix.Index(d)
+	}
+	return ix, docs
+}
+
+func TestMemoryIndex_TitleBoost(t *testing.T) {
+	ix, docs := newTestIndex()
+	res, err := ix.Search(SearchQuery{Text: "canales", Scope: everything})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Total != 2 {
+		t.Fatalf("expected 2 hits, got %d", res.Total)
+	}
+	if res.Hits[0].Article.ID != docs[1].ID {
+		t.Errorf("title match should rank first, got %q", res.Hits[0].Article.Title)
+	}
+	if !strings.Contains(res.Hits[0].Highlights["title"], "<mark>Canales</mark>") {
+		t.Errorf("missing title highlight: %q", res.Hits[0].Highlights["title"])
+	}
+	if !strings.Contains(res.Hits[1].Highlights["content"], "<mark>canales</mark>") {
+		t.Errorf("missing content highlight: %q", res.Hits[1].Highlights["content"])
+	}
+}
+
+func TestMemoryIndex_AccentsAndPhrase(t *testing.T) {
+	ix, docs := newTestIndex()
+	res, _ := ix.Search(SearchQuery{Text: "guia", Scope: everything})
+	if res.Total != 1 || res.Hits[0].Article.ID != docs[0].ID {
+		t.Errorf("accent-insensitive search failed: %+v", res)
+	}
+
+	res, _ = ix.Search(SearchQuery{Text: `"guerra de las galaxias"`, Scope: everything})
+	if res.Total != 1 || res.Hits[0].Article.ID != docs[2].ID {
+		t.Errorf("phrase search failed: %+v", res)
+	}
+	res, _ = ix.Search(SearchQuery{Text: `"galaxias guerra"`, Scope: everything})
+	if res.Total != 0 {
+		t.Errorf("out-of-order phrase should not match, got %d", res.Total)
+	}
+}
+
+func TestMemoryIndex_FiltersAndFacets(t *testing.T) {
+	ix, docs := newTestIndex()
+	now := time.Now()
+	res, _ := ix.Search(SearchQuery{Text: "go", Scope: Filter{VisibleAt: &now, System: true}})
+	if res.Total != 1 || res.Hits[0].Article.ID != docs[0].ID {
+		t.Errorf("visibility filter failed: %+v", res)
+	}
+
+	res, _ = ix.Search(SearchQuery{Text: "go", Scope: everything})
+	if res.Total != 2 {
+		t.Fatalf("expected 2 hits, got %d", res.Total)
+	}
+	statuses := map[string]int{}
+	for _, f := range res.Facets["status"] {
+		statuses[f.Value] = f.Count
+	}
+	if statuses[StatusPublished] != 1 || statuses[StatusDraft] != 1 {
+		t.Errorf("unexpected status facets: %+v", res.Facets["status"])
+	}
+
+	res, _ = ix.Search(SearchQuery{Text: "go", Tag: "media", Scope: everything})
+	if res.Total != 1 || res.Hits[0].Article.ID != docs[1].ID {
+		t.Errorf("tag filter failed: %+v", res)
+	}
+}
+
+func TestMemoryIndex_RemoveAndEmptyQuery(t *testing.T) {
+	ix, docs := newTestIndex()
+	ix.Remove(docs[0].ID)
+	res, _ := ix.Search(SearchQuery{Text: "guia", Scope: everything})
+	if res.Total != 0 {
+		t.Errorf("removed document still matches")
+	}
+	if _, err := ix.Search(SearchQuery{Text: "de la", Scope: everything}); err != ErrEmptyQuery {
+		t.Errorf("expected ErrEmptyQuery for stopword-only query, got %v", err)
+	}
+}
+
+func TestMemoryIndex_ScopeAndPage(t *testing.T) {
+	ix, docs := newTestIndex()
+	if _, err := ix.Search(SearchQuery{Text: "go"}); err != errUnscoped {
+		t.Errorf("a search without organization must be rejected, got %v", err)
+	}
+	res, _ := ix.Search(SearchQuery{Text: "go", Scope: Filter{AuthorID: docs[1].AuthorID, System: true}})
+	if res.Total != 1 || res.Hits[0].Article.ID != docs[1].ID {
+		t.Errorf("the scope filter failed: %+v", res)
+	}
+	res, _ = ix.Search(SearchQuery{Text: "go", Scope: everything, Limit: 1, Offset: 1})
+	if res.Total != 2 || len(res.Hits) != 1 {
+		t.Errorf("expected the second of 2 hits, got %d of %d", len(res.Hits), res.Total)
+	}
+}
+
+func TestTextSearch_QuotesEveryTerm(t *testing.T) {
+	got := textSearch(`Guía de  Go, "la guerra   de las galaxias" y más`)
+	want := `"la guerra de las galaxias" "Guía" "Go" "más"`
+	if got != want {
+		t.Errorf("textSearch = %s, want %s", got, want)
+	}
+}
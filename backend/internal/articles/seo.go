@@ -0,0 +1,307 @@
+package articles
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 🔍 Metadatos SEO y sociales. SEO guarda lo que el editor quiere pisar; lo
+// que falta sale del artículo (título, extracto, imagen, URL pública). El
+// extracto, las palabras y el tiempo de lectura se derivan del contenido en
+// las lecturas públicas y no se guardan.
+
+// SEO son los metadatos opcionales de un artículo para buscadores y redes
+type SEO struct {
+	MetaTitle       string `bson:"meta_title,omitempty" json:"meta_title,omitempty"`
+	MetaDescription string `bson:"meta_description,omitempty" json:"meta_description,omitempty"`
+	// CanonicalURL apunta a la versión original si el artículo se republica
+	CanonicalURL string `bson:"canonical_url,omitempty" json:"canonical_url,omitempty"`
+	NoIndex      bool   `bson:"noindex,omitempty" json:"noindex,omitempty"`
+	// Open Graph y Twitter; vacíos toman los valores de arriba
+	OGTitle            string `bson:"og_title,omitempty" json:"og_title,omitempty"`
+	OGDescription      string `bson:"og_description,omitempty" json:"og_description,omitempty"`
+	OGImage            string `bson:"og_image,omitempty" json:"og_image,omitempty"`
+	TwitterCard        string `bson:"twitter_card,omitempty" json:"twitter_card,omitempty"`
+	TwitterTitle       string `bson:"twitter_title,omitempty" json:"twitter_title,omitempty"`
+	TwitterDescription string `bson:"twitter_description,omitempty" json:"twitter_description,omitempty"`
+	TwitterImage       string `bson:"twitter_image,omitempty" json:"twitter_image,omitempty"`
+}
+
+const (
+	excerptLength        = 200
+	descriptionLength    = 160
+	wordsPerMinute       = 200
+	maxMetaTitle         = 70
+	maxMetaDescription   = 160
+	maxSocialTitle       = 95
+	maxSocialDescription = 200
+	maxSEOURL            = 2048
+)
+
+var twitterCards = map[string]bool{"summary": true, "summary_large_image": true}
+
+// validateSEO recorta espacios y comprueba largos, URLs y tipo de tarjeta
+func validateSEO(s *SEO) error {
+	if s == nil {
+		return nil
+	}
+	texts := []struct {
+		name  string
+		value *string
+		max   int
+	}{
+		{"meta_title", &s.MetaTitle, maxMetaTitle},
+		{"meta_description", &s.MetaDescription, maxMetaDescription},
+		{"og_title", &s.OGTitle, maxSocialTitle},
+		{"og_description", &s.OGDescription, maxSocialDescription},
+		{"twitter_title", &s.TwitterTitle, maxSocialTitle},
+		{"twitter_description", &s.TwitterDescription, maxSocialDescription},
+	}
+	for _, t := range texts {
+		*t.value = strings.TrimSpace(*t.value)
+		if n := utf8.RuneCountInString(*t.value); n > t.max {
+			return fmt.Errorf("seo.%s must be at most %d characters (got %d)", t.name, t.max, n)
+		}
+	}
+	s.CanonicalURL = strings.TrimSpace(s.CanonicalURL)
+	if s.CanonicalURL != "" {
+		u, err := url.Parse(s.CanonicalURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(s.CanonicalURL) > maxSEOURL {
+			return fmt.Errorf("seo.canonical_url must be an absolute http(s) URL")
+		}
+	}
+	for name, img := range map[string]*string{"og_image": &s.OGImage, "twitter_image": &s.TwitterImage} {
+		*img = strings.TrimSpace(*img)
+		if *img != "" && (!safeURL(*img, false) || len(*img) > maxSEOURL) {
+			return fmt.Errorf("seo.%s must be an http(s) or relative URL", name)
+		}
+	}
+	s.TwitterCard = strings.TrimSpace(s.TwitterCard)
+	if s.TwitterCard != "" && !twitterCards[s.TwitterCard] {
+		return fmt.Errorf("seo.twitter_card must be summary or summary_large_image")
+	}
+	return nil
+}
+
+// deriveText completa extracto, palabras y minutos de lectura a partir del HTML
+func deriveText(a *Article, body string) {
+	words := strings.Fields(html.UnescapeString(stripTags(body)))
+	a.WordCount = len(words)
+	a.ReadingTime = 0
+	if a.WordCount > 0 {
+		a.ReadingTime = (a.WordCount + wordsPerMinute - 1) / wordsPerMinute
+	}
+	a.Excerpt = summarize(body, excerptLength)
+}
+
+// summarize corta el texto plano del HTML en el último espacio antes de n runas
+func summarize(content string, n int) string {
+	text := strings.Join(strings.Fields(html.UnescapeString(stripTags(content))), " ")
+	if utf8.RuneCountInString(text) <= n {
+		return text
+	}
+	cut := string([]rune(text)[:n])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,.;:") + "…"
+}
+
+// HeadTag es una etiqueta del <head>; solo van los atributos que correspondan
+type HeadTag struct {
+	Tag      string `json:"tag"`
+	Name     string `json:"name,omitempty"`
+	Property string `json:"property,omitempty"`
+	Rel      string `json:"rel,omitempty"`
+	Hreflang string `json:"hreflang,omitempty"`
+	Href     string `json:"href,omitempty"`
+	Content  string `json:"content,omitempty"`
+}
+
+// ArticleMeta es lo que el frontend necesita para armar el <head> de un artículo
+type ArticleMeta struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Canonical   string         `json:"canonical"`
+	Robots      string         `json:"robots,omitempty"`
+	Excerpt     string         `json:"excerpt"`
+	WordCount   int            `json:"word_count"`
+	ReadingTime int            `json:"reading_time"`
+	Tags        []HeadTag      `json:"tags"`
+	JSONLD      map[string]any `json:"json_ld"`
+	// Head son las mismas etiquetas ya escapadas, listas para insertar
+	Head string `json:"head"`
+}
+
+// 🔍 Metadatos de un artículo publicado: head tags y JSON-LD (público)
+func (h *Handlers) GetArticleMeta(w http.ResponseWriter, r *http.Request) {
+	tenant, err := h.publicTenant(r)
+	if err != nil {
+		http.Error(w, err.Error(), tenantErrorStatus(err))
+		return
+	}
+	article, ok := h.publicBySlug(w, r, tenant, "/meta")
+	if !ok {
+		return
+	}
+	present, err := h.publicPresenter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	present(article)
+	now := time.Now()
+	variants, err := h.Repo.FindArticles(r.Context(), tenant.scope(Filter{TranslationGroup: translationGroupOf(article), VisibleAt: &now}))
+	if err != nil {
+		log.Printf("⚠️ No se pudieron buscar las traducciones de %s: %v", article.ID.Hex(), err)
+	}
+	meta := h.articleMeta(r, article, variants)
+	if h.cacheHeaders(w, r, RouteBySlug, readETag(r, article), article.UpdatedAt, []string{articleKey(article.ID), authorKey(article.AuthorID)}) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(meta)
+}
+
+// articleMeta resuelve los valores efectivos (override o derivado) y arma las etiquetas
+func (h *Handlers) articleMeta(r *http.Request, a *Article, variants []Article) ArticleMeta {
+	org := h.organization(a.OrganizationID)
+	base := h.siteBase(r, org)
+	seo := SEO{}
+	if a.SEO != nil {
+		seo = *a.SEO
+	}
+	siteName := ""
+	if org != nil {
+		siteName = org.Name
+	}
+	meta := ArticleMeta{
+		Title:       cmp.Or(seo.MetaTitle, a.Title),
+		Description: cmp.Or(seo.MetaDescription, summarize(a.Excerpt, descriptionLength)),
+		Canonical:   cmp.Or(seo.CanonicalURL, h.articleURL(base, a)),
+		Excerpt:     a.Excerpt,
+		WordCount:   a.WordCount,
+		ReadingTime: a.ReadingTime,
+	}
+	if seo.NoIndex {
+		meta.Robots = "noindex, nofollow"
+	}
+	image := absoluteURL(base, cmp.Or(seo.OGImage, a.Image))
+	twitterImage := absoluteURL(base, cmp.Or(seo.TwitterImage, seo.OGImage, a.Image))
+	card := seo.TwitterCard
+	if card == "" {
+		card = "summary"
+		if twitterImage != "" {
+			card = "summary_large_image"
+		}
+	}
+	locale := articleLocale(a, org)
+	published := a.CreatedAt
+	if a.PublishedAt != nil {
+		published = *a.PublishedAt
+	}
+
+	tags := []HeadTag{
+		{Tag: "meta", Name: "description", Content: meta.Description},
+		{Tag: "link", Rel: "canonical", Href: meta.Canonical},
+	}
+	if meta.Robots != "" {
+		tags = append(tags, HeadTag{Tag: "meta", Name: "robots", Content: meta.Robots})
+	}
+	if len(variants) > 1 {
+		for i := range variants {
+			v := &variants[i]
+			tags = append(tags, HeadTag{Tag: "link", Rel: "alternate", Hreflang: articleLocale(v, org), Href: h.articleURL(base, v)})
+		}
+	}
+	property := func(name, content string) {
+		if content != "" {
+			tags = append(tags, HeadTag{Tag: "meta", Property: name, Content: content})
+		}
+	}
+	name := func(name, content string) {
+		if content != "" {
+			tags = append(tags, HeadTag{Tag: "meta", Name: name, Content: content})
+		}
+	}
+	property("og:type", "article")
+	property("og:title", cmp.Or(seo.OGTitle, meta.Title))
+	property("og:description", cmp.Or(seo.OGDescription, meta.Description))
+	property("og:url", meta.Canonical)
+	property("og:image", image)
+	property("og:site_name", siteName)
+	property("og:locale", strings.ReplaceAll(locale, "-", "_"))
+	property("article:published_time", published.UTC().Format(time.RFC3339))
+	property("article:modified_time", a.UpdatedAt.UTC().Format(time.RFC3339))
+	property("article:author", a.AuthorName)
+	for _, tag := range a.Tags {
+		property("article:tag", tag)
+	}
+	name("twitter:card", card)
+	name("twitter:title", cmp.Or(seo.TwitterTitle, seo.OGTitle, meta.Title))
+	name("twitter:description", cmp.Or(seo.TwitterDescription, seo.OGDescription, meta.Description))
+	name("twitter:image", twitterImage)
+	meta.Tags = tags
+
+	ld := map[string]any{
+		"@context":         "https://schema.org",
+		"@type":            "Article",
+		"headline":         truncateRunes(meta.Title, 110),
+		"description":      meta.Description,
+		"url":              meta.Canonical,
+		"mainEntityOfPage": map[string]any{"@type": "WebPage", "@id": meta.Canonical},
+		"datePublished":    published.UTC().Format(time.RFC3339),
+		"dateModified":     a.UpdatedAt.UTC().Format(time.RFC3339),
+		"inLanguage":       locale,
+		"wordCount":        a.WordCount,
+	}
+	if a.AuthorName != "" {
+		ld["author"] = map[string]any{"@type": "Person", "name": a.AuthorName}
+	}
+	if siteName != "" {
+		ld["publisher"] = map[string]any{"@type": "Organization", "name": siteName, "url": base + "/"}
+	}
+	if image != "" {
+		ld["image"] = []string{image}
+	}
+	if len(a.Tags) > 0 {
+		ld["keywords"] = strings.Join(a.Tags, ", ")
+	}
+	meta.JSONLD = ld
+	meta.Head = renderHead(meta)
+	return meta
+}
+
+// renderHead escribe <title>, las etiquetas y el JSON-LD como HTML
+func renderHead(meta ArticleMeta) string {
+	var b strings.Builder
+	b.WriteString("<title>" + html.EscapeString(meta.Title) + "</title>\n")
+	for _, t := range meta.Tags {
+		b.WriteString("<" + t.Tag)
+		for _, attr := range [][2]string{{"name", t.Name}, {"property", t.Property}, {"rel", t.Rel}, {"hreflang", t.Hreflang}, {"href", t.Href}, {"content", t.Content}} {
+			if attr[1] != "" {
+				b.WriteString(" " + attr[0] + `="` + html.EscapeString(attr[1]) + `"`)
+			}
+		}
+		b.WriteString(">\n")
+	}
+	// json.Marshal escapa <, > y & así que el script no se puede cerrar desde adentro
+	ld, _ := json.Marshal(meta.JSONLD)
+	b.WriteString(`<script type="application/ld+json">` + string(ld) + "</script>\n")
+	return b.String()
+}
+
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-1]) + "…"
+}
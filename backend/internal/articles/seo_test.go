@@ -0,0 +1,137 @@
+package articles
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestValidateSEO(t *testing.T) {
+	ok := &SEO{MetaTitle: "  Título  ", CanonicalURL: "https://otro.example.com/nota", OGImage: "/media/a.png", TwitterCard: "summary"}
+	if err := validateSEO(ok); err != nil || ok.MetaTitle != "Título" {
+		t.Fatalf("expected valid trimmed seo, got %v %q", err, ok.MetaTitle)
+	}
+	bad := []*SEO{
+		{MetaTitle: strings.Repeat("a", maxMetaTitle+1)},
+		{MetaDescription: strings.Repeat("ñ", maxMetaDescription+1)},
+		{OGDescription: strings.Repeat("a", maxSocialDescription+1)},
+		{CanonicalURL: "/relativa"},
+		{CanonicalURL: "javascript:alert(1)"},
+		{TwitterImage: "javascript:alert(1)"},
+		{TwitterCard: "player"},
+	}
+	for _, s := range bad {
+		if err := validateSEO(s); err == nil {
+			t.Errorf("expected error for %+v", s)
+		}
+	}
+	if err := validateSEO(&SEO{MetaDescription: strings.Repeat("ñ", maxMetaDescription)}); err != nil {
+		t.Errorf("limits count characters, not bytes: %v", err)
+	}
+}
+
+func TestHandlers_SEOValidationOnSave(t *testing.T) {
+	e := newTestEnv()
+	author := e.newUser(e.orgA)
+	long := strings.Repeat("a", maxMetaTitle+1)
+	w := serve(e.h.CreateArticle, request("POST", "/articles", `{"title":"T","content":"x","seo":{"meta_title":"`+long+`"}}`, author, e.orgA))
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "meta_title") {
+		t.Errorf("create: expected 400 naming the field, got %d %s", w.Code, w.Body.String())
+	}
+	a := e.create(t, author, e.orgA, "Con SEO")
+	r := request("PUT", "/articles/"+a.ID.Hex(), `{"title":"Con SEO","content":"x","seo":{"twitter_card":"nope"}}`, author, e.orgA)
+	if w := serve(e.h.UpdateArticle, r, "id", a.ID.Hex()); w.Code != http.StatusBadRequest {
+		t.Errorf("update: expected 400, got %d", w.Code)
+	}
+	r = request("PUT", "/articles/"+a.ID.Hex(), `{"title":"Con SEO","content":"x","seo":{"meta_title":"Otro título","noindex":true}}`, author, e.orgA)
+	if w := serve(e.h.UpdateArticle, r, "id", a.ID.Hex()); w.Code != http.StatusOK {
+		t.Fatalf("update: expected 200, got %d %s", w.Code, w.Body.String())
+	}
+	stored, _ := e.h.Repo.FindArticle(r.Context(), Filter{ID: a.ID, System: true})
+	if stored.SEO == nil || stored.SEO.MetaTitle != "Otro título" || !stored.SEO.NoIndex {
+		t.Errorf("seo should be saved, got %+v", stored.SEO)
+	}
+}
+
+func TestHandlers_PublicReadDerivesText(t *testing.T) {
+	e := newTestEnv()
+	author := e.newUser(e.orgA)
+	body := strings.Repeat("palabra ", 450)
+	a := e.publishedWith(t, author, `{"title":"Larga","content":"<p>Tom &amp; Jerry `+body+`</p>"}`)
+
+	w := serve(e.h.GetArticleByID, request("GET", "/articles/"+a.ID.Hex(), "", primitive.NilObjectID, nil), "id", a.ID.Hex())
+	var got Article
+	json.NewDecoder(w.Body).Decode(&got)
+	if got.WordCount != 453 || got.ReadingTime != 3 {
+		t.Errorf("expected 453 words and 3 minutes, got %d / %d", got.WordCount, got.ReadingTime)
+	}
+	if !strings.HasPrefix(got.Excerpt, "Tom & Jerry palabra") || !strings.HasSuffix(got.Excerpt, "…") || len([]rune(got.Excerpt)) > excerptLength+1 {
+		t.Errorf("unexpected excerpt: %q", got.Excerpt)
+	}
+}
+
+func TestHandlers_ArticleMeta(t *testing.T) {
+	e := newTestEnv()
+	author := e.newUser(e.orgA)
+	a := e.publishedWith(t, author, `{"title":"Nota <b>","content":"<p>Primer párrafo.</p>","image":"/media/n.jpg","tags":["Go"]}`)
+	get := func(slug string) (*ArticleMeta, int) {
+		t.Helper()
+		w := serve(e.h.GetArticleMeta, request("GET", "/articles/slug/"+slug+"/meta", "", primitive.NilObjectID, nil), "slug", slug)
+		if w.Code != http.StatusOK {
+			return nil, w.Code
+		}
+		var m ArticleMeta
+		json.NewDecoder(w.Body).Decode(&m)
+		return &m, w.Code
+	}
+
+	m, _ := get(a.Slug)
+	if m == nil {
+		t.Fatalf("expected meta for %s", a.Slug)
+	}
+	canonical := "https://a.example.com/article/" + a.ID.Hex()
+	if m.Title != "Nota <b>" || m.Description != "Primer párrafo." || m.Canonical != canonical || m.Robots != "" {
+		t.Errorf("unexpected defaults: %+v", m)
+	}
+	tags := map[string]string{}
+	for _, tag := range m.Tags {
+		tags[tag.Name+tag.Property+tag.Rel] = tag.Content + tag.Href
+	}
+	if tags["og:image"] != "https://a.example.com/media/n.jpg" || tags["twitter:card"] != "summary_large_image" || tags["og:site_name"] != "A" || tags["canonical"] != canonical {
+		t.Errorf("unexpected tags: %v", tags)
+	}
+	if m.JSONLD["@type"] != "Article" || m.JSONLD["headline"] != "Nota <b>" || m.JSONLD["author"].(map[string]any)["name"] != "Ana Pérez" {
+		t.Errorf("unexpected json-ld: %v", m.JSONLD)
+	}
+	if !strings.Contains(m.Head, "<title>Nota &lt;b&gt;</title>") || !strings.Contains(m.Head, `<script type="application/ld+json">`) || strings.Contains(m.Head, `"Nota <b>"`) {
+		t.Errorf("head must be escaped and include json-ld:\n%s", m.Head)
+	}
+
+	r := request("PUT", "/articles/"+a.ID.Hex(), `{"title":"Nota <b>","content":"<p>Primer párrafo.</p>","seo":{"meta_title":"SEO","meta_description":"Desc","canonical_url":"https://origen.example.com/x","noindex":true,"og_title":"OG","twitter_card":"summary"}}`, author, e.orgA)
+	if w := serve(e.h.UpdateArticle, r, "id", a.ID.Hex()); w.Code != http.StatusOK {
+		t.Fatalf("update: %d %s", w.Code, w.Body.String())
+	}
+	m, _ = get(a.Slug)
+	tags = map[string]string{}
+	for _, tag := range m.Tags {
+		tags[tag.Name+tag.Property] = tag.Content
+	}
+	if m.Title != "SEO" || m.Description != "Desc" || m.Canonical != "https://origen.example.com/x" || m.Robots != "noindex, nofollow" {
+		t.Errorf("overrides should win: %+v", m)
+	}
+	if tags["og:title"] != "OG" || tags["twitter:title"] != "OG" || tags["twitter:card"] != "summary" || tags["robots"] != "noindex, nofollow" {
+		t.Errorf("unexpected social tags: %v", tags)
+	}
+
+	// noindex también lo saca del sitemap
+	w := serve(e.h.Sitemap, request("GET", "/sitemaps/articles-1.xml", "", primitive.NilObjectID, nil), "file", "articles-1.xml")
+	if strings.Contains(w.Body.String(), a.ID.Hex()) {
+		t.Errorf("noindex articles must not be listed in the sitemap")
+	}
+	if _, code := get("no-existe"); code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown slug, got %d", code)
+	}
+}
@@ -0,0 +1,287 @@
+package articles
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"pittsix/internal/organizations"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 🗺️ Sitemap de los artículos publicados. /sitemap.xml es un índice que apunta
+// a /sitemaps/articles-<n>.xml, cada uno con hasta sitemapURLLimit URLs en
+// orden de creación (los artículos nuevos van al último trozo y los demás no
+// cambian). Cada URL lleva lastmod, su imagen y, si tiene traducciones, los
+// alternates hreflang del grupo. Todo sale del sitio de la organización del host.
+
+// sitemapURLLimit es el máximo de URLs por sitemap del protocolo; variable para los tests
+var sitemapURLLimit = 50000
+
+// DefaultRobots son las reglas de robots.txt si no se configuran otras
+const DefaultRobots = "User-agent: *\nAllow: /\nDisallow: /preview/\n"
+
+const (
+	sitemapNS      = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	sitemapImageNS = "http://www.google.com/schemas/sitemap-image/1.1"
+	sitemapXHTMLNS = "http://www.w3.org/1999/xhtml"
+)
+
+type sitemapIndex struct {
+	XMLName  xml.Name         `xml:"sitemapindex"`
+	NS       string           `xml:"xmlns,attr"`
+	Sitemaps []sitemapPointer `xml:"sitemap"`
+}
+
+type sitemapPointer struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type urlSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	NS      string       `xml:"xmlns,attr"`
+	ImageNS string       `xml:"xmlns:image,attr"`
+	XHTMLNS string       `xml:"xmlns:xhtml,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc        string             `xml:"loc"`
+	LastMod    string             `xml:"lastmod"`
+	Alternates []sitemapAlternate `xml:"xhtml:link"`
+	Images     []sitemapImage     `xml:"image:image"`
+}
+
+type sitemapAlternate struct {
+	Rel      string `xml:"rel,attr"`
+	Hreflang string `xml:"hreflang,attr"`
+	Href     string `xml:"href,attr"`
+}
+
+type sitemapImage struct {
+	Loc   string `xml:"image:loc"`
+	Title string `xml:"image:title,omitempty"`
+}
+
+// sitemapFields son los campos que lee el sitemap; las fechas de programación
+// las usa el caché de lecturas para vencer la página a tiempo
+var sitemapFields = []string{"slug", "title", "image", "locale", "translation_group", "updated_at", "publish_at", "unpublish_at"}
+
+// sitemapSource resuelve la organización del host y el filtro de lo publicado
+func (h *Handlers) sitemapSource(w http.ResponseWriter, r *http.Request) (*organizations.Organization, Filter, bool) {
+	tenant, err := h.publicTenant(r)
+	if err != nil {
+		http.Error(w, err.Error(), tenantErrorStatus(err))
+		return nil, Filter{}, false
+	}
+	org, err := h.Orgs.GetByID(tenant.OrgID)
+	if err != nil || org == nil {
+		http.Error(w, "Organization not found", http.StatusNotFound)
+		return nil, Filter{}, false
+	}
+	now := time.Now()
+	return org, tenant.scope(Filter{VisibleAt: &now}), true
+}
+
+// sitemapPage lee hasta q.Limit artículos indexables (lo marcado noindex no se
+// anuncia) en orden de creación, para que los trozos sean estables
+func (h *Handlers) sitemapPage(ctx context.Context, f Filter, q ListQuery) ([]Article, int64, error) {
+	q.Sort, q.Indexable, q.Fields = "_id", true, sitemapFields
+	items, total, err := h.Repo.ListArticles(ctx, f, q)
+	if len(items) > q.Limit {
+		items = items[:q.Limit]
+	}
+	return items, total, err
+}
+
+// sitemapLastMod es la última modificación de los artículos con _id en [from, before)
+func (h *Handlers) sitemapLastMod(ctx context.Context, f Filter, from, before *primitive.ObjectID) (time.Time, error) {
+	latest, _, err := h.Repo.ListArticles(ctx, f, ListQuery{
+		Sort: "updated_at", Desc: true, Limit: 1, IDFrom: from, IDBefore: before,
+		Indexable: true, Fields: sitemapFields,
+	})
+	if err != nil || len(latest) == 0 {
+		return time.Time{}, err
+	}
+	return latest[0].UpdatedAt, nil
+}
+
+// 🗺️ Índice de sitemaps (público). No lee los artículos: por cada trozo busca
+// el _id donde empieza y el último modificado entre ese y el siguiente.
+func (h *Handlers) SitemapIndex(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	org, f, ok := h.sitemapSource(w, r)
+	if !ok {
+		return
+	}
+	_, total, err := h.sitemapPage(ctx, f, ListQuery{Limit: 1})
+	chunks := max(1, (int(total)+sitemapURLLimit-1)/sitemapURLLimit)
+	starts := make([]*primitive.ObjectID, chunks+1)
+	for n := 1; n < chunks && err == nil; n++ {
+		var first []Article
+		first, _, err = h.sitemapPage(ctx, f, ListQuery{Skip: n * sitemapURLLimit, Limit: 1})
+		if len(first) > 0 {
+			starts[n] = &first[0].ID
+		}
+	}
+	base := h.siteBase(r, org)
+	index := sitemapIndex{NS: sitemapNS, Sitemaps: []sitemapPointer{}}
+	var modified time.Time
+	for n := 0; n < chunks && err == nil; n++ {
+		var last time.Time
+		last, err = h.sitemapLastMod(ctx, f, starts[n], starts[n+1])
+		if last.After(modified) {
+			modified = last
+		}
+		p := sitemapPointer{Loc: base + "/sitemaps/articles-" + strconv.Itoa(n+1) + ".xml"}
+		if !last.IsZero() {
+			p.LastMod = last.UTC().Format(time.RFC3339)
+		}
+		index.Sitemaps = append(index.Sitemaps, p)
+	}
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	h.writeSitemap(w, r, org, index, modified)
+}
+
+// 🗺️ Un trozo del sitemap: GET /sitemaps/articles-<n>.xml (público)
+func (h *Handlers) Sitemap(w http.ResponseWriter, r *http.Request) {
+	name, ok := strings.CutSuffix(r.PathValue("file"), ".xml")
+	if !ok {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(name, "articles-"))
+	if err != nil || n < 1 || !strings.HasPrefix(name, "articles-") {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+	ctx := r.Context()
+	org, f, ok := h.sitemapSource(w, r)
+	if !ok {
+		return
+	}
+	chunk, _, err := h.sitemapPage(ctx, f, ListQuery{Skip: (n - 1) * sitemapURLLimit, Limit: sitemapURLLimit})
+	var groups map[primitive.ObjectID][]*Article
+	if err == nil {
+		groups, err = h.sitemapVariants(ctx, f, chunk)
+	}
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	if n > 1 && len(chunk) == 0 {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+	base := h.siteBase(r, org)
+
+	set := urlSet{NS: sitemapNS, ImageNS: sitemapImageNS, XHTMLNS: sitemapXHTMLNS, URLs: []sitemapURL{}}
+	for i := range chunk {
+		a := &chunk[i]
+		u := sitemapURL{Loc: h.articleURL(base, a), LastMod: a.UpdatedAt.UTC().Format(time.RFC3339)}
+		if variants := groups[translationGroupOf(a)]; len(variants) > 1 {
+			for _, v := range variants {
+				u.Alternates = append(u.Alternates, sitemapAlternate{Rel: "alternate", Hreflang: articleLocale(v, org), Href: h.articleURL(base, v)})
+				// x-default apunta al original si está publicado
+				if v.ID == translationGroupOf(a) {
+					u.Alternates = append(u.Alternates, sitemapAlternate{Rel: "alternate", Hreflang: "x-default", Href: h.articleURL(base, v)})
+				}
+			}
+		}
+		if a.Image != "" {
+			u.Images = []sitemapImage{{Loc: absoluteURL(base, a.Image), Title: a.Title}}
+		}
+		set.URLs = append(set.URLs, u)
+	}
+	h.writeSitemap(w, r, org, set, lastModified(chunk))
+}
+
+// sitemapVariants trae, de a trozos, las variantes publicadas de los grupos de
+// traducción que aparecen en el trozo. Al traducirlo, el original pasa a tener
+// su propio ID como TranslationGroup, así que los artículos sin grupo no tienen
+// variantes y no se consultan.
+func (h *Handlers) sitemapVariants(ctx context.Context, f Filter, chunk []Article) (map[primitive.ObjectID][]*Article, error) {
+	var ids []primitive.ObjectID
+	seen := map[primitive.ObjectID]bool{}
+	for i := range chunk {
+		if g := chunk[i].TranslationGroup; !g.IsZero() && !seen[g] {
+			seen[g] = true
+			ids = append(ids, g)
+		}
+	}
+	groups := map[primitive.ObjectID][]*Article{}
+	for skip := 0; len(ids) > 0; skip += sitemapURLLimit {
+		page, _, err := h.sitemapPage(ctx, f, ListQuery{TranslationGroups: ids, Skip: skip, Limit: sitemapURLLimit})
+		if err != nil {
+			return nil, err
+		}
+		for i := range page {
+			g := translationGroupOf(&page[i])
+			groups[g] = append(groups[g], &page[i])
+		}
+		if len(page) < sitemapURLLimit {
+			break
+		}
+	}
+	return groups, nil
+}
+
+// writeSitemap codifica el documento y lo sirve con los headers de caché; el
+// ETag es la huella del XML
+func (h *Handlers) writeSitemap(w http.ResponseWriter, r *http.Request, org *organizations.Organization, doc any, modified time.Time) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	if err := xml.NewEncoder(&buf).Encode(doc); err != nil {
+		log.Printf("❌ Error generando el sitemap: %v", err)
+		http.Error(w, "Sitemap error", http.StatusInternalServerError)
+		return
+	}
+	etag := `"s-` + digest(&buf) + `"`
+	if h.cacheHeaders(w, r, RouteSitemap, etag, modified, []string{listKey(org.ID)}) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Write(buf.Bytes())
+}
+
+func lastModified(items []Article) time.Time {
+	var last time.Time
+	for i := range items {
+		if items[i].UpdatedAt.After(last) {
+			last = items[i].UpdatedAt
+		}
+	}
+	return last
+}
+
+// 🤖 robots.txt del sitio (público): las reglas configuradas más el sitemap
+// de la organización del host
+func (h *Handlers) Robots(w http.ResponseWriter, r *http.Request) {
+	tenant, err := h.publicTenant(r)
+	if err != nil {
+		http.Error(w, err.Error(), tenantErrorStatus(err))
+		return
+	}
+	rules := h.RobotsRules
+	if rules == "" {
+		rules = DefaultRobots
+	}
+	body := strings.TrimRight(rules, "\n") + "\n\nSitemap: " + h.siteBase(r, h.organization(tenant.OrgID)) + "/sitemap.xml\n"
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if cc := h.CacheControl[RouteRobots]; cc != "" {
+		w.Header().Set("Cache-Control", cc)
+	}
+	w.Write([]byte(body))
+}
@@ -0,0 +1,174 @@
+package articles
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type parsedURLSet struct {
+	URLs []struct {
+		Loc        string `xml:"loc"`
+		LastMod    string `xml:"lastmod"`
+		Alternates []struct {
+			Hreflang string `xml:"hreflang,attr"`
+			Href     string `xml:"href,attr"`
+		} `xml:"http://www.w3.org/1999/xhtml link"`
+		Images []string `xml:"http://www.google.com/schemas/sitemap-image/1.1 image>loc"`
+	} `xml:"url"`
+}
+
+func getSitemap(handler http.HandlerFunc, target string, pathValues ...string) *httptest.ResponseRecorder {
+	return serve(handler, request("GET", target, "", primitive.NilObjectID, nil), pathValues...)
+}
+
+func TestSitemap_IndexChunks(t *testing.T) {
+	defer func(n int) { sitemapURLLimit = n }(sitemapURLLimit)
+	sitemapURLLimit = 2
+	e := newTestEnv()
+	author := e.newUser(e.orgA)
+	for _, title := range []string{"Uno", "Dos", "Tres"} {
+		e.published(t, author, title)
+	}
+	e.create(t, author, e.orgA, "Borrador")
+
+	w := getSitemap(e.h.SitemapIndex, "/sitemap.xml")
+	var index struct {
+		Sitemaps []struct {
+			Loc     string `xml:"loc"`
+			LastMod string `xml:"lastmod"`
+		} `xml:"sitemap"`
+	}
+	if err := xml.Unmarshal(w.Body.Bytes(), &index); err != nil {
+		t.Fatalf("invalid index: %v\n%s", err, w.Body.String())
+	}
+	if len(index.Sitemaps) != 2 || index.Sitemaps[1].Loc != "https://a.example.com/sitemaps/articles-2.xml" || index.Sitemaps[0].LastMod == "" {
+		t.Fatalf("expected 2 chunks on the organization's host, got %+v", index.Sitemaps)
+	}
+
+	urls := 0
+	for _, file := range []string{"articles-1.xml", "articles-2.xml"} {
+		w := getSitemap(e.h.Sitemap, "/sitemaps/"+file, "file", file)
+		var set parsedURLSet
+		if err := xml.Unmarshal(w.Body.Bytes(), &set); err != nil {
+			t.Fatalf("%s: invalid sitemap: %v", file, err)
+		}
+		urls += len(set.URLs)
+	}
+	if urls != 3 {
+		t.Errorf("expected the 3 published articles across chunks, got %d", urls)
+	}
+	for _, file := range []string{"articles-3.xml", "articles-0.xml", "otros-1.xml", "articles-1"} {
+		if w := getSitemap(e.h.Sitemap, "/sitemaps/"+file, "file", file); w.Code != http.StatusNotFound {
+			t.Errorf("%s: expected 404, got %d", file, w.Code)
+		}
+	}
+}
+
+// pagedOnly registra los listados y no deja leer la colección entera
+type pagedOnly struct {
+	Repository
+	queries []ListQuery
+}
+
+func (p *pagedOnly) FindArticles(ctx context.Context, f Filter) ([]Article, error) {
+	return nil, errors.New("the sitemap must not load every article")
+}
+
+func (p *pagedOnly) ListArticles(ctx context.Context, f Filter, q ListQuery) ([]Article, int64, error) {
+	p.queries = append(p.queries, q)
+	return p.Repository.ListArticles(ctx, f, q)
+}
+
+func TestSitemap_ReadsPagedProjections(t *testing.T) {
+	defer func(n int) { sitemapURLLimit = n }(sitemapURLLimit)
+	sitemapURLLimit = 2
+	e := newTestEnv()
+	author := e.newUser(e.orgA)
+	for _, title := range []string{"Uno", "Dos", "Tres", "Cuatro", "Cinco"} {
+		e.published(t, author, title)
+	}
+	repo := &pagedOnly{Repository: e.h.Repo}
+	e.h.Repo = repo
+
+	w := getSitemap(e.h.SitemapIndex, "/sitemap.xml")
+	if w.Code != http.StatusOK || strings.Count(w.Body.String(), "<sitemap>") != 3 {
+		t.Fatalf("expected 3 chunks: %d %s", w.Code, w.Body.String())
+	}
+	w = getSitemap(e.h.Sitemap, "/sitemaps/articles-2.xml", "file", "articles-2.xml")
+	if w.Code != http.StatusOK || strings.Count(w.Body.String(), "<url>") != 2 {
+		t.Fatalf("expected 2 URLs in the second chunk: %d %s", w.Code, w.Body.String())
+	}
+	for _, q := range repo.queries {
+		if q.Limit > sitemapURLLimit || len(q.Fields) == 0 || !q.Indexable {
+			t.Errorf("unbounded or unprojected query: %+v", q)
+		}
+	}
+}
+
+func TestSitemap_ImagesAndHreflang(t *testing.T) {
+	e := newTestEnv()
+	e.orgA.Locales = []string{"es", "en"}
+	author := e.newUser(e.orgA)
+	source := e.publishedWith(t, author, `{"title":"Hola","content":"x","image":"/media/hola.jpg"}`)
+	r := request("POST", "/articles/"+source.ID.Hex()+"/translations", `{"locale":"en","title":"Hello","content":"x"}`, author, e.orgA)
+	res := serve(e.h.CreateTranslation, r, "id", source.ID.Hex())
+	var en Article
+	json.NewDecoder(res.Body).Decode(&en)
+	for _, name := range []string{"submit", "approve", "publish"} {
+		e.transition(t, en.ID, name, author, e.orgA)
+	}
+	e.published(t, author, "Suelto")
+
+	w := getSitemap(e.h.Sitemap, "/sitemaps/articles-1.xml", "file", "articles-1.xml")
+	var set parsedURLSet
+	if err := xml.Unmarshal(w.Body.Bytes(), &set); err != nil || len(set.URLs) != 3 {
+		t.Fatalf("invalid sitemap (%v): %s", err, w.Body.String())
+	}
+	first := set.URLs[0]
+	if first.Loc != "https://a.example.com/article/"+source.ID.Hex() || first.LastMod == "" {
+		t.Errorf("unexpected url: %+v", first)
+	}
+	if len(first.Images) != 1 || first.Images[0] != "https://a.example.com/media/hola.jpg" {
+		t.Errorf("expected an absolute image entry, got %v", first.Images)
+	}
+	langs := map[string]string{}
+	for _, alt := range set.URLs[1].Alternates {
+		langs[alt.Hreflang] = alt.Href
+	}
+	if len(langs) != 3 || langs["en"] != "https://a.example.com/article/"+en.ID.Hex() || langs["x-default"] != first.Loc {
+		t.Errorf("translations must list every variant and x-default, got %v", langs)
+	}
+	if len(set.URLs[2].Alternates) != 0 {
+		t.Errorf("untranslated articles have no alternates, got %+v", set.URLs[2].Alternates)
+	}
+
+	etag := w.Header().Get("ETag")
+	r = request("GET", "/sitemaps/articles-1.xml", "", primitive.NilObjectID, nil)
+	r.Header.Set("If-None-Match", etag)
+	if w := serve(e.h.Sitemap, r, "file", "articles-1.xml"); w.Code != http.StatusNotModified {
+		t.Errorf("expected 304 for an unchanged sitemap, got %d", w.Code)
+	}
+}
+
+func TestRobots(t *testing.T) {
+	e := newTestEnv()
+	w := getSitemap(e.h.Robots, "/robots.txt")
+	body := w.Body.String()
+	if !strings.HasPrefix(body, DefaultRobots) || !strings.HasSuffix(body, "Sitemap: https://a.example.com/sitemap.xml\n") {
+		t.Errorf("unexpected robots.txt:\n%s", body)
+	}
+	e.h.RobotsRules = "User-agent: *\nDisallow: /\n"
+	e.h.SiteURL = "https://sitio.test"
+	w = getSitemap(e.h.Robots, "/robots.txt?org=org-b")
+	if w.Body.String() != "User-agent: *\nDisallow: /\n\nSitemap: https://sitio.test/sitemap.xml\n" {
+		t.Errorf("unexpected configured robots.txt:\n%s", w.Body.String())
+	}
+}
@@ -0,0 +1,158 @@
+package articles
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Los slugs son únicos por organización (índice único en Mongo) y, una vez
+// publicado el artículo, dejan de seguir al título para no romper enlaces.
+
+var errSlugLocked = errors.New("slug is locked once the article has been published")
+
+// fallbackSlug se usa cuando el título no deja ningún carácter válido
+const fallbackSlug = "articulo"
+
+// maxSlugAttempts acota los reintentos cuando otra petición gana el mismo slug
+const maxSlugAttempts = 5
+
+// planSlug decide la base del slug en una edición: mientras el artículo no se
+// publicó sigue al título (o al slug pedido); después queda fijo, salvo que
+// alguien con canOverride pida otro explícitamente
+func planSlug(current *Article, requested, title string, canOverride bool) (string, error) {
+	requested = GenerateSlug(requested)
+	explicit := requested != "" && requested != current.Slug
+	if current.PublishedAt == nil {
+		if explicit {
+			return requested, nil
+		}
+		return GenerateSlug(title), nil
+	}
+	if !explicit {
+		return current.Slug, nil
+	}
+	if !canOverride {
+		return "", errSlugLocked
+	}
+	return requested, nil
+}
+
+// slugTaken indica si otro artículo de la organización usa o usó el slug
+func (h *Handlers) slugTaken(ctx context.Context, a *Article, slug string) (bool, error) {
+	for _, f := range []Filter{
+		{OrganizationID: a.OrganizationID, Slug: slug},
+		{OrganizationID: a.OrganizationID, FormerSlug: slug},
+	} {
+		found, err := h.Repo.FindArticles(ctx, f)
+		if err != nil {
+			return false, err
+		}
+		for _, other := range found {
+			if other.ID != a.ID {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// uniqueSlug devuelve base, o base-2, base-3... si ya está en uso en la organización
+func (h *Handlers) uniqueSlug(ctx context.Context, a *Article, base string) (string, error) {
+	if base == "" {
+		base = fallbackSlug
+	}
+	candidate := base
+	for n := 2; ; n++ {
+		taken, err := h.slugTaken(ctx, a, candidate)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+		candidate = base + "-" + strconv.Itoa(n)
+	}
+}
+
+// nextSlugHistory agrega el slug anterior al historial si el artículo ya se
+// publicó, y saca el nuevo por si vuelve a uno que tuvo antes
+func nextSlugHistory(history []string, previous, slug string, published bool) []string {
+	out := []string{}
+	for _, s := range history {
+		if s != slug && s != previous {
+			out = append(out, s)
+		}
+	}
+	if published && previous != "" && previous != slug {
+		out = append(out, previous)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// assignSlug fija en a un slug único a partir de base y lo persiste con save.
+// Si el índice único rechaza el slug (otra petición lo tomó en paralelo) lo
+// recalcula y reintenta.
+func (h *Handlers) assignSlug(ctx context.Context, a *Article, base string, save func() error) error {
+	previous, history := a.Slug, a.SlugHistory
+	for attempt := 0; attempt < maxSlugAttempts; attempt++ {
+		slug, err := h.uniqueSlug(ctx, a, base)
+		if err != nil {
+			return err
+		}
+		a.Slug = slug
+		a.SlugHistory = nextSlugHistory(history, previous, slug, a.PublishedAt != nil)
+		if err := save(); err != errSlugTaken {
+			return err
+		}
+	}
+	return errSlugTaken
+}
+
+// BackfillSlugs desambigua los slugs repetidos dentro de una organización que
+// quedaron de antes del índice único: conserva el del artículo más antiguo y
+// numera el resto
+func (h *Handlers) BackfillSlugs(ctx context.Context) error {
+	all, err := h.Repo.FindArticles(ctx, Filter{System: true})
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].CreatedAt.Before(all[j].CreatedAt) })
+
+	type key struct {
+		org  primitive.ObjectID
+		slug string
+	}
+	seen := map[key]bool{}
+	for i := range all {
+		art := &all[i]
+		if art.OrganizationID.IsZero() {
+			continue
+		}
+		k := key{art.OrganizationID, art.Slug}
+		if !seen[k] && art.Slug != "" {
+			seen[k] = true
+			continue
+		}
+		current, base := art.Slug, art.Slug
+		if base == "" {
+			base = GenerateSlug(art.Title)
+		}
+		// Sin slug previo: el que se pierde es del artículo más antiguo, no va al historial
+		art.Slug = ""
+		err := h.assignSlug(ctx, art, base, func() error {
+			return h.Repo.UpdateArticle(ctx, Filter{Slug: current, System: true}, art)
+		})
+		if err != nil && err != ErrNotFound {
+			return err
+		}
+		seen[key{art.OrganizationID, art.Slug}] = true
+	}
+	return nil
+}
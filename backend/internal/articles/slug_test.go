@@ -0,0 +1,131 @@
+package articles
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestGenerateSlug_Transliterates(t *testing.T) {
+	cases := map[string]string{
+		"Acción rápida":         "accion-rapida",
+		"  Año   nuevo_2024 ":   "ano-nuevo-2024",
+		"¿Qué pasó? ¡Ñandú!":    "que-paso-nandu",
+		"Straße & Œuvre":        "strasse-oeuvre",
+		"!!!":                   "",
+		"Go -- guía de estilo ": "go-guia-de-estilo",
+	}
+	for in, want := range cases {
+		if got := GenerateSlug(in); got != want {
+			t.Errorf("GenerateSlug(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestPlanSlug(t *testing.T) {
+	now := time.Now()
+	draft := &Article{Slug: "viejo"}
+	published := &Article{Slug: "viejo", PublishedAt: &now}
+
+	if got, _ := planSlug(draft, "", "Título nuevo", false); got != "titulo-nuevo" {
+		t.Errorf("draft slug should follow the title, got %q", got)
+	}
+	if got, _ := planSlug(published, "", "Título nuevo", false); got != "viejo" {
+		t.Errorf("published slug should stay, got %q", got)
+	}
+	if got, _ := planSlug(published, "viejo", "Título nuevo", false); got != "viejo" {
+		t.Errorf("sending the current slug is not a change, got %q", got)
+	}
+	if _, err := planSlug(published, "otro", "x", false); err != errSlugLocked {
+		t.Errorf("expected errSlugLocked, got %v", err)
+	}
+	if got, _ := planSlug(published, "Otro", "x", true); got != "otro" {
+		t.Errorf("admins can change a published slug, got %q", got)
+	}
+}
+
+func TestHandlers_SlugsAreUniquePerOrganization(t *testing.T) {
+	e := newTestEnv()
+	authorA := e.newUser(e.orgA)
+	authorB := e.newUser(e.orgB)
+
+	first := e.create(t, authorA, e.orgA, "Acción rápida")
+	second := e.create(t, authorA, e.orgA, "Acción rápida")
+	other := e.create(t, authorB, e.orgB, "Acción rápida")
+	if first.Slug != "accion-rapida" || second.Slug != "accion-rapida-2" || other.Slug != "accion-rapida" {
+		t.Errorf("unexpected slugs: %q %q %q", first.Slug, second.Slug, other.Slug)
+	}
+
+	err := e.h.Repo.CreateArticle(context.Background(), &Article{OrganizationID: e.orgA.ID, Slug: "accion-rapida"})
+	if err != errSlugTaken {
+		t.Errorf("repository must reject duplicated slugs, got %v", err)
+	}
+}
+
+func TestHandlers_PublishedSlugIsLockedAndRedirects(t *testing.T) {
+	e := newTestEnv()
+	author := e.newUser(e.orgA)
+	a := e.create(t, author, e.orgA, "Primer título")
+
+	r := request("PUT", "/articles/"+a.ID.Hex(), `{"title":"Segundo título"}`, author, e.orgA)
+	serve(e.h.UpdateArticle, r, "id", a.ID.Hex())
+	got, _ := e.h.Repo.FindArticle(context.Background(), Filter{ID: a.ID, System: true})
+	if got.Slug != "segundo-titulo" || len(got.SlugHistory) != 0 {
+		t.Fatalf("draft slug should follow the title without history: %+v", got)
+	}
+
+	for _, step := range []string{"submit", "approve", "publish"} {
+		e.transition(t, a.ID, step, author, e.orgA)
+	}
+	r = request("PUT", "/articles/"+a.ID.Hex(), `{"title":"Tercer título"}`, author, e.orgA)
+	serve(e.h.UpdateArticle, r, "id", a.ID.Hex())
+	got, _ = e.h.Repo.FindArticle(context.Background(), Filter{ID: a.ID, System: true})
+	if got.Slug != "segundo-titulo" {
+		t.Errorf("published slug must not follow the title, got %q", got.Slug)
+	}
+
+	r = request("PUT", "/articles/"+a.ID.Hex(), `{"title":"Tercer título","slug":"tercero"}`, author, e.orgA)
+	if w := serve(e.h.UpdateArticle, r, "id", a.ID.Hex()); w.Code != http.StatusConflict {
+		t.Errorf("expected 409 changing a locked slug, got %d", w.Code)
+	}
+	r = request("PUT", "/articles/"+a.ID.Hex(), `{"title":"Tercer título","slug":"tercero"}`, author, e.orgA, "org_admin")
+	if w := serve(e.h.UpdateArticle, r, "id", a.ID.Hex()); w.Code != http.StatusOK {
+		t.Fatalf("org_admin should change the slug, got %d", w.Code)
+	}
+
+	r = request("GET", "/articles/slug/segundo-titulo?org=org-a", "", primitive.NilObjectID, nil)
+	w := serve(e.h.GetArticleBySlug, r, "slug", "segundo-titulo")
+	if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/articles/slug/tercero?org=org-a" {
+		t.Errorf("expected 301 to the current slug, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+
+	// El slug viejo sigue reservado para no redirigir a otro artículo
+	if b := e.create(t, author, e.orgA, "Segundo título"); b.Slug != "segundo-titulo-2" {
+		t.Errorf("former slugs must stay reserved, got %q", b.Slug)
+	}
+}
+
+func TestBackfillSlugs_KeepsOldestAndNumbersTheRest(t *testing.T) {
+	e := newTestEnv()
+	repo := NewMemoryRepository()
+	e.h.Repo = repo
+	ctx := context.Background()
+	base := time.Now()
+	// Simula datos previos al índice único
+	older := Article{ID: primitive.NewObjectID(), OrganizationID: e.orgA.ID, Slug: "nota", CreatedAt: base}
+	newer := Article{ID: primitive.NewObjectID(), OrganizationID: e.orgA.ID, Slug: "nota", CreatedAt: base.Add(time.Hour)}
+	repo.articles[older.ID] = older
+	repo.articles[newer.ID] = newer
+
+	if err := e.h.BackfillSlugs(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	o, _ := repo.FindArticle(ctx, Filter{ID: older.ID, System: true})
+	n, _ := repo.FindArticle(ctx, Filter{ID: newer.ID, System: true})
+	if o.Slug != "nota" || n.Slug != "nota-2" || len(n.SlugHistory) != 0 {
+		t.Errorf("unexpected backfill: %q %q %v", o.Slug, n.Slug, n.SlugHistory)
+	}
+}
@@ -0,0 +1,195 @@
+package articles
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"pittsix/pkg/middleware"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 🗂️ Category es una sección jerárquica de una organización
+type Category struct {
+	ID             primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	OrganizationID primitive.ObjectID  `bson:"organization_id" json:"organization_id"`
+	ParentID       *primitive.ObjectID `bson:"parent_id,omitempty" json:"parent_id,omitempty"`
+	Name           string              `bson:"name" json:"name"`
+	Slug           string              `bson:"slug" json:"slug"`
+	Description    string              `bson:"description,omitempty" json:"description,omitempty"`
+	CreatedAt      time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt      time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+// 🏷️ Tag es una etiqueta plana; los artículos guardan su slug en Article.Tags
+type Tag struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	OrganizationID primitive.ObjectID `bson:"organization_id" json:"organization_id"`
+	Name           string             `bson:"name" json:"name"`
+	Slug           string             `bson:"slug" json:"slug"`
+	Description    string             `bson:"description,omitempty" json:"description,omitempty"`
+	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt      time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+var (
+	errSlugTaken       = errors.New("slug already exists")
+	errUnknownCategory = errors.New("unknown category")
+	errCategoryCycle   = errors.New("category cannot be its own ancestor")
+)
+
+// TaxonomyRepository persiste categorías y etiquetas, siempre dentro de una organización
+type TaxonomyRepository interface {
+	CreateCategory(ctx context.Context, c *Category) error
+	GetCategory(ctx context.Context, orgID, id primitive.ObjectID) (*Category, error)
+	GetCategoryBySlug(ctx context.Context, orgID primitive.ObjectID, slug string) (*Category, error)
+	ListCategories(ctx context.Context, orgID primitive.ObjectID) ([]Category, error)
+	UpdateCategory(ctx context.Context, c *Category) error
+	DeleteCategory(ctx context.Context, orgID, id primitive.ObjectID) error
+
+	CreateTag(ctx context.Context, t *Tag) error
+	GetTag(ctx context.Context, orgID, id primitive.ObjectID) (*Tag, error)
+	GetTagBySlug(ctx context.Context, orgID primitive.ObjectID, slug string) (*Tag, error)
+	// ListTags devuelve las etiquetas cuyo slug empieza con prefix (todas si está vacío)
+	ListTags(ctx context.Context, orgID primitive.ObjectID, prefix string, limit int) ([]Tag, error)
+	UpdateTag(ctx context.Context, t *Tag) error
+	DeleteTag(ctx context.Context, orgID, id primitive.ObjectID) error
+}
+
+// canManageTaxonomy: admins o quien tenga taxonomy:manage
+func canManageTaxonomy(ctx context.Context) bool {
+	return middleware.HasRole(ctx, "superadmin", "org_admin") || middleware.HasPermission(ctx, "taxonomy:manage")
+}
+
+// resolveTags convierte los nombres recibidos en slugs de etiquetas de la
+// organización, creando las que todavía no existen
+func (h *Handlers) resolveTags(ctx context.Context, orgID primitive.ObjectID, names []string) ([]string, error) {
+	slugs := []string{}
+	seen := map[string]bool{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		slug := GenerateSlug(name)
+		if slug == "" || seen[slug] {
+			continue
+		}
+		seen[slug] = true
+		slugs = append(slugs, slug)
+
+		_, err := h.Taxonomy.GetTagBySlug(ctx, orgID, slug)
+		if err == nil {
+			continue
+		}
+		if err != ErrNotFound {
+			return nil, err
+		}
+		now := time.Now()
+		tag := &Tag{OrganizationID: orgID, Name: name, Slug: slug, CreatedAt: now, UpdatedAt: now}
+		// Si otra petición la creó en paralelo, el índice único la rechaza y nos sirve igual
+		if err := h.Taxonomy.CreateTag(ctx, tag); err != nil && err != errSlugTaken {
+			return nil, err
+		}
+	}
+	return slugs, nil
+}
+
+// classify asigna etiquetas y categorías al artículo dentro de su organización
+func (h *Handlers) classify(ctx context.Context, a *Article, tags []string, categoryIDs []primitive.ObjectID) error {
+	slugs, err := h.resolveTags(ctx, a.OrganizationID, tags)
+	if err != nil {
+		return err
+	}
+	cats, err := h.validateCategories(ctx, a.OrganizationID, categoryIDs)
+	if err != nil {
+		return err
+	}
+	a.Tags = slugs
+	a.CategoryIDs = cats
+	return nil
+}
+
+// taxonomyErrorStatus traduce los errores de taxonomía a HTTP
+func taxonomyErrorStatus(err error) int {
+	switch err {
+	case errUnknownCategory, errCategoryCycle:
+		return http.StatusBadRequest
+	case errSlugTaken:
+		return http.StatusConflict
+	case ErrNotFound:
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// validateCategories comprueba que las categorías asignadas existan en la organización
+func (h *Handlers) validateCategories(ctx context.Context, orgID primitive.ObjectID, ids []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	out := []primitive.ObjectID{}
+	seen := map[primitive.ObjectID]bool{}
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if _, err := h.Taxonomy.GetCategory(ctx, orgID, id); err == ErrNotFound {
+			return nil, errUnknownCategory
+		} else if err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, nil
+}
+
+// descendantIDs devuelve la categoría y todas sus subcategorías
+func descendantIDs(all []Category, root primitive.ObjectID) []primitive.ObjectID {
+	children := map[primitive.ObjectID][]primitive.ObjectID{}
+	for _, c := range all {
+		if c.ParentID != nil {
+			children[*c.ParentID] = append(children[*c.ParentID], c.ID)
+		}
+	}
+	ids := []primitive.ObjectID{root}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, children[ids[i]]...)
+	}
+	return ids
+}
+
+// createsCycle indica si colgar id de parent lo volvería ancestro de sí mismo
+func createsCycle(all []Category, id, parent primitive.ObjectID) bool {
+	for _, d := range descendantIDs(all, id) {
+		if d == parent {
+			return true
+		}
+	}
+	return false
+}
+
+// CategoryNode es una categoría con sus hijas, para ?tree=true
+type CategoryNode struct {
+	Category
+	Children []CategoryNode `json:"children"`
+}
+
+func categoryTree(all []Category) []CategoryNode {
+	byParent := map[primitive.ObjectID][]Category{}
+	var roots []Category
+	for _, c := range all {
+		if c.ParentID == nil {
+			roots = append(roots, c)
+		} else {
+			byParent[*c.ParentID] = append(byParent[*c.ParentID], c)
+		}
+	}
+	var build func([]Category) []CategoryNode
+	build = func(cs []Category) []CategoryNode {
+		nodes := []CategoryNode{}
+		for _, c := range cs {
+			nodes = append(nodes, CategoryNode{Category: c, Children: build(byParent[c.ID])})
+		}
+		return nodes
+	}
+	return build(roots)
+}
@@ -0,0 +1,461 @@
+package articles
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const autocompleteLimit = 10
+
+// taxonomyTenant resuelve la organización para administrar la taxonomía:
+// siempre una sola, nunca ?all_orgs
+func taxonomyTenant(w http.ResponseWriter, r *http.Request) (Tenant, bool) {
+	if !canManageTaxonomy(r.Context()) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return Tenant{}, false
+	}
+	tenant, err := tenantFromRequest(r)
+	if err == nil && tenant.All {
+		err = errNoTenant
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return Tenant{}, false
+	}
+	return tenant, true
+}
+
+type categoryInput struct {
+	Name        string              `json:"name"`
+	Slug        string              `json:"slug"`
+	Description string              `json:"description"`
+	ParentID    *primitive.ObjectID `json:"parent_id"`
+}
+
+// apply vuelca el input en la categoría validando padre y ciclos
+func (in categoryInput) apply(ctx context.Context, repo TaxonomyRepository, c *Category) error {
+	c.Name = strings.TrimSpace(in.Name)
+	c.Slug = GenerateSlug(in.Slug)
+	if c.Slug == "" {
+		c.Slug = GenerateSlug(c.Name)
+	}
+	c.Description = strings.TrimSpace(in.Description)
+	c.ParentID = in.ParentID
+	if c.ParentID == nil {
+		return nil
+	}
+	if _, err := repo.GetCategory(ctx, c.OrganizationID, *c.ParentID); err != nil {
+		if err == ErrNotFound {
+			return errUnknownCategory
+		}
+		return err
+	}
+	if c.ID.IsZero() {
+		return nil
+	}
+	all, err := repo.ListCategories(ctx, c.OrganizationID)
+	if err != nil {
+		return err
+	}
+	if createsCycle(all, c.ID, *c.ParentID) {
+		return errCategoryCycle
+	}
+	return nil
+}
+
+// 🗂️ Listar categorías (público): plano o con ?tree=true
+func (h *Handlers) ListCategories(w http.ResponseWriter, r *http.Request) {
+	tenant, err := h.publicTenant(r)
+	if err != nil {
+		http.Error(w, err.Error(), tenantErrorStatus(err))
+		return
+	}
+	all, err := h.Taxonomy.ListCategories(r.Context(), tenant.OrgID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if r.URL.Query().Get("tree") == "true" {
+		json.NewEncoder(w).Encode(categoryTree(all))
+		return
+	}
+	json.NewEncoder(w).Encode(all)
+}
+
+// ➕ Crear categoría (requiere JWT y taxonomy:manage)
+func (h *Handlers) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := taxonomyTenant(w, r)
+	if !ok {
+		return
+	}
+	var in categoryInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil || strings.TrimSpace(in.Name) == "" {
+		http.Error(w, "Invalid body", http.StatusBadRequest)
+		return
+	}
+	now := time.Now()
+	c := &Category{OrganizationID: tenant.OrgID, CreatedAt: now, UpdatedAt: now}
+	if err := in.apply(r.Context(), h.Taxonomy, c); err != nil {
+		http.Error(w, err.Error(), taxonomyErrorStatus(err))
+		return
+	}
+	if err := h.Taxonomy.CreateCategory(r.Context(), c); err != nil {
+		http.Error(w, err.Error(), taxonomyErrorStatus(err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(c)
+}
+
+// ✏️ Editar categoría (requiere JWT y taxonomy:manage)
+func (h *Handlers) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := taxonomyTenant(w, r)
+	if !ok {
+		return
+	}
+	id, err := primitive.ObjectIDFromHex(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	var in categoryInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil || strings.TrimSpace(in.Name) == "" {
+		http.Error(w, "Invalid body", http.StatusBadRequest)
+		return
+	}
+	c, err := h.Taxonomy.GetCategory(r.Context(), tenant.OrgID, id)
+	if err != nil {
+		http.Error(w, "Category not found", taxonomyErrorStatus(err))
+		return
+	}
+	if err := in.apply(r.Context(), h.Taxonomy, c); err != nil {
+		http.Error(w, err.Error(), taxonomyErrorStatus(err))
+		return
+	}
+	c.UpdatedAt = time.Now()
+	if err := h.Taxonomy.UpdateCategory(r.Context(), c); err != nil {
+		http.Error(w, err.Error(), taxonomyErrorStatus(err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
+
+// 🗑️ Borrar categoría: sus hijas pasan al padre y se desasigna de los artículos
+func (h *Handlers) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := taxonomyTenant(w, r)
+	if !ok {
+		return
+	}
+	ctx := r.Context()
+	id, err := primitive.ObjectIDFromHex(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	c, err := h.Taxonomy.GetCategory(ctx, tenant.OrgID, id)
+	if err != nil {
+		http.Error(w, "Category not found", taxonomyErrorStatus(err))
+		return
+	}
+	all, err := h.Taxonomy.ListCategories(ctx, tenant.OrgID)
+	if err != nil {
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	for i := range all {
+		child := &all[i]
+		if child.ParentID != nil && *child.ParentID == id {
+			child.ParentID = c.ParentID
+			child.UpdatedAt = time.Now()
+			if err := h.Taxonomy.UpdateCategory(ctx, child); err != nil {
+				log.Println(err.Error())
+				http.Error(w, "DB error", http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+	n, err := h.Repo.RemoveCategory(ctx, tenant.OrgID, id)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	if err := h.Taxonomy.DeleteCategory(ctx, tenant.OrgID, id); err != nil {
+		http.Error(w, err.Error(), taxonomyErrorStatus(err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"status": "deleted", "articles_updated": n})
+}
+
+// 📰 Artículos publicados de una categoría y sus subcategorías (público)
+func (h *Handlers) ListCategoryArticles(w http.ResponseWriter, r *http.Request) {
+	tenant, err := h.publicTenant(r)
+	if err != nil {
+		http.Error(w, err.Error(), tenantErrorStatus(err))
+		return
+	}
+	q, err := ParseListQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c, err := h.Taxonomy.GetCategoryBySlug(r.Context(), tenant.OrgID, r.PathValue("slug"))
+	if err != nil {
+		http.Error(w, "Category not found", taxonomyErrorStatus(err))
+		return
+	}
+	all, err := h.Taxonomy.ListCategories(r.Context(), tenant.OrgID)
+	if err != nil {
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	q.Status = nil
+	q.CategoryIDs = descendantIDs(all, c.ID)
+	h.writePublicPage(w, r, tenant, q)
+}
+
+type tagInput struct {
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	Description string `json:"description"`
+}
+
+// 🏷️ Listar etiquetas (público)
+func (h *Handlers) ListTags(w http.ResponseWriter, r *http.Request) {
+	h.writeTags(w, r, "", 0)
+}
+
+// 🔤 Autocompletar etiquetas: GET /tags/autocomplete?q=prefijo
+func (h *Handlers) AutocompleteTags(w http.ResponseWriter, r *http.Request) {
+	prefix := GenerateSlug(r.URL.Query().Get("q"))
+	if prefix == "" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode([]Tag{})
+		return
+	}
+	h.writeTags(w, r, prefix, autocompleteLimit)
+}
+
+func (h *Handlers) writeTags(w http.ResponseWriter, r *http.Request, prefix string, limit int) {
+	tenant, err := h.publicTenant(r)
+	if err != nil {
+		http.Error(w, err.Error(), tenantErrorStatus(err))
+		return
+	}
+	tags, err := h.Taxonomy.ListTags(r.Context(), tenant.OrgID, prefix, limit)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tags)
+}
+
+// ➕ Crear etiqueta (requiere JWT y taxonomy:manage)
+func (h *Handlers) CreateTag(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := taxonomyTenant(w, r)
+	if !ok {
+		return
+	}
+	var in tagInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil || strings.TrimSpace(in.Name) == "" {
+		http.Error(w, "Invalid body", http.StatusBadRequest)
+		return
+	}
+	now := time.Now()
+	t := &Tag{
+		OrganizationID: tenant.OrgID,
+		Name:           strings.TrimSpace(in.Name),
+		Slug:           GenerateSlug(in.Slug),
+		Description:    strings.TrimSpace(in.Description),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if t.Slug == "" {
+		t.Slug = GenerateSlug(t.Name)
+	}
+	if err := h.Taxonomy.CreateTag(r.Context(), t); err != nil {
+		http.Error(w, err.Error(), taxonomyErrorStatus(err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(t)
+}
+
+// ✏️ Renombrar etiqueta: si cambia el slug se reescriben los artículos que la usan
+func (h *Handlers) UpdateTag(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := taxonomyTenant(w, r)
+	if !ok {
+		return
+	}
+	ctx := r.Context()
+	id, err := primitive.ObjectIDFromHex(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	var in tagInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil || strings.TrimSpace(in.Name) == "" {
+		http.Error(w, "Invalid body", http.StatusBadRequest)
+		return
+	}
+	t, err := h.Taxonomy.GetTag(ctx, tenant.OrgID, id)
+	if err != nil {
+		http.Error(w, "Tag not found", taxonomyErrorStatus(err))
+		return
+	}
+	oldSlug := t.Slug
+	t.Name = strings.TrimSpace(in.Name)
+	t.Slug = GenerateSlug(in.Slug)
+	if t.Slug == "" {
+		t.Slug = GenerateSlug(t.Name)
+	}
+	t.Description = strings.TrimSpace(in.Description)
+	t.UpdatedAt = time.Now()
+	// Un slug ocupado por otra etiqueta se resuelve con merge, no con rename
+	if err := h.Taxonomy.UpdateTag(ctx, t); err != nil {
+		http.Error(w, err.Error(), taxonomyErrorStatus(err))
+		return
+	}
+	n, err := h.rewriteTag(ctx, tenant.OrgID, oldSlug, t.Slug)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"tag": t, "articles_updated": n})
+}
+
+// 🗑️ Borrar etiqueta y quitarla de los artículos
+func (h *Handlers) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := taxonomyTenant(w, r)
+	if !ok {
+		return
+	}
+	ctx := r.Context()
+	id, err := primitive.ObjectIDFromHex(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	t, err := h.Taxonomy.GetTag(ctx, tenant.OrgID, id)
+	if err != nil {
+		http.Error(w, "Tag not found", taxonomyErrorStatus(err))
+		return
+	}
+	n, err := h.rewriteTag(ctx, tenant.OrgID, t.Slug, "")
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	if err := h.Taxonomy.DeleteTag(ctx, tenant.OrgID, id); err != nil {
+		http.Error(w, err.Error(), taxonomyErrorStatus(err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"status": "deleted", "articles_updated": n})
+}
+
+// 🔗 Fusionar etiquetas: POST /tags/{id}/merge {"into": "<id>"}. Los artículos
+// pasan a la etiqueta destino y la de origen se borra.
+func (h *Handlers) MergeTag(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := taxonomyTenant(w, r)
+	if !ok {
+		return
+	}
+	ctx := r.Context()
+	id, err := primitive.ObjectIDFromHex(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	var in struct {
+		Into primitive.ObjectID `json:"into"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil || in.Into.IsZero() {
+		http.Error(w, "Invalid body", http.StatusBadRequest)
+		return
+	}
+	if in.Into == id {
+		http.Error(w, "Cannot merge a tag into itself", http.StatusBadRequest)
+		return
+	}
+	source, err := h.Taxonomy.GetTag(ctx, tenant.OrgID, id)
+	if err != nil {
+		http.Error(w, "Tag not found", taxonomyErrorStatus(err))
+		return
+	}
+	target, err := h.Taxonomy.GetTag(ctx, tenant.OrgID, in.Into)
+	if err != nil {
+		http.Error(w, "Target tag not found", taxonomyErrorStatus(err))
+		return
+	}
+	n, err := h.rewriteTag(ctx, tenant.OrgID, source.Slug, target.Slug)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	if err := h.Taxonomy.DeleteTag(ctx, tenant.OrgID, source.ID); err != nil {
+		http.Error(w, err.Error(), taxonomyErrorStatus(err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"tag": target, "articles_updated": n})
+}
+
+// rewriteTag reemplaza un slug en los artículos y los reindexa
+func (h *Handlers) rewriteTag(ctx context.Context, orgID primitive.ObjectID, from, to string) (int64, error) {
+	if from == to {
+		return 0, nil
+	}
+	affected, err := h.Repo.FindArticles(ctx, Filter{OrganizationID: orgID, Tag: from})
+	if err != nil {
+		return 0, err
+	}
+	n, err := h.Repo.ReplaceTag(ctx, orgID, from, to)
+	if err != nil {
+		return 0, err
+	}
+	for _, a := range affected {
+		h.reindex(ctx, a.ID)
+	}
+	return n, nil
+}
+
+// 📰 Artículos publicados con una etiqueta (público)
+func (h *Handlers) ListTagArticles(w http.ResponseWriter, r *http.Request) {
+	tenant, err := h.publicTenant(r)
+	if err != nil {
+		http.Error(w, err.Error(), tenantErrorStatus(err))
+		return
+	}
+	q, err := ParseListQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	t, err := h.Taxonomy.GetTagBySlug(r.Context(), tenant.OrgID, r.PathValue("slug"))
+	if err != nil {
+		http.Error(w, "Tag not found", taxonomyErrorStatus(err))
+		return
+	}
+	q.Status = nil
+	q.Tag = t.Slug
+	h.writePublicPage(w, r, tenant, q)
+}
@@ -0,0 +1,189 @@
+package articles
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryTaxonomyRepository es la contraparte en memoria de MongoTaxonomyRepository
+type MemoryTaxonomyRepository struct {
+	mu         sync.RWMutex
+	categories map[primitive.ObjectID]Category
+	tags       map[primitive.ObjectID]Tag
+}
+
+func NewMemoryTaxonomyRepository() *MemoryTaxonomyRepository {
+	return &MemoryTaxonomyRepository{
+		categories: map[primitive.ObjectID]Category{},
+		tags:       map[primitive.ObjectID]Tag{},
+	}
+}
+
+func (r *MemoryTaxonomyRepository) categorySlugTaken(c *Category) bool {
+	for _, other := range r.categories {
+		if other.ID != c.ID && other.OrganizationID == c.OrganizationID && other.Slug == c.Slug {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *MemoryTaxonomyRepository) tagSlugTaken(t *Tag) bool {
+	for _, other := range r.tags {
+		if other.ID != t.ID && other.OrganizationID == t.OrganizationID && other.Slug == t.Slug {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *MemoryTaxonomyRepository) CreateCategory(ctx context.Context, c *Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.categorySlugTaken(c) {
+		return errSlugTaken
+	}
+	if c.ID.IsZero() {
+		c.ID = primitive.NewObjectID()
+	}
+	r.categories[c.ID] = *c
+	return nil
+}
+
+func (r *MemoryTaxonomyRepository) GetCategory(ctx context.Context, orgID, id primitive.ObjectID) (*Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.categories[id]
+	if !ok || c.OrganizationID != orgID {
+		return nil, ErrNotFound
+	}
+	return &c, nil
+}
+
+func (r *MemoryTaxonomyRepository) GetCategoryBySlug(ctx context.Context, orgID primitive.ObjectID, slug string) (*Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, c := range r.categories {
+		if c.OrganizationID == orgID && c.Slug == slug {
+			return &c, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryTaxonomyRepository) ListCategories(ctx context.Context, orgID primitive.ObjectID) ([]Category, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := []Category{}
+	for _, c := range r.categories {
+		if c.OrganizationID == orgID {
+			out = append(out, c)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func (r *MemoryTaxonomyRepository) UpdateCategory(ctx context.Context, c *Category) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.categories[c.ID]
+	if !ok || current.OrganizationID != c.OrganizationID {
+		return ErrNotFound
+	}
+	if r.categorySlugTaken(c) {
+		return errSlugTaken
+	}
+	r.categories[c.ID] = *c
+	return nil
+}
+
+func (r *MemoryTaxonomyRepository) DeleteCategory(ctx context.Context, orgID, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.categories[id]
+	if !ok || c.OrganizationID != orgID {
+		return ErrNotFound
+	}
+	delete(r.categories, id)
+	return nil
+}
+
+func (r *MemoryTaxonomyRepository) CreateTag(ctx context.Context, t *Tag) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.tagSlugTaken(t) {
+		return errSlugTaken
+	}
+	if t.ID.IsZero() {
+		t.ID = primitive.NewObjectID()
+	}
+	r.tags[t.ID] = *t
+	return nil
+}
+
+func (r *MemoryTaxonomyRepository) GetTag(ctx context.Context, orgID, id primitive.ObjectID) (*Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.tags[id]
+	if !ok || t.OrganizationID != orgID {
+		return nil, ErrNotFound
+	}
+	return &t, nil
+}
+
+func (r *MemoryTaxonomyRepository) GetTagBySlug(ctx context.Context, orgID primitive.ObjectID, slug string) (*Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, t := range r.tags {
+		if t.OrganizationID == orgID && t.Slug == slug {
+			return &t, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *MemoryTaxonomyRepository) ListTags(ctx context.Context, orgID primitive.ObjectID, prefix string, limit int) ([]Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := []Tag{}
+	for _, t := range r.tags {
+		if t.OrganizationID == orgID && strings.HasPrefix(t.Slug, prefix) {
+			out = append(out, t)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Slug < out[j].Slug })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (r *MemoryTaxonomyRepository) UpdateTag(ctx context.Context, t *Tag) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.tags[t.ID]
+	if !ok || current.OrganizationID != t.OrganizationID {
+		return ErrNotFound
+	}
+	if r.tagSlugTaken(t) {
+		return errSlugTaken
+	}
+	r.tags[t.ID] = *t
+	return nil
+}
+
+func (r *MemoryTaxonomyRepository) DeleteTag(ctx context.Context, orgID, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tags[id]
+	if !ok || t.OrganizationID != orgID {
+		return ErrNotFound
+	}
+	delete(r.tags, id)
+	return nil
+}
@@ -0,0 +1,161 @@
+package articles
+
+import (
+	"context"
+	"log"
+	"regexp"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoTaxonomyRepository struct {
+	categories *mongo.Collection
+	tags       *mongo.Collection
+}
+
+// NewMongoTaxonomyRepository usa las colecciones categories y tags de la base
+func NewMongoTaxonomyRepository(db *mongo.Database) *MongoTaxonomyRepository {
+	r := &MongoTaxonomyRepository{
+		categories: db.Collection("categories"),
+		tags:       db.Collection("tags"),
+	}
+	r.ensureIndexes()
+	return r
+}
+
+func (r *MongoTaxonomyRepository) ensureIndexes() {
+	unique := mongo.IndexModel{
+		Keys:    bson.D{{Key: "organization_id", Value: 1}, {Key: "slug", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+	for _, c := range []*mongo.Collection{r.categories, r.tags} {
+		if _, err := c.Indexes().CreateOne(context.Background(), unique); err != nil {
+			log.Printf("⚠️ No se pudo crear el índice de slugs en %s: %v", c.Name(), err)
+		}
+	}
+}
+
+func (r *MongoTaxonomyRepository) CreateCategory(ctx context.Context, c *Category) error {
+	if c.ID.IsZero() {
+		c.ID = primitive.NewObjectID()
+	}
+	_, err := r.categories.InsertOne(ctx, c)
+	if mongo.IsDuplicateKeyError(err) {
+		return errSlugTaken
+	}
+	return err
+}
+
+func (r *MongoTaxonomyRepository) GetCategory(ctx context.Context, orgID, id primitive.ObjectID) (*Category, error) {
+	return findOne[Category](ctx, r.categories, bson.M{"organization_id": orgID, "_id": id})
+}
+
+func (r *MongoTaxonomyRepository) GetCategoryBySlug(ctx context.Context, orgID primitive.ObjectID, slug string) (*Category, error) {
+	return findOne[Category](ctx, r.categories, bson.M{"organization_id": orgID, "slug": slug})
+}
+
+func (r *MongoTaxonomyRepository) ListCategories(ctx context.Context, orgID primitive.ObjectID) ([]Category, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := r.categories.Find(ctx, bson.M{"organization_id": orgID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	return decodeAll[Category](ctx, cursor)
+}
+
+func (r *MongoTaxonomyRepository) UpdateCategory(ctx context.Context, c *Category) error {
+	res, err := r.categories.ReplaceOne(ctx, bson.M{"organization_id": c.OrganizationID, "_id": c.ID}, c)
+	if mongo.IsDuplicateKeyError(err) {
+		return errSlugTaken
+	}
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *MongoTaxonomyRepository) DeleteCategory(ctx context.Context, orgID, id primitive.ObjectID) error {
+	return deleteOne(ctx, r.categories, bson.M{"organization_id": orgID, "_id": id})
+}
+
+func (r *MongoTaxonomyRepository) CreateTag(ctx context.Context, t *Tag) error {
+	if t.ID.IsZero() {
+		t.ID = primitive.NewObjectID()
+	}
+	_, err := r.tags.InsertOne(ctx, t)
+	if mongo.IsDuplicateKeyError(err) {
+		return errSlugTaken
+	}
+	return err
+}
+
+func (r *MongoTaxonomyRepository) GetTag(ctx context.Context, orgID, id primitive.ObjectID) (*Tag, error) {
+	return findOne[Tag](ctx, r.tags, bson.M{"organization_id": orgID, "_id": id})
+}
+
+func (r *MongoTaxonomyRepository) GetTagBySlug(ctx context.Context, orgID primitive.ObjectID, slug string) (*Tag, error) {
+	return findOne[Tag](ctx, r.tags, bson.M{"organization_id": orgID, "slug": slug})
+}
+
+func (r *MongoTaxonomyRepository) ListTags(ctx context.Context, orgID primitive.ObjectID, prefix string, limit int) ([]Tag, error) {
+	filter := bson.M{"organization_id": orgID}
+	if prefix != "" {
+		filter["slug"] = bson.M{"$regex": "^" + regexp.QuoteMeta(prefix)}
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "slug", Value: 1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	cursor, err := r.tags.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	return decodeAll[Tag](ctx, cursor)
+}
+
+func (r *MongoTaxonomyRepository) UpdateTag(ctx context.Context, t *Tag) error {
+	res, err := r.tags.ReplaceOne(ctx, bson.M{"organization_id": t.OrganizationID, "_id": t.ID}, t)
+	if mongo.IsDuplicateKeyError(err) {
+		return errSlugTaken
+	}
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *MongoTaxonomyRepository) DeleteTag(ctx context.Context, orgID, id primitive.ObjectID) error {
+	return deleteOne(ctx, r.tags, bson.M{"organization_id": orgID, "_id": id})
+}
+
+func findOne[T any](ctx context.Context, c *mongo.Collection, filter bson.M) (*T, error) {
+	var v T
+	err := c.FindOne(ctx, filter).Decode(&v)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func deleteOne(ctx context.Context, c *mongo.Collection, filter bson.M) error {
+	res, err := c.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package articles
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestResolveTags_NormalizesAndCreates(t *testing.T) {
+	e := newTestEnv()
+	ctx := context.Background()
+	got, err := e.h.resolveTags(ctx, e.orgA.ID, []string{"Go", " go ", "", "Mongo"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"go", "mongo"}) {
+		t.Errorf("unexpected tags: %v", got)
+	}
+	tags, _ := e.h.Taxonomy.ListTags(ctx, e.orgA.ID, "", 0)
+	if len(tags) != 2 || tags[0].Name != "Go" {
+		t.Errorf("expected tags to be created once, got %+v", tags)
+	}
+	if other, _ := e.h.Taxonomy.ListTags(ctx, e.orgB.ID, "", 0); len(other) != 0 {
+		t.Errorf("tags must be created in the article's organization only")
+	}
+}
+
+func TestCategoryHierarchy(t *testing.T) {
+	root, child, grandchild := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	all := []Category{
+		{ID: root, Name: "Deportes"},
+		{ID: child, Name: "Fútbol", ParentID: &root},
+		{ID: grandchild, Name: "Liga", ParentID: &child},
+		{ID: primitive.NewObjectID(), Name: "Política"},
+	}
+	if ids := descendantIDs(all, root); len(ids) != 3 {
+		t.Errorf("expected root and 2 descendants, got %v", ids)
+	}
+	if !createsCycle(all, root, grandchild) {
+		t.Errorf("moving a category under its grandchild must be a cycle")
+	}
+	if createsCycle(all, grandchild, root) {
+		t.Errorf("moving a leaf under the root is not a cycle")
+	}
+	tree := categoryTree(all)
+	if len(tree) != 2 || len(tree[0].Children) != 1 || len(tree[0].Children[0].Children) != 1 {
+		t.Errorf("unexpected tree: %+v", tree)
+	}
+}
+
+func TestHandlers_CategoryArticlesIncludeSubcategories(t *testing.T) {
+	e := newTestEnv()
+	admin := e.newUser(e.orgA)
+
+	w := serve(e.h.CreateCategory, request("POST", "/categories", `{"name":"Deportes"}`, admin, e.orgA, "org_admin"))
+	var parent Category
+	json.NewDecoder(w.Body).Decode(&parent)
+	w = serve(e.h.CreateCategory, request("POST", "/categories", `{"name":"Fútbol","parent_id":"`+parent.ID.Hex()+`"}`, admin, e.orgA, "org_admin"))
+	var child Category
+	json.NewDecoder(w.Body).Decode(&child)
+	if w.Code != http.StatusCreated || child.ParentID == nil || *child.ParentID != parent.ID {
+		t.Fatalf("unexpected child category: %d %+v", w.Code, child)
+	}
+	if w := serve(e.h.CreateCategory, request("POST", "/categories", `{"name":"Deportes"}`, admin, e.orgA, "org_admin")); w.Code != http.StatusConflict {
+		t.Errorf("expected 409 for duplicated slug, got %d", w.Code)
+	}
+	if w := serve(e.h.CreateCategory, request("POST", "/categories", `{"name":"Nada"}`, admin, e.orgA)); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 without taxonomy:manage, got %d", w.Code)
+	}
+
+	body := `{"title":"Final","content":"gol","category_ids":["` + child.ID.Hex() + `"]}`
+	w = serve(e.h.CreateArticle, request("POST", "/articles", body, admin, e.orgA))
+	var a Article
+	json.NewDecoder(w.Body).Decode(&a)
+	for _, step := range []string{"submit", "approve", "publish"} {
+		e.transition(t, a.ID, step, admin, e.orgA)
+	}
+
+	w = serve(e.h.ListCategoryArticles, request("GET", "/categories/deportes/articles", "", primitive.NilObjectID, nil), "slug", parent.Slug)
+	var page ArticlePage
+	json.NewDecoder(w.Body).Decode(&page)
+	if page.Total != 1 || page.Items[0].ID != a.ID {
+		t.Errorf("parent category should list articles from subcategories, got %+v", page)
+	}
+
+	bad := `{"title":"Otro","category_ids":["` + primitive.NewObjectID().Hex() + `"]}`
+	if w := serve(e.h.CreateArticle, request("POST", "/articles", bad, admin, e.orgA)); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for unknown category, got %d", w.Code)
+	}
+}
+
+func TestHandlers_MergeTagRewritesArticles(t *testing.T) {
+	e := newTestEnv()
+	admin := e.newUser(e.orgA)
+	ctx := context.Background()
+
+	w := serve(e.h.CreateArticle, request("POST", "/articles", `{"title":"Uno","tags":["golang","backend"]}`, admin, e.orgA))
+	var a Article
+	json.NewDecoder(w.Body).Decode(&a)
+	source, _ := e.h.Taxonomy.GetTagBySlug(ctx, e.orgA.ID, "golang")
+	target := &Tag{OrganizationID: e.orgA.ID, Name: "Go", Slug: "go"}
+	e.h.Taxonomy.CreateTag(ctx, target)
+
+	r := request("POST", "/tags/"+source.ID.Hex()+"/merge", `{"into":"`+target.ID.Hex()+`"}`, admin, e.orgA, "org_admin")
+	if w := serve(e.h.MergeTag, r, "id", source.ID.Hex()); w.Code != http.StatusOK {
+		t.Fatalf("merge: expected 200, got %d %s", w.Code, w.Body.String())
+	}
+	got, _ := e.h.Repo.FindArticle(ctx, Filter{ID: a.ID, System: true})
+	if !reflect.DeepEqual(got.Tags, []string{"go", "backend"}) {
+		t.Errorf("article tags not rewritten: %v", got.Tags)
+	}
+	if _, err := e.h.Taxonomy.GetTag(ctx, e.orgA.ID, source.ID); err != ErrNotFound {
+		t.Errorf("merged tag should be deleted, got %v", err)
+	}
+
+	r = request("PUT", "/tags/"+target.ID.Hex(), `{"name":"Golang"}`, admin, e.orgA, "org_admin")
+	if w := serve(e.h.UpdateTag, r, "id", target.ID.Hex()); w.Code != http.StatusOK {
+		t.Fatalf("rename: expected 200, got %d", w.Code)
+	}
+	got, _ = e.h.Repo.FindArticle(ctx, Filter{ID: a.ID, System: true})
+	if !containsString(got.Tags, "golang") || containsString(got.Tags, "go") {
+		t.Errorf("rename should rewrite article tags: %v", got.Tags)
+	}
+
+	w = serve(e.h.AutocompleteTags, request("GET", "/tags/autocomplete?q=Gol", "", primitive.NilObjectID, nil))
+	var suggestions []Tag
+	json.NewDecoder(w.Body).Decode(&suggestions)
+	if len(suggestions) != 1 || suggestions[0].Slug != "golang" {
+		t.Errorf("unexpected autocomplete: %+v", suggestions)
+	}
+}
@@ -0,0 +1,118 @@
+package articles
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestTenantFromRequest(t *testing.T) {
+	org := primitive.NewObjectID()
+
+	r := httptest.NewRequest("GET", "/my-articles", nil)
+	r = r.WithContext(context.WithValue(r.Context(), "organization_id", org.Hex()))
+	tenant, err := tenantFromRequest(r)
+	if err != nil || tenant.All || tenant.OrgID != org {
+		t.Errorf("expected tenant %s, got %+v (%v)", org.Hex(), tenant, err)
+	}
+
+	r = httptest.NewRequest("GET", "/my-articles", nil)
+	if _, err := tenantFromRequest(r); err != errNoTenant {
+		t.Errorf("expected errNoTenant without organization, got %v", err)
+	}
+
+	r = httptest.NewRequest("GET", "/my-articles?all_orgs=true", nil)
+	ctx := context.WithValue(r.Context(), "organization_id", org.Hex())
+	ctx = context.WithValue(ctx, "roles", []string{"org_admin"})
+	if _, err := tenantFromRequest(r.WithContext(ctx)); err != errCrossTenant {
+		t.Errorf("org_admin must not read across organizations, got %v", err)
+	}
+
+	ctx = context.WithValue(ctx, "roles", []string{"superadmin"})
+	tenant, err = tenantFromRequest(r.WithContext(ctx))
+	if err != nil || !tenant.All {
+		t.Errorf("superadmin should get all organizations, got %+v (%v)", tenant, err)
+	}
+}
+
+func TestTenant_ScopeAndOwns(t *testing.T) {
+	org := primitive.NewObjectID()
+	tenant := Tenant{OrgID: org}
+
+	if got := tenant.scope(Filter{Slug: "hola"}); got.OrganizationID != org || got.Slug != "hola" {
+		t.Errorf("unexpected scoped filter: %+v", got)
+	}
+	// Un filtro no puede escaparse del tenant pidiendo otra organización
+	if got := tenant.scope(Filter{OrganizationID: primitive.NewObjectID()}); got.OrganizationID != org {
+		t.Errorf("scope must override the organization: %+v", got)
+	}
+	if got := (Tenant{All: true}).scope(Filter{Slug: "hola"}); !got.OrganizationID.IsZero() || !got.System {
+		t.Errorf("cross-organization tenant must not add conditions: %+v", got)
+	}
+
+	if !tenant.Owns(&Article{OrganizationID: org}) {
+		t.Errorf("tenant should own its own article")
+	}
+	if tenant.Owns(&Article{OrganizationID: primitive.NewObjectID()}) {
+		t.Errorf("tenant must not own another organization's article")
+	}
+}
+
+func TestRepository_RejectsUnscopedFilters(t *testing.T) {
+	e := newTestEnv()
+	author := e.newUser(e.orgA)
+	a := e.create(t, author, e.orgA, "Acotado")
+	ctx := context.Background()
+
+	if _, err := e.h.Repo.FindArticle(ctx, Filter{ID: a.ID}); err != errUnscoped {
+		t.Errorf("a filter without organization must be rejected, got %v", err)
+	}
+	if _, err := e.h.Repo.FindArticles(ctx, Filter{}); err != errUnscoped {
+		t.Errorf("listing every organization must be rejected, got %v", err)
+	}
+	if err := e.h.Repo.DeleteArticle(ctx, Filter{ID: a.ID}); err != errUnscoped {
+		t.Errorf("deletes must be scoped too, got %v", err)
+	}
+	if got, err := e.h.Repo.FindArticle(ctx, Filter{ID: a.ID, OrganizationID: e.orgA.ID}); err != nil || got.ID != a.ID {
+		t.Errorf("scoped reads work: %v", err)
+	}
+	if _, err := e.h.Repo.FindArticle(ctx, Filter{ID: a.ID, System: true}); err != nil {
+		t.Errorf("system tasks can read across organizations: %v", err)
+	}
+}
+
+func TestHandlers_SuperadminCreatesInChosenOrganization(t *testing.T) {
+	e := newTestEnv()
+	admin := e.newUser(e.orgA)
+	create := func(body string, roles ...string) (int, Article) {
+		w := serve(e.h.CreateArticle, request("POST", "/articles?all_orgs=true", body, admin, e.orgA, roles...))
+		var a Article
+		json.NewDecoder(w.Body).Decode(&a)
+		return w.Code, a
+	}
+	body := `{"title":"Para B","organization_id":"` + e.orgB.ID.Hex() + `"}`
+	if code, _ := create(body, "org_admin"); code != http.StatusForbidden {
+		t.Errorf("only superadmins can create across organizations, got %d", code)
+	}
+	if code, _ := create(`{"title":"Sin organización"}`, "superadmin"); code != http.StatusBadRequest {
+		t.Errorf("all_orgs requires organization_id, got %d", code)
+	}
+	if code, _ := create(`{"title":"Inexistente","organization_id":"`+primitive.NewObjectID().Hex()+`"}`, "superadmin"); code != http.StatusUnprocessableEntity {
+		t.Errorf("unknown organizations are rejected, got %d", code)
+	}
+	code, a := create(body, "superadmin")
+	if code != http.StatusCreated || a.OrganizationID != e.orgB.ID || a.AuthorID != admin {
+		t.Errorf("superadmin create: %d %+v", code, a)
+	}
+
+	// Sin all_orgs, organization_id no cambia la organización del JWT
+	w := serve(e.h.CreateArticle, request("POST", "/articles", body, admin, e.orgA, "org_admin"))
+	json.NewDecoder(w.Body).Decode(&a)
+	if a.OrganizationID != e.orgA.ID {
+		t.Errorf("organization_id is ignored without all_orgs: %+v", a)
+	}
+}
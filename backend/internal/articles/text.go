@@ -0,0 +1,132 @@
+package articles
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Análisis de texto compartido por la búsqueda: tokenización, acentos,
+// stopwords y un stemmer liviano para español e inglés.
+
+var accentFold = strings.NewReplacer(
+	"á", "a", "à", "a", "ä", "a", "â", "a", "ã", "a",
+	"é", "e", "è", "e", "ë", "e", "ê", "e",
+	"í", "i", "ì", "i", "ï", "i", "î", "i",
+	"ó", "o", "ò", "o", "ö", "o", "ô", "o", "õ", "o",
+	"ú", "u", "ù", "u", "ü", "u", "û", "u",
+	"ñ", "n", "ç", "c", "ý", "y", "ÿ", "y", "å", "a", "ø", "o",
+	"ß", "ss", "æ", "ae", "œ", "oe", "ł", "l",
+	"Á", "A", "À", "A", "Ä", "A", "Â", "A", "Ã", "A",
+	"É", "E", "È", "E", "Ë", "E", "Ê", "E",
+	"Í", "I", "Ì", "I", "Ï", "I", "Î", "I",
+	"Ó", "O", "Ò", "O", "Ö", "O", "Ô", "O", "Õ", "O",
+	"Ú", "U", "Ù", "U", "Ü", "U", "Û", "U",
+	"Ñ", "N", "Ç", "C", "Ý", "Y", "Å", "A", "Ø", "O",
+	"Æ", "AE", "Œ", "OE", "Ł", "L",
+)
+
+// foldAccents reemplaza letras acentuadas por su equivalente ASCII; la usan
+// la búsqueda y GenerateSlug
+func foldAccents(s string) string {
+	return accentFold.Replace(s)
+}
+
+var htmlTagRegex = regexp.MustCompile(`<[^>]*>`)
+
+// stripTags quita etiquetas HTML dejando el texto plano
+func stripTags(s string) string {
+	return htmlTagRegex.ReplaceAllString(s, " ")
+}
+
+var stopwords = map[string]bool{
+	// español
+	"a": true, "al": true, "con": true, "de": true, "del": true, "el": true, "en": true,
+	"es": true, "la": true, "las": true, "lo": true, "los": true, "para": true, "por": true,
+	"que": true, "se": true, "su": true, "un": true, "una": true, "y": true, "o": true,
+	// inglés
+	"an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"for": true, "from": true, "in": true, "is": true, "it": true, "of": true, "on": true,
+	"or": true, "the": true, "to": true, "with": true,
+}
+
+// token es una palabra del texto original con su posición y rango de bytes
+type token struct {
+	Term  string
+	Pos   int
+	Start int
+	End   int
+}
+
+// tokenize separa en palabras y normaliza cada una; las stopwords ocupan posición
+// pero se marcan con Term vacío para que las frases respeten la distancia original
+func tokenize(s string) []token {
+	var tokens []token
+	start := -1
+	pos := 0
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		tokens = append(tokens, token{Term: analyzeWord(s[start:end]), Pos: pos, Start: start, End: end})
+		pos++
+		start = -1
+	}
+	for i, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		flush(i)
+	}
+	flush(len(s))
+	return tokens
+}
+
+// analyzeWord normaliza una palabra: minúsculas, sin acentos y con stemming
+func analyzeWord(w string) string {
+	w = foldAccents(strings.ToLower(w))
+	if stopwords[w] {
+		return ""
+	}
+	return stem(w)
+}
+
+// Sufijos ordenados de más largo a más corto; se aplica el primero que deje una raíz de 3+ letras
+var stemSuffixes = []string{
+	"amientos", "imientos", "amiento", "imiento", "aciones", "iciones",
+	"ingly", "mente", "ciones", "siones", "idades", "acion", "icion",
+	"ando", "iendo", "ados", "idos", "adas", "idas", "idad", "edly", "ness",
+	"cion", "sion", "ing", "ies", "ado", "ido", "ada", "ida", "ar", "er", "ir",
+	"ed", "ly", "es", "os", "as", "s",
+}
+
+// stem es un stemmer liviano y agresivo común a español e inglés. No busca ser
+// lingüísticamente exacto: solo que las variantes habituales de una palabra
+// ("artículo", "artículos", "article", "articles") colapsen a la misma raíz.
+func stem(w string) string {
+	if len(w) <= 3 {
+		return w
+	}
+	for _, suf := range stemSuffixes {
+		if strings.HasSuffix(w, suf) && len(w)-len(suf) >= 3 {
+			if suf == "s" && strings.HasSuffix(w, "ss") {
+				break
+			}
+			w = w[:len(w)-len(suf)]
+			if suf == "ies" {
+				w += "y"
+			}
+			break
+		}
+	}
+	if len(w) > 4 {
+		switch w[len(w)-1] {
+		case 'a', 'e', 'o':
+			w = w[:len(w)-1]
+		}
+	}
+	return w
+}
@@ -0,0 +1,441 @@
+package articles
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"pittsix/internal/organizations"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 🌐 Traducciones: cada variante de idioma es un artículo propio (con su slug,
+// su flujo editorial y sus revisiones) unido a las demás por TranslationGroup,
+// que es el ID del artículo original.
+
+var (
+	errLocale            = errors.New("Invalid locale")
+	errTranslationExists = errors.New("A translation in that locale already exists")
+	errSourceRevision    = errors.New("source_revision must be between 1 and the source's current revision")
+)
+
+// TranslationLink apunta a una variante publicada en otro idioma
+type TranslationLink struct {
+	ID     primitive.ObjectID `json:"id"`
+	Locale string             `json:"locale"`
+	Slug   string             `json:"slug"`
+}
+
+// TranslationState describe una traducción respecto de su original
+type TranslationState struct {
+	ID             primitive.ObjectID `json:"id"`
+	Locale         string             `json:"locale"`
+	Slug           string             `json:"slug"`
+	Status         string             `json:"status"`
+	SourceRevision int                `json:"source_revision"`
+	Outdated       bool               `json:"outdated"`
+}
+
+// TranslationStatus resume un grupo: qué idiomas de la organización faltan y
+// qué traducciones quedaron atrás de la revisión actual del original
+type TranslationStatus struct {
+	SourceID       primitive.ObjectID `json:"source_id"`
+	SourceLocale   string             `json:"source_locale,omitempty"`
+	SourceRevision int                `json:"source_revision,omitempty"`
+	Translations   []TranslationState `json:"translations"`
+	Missing        []string           `json:"missing"`
+	Outdated       []string           `json:"outdated"`
+}
+
+func translationErrorStatus(err error) int {
+	switch err {
+	case errLocale, errSourceRevision:
+		return http.StatusBadRequest
+	case errTranslationExists:
+		return http.StatusConflict
+	case ErrNotFound:
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// translationGroupOf devuelve el grupo del artículo; el original sin
+// traducciones es su propio grupo
+func translationGroupOf(a *Article) primitive.ObjectID {
+	if a.TranslationGroup.IsZero() {
+		return a.ID
+	}
+	return a.TranslationGroup
+}
+
+// organization devuelve la organización o nil si no se encuentra
+func (h *Handlers) organization(id primitive.ObjectID) *organizations.Organization {
+	if id.IsZero() || h.Orgs == nil {
+		return nil
+	}
+	org, err := h.Orgs.GetByID(id)
+	if err != nil {
+		return nil
+	}
+	return org
+}
+
+// resolveLocale normaliza el locale pedido; vacío toma el de la organización
+func (h *Handlers) resolveLocale(orgID primitive.ObjectID, locale string) (string, bool) {
+	if strings.TrimSpace(locale) == "" {
+		return h.organization(orgID).PrimaryLocale(), true
+	}
+	return organizations.NormalizeLocale(locale)
+}
+
+// articleLocale es el idioma efectivo de un artículo (los anteriores al campo
+// están en el idioma por defecto de la organización)
+func articleLocale(a *Article, org *organizations.Organization) string {
+	if a.Locale != "" {
+		return a.Locale
+	}
+	return org.PrimaryLocale()
+}
+
+// parseAcceptLanguage devuelve los locales válidos del header, del más
+// preferido al menos (se ignoran "*" y q=0)
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+	var prefs []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		locale, ok := organizations.NormalizeLocale(fields[0])
+		if !ok {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			if v, found := strings.CutPrefix(strings.TrimSpace(param), "q="); found {
+				if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q > 0 {
+			prefs = append(prefs, weighted{locale, q})
+		}
+	}
+	sort.SliceStable(prefs, func(i, j int) bool { return prefs[i].q > prefs[j].q })
+	locales := make([]string, len(prefs))
+	for i, p := range prefs {
+		locales[i] = p.locale
+	}
+	return locales
+}
+
+// requestedLocales toma ?locale= o, si no está, Accept-Language
+func requestedLocales(r *http.Request) ([]string, error) {
+	if s := r.URL.Query().Get("locale"); s != "" {
+		locale, ok := organizations.NormalizeLocale(s)
+		if !ok {
+			return nil, errLocale
+		}
+		return []string{locale}, nil
+	}
+	return parseAcceptLanguage(r.Header.Get("Accept-Language")), nil
+}
+
+// pickVariant elige la primera variante que aparece en la cadena de idiomas;
+// un idioma sin región ("en") acepta cualquier variante regional ("en-US")
+func pickVariant(variants []Article, chain []string, org *organizations.Organization) *Article {
+	for _, locale := range chain {
+		for i := range variants {
+			if articleLocale(&variants[i], org) == locale {
+				return &variants[i]
+			}
+		}
+		if strings.Contains(locale, "-") {
+			continue
+		}
+		for i := range variants {
+			if strings.HasPrefix(articleLocale(&variants[i], org), locale+"-") {
+				return &variants[i]
+			}
+		}
+	}
+	return nil
+}
+
+// localize negocia el idioma de una lectura pública: devuelve la variante del
+// grupo que mejor cumple con lo pedido (o el mismo artículo) con los enlaces a
+// las demás variantes publicadas
+func (h *Handlers) localize(ctx context.Context, w http.ResponseWriter, tenant Tenant, a *Article, wanted []string) *Article {
+	org := h.organization(a.OrganizationID)
+	chosen := a
+	var variants []Article
+	if !a.TranslationGroup.IsZero() || len(wanted) > 0 {
+		now := time.Now()
+		found, err := h.Repo.FindArticles(ctx, tenant.scope(Filter{TranslationGroup: translationGroupOf(a), VisibleAt: &now}))
+		if err != nil {
+			log.Printf("⚠️ No se pudieron buscar las traducciones de %s: %v", a.ID.Hex(), err)
+		}
+		variants = found
+	}
+	if len(wanted) > 0 {
+		w.Header().Add("Vary", "Accept-Language")
+		if v := pickVariant(variants, org.LocaleChain(wanted), org); v != nil {
+			chosen = v
+		}
+	}
+	for _, v := range variants {
+		if v.ID != chosen.ID {
+			chosen.Translations = append(chosen.Translations, TranslationLink{ID: v.ID, Locale: articleLocale(&v, org), Slug: v.Slug})
+		}
+	}
+	w.Header().Set("Content-Language", articleLocale(chosen, org))
+	return chosen
+}
+
+// translationStatus compara cada variante del grupo con su original
+func translationStatus(groupID primitive.ObjectID, group []Article, org *organizations.Organization) TranslationStatus {
+	status := TranslationStatus{SourceID: groupID, Translations: []TranslationState{}, Missing: []string{}, Outdated: []string{}}
+	present := map[string]bool{}
+	var source *Article
+	for i := range group {
+		if group[i].ID == groupID {
+			source = &group[i]
+		}
+	}
+	if source != nil {
+		status.SourceLocale = articleLocale(source, org)
+		status.SourceRevision = currentRevision(source)
+		present[status.SourceLocale] = true
+	}
+	for i := range group {
+		a := &group[i]
+		if a.ID == groupID {
+			continue
+		}
+		locale := articleLocale(a, org)
+		present[locale] = true
+		// Como en la negociación, "en-US" cubre también a "en"
+		if base, _, regional := strings.Cut(locale, "-"); regional {
+			present[base] = true
+		}
+		state := TranslationState{ID: a.ID, Locale: locale, Slug: a.Slug, Status: a.Status, SourceRevision: a.SourceRevision}
+		// Sin el original (borrado) no hay contra qué comparar
+		state.Outdated = source != nil && a.SourceRevision < status.SourceRevision
+		if state.Outdated {
+			status.Outdated = append(status.Outdated, locale)
+		}
+		status.Translations = append(status.Translations, state)
+	}
+	if org != nil {
+		for _, locale := range org.Locales {
+			if !present[locale] {
+				status.Missing = append(status.Missing, locale)
+			}
+		}
+	}
+	return status
+}
+
+// findTenantArticle busca el artículo del path dentro de la organización del JWT
+func (h *Handlers) findTenantArticle(ctx context.Context, r *http.Request) (*Article, int, error) {
+	objID, err := primitive.ObjectIDFromHex(r.PathValue("id"))
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.New("Invalid ID")
+	}
+	tenant, err := tenantFromRequest(r)
+	if err != nil {
+		return nil, http.StatusForbidden, err
+	}
+	article, err := h.Repo.FindArticle(ctx, tenant.scope(Filter{ID: objID}))
+	if err != nil {
+		return nil, http.StatusNotFound, errors.New("Article not found")
+	}
+	return article, http.StatusOK, nil
+}
+
+// 🌐 Crear una traducción del artículo (requiere JWT, misma organización).
+// La traducción queda como borrador propio del usuario y registra la revisión
+// del original de la que parte.
+func (h *Handlers) CreateTranslation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	original, code, err := h.findTenantArticle(ctx, r)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	var article Article
+	if err := json.NewDecoder(r.Body).Decode(&article); err != nil {
+		writeBodyError(w, err)
+		return
+	}
+	locale, ok := organizations.NormalizeLocale(article.Locale)
+	if !ok {
+		http.Error(w, "locale required", http.StatusBadRequest)
+		return
+	}
+	groupID := translationGroupOf(original)
+	group, err := h.Repo.FindArticles(ctx, Filter{OrganizationID: original.OrganizationID, TranslationGroup: groupID})
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	org := h.organization(original.OrganizationID)
+	source := original
+	for i := range group {
+		if articleLocale(&group[i], org) == locale {
+			http.Error(w, errTranslationExists.Error(), http.StatusConflict)
+			return
+		}
+		if group[i].ID == groupID {
+			source = &group[i]
+		}
+	}
+	// El original pasa a encabezar el grupo la primera vez que se traduce; solo
+	// cambia ese campo para no pisar una edición que entre en el medio
+	if source.TranslationGroup.IsZero() {
+		saved, err := h.Repo.SetFields(ctx, Filter{ID: source.ID, OrganizationID: source.OrganizationID}, Patch{TranslationGroup: &source.ID})
+		if err != nil {
+			log.Println(err.Error())
+			http.Error(w, "DB error", http.StatusInternalServerError)
+			return
+		}
+		source = saved
+	}
+
+	article.Locale = locale
+	article.OrganizationID = original.OrganizationID
+	article.TranslationGroup = groupID
+	article.SourceRevision = currentRevision(source)
+	if article.Title == "" {
+		article.Title = source.Title
+	}
+	h.createArticle(w, r, &article)
+}
+
+// applyTranslationUpdate aplica locale y source_revision de un PUT sobre updated
+func (h *Handlers) applyTranslationUpdate(ctx context.Context, payload, updated *Article) error {
+	org := h.organization(updated.OrganizationID)
+	if payload.Locale != "" {
+		locale, ok := organizations.NormalizeLocale(payload.Locale)
+		if !ok {
+			return errLocale
+		}
+		if locale != articleLocale(updated, org) && !updated.TranslationGroup.IsZero() {
+			group, err := h.Repo.FindArticles(ctx, Filter{OrganizationID: updated.OrganizationID, TranslationGroup: updated.TranslationGroup})
+			if err != nil {
+				return err
+			}
+			for i := range group {
+				if group[i].ID != updated.ID && articleLocale(&group[i], org) == locale {
+					return errTranslationExists
+				}
+			}
+		}
+		updated.Locale = locale
+	}
+	if payload.SourceRevision == 0 || updated.TranslationGroup.IsZero() || updated.TranslationGroup == updated.ID {
+		return nil
+	}
+	source, err := h.Repo.FindArticle(ctx, Filter{ID: updated.TranslationGroup, OrganizationID: updated.OrganizationID})
+	if err != nil {
+		return err
+	}
+	if payload.SourceRevision < 1 || payload.SourceRevision > currentRevision(source) {
+		return errSourceRevision
+	}
+	updated.SourceRevision = payload.SourceRevision
+	return nil
+}
+
+// 🌐 Estado de las traducciones del grupo del artículo (requiere JWT)
+func (h *Handlers) GetTranslations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	article, code, err := h.findTenantArticle(ctx, r)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	groupID := translationGroupOf(article)
+	group, err := h.Repo.FindArticles(ctx, Filter{OrganizationID: article.OrganizationID, TranslationGroup: groupID})
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(translationStatus(groupID, group, h.organization(article.OrganizationID)))
+}
+
+// 🌐 Traducciones faltantes o desactualizadas de la organización (requiere
+// JWT). Con ?locale= se limita a ese idioma.
+func (h *Handlers) ListTranslationStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tenant, err := tenantFromRequest(r)
+	if err != nil || tenant.All {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	only := ""
+	if s := r.URL.Query().Get("locale"); s != "" {
+		locale, ok := organizations.NormalizeLocale(s)
+		if !ok {
+			http.Error(w, errLocale.Error(), http.StatusBadRequest)
+			return
+		}
+		only = locale
+	}
+	all, err := h.Repo.FindArticles(ctx, tenant.scope(Filter{}))
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	org := h.organization(tenant.OrgID)
+
+	groups := map[primitive.ObjectID][]Article{}
+	var order []primitive.ObjectID
+	for _, a := range all {
+		id := translationGroupOf(&a)
+		if _, seen := groups[id]; !seen {
+			order = append(order, id)
+		}
+		groups[id] = append(groups[id], a)
+	}
+	report := []TranslationStatus{}
+	for _, id := range order {
+		status := translationStatus(id, groups[id], org)
+		// Un grupo sin original no tiene de dónde traducirse
+		if status.SourceLocale == "" {
+			continue
+		}
+		if only != "" {
+			status.Missing = filterLocale(status.Missing, only)
+			status.Outdated = filterLocale(status.Outdated, only)
+		}
+		if len(status.Missing) > 0 || len(status.Outdated) > 0 {
+			report = append(report, status)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+func filterLocale(locales []string, only string) []string {
+	out := []string{}
+	for _, l := range locales {
+		if l == only {
+			out = append(out, l)
+		}
+	}
+	return out
+}
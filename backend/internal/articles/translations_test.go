@@ -0,0 +1,165 @@
+package articles
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	got := parseAcceptLanguage("en-US;q=0.8, es-AR, *;q=0.5, fr;q=0, pt_BR;q=0.9, xx-invalid")
+	want := []string{"es-AR", "pt-BR", "en-US"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseAcceptLanguage = %v, want %v", got, want)
+	}
+}
+
+func TestHandlers_Translations(t *testing.T) {
+	e := newTestEnv()
+	e.orgA.Locales = []string{"es", "en", "pt"}
+	e.orgA.LocaleFallbacks = map[string][]string{"pt": {"es"}}
+	author := e.newUser(e.orgA)
+	publish := func(id primitive.ObjectID) {
+		for _, name := range []string{"submit", "approve", "publish"} {
+			e.transition(t, id, name, author, e.orgA)
+		}
+	}
+
+	source := e.create(t, author, e.orgA, "Hola mundo")
+	if source.Locale != "es" {
+		t.Errorf("new articles should take the organization's default locale, got %q", source.Locale)
+	}
+	publish(source.ID)
+
+	translate := func(id primitive.ObjectID, body string) *httptest.ResponseRecorder {
+		r := request("POST", "/articles/"+id.Hex()+"/translations", body, author, e.orgA)
+		return serve(e.h.CreateTranslation, r, "id", id.Hex())
+	}
+	res := translate(source.ID, `{"locale":"en_us","title":"Hello world","content":"Hi"}`)
+	if res.Code != http.StatusCreated {
+		t.Fatalf("create translation: expected 201, got %d %s", res.Code, res.Body.String())
+	}
+	var en Article
+	json.NewDecoder(res.Body).Decode(&en)
+	if en.Locale != "en-US" || en.TranslationGroup != source.ID || en.SourceRevision != 1 || en.Slug != "hello-world" {
+		t.Errorf("unexpected translation: %+v", en)
+	}
+	if res := translate(en.ID, `{"locale":"es","title":"Otra"}`); res.Code != http.StatusConflict {
+		t.Errorf("expected 409 for a locale already in the group, got %d", res.Code)
+	}
+	publish(en.ID)
+
+	get := func(target, acceptLanguage string) (Article, http.Header) {
+		r := request("GET", target, "", primitive.NilObjectID, nil)
+		if acceptLanguage != "" {
+			r.Header.Set("Accept-Language", acceptLanguage)
+		}
+		w := serve(e.h.GetArticleBySlug, r, "slug", r.URL.Path[len("/articles/slug/"):])
+		var a Article
+		json.NewDecoder(w.Body).Decode(&a)
+		return a, w.Header()
+	}
+	a, header := get("/articles/slug/hola-mundo?locale=en-US", "")
+	if a.ID != en.ID || header.Get("Content-Language") != "en-US" {
+		t.Errorf("?locale=en-US should return the english variant: %s %v", a.Slug, header)
+	}
+	if len(a.Translations) != 1 || a.Translations[0].Slug != "hola-mundo" || a.Translations[0].Locale != "es" {
+		t.Errorf("response should link the other variants: %+v", a.Translations)
+	}
+	// en-GB cae en "en", que acepta en-US; pt cae en es por configuración
+	if a, _ := get("/articles/slug/hello-world", "en-GB"); a.ID != en.ID {
+		t.Errorf("a regional locale should match its base language, got %s", a.Slug)
+	}
+	if a, _ := get("/articles/slug/hello-world", "pt-BR, fr;q=0.5"); a.ID != source.ID {
+		t.Errorf("unmatched locales should fall back through the chain, got %s", a.Slug)
+	}
+	if a, _ := get("/articles/slug/hola-mundo", "fr, en-US;q=0.3"); a.ID != en.ID {
+		t.Errorf("Accept-Language should pick the best available variant, got %s", a.Slug)
+	}
+	if a, header := get("/articles/slug/hola-mundo", ""); a.ID != source.ID || header.Get("Vary") != "" {
+		t.Errorf("without a locale the requested article is returned: %s %v", a.Slug, header)
+	}
+	r := request("GET", "/articles/slug/hola-mundo?locale=no%20valido", "", primitive.NilObjectID, nil)
+	if w := serve(e.h.GetArticleBySlug, r, "slug", "hola-mundo"); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid locale, got %d", w.Code)
+	}
+
+	// Editar el original deja atrás a la traducción
+	r = request("PUT", "/articles/"+source.ID.Hex(), `{"title":"Hola mundo","content":"nuevo"}`, author, e.orgA)
+	if w := serve(e.h.UpdateArticle, r, "id", source.ID.Hex()); w.Code != http.StatusOK {
+		t.Fatalf("update source: %d %s", w.Code, w.Body.String())
+	}
+	status := func(target string) []TranslationStatus {
+		r := request("GET", target, "", author, e.orgA)
+		w := serve(e.h.ListTranslationStatus, r)
+		var report []TranslationStatus
+		json.NewDecoder(w.Body).Decode(&report)
+		return report
+	}
+	report := status("/articles/translations")
+	if len(report) != 1 || !reflect.DeepEqual(report[0].Missing, []string{"pt"}) || !reflect.DeepEqual(report[0].Outdated, []string{"en-US"}) {
+		t.Errorf("unexpected translation report: %+v", report)
+	}
+	if report := status("/articles/translations?locale=pt"); len(report) != 1 || len(report[0].Outdated) != 0 {
+		t.Errorf("?locale= should restrict the report: %+v", report)
+	}
+
+	r = request("PUT", "/articles/"+en.ID.Hex(), `{"title":"Hello world","content":"new","source_revision":3}`, author, e.orgA)
+	if w := serve(e.h.UpdateArticle, r, "id", en.ID.Hex()); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a source revision ahead of the source, got %d", w.Code)
+	}
+	r = request("PUT", "/articles/"+en.ID.Hex(), `{"title":"Hello world","content":"new","source_revision":2}`, author, e.orgA)
+	if w := serve(e.h.UpdateArticle, r, "id", en.ID.Hex()); w.Code != http.StatusOK {
+		t.Fatalf("update translation: %d %s", w.Code, w.Body.String())
+	}
+	r = request("GET", "/articles/"+en.ID.Hex()+"/translations", "", author, e.orgA)
+	w := serve(e.h.GetTranslations, r, "id", en.ID.Hex())
+	var group TranslationStatus
+	json.NewDecoder(w.Body).Decode(&group)
+	if group.SourceID != source.ID || group.SourceRevision != 2 || len(group.Outdated) != 0 || len(group.Translations) != 1 {
+		t.Errorf("unexpected group status: %+v", group)
+	}
+}
+
+// editBetween corre during una sola vez, justo después del próximo FindArticles
+type editBetween struct {
+	Repository
+	during func()
+}
+
+func (r *editBetween) FindArticles(ctx context.Context, f Filter) ([]Article, error) {
+	found, err := r.Repository.FindArticles(ctx, f)
+	if during := r.during; during != nil {
+		r.during = nil
+		during()
+	}
+	return found, err
+}
+
+func TestHandlers_TranslationKeepsConcurrentSourceEdit(t *testing.T) {
+	e := newTestEnv()
+	e.orgA.Locales = []string{"es", "en"}
+	repo := &editBetween{Repository: e.h.Repo}
+	e.h.Repo = repo
+	author := e.newUser(e.orgA)
+	source := e.create(t, author, e.orgA, "Original")
+
+	repo.during = func() {
+		if code := e.edit(source, author, e.orgA); code != http.StatusOK {
+			t.Errorf("edit: expected 200, got %d", code)
+		}
+	}
+	r := request("POST", "/articles/"+source.ID.Hex()+"/translations", `{"locale":"en","title":"Original EN"}`, author, e.orgA)
+	if w := serve(e.h.CreateTranslation, r, "id", source.ID.Hex()); w.Code != http.StatusCreated {
+		t.Fatalf("create translation: expected 201, got %d %s", w.Code, w.Body.String())
+	}
+	got, _ := e.h.Repo.FindArticle(context.Background(), Filter{ID: source.ID, System: true})
+	if got.Content != "editado" || got.Revision != 2 || got.TranslationGroup != source.ID {
+		t.Errorf("joining the group must not revert the source: %+v", got)
+	}
+}
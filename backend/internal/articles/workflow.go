@@ -0,0 +1,244 @@
+package articles
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"pittsix/pkg/events"
+	"pittsix/pkg/middleware"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 🚦 Estados del flujo editorial
+const (
+	StatusDraft     = "draft"
+	StatusInReview  = "in_review"
+	StatusApproved  = "approved"
+	StatusPublished = "published"
+	StatusArchived  = "archived"
+)
+
+// Transition define un paso permitido del flujo editorial
+type Transition struct {
+	Name       string   `json:"name"`
+	From       []string `json:"from"`
+	To         string   `json:"to"`
+	Permission string   `json:"permission"`
+	// AuthorAllowed permite al autor ejecutarla sobre su propio artículo sin el permiso
+	AuthorAllowed bool `json:"author_allowed"`
+	// RequireComment obliga a justificar la transición (p. ej. un rechazo)
+	RequireComment bool `json:"require_comment"`
+}
+
+var Transitions = map[string]Transition{
+	"submit":    {Name: "submit", From: []string{StatusDraft}, To: StatusInReview, Permission: "articles:submit", AuthorAllowed: true},
+	"withdraw":  {Name: "withdraw", From: []string{StatusInReview}, To: StatusDraft, Permission: "articles:submit", AuthorAllowed: true},
+	"approve":   {Name: "approve", From: []string{StatusInReview}, To: StatusApproved, Permission: "articles:review"},
+	"reject":    {Name: "reject", From: []string{StatusInReview, StatusApproved}, To: StatusDraft, Permission: "articles:review", RequireComment: true},
+	"publish":   {Name: "publish", From: []string{StatusApproved}, To: StatusPublished, Permission: "articles:publish"},
+	"archive":   {Name: "archive", From: []string{StatusPublished}, To: StatusArchived, Permission: "articles:publish"},
+	"unarchive": {Name: "unarchive", From: []string{StatusArchived}, To: StatusDraft, Permission: "articles:publish"},
+}
+
+// TransitionRecord deja constancia de quién movió qué artículo y cuándo
+type TransitionRecord struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ArticleID  primitive.ObjectID `bson:"article_id" json:"article_id"`
+	Transition string             `bson:"transition" json:"transition"`
+	From       string             `bson:"from" json:"from"`
+	To         string             `bson:"to" json:"to"`
+	Comment    string             `bson:"comment,omitempty" json:"comment,omitempty"`
+	UserID     primitive.ObjectID `bson:"user_id,omitempty" json:"user_id,omitempty"`
+	UserName   string             `bson:"user_name,omitempty" json:"user_name,omitempty"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// AllowedFrom indica si la transición puede aplicarse desde el estado dado
+func (t Transition) AllowedFrom(status string) bool {
+	for _, s := range t.From {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// canPerform resuelve si el usuario puede ejecutar la transición sobre el artículo
+func canPerform(ctx context.Context, t Transition, article *Article, userID primitive.ObjectID) bool {
+	if middleware.HasRole(ctx, "superadmin", "org_admin") {
+		return true
+	}
+	if middleware.HasPermission(ctx, t.Permission) {
+		return true
+	}
+	role := articleRole(article, userID)
+	return t.AuthorAllowed && (role == RoleOwner || role == RoleEditor)
+}
+
+// canReview permite ver el historial a autor y colaboradores y a quien participa de la revisión
+func canReview(ctx context.Context, article *Article, userID primitive.ObjectID) bool {
+	return articleRole(article, userID) != "" || reviewsAll(ctx)
+}
+
+// reviewsAll indica si el usuario puede ver todo el contenido de la organización
+func reviewsAll(ctx context.Context) bool {
+	if middleware.HasRole(ctx, "superadmin", "org_admin") {
+		return true
+	}
+	return middleware.HasPermission(ctx, "articles:review") || middleware.HasPermission(ctx, "articles:publish")
+}
+
+// applyTransition cambia el estado de forma atómica y registra la transición.
+// Devuelve ErrNotFound si el artículo ya no está en el estado esperado.
+func (h *Handlers) applyTransition(ctx context.Context, article *Article, t Transition, rec TransitionRecord) (*TransitionRecord, error) {
+	now := time.Now()
+	// Solo cambian estado y fechas: una edición que entre en el medio se conserva
+	patch := Patch{Status: &t.To, UpdatedAt: &now}
+	if t.To == StatusPublished && article.PublishedAt == nil {
+		patch.PublishedAt = &now
+	}
+	filter := Filter{ID: article.ID, OrganizationID: article.OrganizationID, Status: article.Status}
+	updated, err := h.Repo.SetFields(ctx, filter, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	rec.ArticleID = article.ID
+	rec.Transition = t.Name
+	rec.From = article.Status
+	rec.To = t.To
+	rec.CreatedAt = now
+	if err := h.Repo.SaveTransition(ctx, &rec); err != nil {
+		return nil, err
+	}
+	h.reindex(ctx, article.ID)
+	actor, _ := ctx.Value("user_id").(string)
+	publishArticle(h.Events, events.ArticleStatusChanged, updated, actor, article.Status)
+	return &rec, nil
+}
+
+// 🔀 Mover un artículo en el flujo editorial (requiere JWT)
+func (h *Handlers) TransitionArticle(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	objID, err := primitive.ObjectIDFromHex(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	userIDStr, ok := ctx.Value("user_id").(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	userObjID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	var input struct {
+		Transition string `json:"transition"`
+		Comment    string `json:"comment"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid body", http.StatusBadRequest)
+		return
+	}
+	t, ok := Transitions[input.Transition]
+	if !ok {
+		http.Error(w, "Unknown transition", http.StatusBadRequest)
+		return
+	}
+	input.Comment = strings.TrimSpace(input.Comment)
+	if t.RequireComment && input.Comment == "" {
+		http.Error(w, "Comment required", http.StatusBadRequest)
+		return
+	}
+
+	tenant, err := tenantFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	article, err := h.Repo.FindArticle(ctx, tenant.scope(Filter{ID: objID}))
+	if err != nil {
+		http.Error(w, "Article not found", http.StatusNotFound)
+		return
+	}
+	if !canPerform(ctx, t, article, userObjID) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	if !t.AllowedFrom(article.Status) {
+		http.Error(w, "Transition not allowed from status "+article.Status, http.StatusConflict)
+		return
+	}
+
+	rec := TransitionRecord{UserID: userObjID, Comment: input.Comment}
+	if user, err := h.Users.GetUserByID(userObjID); err == nil && user != nil {
+		rec.UserName = user.FirstName + " " + user.LastName
+	}
+	saved, err := h.applyTransition(ctx, article, t, rec)
+	if err == ErrNotFound {
+		http.Error(w, "Article status changed, retry", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(saved)
+}
+
+// 🧾 Historial de transiciones de un artículo (requiere JWT)
+func (h *Handlers) ListTransitions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	objID, err := primitive.ObjectIDFromHex(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	userIDStr, ok := ctx.Value("user_id").(string)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	userObjID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid user id", http.StatusBadRequest)
+		return
+	}
+	tenant, err := tenantFromRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	article, err := h.Repo.FindArticle(ctx, tenant.scope(Filter{ID: objID}))
+	if err != nil {
+		http.Error(w, "Article not found", http.StatusNotFound)
+		return
+	}
+	if !canReview(ctx, article, userObjID) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	records, err := h.Repo.ListTransitions(ctx, objID)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(records)
+}
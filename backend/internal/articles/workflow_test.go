@@ -0,0 +1,56 @@
+package articles
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestTransitions_AllowedFrom(t *testing.T) {
+	cases := []struct {
+		transition string
+		from       string
+		want       bool
+	}{
+		{"submit", StatusDraft, true},
+		{"submit", StatusPublished, false},
+		{"approve", StatusInReview, true},
+		{"reject", StatusApproved, true},
+		{"publish", StatusInReview, false},
+		{"publish", StatusApproved, true},
+		{"archive", StatusPublished, true},
+		{"unarchive", StatusArchived, true},
+	}
+	for _, c := range cases {
+		if got := Transitions[c.transition].AllowedFrom(c.from); got != c.want {
+			t.Errorf("%s from %s: got %v, want %v", c.transition, c.from, got, c.want)
+		}
+	}
+}
+
+func TestCanPerform(t *testing.T) {
+	author := primitive.NewObjectID()
+	other := primitive.NewObjectID()
+	article := &Article{AuthorID: author}
+
+	if !canPerform(context.Background(), Transitions["submit"], article, author) {
+		t.Errorf("author should be able to submit own article")
+	}
+	if canPerform(context.Background(), Transitions["submit"], article, other) {
+		t.Errorf("other users should not submit without permission")
+	}
+	if canPerform(context.Background(), Transitions["publish"], article, author) {
+		t.Errorf("author should not publish without permission")
+	}
+
+	withPerm := context.WithValue(context.Background(), "permissions", []string{"articles:publish"})
+	if !canPerform(withPerm, Transitions["publish"], article, other) {
+		t.Errorf("articles:publish should allow publishing")
+	}
+
+	admin := context.WithValue(context.Background(), "roles", []string{"org_admin"})
+	if !canPerform(admin, Transitions["approve"], article, other) {
+		t.Errorf("org_admin should be able to approve")
+	}
+}
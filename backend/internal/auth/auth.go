@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"pittsix/internal/users"
 	"pittsix/pkg/config"
+	"pittsix/pkg/events"
 	"pittsix/pkg/security"
 
 	"crypto/rand"
@@ -22,6 +23,10 @@ type Credentials struct {
 
 type Handlers struct {
 	repo users.Repository
+	// OnProfileChange corre después de que el usuario edita su perfil
+	OnProfileChange []users.ProfileHook
+	// Events recibe los registros y cambios de perfil (nil: no se publican)
+	Events *events.Bus
 }
 
 func NewAuthHandlers(repo users.Repository) *Handlers {
@@ -45,6 +50,7 @@ func (h *Handlers) Register(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "User creation failed", http.StatusInternalServerError)
 		return
 	}
+	users.PublishUser(r.Context(), h.Events, events.UserCreated, user)
 
 	w.WriteHeader(http.StatusCreated)
 }
@@ -108,6 +114,10 @@ func (h *Handlers) Profile(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "Update failed", http.StatusInternalServerError)
 			return
 		}
+		users.NotifyProfileChange(r.Context(), h.repo, userID, h.OnProfileChange)
+		if user, err := h.repo.GetUserByID(userID); err == nil {
+			users.PublishUser(r.Context(), h.Events, events.UserUpdated, user)
+		}
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode(map[string]string{"status": "updated"})
 		return
@@ -20,12 +20,19 @@ func InitUsersAndOrgs(userRepo users.Repository, orgRepo organizations.Repositor
 		org = &organizations.Organization{
 			ID:   primitive.NewObjectID(),
 			Name: orgName,
+			Slug: organizations.Slugify(orgName),
 		}
 		err = orgRepo.Create(org)
 		if err != nil {
 			log.Printf("[bootstrap] Error creating org: %v", err)
 			return
 		}
+	} else if org.Slug == "" {
+		// Organizaciones creadas antes de que existiera el slug
+		org.Slug = organizations.Slugify(org.Name)
+		if err := orgRepo.Update(org.ID, map[string]interface{}{"slug": org.Slug}); err != nil {
+			log.Printf("[bootstrap] Error setting org slug: %v", err)
+		}
 	}
 
 	_, err = userRepo.GetUserByEmail(adminEmail)
@@ -32,6 +32,17 @@ func (m *mockOrgRepo) GetByName(name string) (*organizations.Organization, error
 func (m *mockOrgRepo) GetByID(id primitive.ObjectID) (*organizations.Organization, error) {
 	return nil, nil
 }
+func (m *mockOrgRepo) GetBySlug(slug string) (*organizations.Organization, error) {
+	for _, org := range m.orgs {
+		if org.Slug == slug {
+			return org, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+func (m *mockOrgRepo) GetByHost(host string) (*organizations.Organization, error) {
+	return nil, errors.New("not found")
+}
 func (m *mockOrgRepo) Update(id primitive.ObjectID, update map[string]interface{}) error { return nil }
 func (m *mockOrgRepo) Delete(id primitive.ObjectID) error                                { return nil }
 
@@ -77,6 +88,9 @@ func TestInitUsersAndOrgs_CreatesOrgAndAdmin(t *testing.T) {
 	if userRepo.users["admin@admin.com"] == nil {
 		t.Fatal("admin user not created")
 	}
+	if orgRepo.orgs["Org Principal"].Slug != "org-principal" {
+		t.Fatalf("unexpected org slug: %q", orgRepo.orgs["Org Principal"].Slug)
+	}
 }
 
 func TestInitUsersAndOrgs_OrgExists_AdminCreated(t *testing.T) {
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DefaultMigrationLease es cuánto se espera a una réplica que tomó una
+// migración y no la terminó (se cayó a mitad) antes de que otra la retome
+const DefaultMigrationLease = 30 * time.Minute
+
+// 🧰 Migrations corre cada migración de datos una sola vez en todo el
+// despliegue. Cada una queda registrada por nombre en la colección: la réplica
+// que la toma marca started_at y, al terminar, done_at. Las demás réplicas (y
+// los siguientes arranques) la saltean. Si falla se libera para el próximo arranque.
+type Migrations struct {
+	collection *mongo.Collection
+	Lease      time.Duration
+}
+
+func NewMigrations(collection *mongo.Collection) *Migrations {
+	return &Migrations{collection: collection, Lease: DefaultMigrationLease}
+}
+
+// Run ejecuta fn si la migración name no se aplicó ni la está corriendo otra réplica
+func (m *Migrations) Run(ctx context.Context, name string, fn func(context.Context) error) error {
+	claimed, err := m.claim(ctx, name)
+	if err != nil || !claimed {
+		return err
+	}
+	if err := fn(ctx); err != nil {
+		if _, relErr := m.collection.UpdateByID(ctx, name, bson.M{"$unset": bson.M{"started_at": ""}}); relErr != nil {
+			log.Printf("⚠️ No se pudo liberar la migración %s: %v", name, relErr)
+		}
+		return err
+	}
+	_, err = m.collection.UpdateByID(ctx, name, bson.M{"$set": bson.M{"done_at": time.Now()}})
+	if err == nil {
+		log.Printf("🧰 Migración %s aplicada", name)
+	}
+	return err
+}
+
+// claim toma la migración de forma atómica: el upsert solo matchea si no está
+// hecha y nadie la tiene (o la tomó hace más de Lease); si el documento existe
+// y no matchea, el upsert choca con el _id y la migración es de otro
+func (m *Migrations) claim(ctx context.Context, name string) (bool, error) {
+	now := time.Now()
+	filter := bson.M{
+		"_id":     name,
+		"done_at": bson.M{"$exists": false},
+		"$or": bson.A{
+			bson.M{"started_at": bson.M{"$exists": false}},
+			bson.M{"started_at": bson.M{"$lt": now.Add(-m.Lease)}},
+		},
+	}
+	_, err := m.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"started_at": now}}, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
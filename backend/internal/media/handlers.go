@@ -0,0 +1,338 @@
+package media
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"pittsix/internal/upload"
+	"pittsix/pkg/middleware"
+
+	"github.com/minio/minio-go/v7"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultPageSize = 30
+	maxPageSize     = 100
+)
+
+// Storage es lo que hace falta del cliente de MinIO para borrar archivos
+type Storage interface {
+	RemoveObject(ctx context.Context, bucketName, objectName string, opts minio.RemoveObjectOptions) error
+}
+
+type Handlers struct {
+	Repo    Repository
+	Storage Storage
+	// References buscan dónde se usa un archivo antes de borrarlo
+	References []ReferenceFinder
+	// Now es el reloj de las fechas; los tests lo reemplazan
+	Now func() time.Time
+}
+
+func NewHandlers(repo Repository, storage Storage) *Handlers {
+	return &Handlers{Repo: repo, Storage: storage, Now: time.Now}
+}
+
+// AssetPage es el sobre de respuesta del listado
+type AssetPage struct {
+	Items      []Asset `json:"items"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+	Total      int64   `json:"total"`
+	Limit      int     `json:"limit"`
+}
+
+// canEdit: el dueño, los admins o quien tenga media:manage
+func canEdit(ctx context.Context, a *Asset) bool {
+	if userID, _ := ctx.Value("user_id").(string); userID != "" && userID == a.OwnerID.Hex() {
+		return true
+	}
+	return canManage(ctx)
+}
+
+// canManage: los admins o quien tenga media:manage
+func canManage(ctx context.Context) bool {
+	return middleware.HasRole(ctx, "superadmin", "org_admin") || middleware.HasPermission(ctx, "media:manage")
+}
+
+// orgOf devuelve la organización del JWT; zero si no tiene
+func orgOf(ctx context.Context) primitive.ObjectID {
+	orgIDStr, _ := ctx.Value("organization_id").(string)
+	orgID, _ := primitive.ObjectIDFromHex(orgIDStr)
+	return orgID
+}
+
+// orgFor exige que la petición venga de alguien con organización
+func orgFor(w http.ResponseWriter, r *http.Request) (primitive.ObjectID, bool) {
+	orgID := orgOf(r.Context())
+	if orgID.IsZero() {
+		http.Error(w, "No organization", http.StatusForbidden)
+		return primitive.NilObjectID, false
+	}
+	return orgID, true
+}
+
+// findAsset carga el archivo {id} de la organización de la petición
+func (h *Handlers) findAsset(w http.ResponseWriter, r *http.Request) (*Asset, bool) {
+	orgID, ok := orgFor(w, r)
+	if !ok {
+		return nil, false
+	}
+	id, err := primitive.ObjectIDFromHex(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid id", http.StatusBadRequest)
+		return nil, false
+	}
+	asset, err := h.Repo.GetAsset(r.Context(), orgID, id)
+	if err == ErrNotFound {
+		http.Error(w, "Media not found", http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return nil, false
+	}
+	return asset, true
+}
+
+// references junta lo que devuelven todos los ReferenceFinder
+func (h *Handlers) references(ctx context.Context, a *Asset) ([]Reference, error) {
+	refs := []Reference{}
+	for _, find := range h.References {
+		found, err := find(ctx, a.OrganizationID, a.URLs())
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, found...)
+	}
+	return refs, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// RecordUpload es el upload.UploadHook que registra cada subida en la
+// biblioteca de la organización de quien sube; sin organización no se registra
+func (h *Handlers) RecordUpload(r *http.Request, u *upload.Upload) error {
+	orgID := orgOf(r.Context())
+	if orgID.IsZero() {
+		return nil
+	}
+	userID, _ := r.Context().Value("user_id").(string)
+	ownerID, _ := primitive.ObjectIDFromHex(userID)
+	now := h.Now()
+	asset := Asset{
+		OrganizationID: orgID,
+		OwnerID:        ownerID,
+		URL:            u.Result.URL,
+		Objects:        u.Objects,
+		Filename:       u.Filename,
+		MimeType:       u.Result.ContentType,
+		Size:           u.Size,
+		Width:          u.Result.Width,
+		Height:         u.Result.Height,
+		Blurhash:       u.Result.Blurhash,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	for name, rendition := range u.Result.Renditions {
+		if asset.Renditions == nil {
+			asset.Renditions = map[string]Rendition{}
+		}
+		asset.Renditions[name] = Rendition{URL: rendition.URL, Width: rendition.Width, Height: rendition.Height}
+	}
+	if err := h.Repo.CreateAsset(r.Context(), &asset); err != nil {
+		return err
+	}
+	u.Result.ID = asset.ID.Hex()
+	return nil
+}
+
+// parseQuery lee ?q=&tag=&type=&owner=&limit=&cursor=; owner acepta "me"
+func parseQuery(r *http.Request) (Query, string) {
+	v := r.URL.Query()
+	q := Query{
+		Search: strings.TrimSpace(v.Get("q")),
+		Tag:    strings.ToLower(strings.TrimSpace(v.Get("tag"))),
+		Type:   strings.ToLower(strings.TrimSpace(v.Get("type"))),
+		Limit:  defaultPageSize,
+	}
+	if s := v.Get("owner"); s != "" {
+		if s == "me" {
+			s, _ = r.Context().Value("user_id").(string)
+		}
+		id, err := primitive.ObjectIDFromHex(s)
+		if err != nil {
+			return q, "Invalid owner"
+		}
+		q.OwnerID = id
+	}
+	if s := v.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			return q, "Invalid limit"
+		}
+		q.Limit = min(n, maxPageSize)
+	}
+	if s := v.Get("cursor"); s != "" {
+		id, err := primitive.ObjectIDFromHex(s)
+		if err != nil {
+			return q, "Invalid cursor"
+		}
+		q.After = id
+	}
+	return q, ""
+}
+
+// 🗂️ Listar la biblioteca de la organización, lo más nuevo primero
+// (?q=&tag=&type=image&owner=me&limit=&cursor=)
+func (h *Handlers) ListMedia(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := orgFor(w, r)
+	if !ok {
+		return
+	}
+	q, invalid := parseQuery(r)
+	if invalid != "" {
+		http.Error(w, invalid, http.StatusBadRequest)
+		return
+	}
+	items, total, err := h.Repo.ListAssets(r.Context(), orgID, q)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	page := AssetPage{Items: items, Total: total, Limit: q.Limit}
+	if len(items) > q.Limit {
+		page.Items = items[:q.Limit]
+		page.NextCursor = page.Items[q.Limit-1].ID.Hex()
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+// 🔎 Ver un archivo
+func (h *Handlers) GetMedia(w http.ResponseWriter, r *http.Request) {
+	asset, ok := h.findAsset(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, asset)
+}
+
+type assetInput struct {
+	Alt     *string  `json:"alt"`
+	Caption *string  `json:"caption"`
+	Credits *string  `json:"credits"`
+	Tags    []string `json:"tags"`
+}
+
+// ✏️ Cambiar texto alternativo, pie, créditos o etiquetas; lo que no viene no cambia
+func (h *Handlers) UpdateMedia(w http.ResponseWriter, r *http.Request) {
+	asset, ok := h.findAsset(w, r)
+	if !ok {
+		return
+	}
+	if !canEdit(r.Context(), asset) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	var input assetInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid body", http.StatusBadRequest)
+		return
+	}
+	if input.Alt != nil {
+		asset.Alt = strings.TrimSpace(*input.Alt)
+	}
+	if input.Caption != nil {
+		asset.Caption = strings.TrimSpace(*input.Caption)
+	}
+	if input.Credits != nil {
+		asset.Credits = strings.TrimSpace(*input.Credits)
+	}
+	if input.Tags != nil {
+		asset.Tags = normalizeTags(input.Tags)
+	}
+	asset.UpdatedAt = h.Now()
+	if err := h.Repo.UpdateAsset(r.Context(), asset); err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, asset)
+}
+
+// 🔗 Dónde se usa un archivo
+func (h *Handlers) ListReferences(w http.ResponseWriter, r *http.Request) {
+	asset, ok := h.findAsset(w, r)
+	if !ok {
+		return
+	}
+	refs, err := h.references(r.Context(), asset)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, refs)
+}
+
+// 🗑️ Borrar un archivo con todas sus versiones. Si algún artículo lo usa
+// responde 409 con la lista; con ?force=true lo borra igual y la devuelve
+// como advertencia. Forzar pide media:manage o ser admin: los artículos que
+// lo usan pueden ser de otros.
+func (h *Handlers) DeleteMedia(w http.ResponseWriter, r *http.Request) {
+	asset, ok := h.findAsset(w, r)
+	if !ok {
+		return
+	}
+	if !canEdit(r.Context(), asset) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	refs, err := h.references(r.Context(), asset)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	if len(refs) > 0 && r.URL.Query().Get("force") != "true" {
+		writeJSON(w, http.StatusConflict, map[string]interface{}{
+			"error":      "Media is in use; delete with ?force=true to remove it anyway",
+			"references": refs,
+		})
+		return
+	}
+	if len(refs) > 0 && !canManage(r.Context()) {
+		writeJSON(w, http.StatusForbidden, map[string]interface{}{
+			"error":      "Media is in use; only media managers can force its deletion",
+			"references": refs,
+		})
+		return
+	}
+	if err := h.Repo.DeleteAsset(r.Context(), asset.OrganizationID, asset.ID); err != nil && err != ErrNotFound {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	// Los archivos se borran después del registro: si falla alguno queda
+	// huérfano en el bucket, nunca un registro apuntando a la nada
+	for _, name := range asset.Objects {
+		if err := h.Storage.RemoveObject(r.Context(), upload.Bucket, name, minio.RemoveObjectOptions{}); err != nil {
+			log.Printf("⚠️ No se pudo borrar %s del bucket: %v", name, err)
+		}
+	}
+	if len(refs) > 0 {
+		log.Printf("⚠️ Archivo %s borrado con %d referencias", asset.ID.Hex(), len(refs))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "deleted", "references": refs})
+}
@@ -0,0 +1,214 @@
+package media
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"pittsix/internal/upload"
+
+	"github.com/minio/minio-go/v7"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type fakeStorage struct{ removed []string }
+
+func (s *fakeStorage) RemoveObject(ctx context.Context, bucket, name string, opts minio.RemoveObjectOptions) error {
+	s.removed = append(s.removed, bucket+"/"+name)
+	return nil
+}
+
+func request(method, target, body string, userID, orgID primitive.ObjectID, roles ...string) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	ctx := context.WithValue(r.Context(), "user_id", userID.Hex())
+	ctx = context.WithValue(ctx, "organization_id", orgID.Hex())
+	ctx = context.WithValue(ctx, "roles", roles)
+	return r.WithContext(ctx)
+}
+
+func serve(handler http.HandlerFunc, r *http.Request, pathValues ...string) *httptest.ResponseRecorder {
+	for i := 0; i+1 < len(pathValues); i += 2 {
+		r.SetPathValue(pathValues[i], pathValues[i+1])
+	}
+	w := httptest.NewRecorder()
+	handler(w, r)
+	return w
+}
+
+// record simula una subida a /upload de user en org
+func record(t *testing.T, h *Handlers, user, org primitive.ObjectID, filename, contentType string) *upload.Upload {
+	t.Helper()
+	u := &upload.Upload{
+		Objects:  []string{"upload_1_" + filename, "upload_1_thumb.jpg"},
+		Filename: filename,
+		Size:     1234,
+		Result: &upload.UploadResult{
+			URL:         "http://minio/mybucket/upload_1_" + filename,
+			ContentType: contentType,
+			Width:       800,
+			Height:      600,
+			Renditions:  map[string]upload.RenditionResult{"thumbnail": {URL: "http://minio/mybucket/upload_1_thumb.jpg", Width: 320, Height: 240}},
+		},
+	}
+	if err := h.RecordUpload(request("POST", "/upload", "", user, org), u); err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func TestHandlers_RecordAndList(t *testing.T) {
+	h := NewHandlers(NewMemoryRepository(), &fakeStorage{})
+	alice, bob, org, other := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+
+	u := record(t, h, alice, org, "playa.jpg", "image/jpeg")
+	if u.Result.ID == "" {
+		t.Fatalf("the upload response must carry the media id")
+	}
+	record(t, h, bob, org, "informe.pdf", "application/pdf")
+	record(t, h, bob, org, "montaña.png", "image/png")
+	record(t, h, bob, other, "ajena.jpg", "image/jpeg")
+	if err := h.RecordUpload(request("POST", "/upload", "", alice, primitive.NilObjectID), &upload.Upload{Result: &upload.UploadResult{}}); err != nil {
+		t.Errorf("uploads without organization are not recorded: %v", err)
+	}
+
+	list := func(query string) AssetPage {
+		t.Helper()
+		w := serve(h.ListMedia, request("GET", "/media?"+query, "", alice, org))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: %d %s", query, w.Code, w.Body.String())
+		}
+		var page AssetPage
+		json.NewDecoder(w.Body).Decode(&page)
+		return page
+	}
+	if page := list(""); page.Total != 3 || page.Items[0].Filename != "montaña.png" {
+		t.Errorf("newest first, only this organization: %+v", page)
+	}
+	if page := list("type=image"); page.Total != 2 {
+		t.Errorf("type=image: %+v", page)
+	}
+	if page := list("owner=me"); page.Total != 1 || page.Items[0].Renditions["thumbnail"].Width != 320 || page.Items[0].OwnerID != alice {
+		t.Errorf("owner=me: %+v", page)
+	}
+
+	first := list("limit=2")
+	if len(first.Items) != 2 || first.NextCursor == "" || first.Total != 3 {
+		t.Fatalf("first page: %+v", first)
+	}
+	second := list("limit=2&cursor=" + first.NextCursor)
+	if len(second.Items) != 1 || second.NextCursor != "" || second.Items[0].Filename != "playa.jpg" {
+		t.Errorf("second page: %+v", second)
+	}
+
+	for _, q := range []string{"limit=0", "owner=nope", "cursor=nope"} {
+		if w := serve(h.ListMedia, request("GET", "/media?"+q, "", alice, org)); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", q, w.Code)
+		}
+	}
+}
+
+func TestHandlers_UpdateAndSearch(t *testing.T) {
+	h := NewHandlers(NewMemoryRepository(), &fakeStorage{})
+	alice, bob, org := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	id := record(t, h, alice, org, "IMG_0001.jpg", "image/jpeg").Result.ID
+
+	body := `{"alt":" Atardecer en la playa ","credits":"Ana Pérez","tags":["Verano","playa","verano",""]}`
+	if w := serve(h.UpdateMedia, request("PUT", "/", body, bob, org), "id", id); w.Code != http.StatusForbidden {
+		t.Errorf("only the owner or an admin can edit, got %d", w.Code)
+	}
+	w := serve(h.UpdateMedia, request("PUT", "/", body, alice, org), "id", id)
+	var asset Asset
+	json.NewDecoder(w.Body).Decode(&asset)
+	if w.Code != http.StatusOK || asset.Alt != "Atardecer en la playa" || asset.Credits != "Ana Pérez" || strings.Join(asset.Tags, ",") != "verano,playa" {
+		t.Fatalf("update: %d %+v", w.Code, asset)
+	}
+	w = serve(h.UpdateMedia, request("PUT", "/", `{"caption":"Cádiz, 2024"}`, bob, org, "org_admin"), "id", id)
+	json.NewDecoder(w.Body).Decode(&asset)
+	if asset.Caption != "Cádiz, 2024" || asset.Alt != "Atardecer en la playa" {
+		t.Errorf("missing fields don't change: %+v", asset)
+	}
+
+	for _, q := range []string{"q=atardecer", "q=P%C3%89REZ", "q=c%C3%A1diz", "tag=Verano", "q=img_0001"} {
+		w := serve(h.ListMedia, request("GET", "/media?"+q, "", bob, org))
+		var page AssetPage
+		json.NewDecoder(w.Body).Decode(&page)
+		if page.Total != 1 {
+			t.Errorf("%s should find it: %+v", q, page)
+		}
+	}
+	w = serve(h.ListMedia, request("GET", "/media?q=invierno", "", bob, org))
+	if !strings.Contains(w.Body.String(), `"items":[]`) {
+		t.Errorf("no matches: %s", w.Body.String())
+	}
+	if w := serve(h.GetMedia, request("GET", "/", "", alice, primitive.NewObjectID()), "id", id); w.Code != http.StatusNotFound {
+		t.Errorf("other organizations don't see it, got %d", w.Code)
+	}
+}
+
+func TestHandlers_DeleteChecksReferences(t *testing.T) {
+	storage := &fakeStorage{}
+	h := NewHandlers(NewMemoryRepository(), storage)
+	alice, org := primitive.NewObjectID(), primitive.NewObjectID()
+	u := record(t, h, alice, org, "portada.jpg", "image/jpeg")
+	id := u.Result.ID
+
+	var used []string
+	h.References = append(h.References, func(ctx context.Context, orgID primitive.ObjectID, urls []string) ([]Reference, error) {
+		if orgID != org || len(urls) != 2 {
+			t.Errorf("unexpected lookup: %s %v", orgID.Hex(), urls)
+		}
+		refs := []Reference{}
+		for _, u := range urls {
+			if contains(used, u) {
+				refs = append(refs, Reference{Kind: "article", ID: "a1", Title: "Portada", Field: "image"})
+			}
+		}
+		return refs, nil
+	})
+	used = []string{u.Result.Renditions["thumbnail"].URL}
+
+	w := serve(h.ListReferences, request("GET", "/", "", alice, org), "id", id)
+	if !strings.Contains(w.Body.String(), `"field":"image"`) {
+		t.Errorf("references: %s", w.Body.String())
+	}
+	w = serve(h.DeleteMedia, request("DELETE", "/", "", alice, org), "id", id)
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), `"title":"Portada"`) || len(storage.removed) != 0 {
+		t.Fatalf("in use: expected 409 with the references, got %d %s", w.Code, w.Body.String())
+	}
+	// El dueño no puede forzarlo: los artículos que lo usan pueden ser de otros
+	w = serve(h.DeleteMedia, request("DELETE", "/?force=true", "", alice, org), "id", id)
+	if w.Code != http.StatusForbidden || len(storage.removed) != 0 {
+		t.Fatalf("owner force: expected 403 without touching the bucket, got %d %s", w.Code, w.Body.String())
+	}
+	w = serve(h.DeleteMedia, request("DELETE", "/?force=true", "", primitive.NewObjectID(), org, "org_admin"), "id", id)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"references":[{`) {
+		t.Fatalf("force: %d %s", w.Code, w.Body.String())
+	}
+	if strings.Join(storage.removed, ",") != "mybucket/upload_1_portada.jpg,mybucket/upload_1_thumb.jpg" {
+		t.Errorf("the original and its renditions are removed: %v", storage.removed)
+	}
+	if w := serve(h.GetMedia, request("GET", "/", "", alice, org), "id", id); w.Code != http.StatusNotFound {
+		t.Errorf("deleted: got %d", w.Code)
+	}
+
+	// Sin referencias se borra directo; si la búsqueda falla no se borra nada
+	id = record(t, h, alice, org, "suelta.jpg", "image/jpeg").Result.ID
+	used = nil
+	if w := serve(h.DeleteMedia, request("DELETE", "/", "", primitive.NewObjectID(), org), "id", id); w.Code != http.StatusForbidden {
+		t.Errorf("others can't delete it, got %d", w.Code)
+	}
+	h.References = append(h.References, func(context.Context, primitive.ObjectID, []string) ([]Reference, error) {
+		return nil, errors.New("boom")
+	})
+	if w := serve(h.DeleteMedia, request("DELETE", "/?force=true", "", alice, org), "id", id); w.Code != http.StatusInternalServerError {
+		t.Errorf("failed lookup: expected 500, got %d", w.Code)
+	}
+	h.References = h.References[:1]
+	if w := serve(h.DeleteMedia, request("DELETE", "/", "", alice, org), "id", id); w.Code != http.StatusOK {
+		t.Errorf("unused media: expected 200, got %d", w.Code)
+	}
+}
@@ -0,0 +1,127 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 🗂️ Biblioteca de medios: cada archivo subido a /upload queda registrado con
+// su dueño, organización, tipo, tamaño, dimensiones y versiones, y se le puede
+// poner texto alternativo, pie, créditos y etiquetas. Antes de borrar uno se
+// buscan los artículos que lo usan.
+
+var ErrNotFound = errors.New("not found")
+
+// Rendition es una versión generada de una imagen
+type Rendition struct {
+	URL    string `bson:"url" json:"url"`
+	Width  int    `bson:"width" json:"width"`
+	Height int    `bson:"height" json:"height"`
+}
+
+// Asset es un archivo de la biblioteca
+type Asset struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	OrganizationID primitive.ObjectID `bson:"organization_id" json:"organization_id"`
+	OwnerID        primitive.ObjectID `bson:"owner_id" json:"owner_id"`
+	URL            string             `bson:"url" json:"url"`
+	// Objects son los nombres en el bucket: la original y sus versiones
+	Objects  []string `bson:"objects" json:"-"`
+	Filename string   `bson:"filename" json:"filename"`
+	MimeType string   `bson:"mime_type" json:"mime_type"`
+	Size     int64    `bson:"size" json:"size"`
+	// Width, Height, Blurhash y Renditions solo en imágenes
+	Width      int                  `bson:"width,omitempty" json:"width,omitempty"`
+	Height     int                  `bson:"height,omitempty" json:"height,omitempty"`
+	Blurhash   string               `bson:"blurhash,omitempty" json:"blurhash,omitempty"`
+	Renditions map[string]Rendition `bson:"renditions,omitempty" json:"renditions,omitempty"`
+	Alt        string               `bson:"alt,omitempty" json:"alt,omitempty"`
+	Caption    string               `bson:"caption,omitempty" json:"caption,omitempty"`
+	Credits    string               `bson:"credits,omitempty" json:"credits,omitempty"`
+	Tags       []string             `bson:"tags,omitempty" json:"tags,omitempty"`
+	CreatedAt  time.Time            `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time            `bson:"updated_at" json:"updated_at"`
+}
+
+// URLs devuelve la URL de la original y las de sus versiones
+func (a *Asset) URLs() []string {
+	urls := []string{a.URL}
+	for _, r := range a.Renditions {
+		urls = append(urls, r.URL)
+	}
+	return urls
+}
+
+// Reference es un lugar donde se usa un archivo
+type Reference struct {
+	// Kind es el tipo de lo que lo usa, p. ej. "article"
+	Kind  string `json:"kind"`
+	ID    string `json:"id"`
+	Title string `json:"title,omitempty"`
+	// Field dice dónde: "image", "content", "seo"...
+	Field string `json:"field"`
+}
+
+// ReferenceFinder busca quién usa alguna de las URLs dentro de la organización
+type ReferenceFinder func(ctx context.Context, orgID primitive.ObjectID, urls []string) ([]Reference, error)
+
+// Query filtra y pagina el listado de la biblioteca
+type Query struct {
+	// Search busca en nombre, texto alternativo, pie, créditos y etiquetas
+	Search string
+	Tag    string
+	// Type es un MIME exacto ("image/png") o solo su tipo ("image")
+	Type    string
+	OwnerID primitive.ObjectID
+	Limit   int
+	// After es el ID del último archivo de la página anterior
+	After primitive.ObjectID
+}
+
+// Matches evalúa los filtros (no la página) en memoria con la misma semántica que la consulta a Mongo
+func (q Query) Matches(a *Asset) bool {
+	if !q.OwnerID.IsZero() && a.OwnerID != q.OwnerID {
+		return false
+	}
+	if q.Tag != "" && !contains(a.Tags, q.Tag) {
+		return false
+	}
+	if q.Type != "" && a.MimeType != q.Type && !strings.HasPrefix(a.MimeType, q.Type+"/") {
+		return false
+	}
+	if q.Search == "" {
+		return true
+	}
+	s := strings.ToLower(q.Search)
+	for _, field := range append([]string{a.Filename, a.Alt, a.Caption, a.Credits}, a.Tags...) {
+		if strings.Contains(strings.ToLower(field), s) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeTags deja las etiquetas en minúscula, sin espacios de más ni duplicados
+func normalizeTags(in []string) []string {
+	out := []string{}
+	for _, t := range in {
+		t = strings.ToLower(strings.Join(strings.Fields(t), " "))
+		if t != "" && !contains(out, t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
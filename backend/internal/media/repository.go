@@ -0,0 +1,18 @@
+package media
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Repository guarda la biblioteca, siempre por organización
+type Repository interface {
+	CreateAsset(ctx context.Context, a *Asset) error
+	GetAsset(ctx context.Context, orgID, id primitive.ObjectID) (*Asset, error)
+	// ListAssets devuelve hasta q.Limit+1 archivos, los más nuevos primero,
+	// junto con el total que matchea sin paginar
+	ListAssets(ctx context.Context, orgID primitive.ObjectID, q Query) ([]Asset, int64, error)
+	UpdateAsset(ctx context.Context, a *Asset) error
+	DeleteAsset(ctx context.Context, orgID, id primitive.ObjectID) error
+}
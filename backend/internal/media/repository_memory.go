@@ -0,0 +1,92 @@
+package media
+
+import (
+	"context"
+	"maps"
+	"sort"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryRepository es la contraparte en memoria de MongoRepository
+type MemoryRepository struct {
+	mu     sync.Mutex
+	assets map[primitive.ObjectID]Asset
+}
+
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{assets: map[primitive.ObjectID]Asset{}}
+}
+
+func (r *MemoryRepository) CreateAsset(ctx context.Context, a *Asset) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if a.ID.IsZero() {
+		a.ID = primitive.NewObjectID()
+	}
+	r.assets[a.ID] = cloneAsset(*a)
+	return nil
+}
+
+func (r *MemoryRepository) GetAsset(ctx context.Context, orgID, id primitive.ObjectID) (*Asset, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a, ok := r.assets[id]
+	if !ok || a.OrganizationID != orgID {
+		return nil, ErrNotFound
+	}
+	a = cloneAsset(a)
+	return &a, nil
+}
+
+func (r *MemoryRepository) ListAssets(ctx context.Context, orgID primitive.ObjectID, q Query) ([]Asset, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	matched := []Asset{}
+	for _, a := range r.assets {
+		if a.OrganizationID == orgID && q.Matches(&a) {
+			matched = append(matched, cloneAsset(a))
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID.Hex() > matched[j].ID.Hex() })
+	page := []Asset{}
+	for _, a := range matched {
+		if len(page) > q.Limit {
+			break
+		}
+		if q.After.IsZero() || a.ID.Hex() < q.After.Hex() {
+			page = append(page, a)
+		}
+	}
+	return page, int64(len(matched)), nil
+}
+
+func (r *MemoryRepository) UpdateAsset(ctx context.Context, a *Asset) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.assets[a.ID]
+	if !ok || current.OrganizationID != a.OrganizationID {
+		return ErrNotFound
+	}
+	r.assets[a.ID] = cloneAsset(*a)
+	return nil
+}
+
+func (r *MemoryRepository) DeleteAsset(ctx context.Context, orgID, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a, ok := r.assets[id]
+	if !ok || a.OrganizationID != orgID {
+		return ErrNotFound
+	}
+	delete(r.assets, id)
+	return nil
+}
+
+func cloneAsset(a Asset) Asset {
+	a.Objects = append([]string(nil), a.Objects...)
+	a.Tags = append([]string(nil), a.Tags...)
+	a.Renditions = maps.Clone(a.Renditions)
+	return a
+}
@@ -0,0 +1,124 @@
+package media
+
+import (
+	"context"
+	"log"
+	"regexp"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoRepository struct {
+	assets *mongo.Collection
+}
+
+// NewMongoRepository usa la colección media_assets de la base
+func NewMongoRepository(db *mongo.Database) *MongoRepository {
+	r := &MongoRepository{assets: db.Collection("media_assets")}
+	r.ensureIndexes()
+	return r
+}
+
+func (r *MongoRepository) ensureIndexes() {
+	_, err := r.assets.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		// El listado: por organización, los más nuevos primero
+		{Keys: bson.D{{Key: "organization_id", Value: 1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "organization_id", Value: 1}, {Key: "tags", Value: 1}}},
+	})
+	if err != nil {
+		log.Printf("⚠️ No se pudieron crear los índices de la biblioteca de medios: %v", err)
+	}
+}
+
+// filter traduce los filtros del query (sin la página) a una consulta de Mongo
+func (q Query) filter(orgID primitive.ObjectID) bson.M {
+	filter := bson.M{"organization_id": orgID}
+	if !q.OwnerID.IsZero() {
+		filter["owner_id"] = q.OwnerID
+	}
+	if q.Tag != "" {
+		filter["tags"] = q.Tag
+	}
+	if q.Type != "" {
+		filter["mime_type"] = bson.M{"$regex": "^" + regexp.QuoteMeta(q.Type) + "(/|$)"}
+	}
+	if q.Search != "" {
+		re := primitive.Regex{Pattern: regexp.QuoteMeta(q.Search), Options: "i"}
+		filter["$or"] = bson.A{
+			bson.M{"filename": re}, bson.M{"alt": re}, bson.M{"caption": re},
+			bson.M{"credits": re}, bson.M{"tags": re},
+		}
+	}
+	return filter
+}
+
+func (r *MongoRepository) CreateAsset(ctx context.Context, a *Asset) error {
+	if a.ID.IsZero() {
+		a.ID = primitive.NewObjectID()
+	}
+	_, err := r.assets.InsertOne(ctx, a)
+	return err
+}
+
+func (r *MongoRepository) GetAsset(ctx context.Context, orgID, id primitive.ObjectID) (*Asset, error) {
+	var a Asset
+	err := r.assets.FindOne(ctx, bson.M{"organization_id": orgID, "_id": id}).Decode(&a)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *MongoRepository) ListAssets(ctx context.Context, orgID primitive.ObjectID, q Query) ([]Asset, int64, error) {
+	filter := q.filter(orgID)
+	total, err := r.assets.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !q.After.IsZero() {
+		filter["_id"] = bson.M{"$lt": q.After}
+	}
+	cursor, err := r.assets.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(int64(q.Limit+1)))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+	out := []Asset{}
+	for cursor.Next(ctx) {
+		var a Asset
+		if err := cursor.Decode(&a); err == nil {
+			out = append(out, a)
+		}
+	}
+	return out, total, cursor.Err()
+}
+
+func (r *MongoRepository) UpdateAsset(ctx context.Context, a *Asset) error {
+	res, err := r.assets.ReplaceOne(ctx, bson.M{"organization_id": a.OrganizationID, "_id": a.ID}, a)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *MongoRepository) DeleteAsset(ctx context.Context, orgID, id primitive.ObjectID) error {
+	res, err := r.assets.DeleteOne(ctx, bson.M{"organization_id": orgID, "_id": id})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
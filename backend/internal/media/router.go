@@ -0,0 +1,14 @@
+package media
+
+import (
+	"net/http"
+	"pittsix/pkg/middleware"
+)
+
+func RegisterHandlers(mux *http.ServeMux, h *Handlers) {
+	mux.Handle("GET /media", middleware.JWTAuth(http.HandlerFunc(h.ListMedia)))
+	mux.Handle("GET /media/{id}", middleware.JWTAuth(http.HandlerFunc(h.GetMedia)))
+	mux.Handle("PUT /media/{id}", middleware.JWTAuth(http.HandlerFunc(h.UpdateMedia)))
+	mux.Handle("DELETE /media/{id}", middleware.JWTAuth(http.HandlerFunc(h.DeleteMedia)))
+	mux.Handle("GET /media/{id}/references", middleware.JWTAuth(http.HandlerFunc(h.ListReferences)))
+}
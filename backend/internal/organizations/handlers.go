@@ -2,10 +2,12 @@ package organizations
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 
 	"pittsix/internal/users"
+	"pittsix/pkg/events"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -13,6 +15,23 @@ import (
 type Handlers struct {
 	repo     Repository
 	userRepo users.Repository
+	// Events recibe las altas, bajas y cambios de organizaciones (nil: no se publican)
+	Events *events.Bus
+}
+
+// publish publica un evento de la organización; lo ven todos sus miembros
+func (h *Handlers) publish(r *http.Request, kind string, o *Organization) {
+	if h.Events == nil || o == nil {
+		return
+	}
+	actor, _ := r.Context().Value("user_id").(string)
+	h.Events.Publish(events.Event{
+		Type:           kind,
+		OrganizationID: o.ID.Hex(),
+		ActorID:        actor,
+		SubjectID:      o.ID.Hex(),
+		Data:           map[string]string{"id": o.ID.Hex(), "name": o.Name, "slug": o.Slug},
+	})
 }
 
 func NewHandlers(repo Repository, userRepo users.Repository) *Handlers {
@@ -54,11 +73,23 @@ func (h *Handlers) CreateOrganization(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Organization already exists", http.StatusConflict)
 		return
 	}
+	if !input.validateLocales() {
+		http.Error(w, "Invalid locale", http.StatusBadRequest)
+		return
+	}
+	if input.Slug == "" {
+		input.Slug = Slugify(input.Name)
+	}
+	if org, _ := h.repo.GetBySlug(input.Slug); org != nil {
+		http.Error(w, "Organization slug already exists", http.StatusConflict)
+		return
+	}
 	input.ID = primitive.NewObjectID()
 	if err := h.repo.Create(&input); err != nil {
 		http.Error(w, "DB error", http.StatusInternalServerError)
 		return
 	}
+	h.publish(r, events.OrganizationCreated, &input)
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(input)
 }
@@ -81,10 +112,23 @@ func (h *Handlers) UpdateOrganization(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
+	if slug, ok := update["slug"].(string); ok && slug != "" {
+		if org, _ := h.repo.GetBySlug(slug); org != nil && org.ID != id {
+			http.Error(w, "Organization slug already exists", http.StatusConflict)
+			return
+		}
+	}
+	if err := normalizeLocaleUpdate(update); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err := h.repo.Update(id, update); err != nil {
 		http.Error(w, "DB error", http.StatusInternalServerError)
 		return
 	}
+	if org, err := h.repo.GetByID(id); err == nil {
+		h.publish(r, events.OrganizationUpdated, org)
+	}
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"status": "updated"})
 }
@@ -106,6 +150,7 @@ func (h *Handlers) DeleteOrganization(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "DB error", http.StatusInternalServerError)
 		return
 	}
+	h.publish(r, events.OrganizationDeleted, &Organization{ID: id})
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"status": "deleted"})
 }
@@ -125,3 +170,27 @@ func (h *Handlers) ListOrganizationUsers(w http.ResponseWriter, r *http.Request)
 	}
 	json.NewEncoder(w).Encode(users)
 }
+
+// normalizeLocaleUpdate valida y normaliza los campos de idioma de un update parcial
+func normalizeLocaleUpdate(update map[string]interface{}) error {
+	keys := []string{"default_locale", "locales", "locale_fallbacks"}
+	partial := map[string]interface{}{}
+	for _, k := range keys {
+		if v, ok := update[k]; ok {
+			partial[k] = v
+		}
+	}
+	if len(partial) == 0 {
+		return nil
+	}
+	raw, _ := json.Marshal(partial)
+	var org Organization
+	if err := json.Unmarshal(raw, &org); err != nil || !org.validateLocales() {
+		return errors.New("Invalid locale")
+	}
+	normalized := map[string]interface{}{"default_locale": org.DefaultLocale, "locales": org.Locales, "locale_fallbacks": org.LocaleFallbacks}
+	for k := range partial {
+		update[k] = normalized[k]
+	}
+	return nil
+}
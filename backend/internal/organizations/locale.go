@@ -0,0 +1,100 @@
+package organizations
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DefaultLocale es el idioma de las organizaciones que no configuraron uno
+const DefaultLocale = "es"
+
+var localeRegex = regexp.MustCompile(`^([a-zA-Z]{2,3})(?:[-_]([a-zA-Z]{2}|[0-9]{3}))?$`)
+
+// NormalizeLocale lleva un locale a la forma "es" o "es-AR"; ok es false si no lo es
+func NormalizeLocale(s string) (string, bool) {
+	m := localeRegex.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return "", false
+	}
+	locale := strings.ToLower(m[1])
+	if m[2] != "" {
+		locale += "-" + strings.ToUpper(m[2])
+	}
+	return locale, true
+}
+
+// baseLanguage devuelve "es" para "es-AR"
+func baseLanguage(locale string) string {
+	if i := strings.IndexByte(locale, '-'); i >= 0 {
+		return locale[:i]
+	}
+	return locale
+}
+
+// PrimaryLocale es el idioma por defecto de la organización
+func (o *Organization) PrimaryLocale() string {
+	if o != nil && o.DefaultLocale != "" {
+		return o.DefaultLocale
+	}
+	return DefaultLocale
+}
+
+// LocaleChain arma el orden en que se buscan traducciones para los locales
+// pedidos: cada uno seguido de sus fallbacks configurados y de su idioma base,
+// y al final el idioma por defecto de la organización
+func (o *Organization) LocaleChain(wanted []string) []string {
+	chain := []string{}
+	seen := map[string]bool{}
+	add := func(locale string) {
+		if !seen[locale] {
+			seen[locale] = true
+			chain = append(chain, locale)
+		}
+	}
+	for _, locale := range wanted {
+		add(locale)
+		if o != nil {
+			for _, fallback := range o.LocaleFallbacks[locale] {
+				add(fallback)
+			}
+		}
+		if base := baseLanguage(locale); base != locale {
+			add(base)
+			if o != nil {
+				for _, fallback := range o.LocaleFallbacks[base] {
+					add(fallback)
+				}
+			}
+		}
+	}
+	add(o.PrimaryLocale())
+	return chain
+}
+
+// validateLocales normaliza la configuración de idiomas; false si algún locale es inválido
+func (o *Organization) validateLocales() bool {
+	ok := true
+	norm := func(s string) string {
+		locale, valid := NormalizeLocale(s)
+		ok = ok && valid
+		return locale
+	}
+	if o.DefaultLocale != "" {
+		o.DefaultLocale = norm(o.DefaultLocale)
+	}
+	for i, l := range o.Locales {
+		o.Locales[i] = norm(l)
+	}
+	fallbacks := make(map[string][]string, len(o.LocaleFallbacks))
+	for from, chain := range o.LocaleFallbacks {
+		normalized := make([]string, len(chain))
+		for i, l := range chain {
+			normalized[i] = norm(l)
+		}
+		fallbacks[norm(from)] = normalized
+	}
+	if o.LocaleFallbacks != nil {
+		o.LocaleFallbacks = fallbacks
+	}
+	return ok
+}
@@ -1,12 +1,25 @@
 package organizations
 
 import (
+	"regexp"
+	"strings"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type Organization struct {
 	ID   primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	Name string             `bson:"name" json:"name"`
+	// Slug identifica a la organización en lecturas públicas (?org=)
+	Slug string `bson:"slug" json:"slug"`
+	// Hosts son los dominios que sirven el contenido público de la organización
+	Hosts []string `bson:"hosts,omitempty" json:"hosts,omitempty"`
+	// DefaultLocale es el idioma de los artículos sin locale (por defecto "es")
+	DefaultLocale string `bson:"default_locale,omitempty" json:"default_locale,omitempty"`
+	// Locales son los idiomas a los que se traduce el contenido
+	Locales []string `bson:"locales,omitempty" json:"locales,omitempty"`
+	// LocaleFallbacks indica qué probar cuando falta un idioma ("pt": ["es"])
+	LocaleFallbacks map[string][]string `bson:"locale_fallbacks,omitempty" json:"locale_fallbacks,omitempty"`
 	// Puedes agregar más campos si lo necesitas
 }
 
@@ -14,6 +27,21 @@ type Repository interface {
 	Create(org *Organization) error
 	GetByName(name string) (*Organization, error)
 	GetByID(id primitive.ObjectID) (*Organization, error)
+	GetBySlug(slug string) (*Organization, error)
+	GetByHost(host string) (*Organization, error)
 	Update(id primitive.ObjectID, update map[string]interface{}) error
 	Delete(id primitive.ObjectID) error
 }
+
+var (
+	slugSpaces       = regexp.MustCompile(`[\s_]+`)
+	slugInvalidChars = regexp.MustCompile(`[^a-z0-9-]+`)
+)
+
+// Slugify genera el slug de una organización a partir de su nombre
+func Slugify(name string) string {
+	slug := strings.ToLower(strings.TrimSpace(name))
+	slug = slugSpaces.ReplaceAllString(slug, "-")
+	slug = slugInvalidChars.ReplaceAllString(slug, "")
+	return strings.Trim(slug, "-")
+}
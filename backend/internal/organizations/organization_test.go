@@ -0,0 +1,45 @@
+package organizations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	cases := map[string]string{
+		"Org Principal":   "org-principal",
+		"  Acme  Corp!! ": "acme-corp",
+		"pittsix":         "pittsix",
+	}
+	for in, want := range cases {
+		if got := Slugify(in); got != want {
+			t.Errorf("Slugify(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestNormalizeLocale(t *testing.T) {
+	cases := map[string]string{"es": "es", "ES_ar": "es-AR", " pt-br ": "pt-BR", "es-419": "es-419"}
+	for in, want := range cases {
+		if got, ok := NormalizeLocale(in); !ok || got != want {
+			t.Errorf("NormalizeLocale(%q) = %q, %v; want %q", in, got, ok, want)
+		}
+	}
+	for _, bad := range []string{"", "español", "e", "es-ARG", "*"} {
+		if _, ok := NormalizeLocale(bad); ok {
+			t.Errorf("NormalizeLocale(%q) should fail", bad)
+		}
+	}
+}
+
+func TestLocaleChain(t *testing.T) {
+	org := &Organization{DefaultLocale: "en", LocaleFallbacks: map[string][]string{"pt": {"es"}, "ca": {"es"}}}
+	got := strings.Join(org.LocaleChain([]string{"pt-BR", "ca"}), ",")
+	if got != "pt-BR,pt,es,ca,en" {
+		t.Errorf("unexpected chain: %s", got)
+	}
+	var none *Organization
+	if got := strings.Join(none.LocaleChain([]string{"en-US"}), ","); got != "en-US,en,es" {
+		t.Errorf("without organization the default is %q: %s", DefaultLocale, got)
+	}
+}
@@ -3,6 +3,7 @@ package organizations
 import (
 	"context"
 	"errors"
+	"strings"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -47,6 +48,30 @@ func (r *MongoRepository) GetByID(id primitive.ObjectID) (*Organization, error)
 	return &org, nil
 }
 
+func (r *MongoRepository) GetBySlug(slug string) (*Organization, error) {
+	var org Organization
+	err := r.collection.FindOne(context.Background(), bson.M{"slug": slug}).Decode(&org)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("not found")
+		}
+		return nil, err
+	}
+	return &org, nil
+}
+
+func (r *MongoRepository) GetByHost(host string) (*Organization, error) {
+	var org Organization
+	err := r.collection.FindOne(context.Background(), bson.M{"hosts": strings.ToLower(host)}).Decode(&org)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("not found")
+		}
+		return nil, err
+	}
+	return &org, nil
+}
+
 func (r *MongoRepository) Update(id primitive.ObjectID, update map[string]interface{}) error {
 	_, err := r.collection.UpdateOne(
 		context.Background(),
@@ -0,0 +1,91 @@
+package upload
+
+import (
+	"image"
+	"math"
+	"strings"
+)
+
+// 🌫️ BlurHash (https://blurha.sh): unos 30 caracteres que el frontend pinta
+// como un degradado borroso mientras carga la imagen de verdad. Se calcula
+// sobre una versión diminuta, el resultado es el mismo.
+
+const base83Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+// blurhash codifica m con x por y componentes (1 a 9 cada uno)
+func blurhash(m *image.RGBA, x, y int) string {
+	w, h := m.Rect.Dx(), m.Rect.Dy()
+	factors := make([][3]float64, 0, x*y)
+	for j := 0; j < y; j++ {
+		for i := 0; i < x; i++ {
+			norm := 2.0
+			if i == 0 && j == 0 {
+				norm = 1
+			}
+			var f [3]float64
+			for py := 0; py < h; py++ {
+				cy := math.Cos(math.Pi * float64(j) * float64(py) / float64(h))
+				for px := 0; px < w; px++ {
+					basis := norm * math.Cos(math.Pi*float64(i)*float64(px)/float64(w)) * cy
+					p := m.Pix[py*m.Stride+px*4:]
+					f[0] += basis * srgbToLinear(p[0])
+					f[1] += basis * srgbToLinear(p[1])
+					f[2] += basis * srgbToLinear(p[2])
+				}
+			}
+			scale := 1 / float64(w*h)
+			factors = append(factors, [3]float64{f[0] * scale, f[1] * scale, f[2] * scale})
+		}
+	}
+
+	var sb strings.Builder
+	base83(&sb, (x-1)+(y-1)*9, 1)
+	maxValue := 1.0
+	if len(factors) > 1 {
+		actual := 0.0
+		for _, f := range factors[1:] {
+			actual = max(actual, math.Abs(f[0]), math.Abs(f[1]), math.Abs(f[2]))
+		}
+		quantised := int(max(0, min(82, math.Floor(actual*166-0.5))))
+		maxValue = float64(quantised+1) / 166
+		base83(&sb, quantised, 1)
+	} else {
+		base83(&sb, 0, 1)
+	}
+	dc := factors[0]
+	base83(&sb, linearToSRGB(dc[0])<<16|linearToSRGB(dc[1])<<8|linearToSRGB(dc[2]), 4)
+	for _, f := range factors[1:] {
+		q := func(v float64) int {
+			return int(max(0, min(18, math.Floor(signPow(v/maxValue, 0.5)*9+9.5))))
+		}
+		base83(&sb, q(f[0])*19*19+q(f[1])*19+q(f[2]), 2)
+	}
+	return sb.String()
+}
+
+func base83(sb *strings.Builder, n, length int) {
+	for i := 1; i <= length; i++ {
+		digit := n / int(math.Pow(83, float64(length-i))) % 83
+		sb.WriteByte(base83Chars[digit])
+	}
+}
+
+func srgbToLinear(c uint8) float64 {
+	v := float64(c) / 255
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(v float64) int {
+	v = max(0, min(1, v))
+	if v <= 0.0031308 {
+		return int(v*12.92*255 + 0.5)
+	}
+	return int((1.055*math.Pow(v, 1/2.4)-0.055)*255 + 0.5)
+}
+
+func signPow(v, exp float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(v), exp), v)
+}
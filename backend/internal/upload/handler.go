@@ -1,11 +1,18 @@
 package upload
 
 import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
+	"path"
+	"strings"
 	"time"
 
 	"context"
@@ -21,14 +28,75 @@ type MinioClient interface {
 
 type Handler struct {
 	Client MinioClient
+	// Renditions son las versiones que se generan de cada imagen subida
+	Renditions []Rendition
+	// Quality es la calidad JPEG y WebP de las versiones
+	Quality int
+	// MaxBytes limita el tamaño del archivo y MaxPixels el de la imagen decodificada
+	MaxBytes  int64
+	MaxPixels int
+	// OnUpload corre después de cada subida a /upload (no las fotos de perfil),
+	// p. ej. para registrarla en la biblioteca de medios; si falla, la subida
+	// responde 500 aunque los archivos ya estén en el bucket
+	OnUpload []UploadHook
 }
 
+// Upload describe un archivo ya subido con todas sus versiones
+type Upload struct {
+	// Objects son los nombres en el bucket: la original primero y luego las versiones
+	Objects  []string
+	Filename string
+	Size     int64
+	Result   *UploadResult
+}
+
+// UploadHook recibe la subida terminada; puede completar Result (p. ej. con el ID)
+type UploadHook func(r *http.Request, upload *Upload) error
+
 func NewHandler(client MinioClient) *Handler {
-	return &Handler{Client: client}
+	return &Handler{
+		Client:     client,
+		Renditions: DefaultRenditions,
+		Quality:    DefaultQuality,
+		MaxBytes:   DefaultMaxBytes,
+		MaxPixels:  DefaultMaxPixels,
+	}
+}
+
+// UploadResult es la respuesta de una subida; las imágenes traen además sus
+// dimensiones, el blurhash y las URLs de cada versión
+type UploadResult struct {
+	// ID es el del registro que crea un UploadHook, si hay
+	ID          string                     `json:"id,omitempty"`
+	URL         string                     `json:"url"`
+	ContentType string                     `json:"content_type,omitempty"`
+	Width       int                        `json:"width,omitempty"`
+	Height      int                        `json:"height,omitempty"`
+	Blurhash    string                     `json:"blurhash,omitempty"`
+	Renditions  map[string]RenditionResult `json:"renditions,omitempty"`
+}
+
+type RenditionResult struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
 }
 
+// Bucket es donde quedan todas las subidas
+const Bucket = "mybucket"
+
 func (h *Handler) UploadHandler(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
+	h.upload(w, r, "upload", "✅ Archivo subido", h.OnUpload)
+}
+
+func (h *Handler) UploadProfileImageHandler(w http.ResponseWriter, r *http.Request) {
+	h.upload(w, r, "profile", "✅ Foto de perfil subida", nil)
+}
+
+// upload sube el archivo del campo "file"; las imágenes pasan por el pipeline
+// y sus versiones quedan al lado: <nombre>_<versión>.<ext>
+func (h *Handler) upload(w http.ResponseWriter, r *http.Request, prefix, done string, hooks []UploadHook) {
+	ctx := r.Context()
 	file, header, err := r.FormFile("file")
 	if err != nil {
 		log.Printf("❌ Error leyendo archivo: %v", err)
@@ -36,49 +104,125 @@ func (h *Handler) UploadHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	defer file.Close()
-	objectName := fmt.Sprintf("upload_%d_%s", time.Now().Unix(), header.Filename)
-	uploadInfo, err := h.Client.PutObject(ctx, "mybucket", objectName, file, header.Size, minio.PutObjectOptions{
-		ContentType: header.Header.Get("Content-Type"),
-	})
-	if err != nil {
-		log.Printf("❌ Error subiendo a MinIO: %v", err)
-		http.Error(w, "Error interno al subir", http.StatusInternalServerError)
+	if h.MaxBytes > 0 && header.Size > h.MaxBytes {
+		http.Error(w, "Archivo demasiado grande", http.StatusRequestEntityTooLarge)
 		return
 	}
-	baseURL := os.Getenv("MINIO_PUBLIC_URL_BASE")
-	if baseURL == "" {
-		baseURL = "http://localhost:9000"
-	}
-	publicURL := fmt.Sprintf("%s/mybucket/%s", baseURL, objectName)
-	log.Printf("✅ Archivo subido: %+v", uploadInfo)
-	w.Header().Set("Content-Type", "application/json")
-	w.Write([]byte(fmt.Sprintf(`{"url":"%s"}`, publicURL)))
-}
-
-func (h *Handler) UploadProfileImageHandler(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background()
-	file, header, err := r.FormFile("file")
+	data, err := io.ReadAll(file)
 	if err != nil {
 		log.Printf("❌ Error leyendo archivo: %v", err)
 		http.Error(w, "Archivo inválido", http.StatusBadRequest)
 		return
 	}
-	defer file.Close()
-	objectName := fmt.Sprintf("profile_%d_%s", time.Now().Unix(), header.Filename)
-	uploadInfo, err := h.Client.PutObject(ctx, "mybucket", objectName, file, header.Size, minio.PutObjectOptions{
-		ContentType: header.Header.Get("Content-Type"),
-	})
+	objectName, err := newObjectName(ctx, prefix, header.Filename)
 	if err != nil {
-		log.Printf("❌ Error subiendo a MinIO: %v", err)
+		log.Printf("❌ Error generando el nombre del archivo: %v", err)
 		http.Error(w, "Error interno al subir", http.StatusInternalServerError)
 		return
 	}
+
+	format := imageFormat(data)
+	if format == "" {
+		contentType := header.Header.Get("Content-Type")
+		if contentType == "" || contentType == "application/octet-stream" {
+			contentType = http.DetectContentType(data)
+		}
+		if !h.put(ctx, w, objectName, data, contentType) {
+			return
+		}
+		log.Printf("%s: %s", done, objectName)
+		result := UploadResult{URL: publicURL(objectName), ContentType: contentType}
+		finish(w, r, &Upload{Objects: []string{objectName}, Filename: header.Filename, Size: int64(len(data)), Result: &result}, hooks)
+		return
+	}
+
+	img, err := h.processImage(data, format)
+	switch {
+	case errors.Is(err, errInvalidImage):
+		http.Error(w, "Imagen inválida", http.StatusBadRequest)
+		return
+	case errors.Is(err, errImageTooLarge):
+		http.Error(w, "Imagen demasiado grande", http.StatusRequestEntityTooLarge)
+		return
+	case err != nil:
+		log.Printf("❌ Error procesando imagen: %v", err)
+		http.Error(w, "Error interno al procesar la imagen", http.StatusInternalServerError)
+		return
+	}
+	if !h.put(ctx, w, objectName, img.original.data, img.original.contentType) {
+		return
+	}
+	result := UploadResult{
+		URL:         publicURL(objectName),
+		ContentType: img.original.contentType,
+		Width:       img.original.width,
+		Height:      img.original.height,
+		Blurhash:    img.blurhash,
+		Renditions:  map[string]RenditionResult{},
+	}
+	objects := []string{objectName}
+	base := strings.TrimSuffix(objectName, path.Ext(objectName))
+	for i, rendition := range h.Renditions {
+		out := img.renditions[i]
+		name := base + "_" + rendition.Name + out.ext
+		if !h.put(ctx, w, name, out.data, out.contentType) {
+			return
+		}
+		result.Renditions[rendition.Name] = RenditionResult{URL: publicURL(name), Width: out.width, Height: out.height}
+		objects = append(objects, name)
+	}
+	log.Printf("%s: %s (%dx%d, %d versiones)", done, objectName, result.Width, result.Height, len(result.Renditions))
+	finish(w, r, &Upload{Objects: objects, Filename: header.Filename, Size: int64(len(img.original.data)), Result: &result}, hooks)
+}
+
+// newObjectName arma <organización>/<prefijo>_<unix>_<azar>_<archivo>. La
+// carpeta y el sufijo al azar evitan que dos subidas con el mismo nombre en el
+// mismo segundo compartan objetos y que borrar una se lleve los de la otra.
+func newObjectName(ctx context.Context, prefix, filename string) (string, error) {
+	org, _ := ctx.Value("organization_id").(string)
+	if org == "" {
+		org = "shared"
+	}
+	suffix := make([]byte, 6)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/%s_%d_%s_%s", org, prefix, time.Now().Unix(), hex.EncodeToString(suffix), filename), nil
+}
+
+// finish corre los hooks y responde
+func finish(w http.ResponseWriter, r *http.Request, upload *Upload, hooks []UploadHook) {
+	for _, hook := range hooks {
+		if err := hook(r, upload); err != nil {
+			log.Printf("❌ Error registrando la subida %s: %v", upload.Objects[0], err)
+			http.Error(w, "Error interno al registrar la subida", http.StatusInternalServerError)
+			return
+		}
+	}
+	writeResult(w, *upload.Result)
+}
+
+// put sube un objeto; si falla responde 500 y devuelve false
+func (h *Handler) put(ctx context.Context, w http.ResponseWriter, name string, data []byte, contentType string) bool {
+	if _, err := h.Client.PutObject(ctx, Bucket, name, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+		ContentType: contentType,
+	}); err != nil {
+		log.Printf("❌ Error subiendo a MinIO: %v", err)
+		http.Error(w, "Error interno al subir", http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+func publicURL(objectName string) string {
 	baseURL := os.Getenv("MINIO_PUBLIC_URL_BASE")
 	if baseURL == "" {
 		baseURL = "http://localhost:9000"
 	}
-	publicURL := fmt.Sprintf("%s/mybucket/%s", baseURL, objectName)
-	log.Printf("✅ Foto de perfil subida: %+v", uploadInfo)
+	return fmt.Sprintf("%s/%s/%s", baseURL, Bucket, objectName)
+}
+
+func writeResult(w http.ResponseWriter, result UploadResult) {
 	w.Header().Set("Content-Type", "application/json")
-	w.Write([]byte(fmt.Sprintf(`{"url":"%s"}`, publicURL)))
+	json.NewEncoder(w).Encode(result)
 }
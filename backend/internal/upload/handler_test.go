@@ -16,12 +16,23 @@ import (
 
 type mockMinioClient struct {
 	fail bool
+	// objects guarda lo subido si no es nil
+	objects map[string]storedObject
+}
+
+type storedObject struct {
+	data        []byte
+	contentType string
 }
 
 func (m *mockMinioClient) PutObject(ctx context.Context, bucket, object string, reader io.Reader, size int64, opts minio.PutObjectOptions) (minio.UploadInfo, error) {
 	if m.fail {
 		return minio.UploadInfo{}, io.ErrUnexpectedEOF
 	}
+	if m.objects != nil {
+		data, _ := io.ReadAll(reader)
+		m.objects[object] = storedObject{data: data, contentType: opts.ContentType}
+	}
 	return minio.UploadInfo{}, nil
 }
 
@@ -80,6 +91,44 @@ func TestUploadHandler_ErrorOnPutObject(t *testing.T) {
 	}
 }
 
+func TestUploadHandler_ObjectNamesPerOrganization(t *testing.T) {
+	client := &mockMinioClient{objects: map[string]storedObject{}}
+	h := NewHandler(client)
+	upload := func(org string) {
+		var b bytes.Buffer
+		mw := multipart.NewWriter(&b)
+		fw, _ := mw.CreateFormFile("file", "notas.txt")
+		fw.Write([]byte("hello"))
+		mw.Close()
+		req := httptest.NewRequest(http.MethodPost, "/upload", &b)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		req = req.WithContext(context.WithValue(req.Context(), "organization_id", org))
+		w := httptest.NewRecorder()
+		h.UploadHandler(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", w.Code)
+		}
+	}
+	// El mismo archivo, en el mismo segundo, dos veces en una organización y una en otra
+	upload("org-a")
+	upload("org-a")
+	upload("org-b")
+	if len(client.objects) != 3 {
+		t.Fatalf("every upload needs its own object, got %d", len(client.objects))
+	}
+	perOrg := map[string]int{}
+	for name := range client.objects {
+		org, file, _ := strings.Cut(name, "/")
+		if !strings.HasPrefix(file, "upload_") || !strings.HasSuffix(file, "_notas.txt") {
+			t.Errorf("unexpected object name %q", name)
+		}
+		perOrg[org]++
+	}
+	if perOrg["org-a"] != 2 || perOrg["org-b"] != 1 {
+		t.Errorf("objects must live under their organization: %v", perOrg)
+	}
+}
+
 func TestUploadProfileImageHandler_InvalidFile(t *testing.T) {
 	h := NewHandler(&mockMinioClient{})
 	req := httptest.NewRequest(http.MethodPost, "/upload/profile", nil)
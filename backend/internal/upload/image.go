@@ -0,0 +1,227 @@
+package upload
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"pittsix/pkg/webp"
+
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// 🖼️ Pipeline de imágenes: las subidas JPEG, PNG, GIF o WebP se decodifican
+// (las que no decodifican se rechazan), se guarda la original sin metadatos y
+// derecha según su EXIF, y al lado una versión por cada Rendition, más chica y
+// recomprimida. Los GIF animados quedan animados en la original; las versiones
+// son del primer cuadro. El resto de los archivos se sube tal cual.
+
+// Rendition es una versión generada de cada imagen
+type Rendition struct {
+	Name  string
+	Width int
+	// Format es "webp" o vacío para JPEG (PNG si la imagen tiene transparencia)
+	Format string
+}
+
+// DefaultRenditions son las versiones si no se configuran otras
+var DefaultRenditions = []Rendition{
+	{Name: "thumbnail", Width: 320},
+	{Name: "medium", Width: 800},
+	{Name: "large", Width: 1600},
+	{Name: "thumbnail_webp", Width: 320, Format: "webp"},
+	{Name: "medium_webp", Width: 800, Format: "webp"},
+	{Name: "large_webp", Width: 1600, Format: "webp"},
+}
+
+const (
+	// DefaultQuality es la calidad JPEG y WebP de las versiones
+	DefaultQuality = 82
+	// DefaultMaxBytes es el tamaño máximo de un archivo subido
+	DefaultMaxBytes = 20 << 20
+	// DefaultMaxPixels corta las imágenes que pesan poco pero ocupan gigas al
+	// decodificarse
+	DefaultMaxPixels = 50_000_000
+
+	// originalQuality es la calidad con la que se recomprime una original que
+	// hubo que rotar
+	originalQuality = 92
+	// blurhashSize es el ancho de la miniatura sobre la que se calcula el blurhash
+	blurhashSize = 32
+)
+
+var (
+	errInvalidImage  = errors.New("invalid image")
+	errImageTooLarge = errors.New("image too large")
+)
+
+// imageFormats son los formatos que se procesan, según el contenido y no el nombre
+var imageFormats = map[string]string{
+	"image/jpeg": "jpeg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+	"image/webp": "webp",
+}
+
+var formatExt = map[string]string{"jpeg": ".jpg", "png": ".png", "gif": ".gif", "webp": ".webp"}
+
+// ParseRenditions lee "nombre:ancho,nombre:ancho"; con withWebP suma una
+// variante <nombre>_webp de cada una
+func ParseRenditions(spec string, withWebP bool) ([]Rendition, error) {
+	var out []Rendition
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, width, ok := strings.Cut(item, ":")
+		n, err := strconv.Atoi(width)
+		if !ok || err != nil || n < 1 || n > webp.MaxSize || name == "" {
+			return nil, fmt.Errorf("invalid rendition %q", item)
+		}
+		out = append(out, Rendition{Name: name, Width: n})
+	}
+	if withWebP {
+		for _, r := range slices.Clone(out) {
+			out = append(out, Rendition{Name: r.Name + "_webp", Width: r.Width, Format: "webp"})
+		}
+	}
+	return out, nil
+}
+
+// imageFormat devuelve el formato de data si es una imagen que se procesa
+func imageFormat(data []byte) string {
+	return imageFormats[http.DetectContentType(data)]
+}
+
+// encodedImage es un archivo listo para subir
+type encodedImage struct {
+	data          []byte
+	contentType   string
+	ext           string
+	width, height int
+}
+
+// processedImage es el resultado del pipeline: la original limpia, sus
+// versiones en el orden configurado y el blurhash
+type processedImage struct {
+	original   encodedImage
+	renditions []encodedImage
+	blurhash   string
+}
+
+// processImage decodifica, valida y genera la original limpia y las versiones
+func (h *Handler) processImage(data []byte, format string) (*processedImage, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width < 1 || cfg.Height < 1 {
+		return nil, errInvalidImage
+	}
+	if h.MaxPixels > 0 && cfg.Width*cfg.Height > h.MaxPixels {
+		return nil, errImageTooLarge
+	}
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errInvalidImage
+	}
+	src := image.NewRGBA(image.Rect(0, 0, cfg.Width, cfg.Height))
+	draw.Draw(src, src.Rect, decoded, decoded.Bounds().Min, draw.Src)
+	orientation := 1
+	if format == "jpeg" {
+		orientation = jpegOrientation(data)
+	}
+	src = orient(src, orientation)
+	ow, oh := src.Rect.Dx(), src.Rect.Dy()
+
+	out := &processedImage{}
+	out.original = encodedImage{contentType: "image/" + format, ext: formatExt[format], width: ow, height: oh}
+	if clean, ok := stripMetadata(data, format); ok && orientation == 1 {
+		out.original.data = clean
+	} else if out.original.data, err = encode(src, format, originalQuality); err != nil {
+		return nil, err
+	}
+
+	// Cada versión se escala desde la más chica ya hecha que la cubra
+	scaled := map[int]*image.RGBA{ow: src}
+	widths := []int{ow}
+	scale := func(width int) *image.RGBA {
+		width = min(width, ow)
+		if m, ok := scaled[width]; ok {
+			return m
+		}
+		from := src
+		for _, sw := range widths {
+			if sw >= width && sw < from.Rect.Dx() {
+				from = scaled[sw]
+			}
+		}
+		height := max(1, (oh*width+ow/2)/ow)
+		m := image.NewRGBA(image.Rect(0, 0, width, height))
+		xdraw.CatmullRom.Scale(m, m.Rect, from, from.Rect, xdraw.Src, nil)
+		scaled[width] = m
+		widths = append(widths, width)
+		return m
+	}
+	byWidth := slices.Clone(h.Renditions)
+	slices.SortStableFunc(byWidth, func(a, b Rendition) int { return b.Width - a.Width })
+	for _, r := range byWidth {
+		scale(r.Width)
+	}
+	opaque := src.Opaque()
+	for _, r := range h.Renditions {
+		m := scale(r.Width)
+		f := r.Format
+		if f == "" {
+			f = "jpeg"
+			if !opaque {
+				f = "png"
+			}
+		}
+		encoded, err := encode(m, f, h.quality())
+		if err != nil {
+			return nil, err
+		}
+		out.renditions = append(out.renditions, encodedImage{data: encoded, contentType: "image/" + f, ext: formatExt[f], width: m.Rect.Dx(), height: m.Rect.Dy()})
+	}
+
+	base := scale(blurhashSize * 4)
+	tiny := image.NewRGBA(image.Rect(0, 0, min(blurhashSize, ow), max(1, min(blurhashSize, ow)*oh/ow)))
+	xdraw.ApproxBiLinear.Scale(tiny, tiny.Rect, base, base.Rect, xdraw.Src, nil)
+	out.blurhash = blurhash(tiny, 4, 3)
+	return out, nil
+}
+
+func (h *Handler) quality() int {
+	if h.Quality > 0 {
+		return h.Quality
+	}
+	return DefaultQuality
+}
+
+// encode codifica m en el formato dado
+func encode(m *image.RGBA, format string, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(&buf, m, &jpeg.Options{Quality: quality})
+	case "png":
+		err = png.Encode(&buf, m)
+	case "webp":
+		n := image.NewNRGBA(m.Rect)
+		draw.Draw(n, n.Rect, m, image.Point{}, draw.Src)
+		err = webp.Encode(&buf, n, &webp.Options{Quality: quality})
+	default:
+		err = fmt.Errorf("unsupported format %q", format)
+	}
+	return buf.Bytes(), err
+}
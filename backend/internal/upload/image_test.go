@@ -0,0 +1,282 @@
+package upload
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+func uploadFile(h *Handler, name string, data []byte) *httptest.ResponseRecorder {
+	var b bytes.Buffer
+	mw := multipart.NewWriter(&b)
+	fw, _ := mw.CreateFormFile("file", name)
+	fw.Write(data)
+	mw.Close()
+	req := httptest.NewRequest(http.MethodPost, "/upload", &b)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	w := httptest.NewRecorder()
+	h.UploadHandler(w, req)
+	return w
+}
+
+// halves es una imagen con la mitad izquierda roja y la derecha azul
+func halves(w, h int, alpha uint8) *image.NRGBA {
+	m := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(m, image.Rect(0, 0, w/2, h), image.NewUniform(color.NRGBA{255, 0, 0, alpha}), image.Point{}, draw.Src)
+	draw.Draw(m, image.Rect(w/2, 0, w, h), image.NewUniform(color.NRGBA{0, 0, 255, alpha}), image.Point{}, draw.Src)
+	return m
+}
+
+// withExif mete un APP1 con la orientación dada justo después del SOI
+func withExif(t *testing.T, m image.Image, orientation byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, m, &jpeg.Options{Quality: 90}); err != nil {
+		t.Fatal(err)
+	}
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08\x00\x01\x01\x12\x00\x03\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00")
+	tiff[19] = orientation
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	payload = append(payload, "GPS 40.4168 -3.7038"...)
+	seg := []byte{0xff, 0xe1, byte((len(payload) + 2) >> 8), byte(len(payload) + 2)}
+	data := buf.Bytes()
+	return append(append(append([]byte{}, data[:2]...), append(seg, payload...)...), data[2:]...)
+}
+
+func isRed(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return r > 0xc000 && g < 0x4000 && b < 0x4000
+}
+
+func isBlue(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return b > 0xc000 && r < 0x4000 && g < 0x4000
+}
+
+func TestUploadHandler_ImageRenditions(t *testing.T) {
+	t.Setenv("MINIO_PUBLIC_URL_BASE", "http://mock")
+	client := &mockMinioClient{objects: map[string]storedObject{}}
+	h := NewHandler(client)
+	data := withExif(t, halves(2000, 1000, 255), 6)
+	if jpegOrientation(data) != 6 {
+		t.Fatalf("orientation not detected")
+	}
+
+	w := uploadFile(h, "foto.jpg", data)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", w.Code, w.Body.String())
+	}
+	var res UploadResult
+	json.NewDecoder(w.Body).Decode(&res)
+	// Orientación 6 gira 90° a la derecha: queda vertical con el rojo arriba
+	if res.Width != 1000 || res.Height != 2000 || res.ContentType != "image/jpeg" || len(res.Blurhash) != 28 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if len(res.Renditions) != len(DefaultRenditions) {
+		t.Fatalf("expected every rendition, got %v", res.Renditions)
+	}
+	thumb := res.Renditions["thumbnail"]
+	if thumb.Width != 320 || thumb.Height != 640 || !strings.HasPrefix(thumb.URL, strings.TrimSuffix(res.URL, ".jpg")+"_thumbnail") {
+		t.Errorf("unexpected thumbnail: %+v (original %s)", thumb, res.URL)
+	}
+	if large := res.Renditions["large"]; large.Width != 1000 || large.Height != 2000 {
+		t.Errorf("renditions must not upscale, got %+v", large)
+	}
+
+	object := func(url string) storedObject {
+		t.Helper()
+		obj, ok := client.objects[strings.TrimPrefix(url, "http://mock/mybucket/")]
+		if !ok {
+			t.Fatalf("%s was not stored", url)
+		}
+		return obj
+	}
+	if orig := object(res.URL); bytes.Contains(orig.data, []byte("Exif")) || bytes.Contains(orig.data, []byte("GPS")) {
+		t.Errorf("the stored original must not carry EXIF")
+	}
+	m, err := jpeg.Decode(bytes.NewReader(object(thumb.URL).data))
+	if err != nil || !isRed(m.At(160, 20)) || !isBlue(m.At(160, 620)) {
+		t.Errorf("thumbnail should be upright (red on top): %v", err)
+	}
+	obj := object(res.Renditions["medium_webp"].URL)
+	m, err = webp.Decode(bytes.NewReader(obj.data))
+	if err != nil || obj.contentType != "image/webp" || m.Bounds().Dx() != 800 || m.Bounds().Dy() != 1600 {
+		t.Fatalf("unexpected webp rendition (%v): %v", err, m.Bounds())
+	}
+	if !isRed(m.At(400, 100)) || !isBlue(m.At(400, 1500)) {
+		t.Errorf("unexpected webp colors: %v / %v", m.At(400, 100), m.At(400, 1500))
+	}
+}
+
+func TestUploadHandler_StripsMetadataLosslessly(t *testing.T) {
+	client := &mockMinioClient{objects: map[string]storedObject{}}
+	h := NewHandler(client)
+	h.Renditions = []Rendition{{Name: "small", Width: 50}}
+	data := withExif(t, halves(100, 40, 255), 1)
+
+	w := uploadFile(h, "foto.jpg", data)
+	var res UploadResult
+	json.NewDecoder(w.Body).Decode(&res)
+	if res.Width != 100 || res.Height != 40 || res.Renditions["small"].Height != 20 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	for name, obj := range client.objects {
+		if strings.HasSuffix(name, "foto.jpg") {
+			if bytes.Contains(obj.data, []byte("Exif")) || !bytes.HasSuffix(data, obj.data[len(obj.data)-200:]) {
+				t.Errorf("the original should keep its image data and lose only the metadata")
+			}
+		}
+	}
+}
+
+func TestUploadHandler_TransparentPNG(t *testing.T) {
+	t.Setenv("MINIO_PUBLIC_URL_BASE", "http://mock")
+	client := &mockMinioClient{objects: map[string]storedObject{}}
+	h := NewHandler(client)
+	h.Renditions = []Rendition{{Name: "thumbnail", Width: 64}, {Name: "thumbnail_webp", Width: 64, Format: "webp"}}
+	var buf bytes.Buffer
+	png.Encode(&buf, halves(128, 64, 128))
+	// Un chunk tEXt antes del IEND
+	text := []byte("\x00\x00\x00\x09tEXtAutor\x00Ana")
+	text = binary.BigEndian.AppendUint32(text, crc32.ChecksumIEEE(text[4:]))
+	data := append(append([]byte{}, buf.Bytes()[:buf.Len()-12]...), append(text, buf.Bytes()[buf.Len()-12:]...)...)
+
+	w := uploadFile(h, "logo.png", data)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %s", w.Code, w.Body.String())
+	}
+	var res UploadResult
+	json.NewDecoder(w.Body).Decode(&res)
+	thumb := client.objects[strings.TrimPrefix(res.Renditions["thumbnail"].URL, "http://mock/mybucket/")]
+	if thumb.contentType != "image/png" || !strings.HasSuffix(res.Renditions["thumbnail"].URL, ".png") {
+		t.Errorf("transparent images keep a png rendition, got %q", thumb.contentType)
+	}
+	webpObj := client.objects[strings.TrimPrefix(res.Renditions["thumbnail_webp"].URL, "http://mock/mybucket/")]
+	m, err := webp.Decode(bytes.NewReader(webpObj.data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, a := m.At(10, 10).RGBA(); a>>8 < 120 || a>>8 > 136 {
+		t.Errorf("webp rendition should keep alpha, got %d", a>>8)
+	}
+	for name, obj := range client.objects {
+		if strings.HasSuffix(name, "logo.png") && bytes.Contains(obj.data, []byte("tEXt")) {
+			t.Errorf("text chunks must be stripped from the original")
+		}
+	}
+}
+
+func TestUploadHandler_ImageValidation(t *testing.T) {
+	h := NewHandler(&mockMinioClient{})
+	broken := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{1}, 64)...)
+	if w := uploadFile(h, "roto.png", broken); w.Code != http.StatusBadRequest {
+		t.Errorf("undecodable image: expected 400, got %d", w.Code)
+	}
+	var buf bytes.Buffer
+	png.Encode(&buf, halves(200, 200, 255))
+	h.MaxPixels = 100 * 100
+	if w := uploadFile(h, "grande.png", buf.Bytes()); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("too many pixels: expected 413, got %d", w.Code)
+	}
+	h.MaxBytes = 10
+	if w := uploadFile(h, "doc.txt", []byte("más de diez bytes")); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("too many bytes: expected 413, got %d", w.Code)
+	}
+}
+
+func TestBlurhash(t *testing.T) {
+	m := image.NewRGBA(image.Rect(0, 0, 16, 12))
+	draw.Draw(m, m.Rect, image.NewUniform(color.RGBA{255, 0, 0, 255}), image.Point{}, draw.Src)
+	// 'L' son 4x3 componentes y "TI:j" el color medio #ff0000
+	if got := blurhash(m, 4, 3); len(got) != 28 || got[0] != 'L' || got[2:6] != "TI:j" {
+		t.Errorf("solid red: got %s", got)
+	}
+	if got := blurhash(image.NewRGBA(image.Rect(0, 0, 8, 8)), 1, 1); got != "000000" {
+		t.Errorf("1x1 components of black: got %s", got)
+	}
+}
+
+func TestParseRenditions(t *testing.T) {
+	got, err := ParseRenditions("thumb:200, hero:1200", true)
+	if err != nil || len(got) != 4 || got[1] != (Rendition{Name: "hero", Width: 1200}) || got[3] != (Rendition{Name: "hero_webp", Width: 1200, Format: "webp"}) {
+		t.Errorf("unexpected renditions: %+v %v", got, err)
+	}
+	for _, spec := range []string{"thumb", "thumb:0", ":100", "thumb:abc"} {
+		if _, err := ParseRenditions(spec, false); err == nil {
+			t.Errorf("%q: expected error", spec)
+		}
+	}
+}
+
+func TestOrient(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	src.Set(0, 0, color.RGBA{255, 0, 0, 255}) // esquina superior izquierda
+	cases := map[int]image.Point{1: {0, 0}, 2: {2, 0}, 3: {2, 1}, 4: {0, 1}, 5: {0, 0}, 6: {1, 0}, 7: {1, 2}, 8: {0, 2}}
+	for o, want := range cases {
+		m := orient(src, o)
+		if o >= 5 && m.Rect.Dx() != 2 {
+			t.Errorf("orientation %d must swap dimensions", o)
+		}
+		if !isRed(m.At(want.X, want.Y)) {
+			t.Errorf("orientation %d: corner expected at %v", o, want)
+		}
+	}
+}
+
+func TestUploadHandler_OnUpload(t *testing.T) {
+	h := NewHandler(&mockMinioClient{})
+	h.Renditions = []Rendition{{Name: "thumbnail", Width: 50}}
+	var got []*Upload
+	h.OnUpload = append(h.OnUpload, func(r *http.Request, u *Upload) error {
+		got = append(got, u)
+		u.Result.ID = "asset-1"
+		return nil
+	})
+	var buf bytes.Buffer
+	png.Encode(&buf, halves(100, 40, 255))
+
+	w := uploadFile(h, "logo.png", buf.Bytes())
+	var res UploadResult
+	json.NewDecoder(w.Body).Decode(&res)
+	if res.ID != "asset-1" || len(got) != 1 {
+		t.Fatalf("the hook can complete the response: %+v", res)
+	}
+	if u := got[0]; len(u.Objects) != 2 || !strings.HasSuffix(u.Objects[1], "_logo_thumbnail.jpg") || u.Filename != "logo.png" || u.Size == 0 {
+		t.Errorf("unexpected upload: %+v", u)
+	}
+	w = uploadFile(h, "notas.txt", []byte("texto plano"))
+	if json.NewDecoder(w.Body).Decode(&res); res.ContentType != "text/plain; charset=utf-8" || len(got) != 2 {
+		t.Errorf("other files run the hooks too: %+v", res)
+	}
+
+	var b bytes.Buffer
+	mw := multipart.NewWriter(&b)
+	fw, _ := mw.CreateFormFile("file", "yo.png")
+	fw.Write(buf.Bytes())
+	mw.Close()
+	req := httptest.NewRequest(http.MethodPost, "/upload/profile-image", &b)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	h.UploadProfileImageHandler(httptest.NewRecorder(), req)
+	if len(got) != 2 {
+		t.Errorf("profile pictures don't go to the library")
+	}
+
+	h.OnUpload = append(h.OnUpload, func(*http.Request, *Upload) error { return errors.New("boom") })
+	if w := uploadFile(h, "notas.txt", []byte("texto plano")); w.Code != http.StatusInternalServerError {
+		t.Errorf("a failing hook: expected 500, got %d", w.Code)
+	}
+}
@@ -0,0 +1,208 @@
+package upload
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+)
+
+// 🧽 Metadatos de las imágenes subidas. Las fotos del teléfono traen EXIF con
+// GPS, modelo y fecha: se quitan de la original sin recomprimirla cuando se
+// puede, y la orientación EXIF (sólo JPEG) se aplica a los píxeles.
+
+// jpegOrientation devuelve la orientación EXIF (1 a 8) de un JPEG; 1 si no tiene
+func jpegOrientation(data []byte) int {
+	orientation := 1
+	jpegSegments(data, func(marker byte, payload []byte) {
+		if tiff, ok := bytes.CutPrefix(payload, []byte("Exif\x00\x00")); ok && marker == 0xe1 {
+			orientation = exifOrientation(tiff)
+		}
+	})
+	return orientation
+}
+
+// jpegSegments recorre los segmentos previos a los datos de imagen; devuelve
+// el índice donde empieza el SOS o -1 si el archivo no se puede recorrer
+func jpegSegments(data []byte, fn func(marker byte, payload []byte)) int {
+	if len(data) < 4 || data[0] != 0xff || data[1] != 0xd8 {
+		return -1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xff {
+			return -1
+		}
+		marker := data[i+1]
+		if marker == 0xff { // relleno
+			i++
+			continue
+		}
+		if marker == 0xda {
+			return i
+		}
+		n := int(binary.BigEndian.Uint16(data[i+2:]))
+		if n < 2 || i+2+n > len(data) {
+			return -1
+		}
+		fn(marker, data[i+4:i+2+n])
+		i += 2 + n
+	}
+	return -1
+}
+
+// exifOrientation lee la etiqueta Orientation (0x0112) del IFD0 de un bloque TIFF
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < entries; i++ {
+		e := ifd + 2 + 12*i
+		if e+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[e:]) == 0x0112 {
+			if v := int(order.Uint16(tiff[e+8:])); v >= 1 && v <= 8 {
+				return v
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// orient aplica una orientación EXIF y devuelve la imagen derecha
+func orient(m *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 || orientation > 8 {
+		return m
+	}
+	w, h := m.Rect.Dx(), m.Rect.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	out := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // espejo horizontal
+				sx, sy = w-1-x, y
+			case 3: // 180°
+				sx, sy = w-1-x, h-1-y
+			case 4: // espejo vertical
+				sx, sy = x, h-1-y
+			case 5: // transpuesta
+				sx, sy = y, x
+			case 6: // 90° horaria
+				sx, sy = y, h-1-x
+			case 7: // transversa
+				sx, sy = w-1-y, h-1-x
+			case 8: // 90° antihoraria
+				sx, sy = w-1-y, x
+			}
+			copy(out.Pix[y*out.Stride+x*4:y*out.Stride+x*4+4], m.Pix[sy*m.Stride+sx*4:])
+		}
+	}
+	return out
+}
+
+// stripMetadata quita EXIF, XMP, IPTC y comentarios sin tocar los píxeles;
+// false si el formato no se pudo recorrer. GIF no lleva EXIF y queda igual.
+func stripMetadata(data []byte, format string) ([]byte, bool) {
+	switch format {
+	case "jpeg":
+		return stripJPEG(data)
+	case "png":
+		return stripPNG(data)
+	case "webp":
+		return stripWebP(data)
+	}
+	return data, true
+}
+
+// stripJPEG descarta APP1 (EXIF/XMP), APP13 (IPTC) y COM; conserva ICC y JFIF
+func stripJPEG(data []byte) ([]byte, bool) {
+	out := []byte{0xff, 0xd8}
+	sos := jpegSegments(data, func(marker byte, payload []byte) {
+		if marker == 0xe1 || marker == 0xed || marker == 0xfe {
+			return
+		}
+		out = append(out, 0xff, marker, 0, 0)
+		binary.BigEndian.PutUint16(out[len(out)-2:], uint16(len(payload)+2))
+		out = append(out, payload...)
+	})
+	if sos < 0 {
+		return nil, false
+	}
+	return append(out, data[sos:]...), true
+}
+
+// pngMetadata son los chunks de texto, EXIF y fecha que no hacen falta para dibujar
+var pngMetadata = map[string]bool{"tEXt": true, "zTXt": true, "iTXt": true, "eXIf": true, "tIME": true}
+
+func stripPNG(data []byte) ([]byte, bool) {
+	const sig = "\x89PNG\r\n\x1a\n"
+	if !bytes.HasPrefix(data, []byte(sig)) {
+		return nil, false
+	}
+	out := []byte(sig)
+	for i := len(sig); i+12 <= len(data); {
+		n := int(binary.BigEndian.Uint32(data[i:]))
+		end := i + 12 + n
+		if n < 0 || end > len(data) {
+			return nil, false
+		}
+		kind := string(data[i+4 : i+8])
+		if !pngMetadata[kind] {
+			out = append(out, data[i:end]...)
+		}
+		if kind == "IEND" {
+			return out, true
+		}
+		i = end
+	}
+	return nil, false
+}
+
+// stripWebP quita los chunks EXIF y XMP y sus marcas en VP8X
+func stripWebP(data []byte) ([]byte, bool) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, false
+	}
+	out := append([]byte(nil), data[:12]...)
+	for i := 12; i+8 <= len(data); {
+		n := int(binary.LittleEndian.Uint32(data[i+4:]))
+		end := i + 8 + n + n%2
+		if n < 0 || i+8+n > len(data) {
+			return nil, false
+		}
+		end = min(end, len(data))
+		switch string(data[i : i+4]) {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			c := append([]byte(nil), data[i:end]...)
+			if len(c) > 8 {
+				c[8] &^= 0x08 | 0x04
+			}
+			out = append(out, c...)
+		default:
+			out = append(out, data[i:end]...)
+		}
+		i = end
+	}
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out, true
+}
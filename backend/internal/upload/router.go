@@ -1,8 +1,12 @@
 package upload
 
-import "net/http"
+import (
+	"net/http"
+	"pittsix/pkg/middleware"
+)
 
+// Las subidas piden sesión: la biblioteca de medios registra quién sube y de qué organización
 func RegisterHandlers(mux *http.ServeMux, h *Handler) {
-	mux.HandleFunc("POST /upload", h.UploadHandler)
-	mux.HandleFunc("POST /upload/profile-image", h.UploadProfileImageHandler)
+	mux.Handle("POST /upload", middleware.JWTAuth(http.HandlerFunc(h.UploadHandler)))
+	mux.Handle("POST /upload/profile-image", middleware.JWTAuth(http.HandlerFunc(h.UploadProfileImageHandler)))
 }
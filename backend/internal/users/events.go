@@ -0,0 +1,51 @@
+package users
+
+import (
+	"context"
+
+	"pittsix/pkg/events"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UserSummary es lo que viaja en los eventos de usuarios, sin datos sensibles
+type UserSummary struct {
+	ID        primitive.ObjectID `json:"id"`
+	Email     string             `json:"email"`
+	FirstName string             `json:"first_name"`
+	LastName  string             `json:"last_name"`
+	Roles     []string           `json:"roles"`
+}
+
+// PublishUser publica un evento del usuario; lo ven él mismo y los admins de su organización
+func PublishUser(ctx context.Context, bus *events.Bus, kind string, u *User) {
+	if bus == nil || u == nil {
+		return
+	}
+	actor, _ := ctx.Value("user_id").(string)
+	bus.Publish(events.Event{
+		Type:           kind,
+		OrganizationID: hexOrEmpty(u.OrganizationID),
+		ActorID:        actor,
+		SubjectID:      u.ID.Hex(),
+		Audience:       events.Audience{UserIDs: []string{u.ID.Hex()}},
+		Data:           UserSummary{ID: u.ID, Email: u.Email, FirstName: u.FirstName, LastName: u.LastName, Roles: u.Roles},
+	})
+}
+
+// publishUpdated relee el usuario y publica user.updated
+func (h *Handlers) publishUpdated(ctx context.Context, id primitive.ObjectID) {
+	if h.Events == nil {
+		return
+	}
+	if u, err := h.Repo.GetUserByID(id); err == nil {
+		PublishUser(ctx, h.Events, events.UserUpdated, u)
+	}
+}
+
+func hexOrEmpty(id primitive.ObjectID) string {
+	if id.IsZero() {
+		return ""
+	}
+	return id.Hex()
+}
@@ -1,18 +1,67 @@
 package users
 
 import (
+	"context"
 	"encoding/json"
+	"log"
 	"net/http"
 	"regexp"
 	"time"
 
 	"strings"
 
+	"pittsix/pkg/events"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type Handlers struct {
 	Repo Repository
+	// OnDelete corre antes de borrar un usuario, p. ej. para reasignar sus artículos;
+	// si alguno falla el usuario no se borra
+	OnDelete []DeleteHook
+	// OnProfileChange corre después de cambiar nombre, bio o foto de un usuario
+	OnProfileChange []ProfileHook
+	// Events recibe las altas, bajas y cambios de usuarios (nil: no se publican)
+	Events *events.Bus
+}
+
+// DeleteHook recibe el usuario que se va a borrar y, si se indicó con
+// ?transfer_to=, a quién pasarle lo suyo (si no, NilObjectID)
+type DeleteHook func(ctx context.Context, deleted *User, successor primitive.ObjectID) error
+
+// ProfileHook recibe el usuario ya actualizado, p. ej. para copiar su firma en sus artículos
+type ProfileHook func(ctx context.Context, user *User) error
+
+// profileFields son los datos públicos que otros módulos copian del usuario
+var profileFields = []string{"first_name", "last_name", "bio", "profile_image"}
+
+// TouchesProfile indica si la actualización cambia datos públicos del perfil
+func TouchesProfile(update map[string]interface{}) bool {
+	for _, f := range profileFields {
+		if _, ok := update[f]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// NotifyProfileChange relee el usuario y avisa a los hooks. Los errores solo se
+// registran: el perfil ya se guardó y la copia se corrige en el próximo cambio
+// o al reiniciar.
+func NotifyProfileChange(ctx context.Context, repo Repository, id primitive.ObjectID, hooks []ProfileHook) {
+	if len(hooks) == 0 {
+		return
+	}
+	user, err := repo.GetUserByID(id)
+	if err != nil || user == nil {
+		return
+	}
+	for _, hook := range hooks {
+		if err := hook(ctx, user); err != nil {
+			log.Printf("⚠️ Error propagando el perfil de %s: %v", id.Hex(), err)
+		}
+	}
 }
 
 var validRoles = map[string]bool{"user": true, "org_admin": true, "superadmin": true}
@@ -81,6 +130,7 @@ func (h *Handlers) CreateUser(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "DB error", http.StatusInternalServerError)
 		return
 	}
+	PublishUser(r.Context(), h.Events, events.UserCreated, &input)
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(input)
 }
@@ -108,6 +158,10 @@ func (h *Handlers) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "DB error", http.StatusInternalServerError)
 		return
 	}
+	if TouchesProfile(update) {
+		NotifyProfileChange(r.Context(), h.Repo, id, h.OnProfileChange)
+	}
+	h.publishUpdated(r.Context(), id)
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"status": "updated"})
 }
@@ -126,10 +180,32 @@ func (h *Handlers) DeleteUser(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "User not found", http.StatusNotFound)
 		return
 	}
+	// Sucesor opcional: DELETE /users/{id}?transfer_to={otro}, de la misma organización
+	successor := primitive.NilObjectID
+	if s := r.URL.Query().Get("transfer_to"); s != "" {
+		successor, err = primitive.ObjectIDFromHex(s)
+		if err != nil || successor == id {
+			http.Error(w, "Invalid transfer_to", http.StatusBadRequest)
+			return
+		}
+		heir, _ := h.Repo.GetUserByID(successor)
+		if heir == nil || heir.OrganizationID != user.OrganizationID {
+			http.Error(w, "transfer_to must be a user of the same organization", http.StatusBadRequest)
+			return
+		}
+	}
+	for _, hook := range h.OnDelete {
+		if err := hook(r.Context(), user, successor); err != nil {
+			log.Printf("❌ Error reasignando lo del usuario %s: %v", id.Hex(), err)
+			http.Error(w, "Could not reassign the user's content", http.StatusInternalServerError)
+			return
+		}
+	}
 	if err := h.Repo.DeleteUser(id); err != nil {
 		http.Error(w, "DB error", http.StatusInternalServerError)
 		return
 	}
+	PublishUser(r.Context(), h.Events, events.UserDeleted, user)
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"status": "deleted"})
 }
@@ -155,6 +231,7 @@ func (h *Handlers) UpdateUserRoles(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "DB error", http.StatusInternalServerError)
 		return
 	}
+	h.publishUpdated(r.Context(), id)
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"status": "roles updated"})
 }
@@ -197,6 +274,10 @@ func (h *Handlers) UpdateMe(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "DB error", http.StatusInternalServerError)
 		return
 	}
+	if TouchesProfile(fields) {
+		NotifyProfileChange(r.Context(), h.Repo, userID, h.OnProfileChange)
+	}
+	h.publishUpdated(r.Context(), userID)
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"status": "updated"})
 }
@@ -233,6 +314,7 @@ func (h *Handlers) InviteUser(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "DB error", http.StatusInternalServerError)
 		return
 	}
+	PublishUser(r.Context(), h.Events, events.UserCreated, user)
 	// Generar token de invitación (stub, puedes usar JWT u otro método)
 	token := "invite-token-stub-" + user.ID.Hex()
 	w.WriteHeader(http.StatusCreated)
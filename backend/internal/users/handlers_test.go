@@ -8,3 +8,12 @@ func TestHandlers_CreateUser(t *testing.T)      {}
 func TestHandlers_UpdateUser(t *testing.T)      {}
 func TestHandlers_DeleteUser(t *testing.T)      {}
 func TestHandlers_UpdateUserRoles(t *testing.T) {}
+
+func TestTouchesProfile(t *testing.T) {
+	if !TouchesProfile(map[string]interface{}{"bio": "", "email": "a@b.com"}) {
+		t.Error("bio is part of the public profile")
+	}
+	if TouchesProfile(map[string]interface{}{"reset_token": "x"}) {
+		t.Error("reset tokens are not part of the public profile")
+	}
+}
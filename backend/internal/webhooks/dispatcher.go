@@ -0,0 +1,322 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"pittsix/pkg/events"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultInterval     = 5 * time.Second
+	defaultTimeout      = 10 * time.Second
+	defaultMaxAttempts  = 8
+	defaultBaseDelay    = 30 * time.Second
+	defaultMaxDelay     = 6 * time.Hour
+	defaultDisableAfter = 20
+	// claimBatch limita cuántas entregas se procesan por vuelta
+	claimBatch = 100
+	// drainLimit es cuánto de la respuesta se lee para reusar la conexión
+	drainLimit = 4096
+)
+
+// 📮 Dispatcher convierte los eventos del bus en entregas encoladas y las
+// envía. La cola vive en el Repository, así que lo pendiente sobrevive a un
+// reinicio y varias réplicas pueden enviar sin pisarse.
+type Dispatcher struct {
+	Repo Repository
+	// Client no conecta a direcciones internas (ver guard.go)
+	Client *http.Client
+	Now    func() time.Time
+	// Interval es cada cuánto se revisa la cola
+	Interval time.Duration
+	// MaxAttempts es cuántas veces se intenta una entrega antes de darla por fallida
+	MaxAttempts int
+	// BaseDelay y MaxDelay acotan el backoff exponencial entre intentos
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// DisableAfter es cuántos intentos fallidos seguidos apagan el webhook (0: nunca)
+	DisableAfter int
+	// wake despierta a Run cuando se encola algo, sin esperar al ticker
+	wake chan struct{}
+}
+
+func NewDispatcher(repo Repository) *Dispatcher {
+	return &Dispatcher{
+		Repo:         repo,
+		Client:       newClient(),
+		Now:          time.Now,
+		Interval:     defaultInterval,
+		MaxAttempts:  defaultMaxAttempts,
+		BaseDelay:    defaultBaseDelay,
+		MaxDelay:     defaultMaxDelay,
+		DisableAfter: defaultDisableAfter,
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// Listen encola cada evento del bus para los webhooks de su organización hasta
+// que se cancele el contexto. Si el bus corta la suscripción por lentitud se
+// vuelve a suscribir desde el último evento visto.
+func (d *Dispatcher) Listen(ctx context.Context, bus *events.Bus) {
+	var lastID uint64
+	for ctx.Err() == nil {
+		sub, replay, complete := bus.Subscribe(lastID, func(e events.Event) bool {
+			return e.OrganizationID != "" && e.Type != events.Reset
+		})
+		if !complete {
+			log.Printf("⚠️ Webhooks: se perdieron eventos posteriores a %d", lastID)
+		}
+		for _, e := range replay {
+			d.enqueueEvent(ctx, e)
+			lastID = e.ID
+		}
+		lastID = d.drain(ctx, sub, lastID)
+		sub.Close()
+	}
+}
+
+// drain consume la suscripción hasta que se cierre o se cancele el contexto
+func (d *Dispatcher) drain(ctx context.Context, sub *events.Subscription, lastID uint64) uint64 {
+	for {
+		select {
+		case <-ctx.Done():
+			return lastID
+		case e, ok := <-sub.C:
+			if !ok {
+				return lastID
+			}
+			d.enqueueEvent(ctx, e)
+			lastID = e.ID
+		}
+	}
+}
+
+func (d *Dispatcher) enqueueEvent(ctx context.Context, e events.Event) {
+	orgID, err := primitive.ObjectIDFromHex(e.OrganizationID)
+	if err != nil {
+		return
+	}
+	if _, err := d.Enqueue(ctx, orgID, e); err != nil {
+		log.Printf("❌ Webhooks: no se pudo encolar %s: %v", e.Type, err)
+	}
+}
+
+// Enqueue crea una entrega pendiente por cada webhook activo de la organización
+// suscripto al tipo del evento
+func (d *Dispatcher) Enqueue(ctx context.Context, orgID primitive.ObjectID, e events.Event) (int, error) {
+	hooks, err := d.Repo.ListWebhooks(ctx, orgID, true)
+	if err != nil {
+		return 0, err
+	}
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for i := range hooks {
+		if !hooks[i].Subscribed(e.Type) {
+			continue
+		}
+		if err := d.Repo.EnqueueDelivery(ctx, d.newDelivery(&hooks[i], e.ID, e.Type, string(payload))); err != nil {
+			return n, err
+		}
+		n++
+	}
+	if n > 0 {
+		d.Wake()
+	}
+	return n, nil
+}
+
+func (d *Dispatcher) newDelivery(w *Webhook, eventID uint64, eventType, payload string) *Delivery {
+	now := d.Now()
+	return &Delivery{
+		WebhookID:      w.ID,
+		OrganizationID: w.OrganizationID,
+		EventID:        eventID,
+		EventType:      eventType,
+		Payload:        payload,
+		Status:         DeliveryPending,
+		NextAttemptAt:  now,
+		Attempts:       []Attempt{},
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// Wake pide una vuelta de Run sin esperar al próximo tick
+func (d *Dispatcher) Wake() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run envía las entregas vencidas cada Interval hasta que se cancele el contexto
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+	log.Printf("📮 Dispatcher de webhooks activo (cada %s)", d.Interval)
+	for {
+		if _, err := d.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("❌ Error en webhooks: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			log.Println("📮 Dispatcher de webhooks detenido")
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// RunOnce intenta las entregas vencidas y devuelve cuántas procesó
+func (d *Dispatcher) RunOnce(ctx context.Context) (int, error) {
+	n := 0
+	for n < claimBatch && ctx.Err() == nil {
+		now := d.Now()
+		// El lease cubre el timeout del envío; si la réplica muere a mitad, la
+		// entrega vuelve a estar disponible al vencer
+		delivery, err := d.Repo.ClaimDelivery(ctx, now, now.Add(2*d.Client.Timeout+time.Minute))
+		if err == ErrNotFound {
+			break
+		}
+		if err != nil {
+			return n, err
+		}
+		if err := d.attempt(ctx, delivery); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// attempt hace un intento de la entrega y deja anotado el resultado
+func (d *Dispatcher) attempt(ctx context.Context, delivery *Delivery) error {
+	hook, err := d.Repo.GetWebhook(ctx, delivery.OrganizationID, delivery.WebhookID)
+	if err == ErrNotFound {
+		hook = nil
+	} else if err != nil {
+		return err
+	}
+	now := d.Now()
+	var result Attempt
+	switch {
+	case hook == nil:
+		result = Attempt{At: now, Error: "webhook deleted"}
+	case !hook.Active:
+		result = Attempt{At: now, Error: "webhook disabled"}
+	default:
+		result = d.send(ctx, hook, delivery)
+	}
+	ok := result.Error == "" && result.StatusCode >= 200 && result.StatusCode < 300
+
+	delivery.Attempts = append(delivery.Attempts, result)
+	delivery.UpdatedAt = now
+	switch {
+	case ok:
+		delivery.Status = DeliverySucceeded
+	case hook == nil || !hook.Active || len(delivery.Attempts) >= d.MaxAttempts:
+		delivery.Status = DeliveryFailed
+	default:
+		delivery.NextAttemptAt = now.Add(d.backoff(len(delivery.Attempts)))
+	}
+	if err := d.Repo.SaveDelivery(ctx, delivery); err != nil && err != ErrNotFound {
+		return err
+	}
+
+	if hook == nil || !hook.Active {
+		return nil
+	}
+	updated, err := d.Repo.RecordResult(ctx, hook.ID, ok, d.DisableAfter, now)
+	if err != nil && err != ErrNotFound {
+		return err
+	}
+	if updated != nil && !updated.Active {
+		log.Printf("🪝 Webhook %s desactivado tras %d fallos seguidos", hook.ID.Hex(), updated.ConsecutiveFailures)
+	}
+	return nil
+}
+
+// send hace el POST firmado
+func (d *Dispatcher) send(ctx context.Context, hook *Webhook, delivery *Delivery) Attempt {
+	started := d.Now()
+	result := Attempt{At: started}
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "pittsix-webhooks/1")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, delivery.ID.Hex())
+	req.Header.Set(SignatureHeader, Sign(hook.Secret, started, body))
+
+	res, err := d.Client.Do(req)
+	result.DurationMs = time.Since(started).Milliseconds()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, drainLimit))
+	result.StatusCode = res.StatusCode
+	return result
+}
+
+// backoff es la espera antes del intento siguiente: BaseDelay, 2×, 4×... hasta MaxDelay
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.BaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= d.MaxDelay {
+			return d.MaxDelay
+		}
+	}
+	return delay
+}
+
+// Redeliver encola de nuevo el mismo cuerpo de una entrega, para ya
+func (d *Dispatcher) Redeliver(ctx context.Context, hook *Webhook, original *Delivery) (*Delivery, error) {
+	again := d.newDelivery(hook, original.EventID, original.EventType, original.Payload)
+	id := original.ID
+	again.RedeliveryOf = &id
+	if err := d.Repo.EnqueueDelivery(ctx, again); err != nil {
+		return nil, err
+	}
+	d.Wake()
+	return again, nil
+}
+
+// SendPing encola un evento de prueba para el webhook
+func (d *Dispatcher) SendPing(ctx context.Context, hook *Webhook, actorID string) (*Delivery, error) {
+	payload, err := json.Marshal(events.Event{
+		Type:           Ping,
+		OrganizationID: hook.OrganizationID.Hex(),
+		ActorID:        actorID,
+		SubjectID:      hook.ID.Hex(),
+		Time:           d.Now(),
+		Data:           map[string]string{"message": "pong"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	delivery := d.newDelivery(hook, 0, Ping, string(payload))
+	if err := d.Repo.EnqueueDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+	d.Wake()
+	return delivery, nil
+}
@@ -0,0 +1,237 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"pittsix/pkg/events"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// receiver es un endpoint local que responde status y guarda lo recibido
+type receiver struct {
+	mu     sync.Mutex
+	status int
+	bodies []string
+	heads  []http.Header
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.bodies = append(rc.bodies, string(body))
+	rc.heads = append(rc.heads, r.Header.Clone())
+	w.WriteHeader(rc.status)
+	io.WriteString(w, "recibido")
+}
+
+type testClock struct{ now time.Time }
+
+func (c *testClock) Now() time.Time { return c.now }
+
+func newTestDispatcher() (*Dispatcher, *testClock) {
+	clock := &testClock{now: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)}
+	d := NewDispatcher(NewMemoryRepository())
+	d.Now = clock.Now
+	// Los receptores de prueba escuchan en loopback, que el cliente real rechaza
+	d.Client = &http.Client{Timeout: defaultTimeout}
+	return d, clock
+}
+
+func addHook(t *testing.T, d *Dispatcher, orgID primitive.ObjectID, url string, evs ...string) *Webhook {
+	t.Helper()
+	hook := &Webhook{OrganizationID: orgID, URL: url, Secret: "s3cr3t", Events: evs, Active: true}
+	if err := d.Repo.CreateWebhook(context.Background(), hook); err != nil {
+		t.Fatal(err)
+	}
+	return hook
+}
+
+func TestDispatcher_DeliversSignedEvents(t *testing.T) {
+	rc := &receiver{status: http.StatusOK}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+	d, clock := newTestDispatcher()
+	ctx := context.Background()
+	orgA, orgB := primitive.NewObjectID(), primitive.NewObjectID()
+	hook := addHook(t, d, orgA, srv.URL, "article")
+	addHook(t, d, orgA, srv.URL, events.UserDeleted)
+	addHook(t, d, orgB, srv.URL)
+
+	n, err := d.Enqueue(ctx, orgA, events.Event{ID: 7, Type: events.ArticleCreated, OrganizationID: orgA.Hex(), Data: map[string]string{"title": "Hola"}})
+	if err != nil || n != 1 {
+		t.Fatalf("only the article subscriber of org A gets it: %d %v", n, err)
+	}
+	clock.now = time.Now()
+	if sent, err := d.RunOnce(ctx); err != nil || sent != 1 {
+		t.Fatalf("run: %d %v", sent, err)
+	}
+	if len(rc.bodies) != 1 {
+		t.Fatalf("expected one request, got %d", len(rc.bodies))
+	}
+	h := rc.heads[0]
+	if h.Get(EventHeader) != events.ArticleCreated || h.Get(DeliveryHeader) == "" {
+		t.Errorf("unexpected headers: %v", h)
+	}
+	if err := Verify("s3cr3t", h.Get(SignatureHeader), []byte(rc.bodies[0]), 5*time.Minute, time.Now()); err != nil {
+		t.Errorf("signature must verify with the webhook secret: %v", err)
+	}
+	if Verify("otro", h.Get(SignatureHeader), []byte(rc.bodies[0]), 0, time.Now()) == nil {
+		t.Error("a different secret must not verify")
+	}
+	if Verify("s3cr3t", h.Get(SignatureHeader), []byte(rc.bodies[0]), time.Minute, time.Now().Add(time.Hour)) == nil {
+		t.Error("old signatures must be rejected")
+	}
+
+	log, _ := d.Repo.ListDeliveries(ctx, orgA, hook.ID, "", 0)
+	if len(log) != 1 || log[0].Status != DeliverySucceeded || log[0].LastAttempt().StatusCode != 200 {
+		t.Errorf("delivery log: %+v", log)
+	}
+	if log[0].EventID != 7 || log[0].Payload != rc.bodies[0] {
+		t.Errorf("the stored payload is what was sent: %+v", log[0])
+	}
+}
+
+func TestDispatcher_RetriesWithBackoffAndDisables(t *testing.T) {
+	rc := &receiver{status: http.StatusInternalServerError}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+	d, clock := newTestDispatcher()
+	d.MaxAttempts = 3
+	d.DisableAfter = 4
+	ctx := context.Background()
+	org := primitive.NewObjectID()
+	hook := addHook(t, d, org, srv.URL)
+
+	d.Enqueue(ctx, org, events.Event{ID: 1, Type: events.ArticleUpdated})
+	start := clock.now
+	d.RunOnce(ctx)
+	pending, _ := d.Repo.ListDeliveries(ctx, org, hook.ID, DeliveryPending, 0)
+	if len(pending) != 1 || !pending[0].NextAttemptAt.Equal(start.Add(30*time.Second)) {
+		t.Fatalf("first retry after BaseDelay: %+v", pending)
+	}
+	// Antes de que venza no se reintenta
+	if sent, _ := d.RunOnce(ctx); sent != 0 {
+		t.Errorf("retried too early")
+	}
+	clock.now = start.Add(30 * time.Second)
+	d.RunOnce(ctx)
+	pending, _ = d.Repo.ListDeliveries(ctx, org, hook.ID, DeliveryPending, 0)
+	if len(pending) != 1 || !pending[0].NextAttemptAt.Equal(clock.now.Add(time.Minute)) {
+		t.Fatalf("second retry doubles the wait: %+v", pending)
+	}
+	clock.now = clock.now.Add(time.Minute)
+	d.RunOnce(ctx)
+	failed, _ := d.Repo.ListDeliveries(ctx, org, hook.ID, DeliveryFailed, 0)
+	if len(failed) != 1 || len(failed[0].Attempts) != 3 || failed[0].LastAttempt().StatusCode != 500 {
+		t.Fatalf("gives up after MaxAttempts: %+v", failed)
+	}
+
+	// Un fallo más y el webhook se apaga
+	d.Enqueue(ctx, org, events.Event{ID: 2, Type: events.ArticleUpdated})
+	d.RunOnce(ctx)
+	got, _ := d.Repo.GetWebhook(ctx, org, hook.ID)
+	if got.Active || got.DisabledAt == nil || got.ConsecutiveFailures != 4 {
+		t.Fatalf("webhook should be disabled: %+v", got)
+	}
+	// Lo pendiente de un webhook apagado se da por fallido sin llamar
+	calls := len(rc.bodies)
+	clock.now = clock.now.Add(time.Hour)
+	d.RunOnce(ctx)
+	if len(rc.bodies) != calls {
+		t.Error("disabled webhooks receive nothing")
+	}
+	if n, _ := d.Enqueue(ctx, org, events.Event{ID: 3, Type: events.ArticleUpdated}); n != 0 {
+		t.Error("disabled webhooks are not subscribed")
+	}
+}
+
+func TestDispatcher_Backoff(t *testing.T) {
+	d := NewDispatcher(NewMemoryRepository())
+	d.BaseDelay, d.MaxDelay = time.Second, 10*time.Second
+	for attempts, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 4: 8 * time.Second, 5: 10 * time.Second, 30: 10 * time.Second} {
+		if got := d.backoff(attempts); got != want {
+			t.Errorf("backoff(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
+
+func TestDispatcher_ListenEnqueuesFromBus(t *testing.T) {
+	d, _ := newTestDispatcher()
+	org := primitive.NewObjectID()
+	hook := addHook(t, d, org, "http://example.invalid/hook")
+	bus := events.NewBus(0)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		d.Listen(ctx, bus)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		bus.Publish(events.Event{Type: events.ArticleDeleted, OrganizationID: org.Hex()})
+		log, _ := d.Repo.ListDeliveries(ctx, org, hook.ID, "", 0)
+		if len(log) > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("events published on the bus must be queued")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+}
+
+func TestDispatcher_RefusesInternalTargets(t *testing.T) {
+	rc := &receiver{status: http.StatusOK}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+	d, clock := newTestDispatcher()
+	d.Client = NewDispatcher(d.Repo).Client
+	ctx := context.Background()
+	org := primitive.NewObjectID()
+	// Guardado sin pasar por validateURL, como si el nombre resolviera a loopback
+	hook := addHook(t, d, org, srv.URL)
+
+	d.Enqueue(ctx, org, events.Event{ID: 1, Type: events.ArticleCreated, OrganizationID: org.Hex()})
+	clock.now = time.Now()
+	d.RunOnce(ctx)
+	if len(rc.bodies) != 0 {
+		t.Fatalf("nothing must reach a loopback address, got %d requests", len(rc.bodies))
+	}
+	log, _ := d.Repo.ListDeliveries(ctx, org, hook.ID, "", 0)
+	if len(log) != 1 || log[0].LastAttempt().StatusCode != 0 || !strings.Contains(log[0].LastAttempt().Error, errPrivateAddress.Error()) {
+		t.Errorf("the attempt must fail without a response: %+v", log)
+	}
+}
+
+func TestValidateURL_RejectsInternalHosts(t *testing.T) {
+	for _, raw := range []string{
+		"http://127.0.0.1/hook", "http://localhost:8080/", "https://169.254.169.254/latest/meta-data",
+		"http://10.0.0.5/", "http://[::1]/", "http://[::ffff:192.168.1.1]/", "http://api.svc.cluster.local/",
+		"http://0.0.0.0/", "http://100.64.1.1/",
+	} {
+		if err := validateURL(raw); err != errPrivateAddress {
+			t.Errorf("%s: expected errPrivateAddress, got %v", raw, err)
+		}
+	}
+	if err := validateURL("https://hooks.example.com/pittsix"); err != nil {
+		t.Errorf("public hosts are allowed: %v", err)
+	}
+	if err := dialPublic("tcp", "93.184.216.34:443", nil); err != nil {
+		t.Errorf("public addresses can be dialed: %v", err)
+	}
+	if err := dialPublic("tcp", "[fd00::1]:443", nil); err != errPrivateAddress {
+		t.Errorf("unique local IPv6 must be refused, got %v", err)
+	}
+}
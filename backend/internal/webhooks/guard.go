@@ -0,0 +1,80 @@
+package webhooks
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// 🛡️ Un webhook lo configura cualquier admin de una organización, así que el
+// dispatcher no puede conectarse a la red interna: loopback, redes privadas,
+// link-local (la metadata de la nube en 169.254.169.254) y demás quedan
+// fuera. Se controla al conectar, con la IP ya resuelta, para que un DNS que
+// cambie entre la validación y el envío no lo saltee.
+
+var errPrivateAddress = errors.New("webhook target is a private or local address")
+
+// nonPublic son rangos reservados que net/netip no clasifica
+var nonPublic = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// publicAddress dice si se puede mandar un webhook a esa IP
+func publicAddress(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsValid() || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, p := range nonPublic {
+		if p.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// dialPublic es el Control del dialer: corre con la dirección ya resuelta,
+// justo antes de cada conexión, incluidas las de las redirecciones
+func dialPublic(network, address string, _ syscall.RawConn) error {
+	addr, err := netip.ParseAddrPort(address)
+	if err != nil || !publicAddress(addr.Addr()) {
+		return errPrivateAddress
+	}
+	return nil
+}
+
+// newClient arma el cliente del dispatcher. Sin proxy: el control tiene que
+// ver la IP del destino, no la del proxy.
+func newClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{
+		Timeout:   defaultTimeout,
+		KeepAlive: 30 * time.Second,
+		Control:   dialPublic,
+	}).DialContext
+	return &http.Client{Timeout: defaultTimeout, Transport: transport}
+}
+
+// localHost rechaza de entrada los destinos que ya se sabe que son internos;
+// los nombres que resuelven a una IP interna los frena dialPublic
+func localHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, suffix := range []string{".localhost", ".local", ".internal"} {
+		if host == suffix[1:] || strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	ip, err := netip.ParseAddr(host)
+	return err == nil && !publicAddress(ip)
+}
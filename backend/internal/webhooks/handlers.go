@@ -0,0 +1,341 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"pittsix/pkg/middleware"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultDeliveryLimit = 50
+	maxDeliveryLimit     = 200
+)
+
+type Handlers struct {
+	Repo       Repository
+	Dispatcher *Dispatcher
+}
+
+func NewHandlers(repo Repository, dispatcher *Dispatcher) *Handlers {
+	return &Handlers{Repo: repo, Dispatcher: dispatcher}
+}
+
+// canManage: admins o quien tenga webhooks:manage
+func canManage(ctx context.Context) bool {
+	return middleware.HasRole(ctx, "superadmin", "org_admin") || middleware.HasPermission(ctx, "webhooks:manage")
+}
+
+// orgFor resuelve la organización del JWT y exige permiso de administrar webhooks
+func orgFor(w http.ResponseWriter, r *http.Request) (primitive.ObjectID, bool) {
+	if !canManage(r.Context()) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return primitive.NilObjectID, false
+	}
+	orgIDStr, _ := r.Context().Value("organization_id").(string)
+	orgID, err := primitive.ObjectIDFromHex(orgIDStr)
+	if err != nil || orgID.IsZero() {
+		http.Error(w, "No organization", http.StatusForbidden)
+		return primitive.NilObjectID, false
+	}
+	return orgID, true
+}
+
+// findWebhook carga el webhook {id} de la organización de la petición
+func (h *Handlers) findWebhook(w http.ResponseWriter, r *http.Request) (*Webhook, bool) {
+	orgID, ok := orgFor(w, r)
+	if !ok {
+		return nil, false
+	}
+	id, err := primitive.ObjectIDFromHex(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid id", http.StatusBadRequest)
+		return nil, false
+	}
+	hook, err := h.Repo.GetWebhook(r.Context(), orgID, id)
+	if err == ErrNotFound {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return nil, false
+	}
+	return hook, true
+}
+
+// redacted oculta el secreto: solo se muestra al crear o rotar
+func redacted(hook Webhook) Webhook {
+	hook.Secret = ""
+	return hook
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+type webhookInput struct {
+	URL         string   `json:"url"`
+	Description string   `json:"description"`
+	Events      []string `json:"events"`
+	Active      *bool    `json:"active"`
+	// Secret opcional al crear; si falta se genera uno
+	Secret string `json:"secret"`
+}
+
+// apply vuelca el input en el webhook validando URL y tipos de evento
+func (in webhookInput) apply(hook *Webhook) error {
+	hook.URL = strings.TrimSpace(in.URL)
+	if err := validateURL(hook.URL); err != nil {
+		return err
+	}
+	evs, err := normalizeEvents(in.Events)
+	if err != nil {
+		return err
+	}
+	hook.Events = evs
+	hook.Description = strings.TrimSpace(in.Description)
+	return nil
+}
+
+// 🪝 Listar los webhooks de la organización (org_admin o webhooks:manage)
+func (h *Handlers) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := orgFor(w, r)
+	if !ok {
+		return
+	}
+	hooks, err := h.Repo.ListWebhooks(r.Context(), orgID, false)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	for i := range hooks {
+		hooks[i] = redacted(hooks[i])
+	}
+	writeJSON(w, http.StatusOK, hooks)
+}
+
+// ➕ Registrar un webhook; la respuesta es la única vez que se ve el secreto
+func (h *Handlers) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := orgFor(w, r)
+	if !ok {
+		return
+	}
+	var input webhookInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid body", http.StatusBadRequest)
+		return
+	}
+	now := h.Dispatcher.Now()
+	hook := Webhook{OrganizationID: orgID, Active: true, CreatedAt: now, UpdatedAt: now}
+	if err := input.apply(&hook); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if input.Active != nil {
+		hook.Active = *input.Active
+	}
+	hook.Secret = input.Secret
+	if hook.Secret == "" {
+		secret, err := newSecret()
+		if err != nil {
+			http.Error(w, "Could not generate secret", http.StatusInternalServerError)
+			return
+		}
+		hook.Secret = secret
+	}
+	if userID, _ := r.Context().Value("user_id").(string); userID != "" {
+		hook.CreatedBy, _ = primitive.ObjectIDFromHex(userID)
+	}
+	if err := h.Repo.CreateWebhook(r.Context(), &hook); err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, hook)
+}
+
+// 🔎 Ver un webhook
+func (h *Handlers) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	hook, ok := h.findWebhook(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, redacted(*hook))
+}
+
+// ✏️ Cambiar URL, eventos o estado. Reactivar un webhook apagado por fallos
+// reinicia el contador; el secreto se cambia con rotate-secret.
+func (h *Handlers) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	hook, ok := h.findWebhook(w, r)
+	if !ok {
+		return
+	}
+	var input webhookInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid body", http.StatusBadRequest)
+		return
+	}
+	if err := input.apply(hook); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if input.Active != nil {
+		if *input.Active && !hook.Active {
+			hook.ConsecutiveFailures = 0
+			hook.DisabledAt = nil
+			hook.DisabledReason = ""
+		}
+		hook.Active = *input.Active
+	}
+	hook.UpdatedAt = h.Dispatcher.Now()
+	if err := h.Repo.UpdateWebhook(r.Context(), hook); err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, redacted(*hook))
+}
+
+// 🔑 Generar un secreto nuevo; el anterior deja de valer en el acto
+func (h *Handlers) RotateSecret(w http.ResponseWriter, r *http.Request) {
+	hook, ok := h.findWebhook(w, r)
+	if !ok {
+		return
+	}
+	secret, err := newSecret()
+	if err != nil {
+		http.Error(w, "Could not generate secret", http.StatusInternalServerError)
+		return
+	}
+	hook.Secret = secret
+	hook.UpdatedAt = h.Dispatcher.Now()
+	if err := h.Repo.UpdateWebhook(r.Context(), hook); err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, hook)
+}
+
+// 🗑️ Borrar un webhook junto con su log de entregas
+func (h *Handlers) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	hook, ok := h.findWebhook(w, r)
+	if !ok {
+		return
+	}
+	if err := h.Repo.DeleteWebhook(r.Context(), hook.OrganizationID, hook.ID); err != nil && err != ErrNotFound {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
+}
+
+// 🏓 Encolar un evento de prueba
+func (h *Handlers) PingWebhook(w http.ResponseWriter, r *http.Request) {
+	hook, ok := h.findWebhook(w, r)
+	if !ok {
+		return
+	}
+	actor, _ := r.Context().Value("user_id").(string)
+	delivery, err := h.Dispatcher.SendPing(r.Context(), hook, actor)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, delivery)
+}
+
+// 📜 Log de entregas del webhook, las más nuevas primero (?status=, ?limit=)
+func (h *Handlers) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	hook, ok := h.findWebhook(w, r)
+	if !ok {
+		return
+	}
+	status := r.URL.Query().Get("status")
+	if status != "" && status != DeliveryPending && status != DeliverySucceeded && status != DeliveryFailed {
+		http.Error(w, "Invalid status", http.StatusBadRequest)
+		return
+	}
+	limit := defaultDeliveryLimit
+	if s := r.URL.Query().Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxDeliveryLimit)
+	}
+	deliveries, err := h.Repo.ListDeliveries(r.Context(), hook.OrganizationID, hook.ID, status, limit)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, deliveries)
+}
+
+// findDelivery carga la entrega {delivery} del webhook de la petición
+func (h *Handlers) findDelivery(w http.ResponseWriter, r *http.Request) (*Webhook, *Delivery, bool) {
+	hook, ok := h.findWebhook(w, r)
+	if !ok {
+		return nil, nil, false
+	}
+	id, err := primitive.ObjectIDFromHex(r.PathValue("delivery"))
+	if err != nil {
+		http.Error(w, "Invalid delivery id", http.StatusBadRequest)
+		return nil, nil, false
+	}
+	delivery, err := h.Repo.GetDelivery(r.Context(), hook.OrganizationID, id)
+	if err == ErrNotFound || (err == nil && delivery.WebhookID != hook.ID) {
+		http.Error(w, "Delivery not found", http.StatusNotFound)
+		return nil, nil, false
+	}
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	return hook, delivery, true
+}
+
+// 🔎 Ver una entrega con todos sus intentos
+func (h *Handlers) GetDelivery(w http.ResponseWriter, r *http.Request) {
+	_, delivery, ok := h.findDelivery(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, delivery)
+}
+
+// 🔁 Reenviar una entrega: encola una nueva con el mismo cuerpo. El webhook
+// tiene que estar activo.
+func (h *Handlers) Redeliver(w http.ResponseWriter, r *http.Request) {
+	hook, delivery, ok := h.findDelivery(w, r)
+	if !ok {
+		return
+	}
+	if !hook.Active {
+		http.Error(w, "Webhook is disabled; enable it first", http.StatusConflict)
+		return
+	}
+	again, err := h.Dispatcher.Redeliver(r.Context(), hook, delivery)
+	if err != nil {
+		log.Println(err.Error())
+		http.Error(w, "DB error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, again)
+}
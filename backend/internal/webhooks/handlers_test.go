@@ -0,0 +1,133 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func request(method, target, body string, orgID primitive.ObjectID, roles ...string) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	ctx := context.WithValue(r.Context(), "user_id", primitive.NewObjectID().Hex())
+	ctx = context.WithValue(ctx, "organization_id", orgID.Hex())
+	ctx = context.WithValue(ctx, "roles", roles)
+	return r.WithContext(ctx)
+}
+
+func serve(handler http.HandlerFunc, r *http.Request, pathValues ...string) *httptest.ResponseRecorder {
+	for i := 0; i+1 < len(pathValues); i += 2 {
+		r.SetPathValue(pathValues[i], pathValues[i+1])
+	}
+	w := httptest.NewRecorder()
+	handler(w, r)
+	return w
+}
+
+func TestHandlers_WebhookCRUD(t *testing.T) {
+	d, _ := newTestDispatcher()
+	h := NewHandlers(d.Repo, d)
+	org, other := primitive.NewObjectID(), primitive.NewObjectID()
+
+	if w := serve(h.CreateWebhook, request("POST", "/webhooks", `{"url":"https://example.com/hook"}`, org)); w.Code != http.StatusForbidden {
+		t.Errorf("members can't manage webhooks, got %d", w.Code)
+	}
+	for _, body := range []string{`{"url":"ftp://example.com"}`, `{"url":"/relative"}`, `{"url":"https://example.com","events":["article.nope"]}`} {
+		if w := serve(h.CreateWebhook, request("POST", "/webhooks", body, org, "org_admin")); w.Code != http.StatusBadRequest {
+			t.Errorf("%s should be rejected, got %d", body, w.Code)
+		}
+	}
+	w := serve(h.CreateWebhook, request("POST", "/webhooks", `{"url":"https://example.com/hook","events":["article.*","user.deleted","article.*"]}`, org, "org_admin"))
+	var created Webhook
+	json.NewDecoder(w.Body).Decode(&created)
+	if w.Code != http.StatusCreated || !strings.HasPrefix(created.Secret, "whsec_") || !created.Active || len(created.Events) != 2 {
+		t.Fatalf("create: %d %+v", w.Code, created)
+	}
+	id := created.ID.Hex()
+
+	w = serve(h.GetWebhook, request("GET", "/webhooks/"+id, "", org, "org_admin"), "id", id)
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "whsec_") {
+		t.Errorf("the secret is only shown on create: %s", w.Body.String())
+	}
+	if w := serve(h.GetWebhook, request("GET", "/webhooks/"+id, "", other, "org_admin"), "id", id); w.Code != http.StatusNotFound {
+		t.Errorf("other organizations don't see it, got %d", w.Code)
+	}
+
+	w = serve(h.RotateSecret, request("POST", "/", "", org, "org_admin"), "id", id)
+	var rotated Webhook
+	json.NewDecoder(w.Body).Decode(&rotated)
+	if rotated.Secret == "" || rotated.Secret == created.Secret {
+		t.Errorf("rotate must return a new secret")
+	}
+
+	// Reactivar uno apagado por fallos reinicia el contador
+	stored, _ := d.Repo.GetWebhook(context.Background(), org, created.ID)
+	disable(stored, d.Now())
+	stored.ConsecutiveFailures = 20
+	d.Repo.UpdateWebhook(context.Background(), stored)
+	w = serve(h.UpdateWebhook, request("PUT", "/", `{"url":"https://example.com/v2","active":true}`, org, "org_admin"), "id", id)
+	var updated Webhook
+	json.NewDecoder(w.Body).Decode(&updated)
+	if !updated.Active || updated.ConsecutiveFailures != 0 || updated.DisabledAt != nil || updated.URL != "https://example.com/v2" || len(updated.Events) != 0 {
+		t.Errorf("update: %+v", updated)
+	}
+
+	if w := serve(h.DeleteWebhook, request("DELETE", "/", "", org, "org_admin"), "id", id); w.Code != http.StatusOK {
+		t.Errorf("delete: %d", w.Code)
+	}
+	if w := serve(h.ListWebhooks, request("GET", "/webhooks", "", org, "org_admin")); w.Body.String() != "[]\n" {
+		t.Errorf("list after delete: %s", w.Body.String())
+	}
+}
+
+func TestHandlers_PingAndRedeliver(t *testing.T) {
+	rc := &receiver{status: http.StatusGone}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+	d, _ := newTestDispatcher()
+	h := NewHandlers(d.Repo, d)
+	org := primitive.NewObjectID()
+	hook := addHook(t, d, org, srv.URL, "user")
+	id := hook.ID.Hex()
+	ctx := context.Background()
+
+	w := serve(h.PingWebhook, request("POST", "/", "", org, "org_admin"), "id", id)
+	var ping Delivery
+	json.NewDecoder(w.Body).Decode(&ping)
+	if w.Code != http.StatusAccepted || ping.EventType != Ping {
+		t.Fatalf("ping: %d %+v", w.Code, ping)
+	}
+	d.MaxAttempts = 1
+	d.RunOnce(ctx)
+
+	w = serve(h.ListDeliveries, request("GET", "/?status=failed", "", org, "org_admin"), "id", id)
+	var log []Delivery
+	json.NewDecoder(w.Body).Decode(&log)
+	if len(log) != 1 || log[0].LastAttempt().StatusCode != http.StatusGone {
+		t.Fatalf("failed ping in the log: %+v", log)
+	}
+
+	rc.status = http.StatusNoContent
+	w = serve(h.Redeliver, request("POST", "/", "", org, "org_admin"), "id", id, "delivery", ping.ID.Hex())
+	var again Delivery
+	json.NewDecoder(w.Body).Decode(&again)
+	if w.Code != http.StatusAccepted || again.RedeliveryOf == nil || *again.RedeliveryOf != ping.ID || again.Payload != ping.Payload {
+		t.Fatalf("redeliver: %d %+v", w.Code, again)
+	}
+	d.RunOnce(ctx)
+	w = serve(h.GetDelivery, request("GET", "/", "", org, "org_admin"), "id", id, "delivery", again.ID.Hex())
+	var got Delivery
+	json.NewDecoder(w.Body).Decode(&got)
+	if got.Status != DeliverySucceeded || len(rc.bodies) != 2 || rc.bodies[0] != rc.bodies[1] {
+		t.Errorf("redelivery sends the same body: %+v", got)
+	}
+
+	other := addHook(t, d, org, srv.URL)
+	if w := serve(h.GetDelivery, request("GET", "/", "", org, "org_admin"), "id", other.ID.Hex(), "delivery", ping.ID.Hex()); w.Code != http.StatusNotFound {
+		t.Errorf("deliveries belong to their webhook, got %d", w.Code)
+	}
+}
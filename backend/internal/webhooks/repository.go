@@ -0,0 +1,32 @@
+package webhooks
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Repository guarda los webhooks y su cola de entregas, siempre por organización
+type Repository interface {
+	CreateWebhook(ctx context.Context, w *Webhook) error
+	GetWebhook(ctx context.Context, orgID, id primitive.ObjectID) (*Webhook, error)
+	// ListWebhooks devuelve los de la organización; activeOnly deja solo los encendidos
+	ListWebhooks(ctx context.Context, orgID primitive.ObjectID, activeOnly bool) ([]Webhook, error)
+	UpdateWebhook(ctx context.Context, w *Webhook) error
+	// DeleteWebhook borra el webhook y sus entregas
+	DeleteWebhook(ctx context.Context, orgID, id primitive.ObjectID) error
+	// RecordResult anota el resultado de un intento: un éxito reinicia los fallos
+	// seguidos; un fallo los suma y, al llegar a disableAfter, apaga el webhook.
+	// Devuelve el webhook actualizado.
+	RecordResult(ctx context.Context, id primitive.ObjectID, ok bool, disableAfter int, now time.Time) (*Webhook, error)
+
+	EnqueueDelivery(ctx context.Context, d *Delivery) error
+	// ClaimDelivery toma la entrega pendiente más vencida y corre su
+	// NextAttemptAt hasta leaseUntil; ErrNotFound si no hay ninguna
+	ClaimDelivery(ctx context.Context, now, leaseUntil time.Time) (*Delivery, error)
+	SaveDelivery(ctx context.Context, d *Delivery) error
+	GetDelivery(ctx context.Context, orgID, id primitive.ObjectID) (*Delivery, error)
+	// ListDeliveries devuelve las entregas del webhook, las más nuevas primero
+	ListDeliveries(ctx context.Context, orgID, webhookID primitive.ObjectID, status string, limit int) ([]Delivery, error)
+}
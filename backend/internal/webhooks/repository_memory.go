@@ -0,0 +1,191 @@
+package webhooks
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryRepository es la contraparte en memoria de MongoRepository
+type MemoryRepository struct {
+	mu         sync.Mutex
+	webhooks   map[primitive.ObjectID]Webhook
+	deliveries map[primitive.ObjectID]Delivery
+}
+
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		webhooks:   map[primitive.ObjectID]Webhook{},
+		deliveries: map[primitive.ObjectID]Delivery{},
+	}
+}
+
+func (r *MemoryRepository) CreateWebhook(ctx context.Context, w *Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if w.ID.IsZero() {
+		w.ID = primitive.NewObjectID()
+	}
+	r.webhooks[w.ID] = cloneWebhook(*w)
+	return nil
+}
+
+func (r *MemoryRepository) GetWebhook(ctx context.Context, orgID, id primitive.ObjectID) (*Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	w, ok := r.webhooks[id]
+	if !ok || w.OrganizationID != orgID {
+		return nil, ErrNotFound
+	}
+	w = cloneWebhook(w)
+	return &w, nil
+}
+
+func (r *MemoryRepository) ListWebhooks(ctx context.Context, orgID primitive.ObjectID, activeOnly bool) ([]Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := []Webhook{}
+	for _, w := range r.webhooks {
+		if w.OrganizationID == orgID && (w.Active || !activeOnly) {
+			out = append(out, cloneWebhook(w))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (r *MemoryRepository) UpdateWebhook(ctx context.Context, w *Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.webhooks[w.ID]
+	if !ok || current.OrganizationID != w.OrganizationID {
+		return ErrNotFound
+	}
+	r.webhooks[w.ID] = cloneWebhook(*w)
+	return nil
+}
+
+func (r *MemoryRepository) DeleteWebhook(ctx context.Context, orgID, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	w, ok := r.webhooks[id]
+	if !ok || w.OrganizationID != orgID {
+		return ErrNotFound
+	}
+	delete(r.webhooks, id)
+	for did, d := range r.deliveries {
+		if d.WebhookID == id {
+			delete(r.deliveries, did)
+		}
+	}
+	return nil
+}
+
+func (r *MemoryRepository) RecordResult(ctx context.Context, id primitive.ObjectID, ok bool, disableAfter int, now time.Time) (*Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	w, found := r.webhooks[id]
+	if !found {
+		return nil, ErrNotFound
+	}
+	if ok {
+		w.ConsecutiveFailures = 0
+	} else {
+		w.ConsecutiveFailures++
+		if disableAfter > 0 && w.ConsecutiveFailures >= disableAfter && w.Active {
+			disable(&w, now)
+		}
+	}
+	r.webhooks[id] = w
+	w = cloneWebhook(w)
+	return &w, nil
+}
+
+func (r *MemoryRepository) EnqueueDelivery(ctx context.Context, d *Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if d.ID.IsZero() {
+		d.ID = primitive.NewObjectID()
+	}
+	r.deliveries[d.ID] = cloneDelivery(*d)
+	return nil
+}
+
+func (r *MemoryRepository) ClaimDelivery(ctx context.Context, now, leaseUntil time.Time) (*Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var due *Delivery
+	for _, d := range r.deliveries {
+		if d.Status != DeliveryPending || d.NextAttemptAt.After(now) {
+			continue
+		}
+		if due == nil || d.NextAttemptAt.Before(due.NextAttemptAt) {
+			d := d
+			due = &d
+		}
+	}
+	if due == nil {
+		return nil, ErrNotFound
+	}
+	due.NextAttemptAt = leaseUntil
+	r.deliveries[due.ID] = cloneDelivery(*due)
+	claimed := cloneDelivery(*due)
+	return &claimed, nil
+}
+
+func (r *MemoryRepository) SaveDelivery(ctx context.Context, d *Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.deliveries[d.ID]; !ok {
+		return ErrNotFound
+	}
+	r.deliveries[d.ID] = cloneDelivery(*d)
+	return nil
+}
+
+func (r *MemoryRepository) GetDelivery(ctx context.Context, orgID, id primitive.ObjectID) (*Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	d, ok := r.deliveries[id]
+	if !ok || d.OrganizationID != orgID {
+		return nil, ErrNotFound
+	}
+	d = cloneDelivery(d)
+	return &d, nil
+}
+
+func (r *MemoryRepository) ListDeliveries(ctx context.Context, orgID, webhookID primitive.ObjectID, status string, limit int) ([]Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := []Delivery{}
+	for _, d := range r.deliveries {
+		if d.OrganizationID == orgID && d.WebhookID == webhookID && (status == "" || d.Status == status) {
+			out = append(out, cloneDelivery(d))
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// disable apaga el webhook por fallos repetidos
+func disable(w *Webhook, now time.Time) {
+	w.Active = false
+	w.DisabledAt = &now
+	w.DisabledReason = "too many consecutive failures"
+}
+
+func cloneWebhook(w Webhook) Webhook {
+	w.Events = append([]string(nil), w.Events...)
+	return w
+}
+
+func cloneDelivery(d Delivery) Delivery {
+	d.Attempts = append([]Attempt(nil), d.Attempts...)
+	return d
+}
@@ -0,0 +1,201 @@
+package webhooks
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoRepository struct {
+	webhooks   *mongo.Collection
+	deliveries *mongo.Collection
+}
+
+// NewMongoRepository usa las colecciones webhooks y webhook_deliveries de la base
+func NewMongoRepository(db *mongo.Database) *MongoRepository {
+	r := &MongoRepository{
+		webhooks:   db.Collection("webhooks"),
+		deliveries: db.Collection("webhook_deliveries"),
+	}
+	r.ensureIndexes()
+	return r
+}
+
+func (r *MongoRepository) ensureIndexes() {
+	ctx := context.Background()
+	if _, err := r.webhooks.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "organization_id", Value: 1}, {Key: "active", Value: 1}},
+	}); err != nil {
+		log.Printf("⚠️ No se pudo crear el índice de webhooks: %v", err)
+	}
+	_, err := r.deliveries.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		// La cola: pendientes por próximo intento
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}}},
+		// El log de cada webhook
+		{Keys: bson.D{{Key: "organization_id", Value: 1}, {Key: "webhook_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+	if err != nil {
+		log.Printf("⚠️ No se pudieron crear los índices de entregas: %v", err)
+	}
+}
+
+func (r *MongoRepository) CreateWebhook(ctx context.Context, w *Webhook) error {
+	if w.ID.IsZero() {
+		w.ID = primitive.NewObjectID()
+	}
+	_, err := r.webhooks.InsertOne(ctx, w)
+	return err
+}
+
+func (r *MongoRepository) GetWebhook(ctx context.Context, orgID, id primitive.ObjectID) (*Webhook, error) {
+	return findOne[Webhook](ctx, r.webhooks, bson.M{"organization_id": orgID, "_id": id})
+}
+
+func (r *MongoRepository) ListWebhooks(ctx context.Context, orgID primitive.ObjectID, activeOnly bool) ([]Webhook, error) {
+	filter := bson.M{"organization_id": orgID}
+	if activeOnly {
+		filter["active"] = true
+	}
+	cursor, err := r.webhooks.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	return decodeAll[Webhook](ctx, cursor)
+}
+
+func (r *MongoRepository) UpdateWebhook(ctx context.Context, w *Webhook) error {
+	res, err := r.webhooks.ReplaceOne(ctx, bson.M{"organization_id": w.OrganizationID, "_id": w.ID}, w)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *MongoRepository) DeleteWebhook(ctx context.Context, orgID, id primitive.ObjectID) error {
+	res, err := r.webhooks.DeleteOne(ctx, bson.M{"organization_id": orgID, "_id": id})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	_, err = r.deliveries.DeleteMany(ctx, bson.M{"webhook_id": id})
+	return err
+}
+
+func (r *MongoRepository) RecordResult(ctx context.Context, id primitive.ObjectID, ok bool, disableAfter int, now time.Time) (*Webhook, error) {
+	update := bson.M{"$inc": bson.M{"consecutive_failures": 1}}
+	if ok {
+		update = bson.M{"$set": bson.M{"consecutive_failures": 0}}
+	}
+	var w Webhook
+	err := r.webhooks.FindOneAndUpdate(ctx, bson.M{"_id": id}, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&w)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if ok || disableAfter <= 0 || w.ConsecutiveFailures < disableAfter || !w.Active {
+		return &w, nil
+	}
+	disable(&w, now)
+	_, err = r.webhooks.UpdateOne(ctx, bson.M{"_id": id, "active": true}, bson.M{"$set": bson.M{
+		"active":          false,
+		"disabled_at":     w.DisabledAt,
+		"disabled_reason": w.DisabledReason,
+	}})
+	return &w, err
+}
+
+func (r *MongoRepository) EnqueueDelivery(ctx context.Context, d *Delivery) error {
+	if d.ID.IsZero() {
+		d.ID = primitive.NewObjectID()
+	}
+	_, err := r.deliveries.InsertOne(ctx, d)
+	return err
+}
+
+// ClaimDelivery es atómico: si dos réplicas reclaman a la vez, cada una se
+// lleva una entrega distinta
+func (r *MongoRepository) ClaimDelivery(ctx context.Context, now, leaseUntil time.Time) (*Delivery, error) {
+	var d Delivery
+	err := r.deliveries.FindOneAndUpdate(ctx,
+		bson.M{"status": DeliveryPending, "next_attempt_at": bson.M{"$lte": now}},
+		bson.M{"$set": bson.M{"next_attempt_at": leaseUntil}},
+		options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+			SetReturnDocument(options.After),
+	).Decode(&d)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *MongoRepository) SaveDelivery(ctx context.Context, d *Delivery) error {
+	res, err := r.deliveries.ReplaceOne(ctx, bson.M{"_id": d.ID}, d)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *MongoRepository) GetDelivery(ctx context.Context, orgID, id primitive.ObjectID) (*Delivery, error) {
+	return findOne[Delivery](ctx, r.deliveries, bson.M{"organization_id": orgID, "_id": id})
+}
+
+func (r *MongoRepository) ListDeliveries(ctx context.Context, orgID, webhookID primitive.ObjectID, status string, limit int) ([]Delivery, error) {
+	filter := bson.M{"organization_id": orgID, "webhook_id": webhookID}
+	if status != "" {
+		filter["status"] = status
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	cursor, err := r.deliveries.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	return decodeAll[Delivery](ctx, cursor)
+}
+
+func findOne[T any](ctx context.Context, c *mongo.Collection, filter bson.M) (*T, error) {
+	var v T
+	err := c.FindOne(ctx, filter).Decode(&v)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func decodeAll[T any](ctx context.Context, cursor *mongo.Cursor) ([]T, error) {
+	defer cursor.Close(ctx)
+	out := []T{}
+	for cursor.Next(ctx) {
+		var v T
+		if err := cursor.Decode(&v); err == nil {
+			out = append(out, v)
+		}
+	}
+	return out, cursor.Err()
+}
@@ -0,0 +1,21 @@
+package webhooks
+
+import (
+	"net/http"
+	"pittsix/pkg/middleware"
+)
+
+func RegisterHandlers(mux *http.ServeMux, h *Handlers) {
+	mux.Handle("GET /webhooks", middleware.JWTAuth(http.HandlerFunc(h.ListWebhooks)))
+	mux.Handle("POST /webhooks", middleware.JWTAuth(http.HandlerFunc(h.CreateWebhook)))
+	mux.Handle("GET /webhooks/{id}", middleware.JWTAuth(http.HandlerFunc(h.GetWebhook)))
+	mux.Handle("PUT /webhooks/{id}", middleware.JWTAuth(http.HandlerFunc(h.UpdateWebhook)))
+	mux.Handle("DELETE /webhooks/{id}", middleware.JWTAuth(http.HandlerFunc(h.DeleteWebhook)))
+	mux.Handle("POST /webhooks/{id}/rotate-secret", middleware.JWTAuth(http.HandlerFunc(h.RotateSecret)))
+	mux.Handle("POST /webhooks/{id}/ping", middleware.JWTAuth(http.HandlerFunc(h.PingWebhook)))
+
+	// Log de entregas
+	mux.Handle("GET /webhooks/{id}/deliveries", middleware.JWTAuth(http.HandlerFunc(h.ListDeliveries)))
+	mux.Handle("GET /webhooks/{id}/deliveries/{delivery}", middleware.JWTAuth(http.HandlerFunc(h.GetDelivery)))
+	mux.Handle("POST /webhooks/{id}/deliveries/{delivery}/redeliver", middleware.JWTAuth(http.HandlerFunc(h.Redeliver)))
+}
@@ -0,0 +1,80 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 🔏 Firma de los envíos. El header X-Pittsix-Signature lleva
+// "t=<unix>,v1=<hex>", donde v1 es el HMAC-SHA256 con el secreto del webhook
+// de "<t>.<cuerpo>". El receptor recalcula el HMAC y rechaza timestamps viejos
+// para que no se puedan reenviar capturas.
+
+const (
+	SignatureHeader = "X-Pittsix-Signature"
+	EventHeader     = "X-Pittsix-Event"
+	DeliveryHeader  = "X-Pittsix-Delivery"
+)
+
+var errBadSignature = errors.New("invalid webhook signature")
+
+// Sign arma el valor del header de firma
+func Sign(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + ts + ",v1=" + mac(secret, ts, body)
+}
+
+// Verify comprueba un header de firma; tolerance > 0 limita la antigüedad del timestamp
+func Verify(secret, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var ts string
+	var sigs []string
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			sigs = append(sigs, v)
+		}
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return errBadSignature
+	}
+	if tolerance > 0 {
+		age := now.Sub(time.Unix(unix, 0))
+		if age > tolerance || age < -tolerance {
+			return errBadSignature
+		}
+	}
+	want := mac(secret, ts, body)
+	for _, sig := range sigs {
+		if hmac.Equal([]byte(sig), []byte(want)) {
+			return nil
+		}
+	}
+	return errBadSignature
+}
+
+func mac(secret, ts string, body []byte) string {
+	m := hmac.New(sha256.New, []byte(secret))
+	m.Write([]byte(ts))
+	m.Write([]byte("."))
+	m.Write(body)
+	return hex.EncodeToString(m.Sum(nil))
+}
+
+// newSecret genera un secreto aleatorio con prefijo reconocible
+func newSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,154 @@
+package webhooks
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"pittsix/pkg/events"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// 🪝 Webhooks salientes: cada organización registra URLs que reciben, firmados,
+// los eventos del bus. Las entregas se encolan en la base y un dispatcher las
+// envía con reintentos; después de muchos fallos seguidos el webhook se apaga.
+
+// Estados de una entrega
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// Ping es el evento de prueba que se manda a pedido desde la API
+const Ping = "ping"
+
+var (
+	ErrNotFound     = errors.New("not found")
+	errInvalidURL   = errors.New("url must be an absolute http or https URL")
+	errInvalidEvent = errors.New("unknown event type")
+)
+
+// EventTypes son los tipos a los que se puede suscribir un webhook
+var EventTypes = []string{
+	events.ArticleCreated, events.ArticleUpdated, events.ArticleStatusChanged, events.ArticleDeleted,
+	events.UserCreated, events.UserUpdated, events.UserDeleted,
+	events.OrganizationUpdated, events.OrganizationDeleted,
+}
+
+// Webhook es una suscripción de una organización. Events vacío recibe todo;
+// admite tipos exactos, prefijos ("article" o "article.*") y "*".
+type Webhook struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	OrganizationID primitive.ObjectID `bson:"organization_id" json:"organization_id"`
+	URL            string             `bson:"url" json:"url"`
+	Description    string             `bson:"description,omitempty" json:"description,omitempty"`
+	// Secret firma los envíos; la API solo lo muestra al crear o rotar
+	Secret string   `bson:"secret" json:"secret,omitempty"`
+	Events []string `bson:"events" json:"events"`
+	Active bool     `bson:"active" json:"active"`
+	// ConsecutiveFailures cuenta intentos fallidos seguidos; un éxito lo reinicia
+	ConsecutiveFailures int                `bson:"consecutive_failures" json:"consecutive_failures"`
+	DisabledAt          *time.Time         `bson:"disabled_at,omitempty" json:"disabled_at,omitempty"`
+	DisabledReason      string             `bson:"disabled_reason,omitempty" json:"disabled_reason,omitempty"`
+	CreatedBy           primitive.ObjectID `bson:"created_by" json:"created_by"`
+	CreatedAt           time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt           time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// Attempt es un intento de entrega
+type Attempt struct {
+	At time.Time `bson:"at" json:"at"`
+	// El cuerpo de la respuesta no se guarda: el log lo leen los admins de la
+	// organización y no tiene que servir para leer lo que devuelve el destino
+	StatusCode int    `bson:"status_code,omitempty" json:"status_code,omitempty"`
+	Error      string `bson:"error,omitempty" json:"error,omitempty"`
+	DurationMs int64  `bson:"duration_ms" json:"duration_ms"`
+}
+
+// Delivery es un evento encolado para un webhook junto con su historial de intentos
+type Delivery struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	WebhookID      primitive.ObjectID `bson:"webhook_id" json:"webhook_id"`
+	OrganizationID primitive.ObjectID `bson:"organization_id" json:"organization_id"`
+	EventID        uint64             `bson:"event_id" json:"event_id"`
+	EventType      string             `bson:"event_type" json:"event_type"`
+	// Payload es el cuerpo exacto que se envía; una reentrega manda el mismo
+	Payload string `bson:"payload" json:"payload"`
+	Status  string `bson:"status" json:"status"`
+	// NextAttemptAt es cuándo toca el próximo intento; mientras un dispatcher la
+	// procesa se corre hacia adelante para que otra réplica no la tome
+	NextAttemptAt time.Time `bson:"next_attempt_at" json:"next_attempt_at"`
+	Attempts      []Attempt `bson:"attempts" json:"attempts"`
+	// RedeliveryOf apunta a la entrega original cuando es una reentrega manual
+	RedeliveryOf *primitive.ObjectID `bson:"redelivery_of,omitempty" json:"redelivery_of,omitempty"`
+	CreatedAt    time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt    time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+// LastAttempt devuelve el último intento, o nil si todavía no se intentó
+func (d *Delivery) LastAttempt() *Attempt {
+	if len(d.Attempts) == 0 {
+		return nil
+	}
+	return &d.Attempts[len(d.Attempts)-1]
+}
+
+// Subscribed indica si el webhook recibe ese tipo de evento. El ping llega siempre.
+func (w *Webhook) Subscribed(eventType string) bool {
+	if len(w.Events) == 0 || eventType == Ping {
+		return true
+	}
+	for _, e := range w.Events {
+		e = strings.TrimSuffix(e, ".*")
+		if e == "*" || e == eventType || strings.HasPrefix(eventType, e+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// validateURL exige una URL http(s) absoluta que no apunte a la red interna
+func validateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errInvalidURL
+	}
+	if localHost(u.Hostname()) {
+		return errPrivateAddress
+	}
+	return nil
+}
+
+// normalizeEvents valida los tipos pedidos y los deja sin duplicados
+func normalizeEvents(in []string) ([]string, error) {
+	out := []string{}
+	seen := map[string]bool{}
+	for _, e := range in {
+		e = strings.TrimSpace(e)
+		if e == "" || seen[e] {
+			continue
+		}
+		if !knownEvent(e) {
+			return nil, errInvalidEvent
+		}
+		seen[e] = true
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+func knownEvent(e string) bool {
+	if e == "*" {
+		return true
+	}
+	prefix := strings.TrimSuffix(e, ".*")
+	for _, t := range EventTypes {
+		if t == e || strings.HasPrefix(t, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
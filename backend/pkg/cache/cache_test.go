@@ -0,0 +1,160 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLRU(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewLRU(2)
+	c.now = func() time.Time { return now }
+
+	c.Set(ctx, "a", []byte("1"), 0)
+	c.Set(ctx, "b", []byte("2"), time.Minute)
+	c.Get(ctx, "a") // a pasa a ser la más usada
+	c.Set(ctx, "c", []byte("3"), 0)
+	if _, ok, _ := c.Get(ctx, "b"); ok {
+		t.Error("b was the least recently used and should be evicted")
+	}
+	if v, ok, _ := c.Get(ctx, "a"); !ok || string(v) != "1" {
+		t.Errorf("a: %q %v", v, ok)
+	}
+
+	c.Set(ctx, "c", []byte("3"), time.Minute)
+	now = now.Add(time.Minute)
+	if _, ok, _ := c.Get(ctx, "c"); ok {
+		t.Error("c expired")
+	}
+	c.Delete(ctx, "a", "missing")
+	if s := c.Stats(); s.Entries != 0 || s.Evictions != 1 || s.Expired != 1 {
+		t.Errorf("stats: %+v", s)
+	}
+}
+
+// fakeRedis entiende GET, SET (con PX), DEL y AUTH, lo justo para el cliente;
+// a HELLO responde como un servidor viejo y el cliente cae en AUTH
+func fakeRedis(t *testing.T, password string) (addr string, commands *[]string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	var mu sync.Mutex
+	data := map[string]string{}
+	seen := []string{}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					args, err := readCommand(r)
+					if err != nil {
+						return
+					}
+					args[0] = strings.ToUpper(args[0])
+					mu.Lock()
+					seen = append(seen, strings.Join(args, " "))
+					var reply string
+					switch args[0] {
+					case "AUTH":
+						reply = "+OK\r\n"
+						if args[1] != password {
+							reply = "-WRONGPASS invalid password\r\n"
+						}
+					case "GET":
+						if v, ok := data[args[1]]; ok {
+							reply = "$" + strconv.Itoa(len(v)) + "\r\n" + v + "\r\n"
+						} else {
+							reply = "$-1\r\n"
+						}
+					case "SET":
+						data[args[1]] = args[2]
+						reply = "+OK\r\n"
+					case "DEL":
+						n := 0
+						for _, k := range args[1:] {
+							if _, ok := data[k]; ok {
+								delete(data, k)
+								n++
+							}
+						}
+						reply = ":" + strconv.Itoa(n) + "\r\n"
+					default:
+						reply = "-ERR unknown command\r\n"
+					}
+					mu.Unlock()
+					conn.Write([]byte(reply))
+				}
+			}()
+		}
+	}()
+	return ln.Addr().String(), &seen
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+	args := make([]string, n)
+	for i := range args {
+		head, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, _ := strconv.Atoi(strings.TrimSpace(head[1:]))
+		arg := make([]byte, size+2)
+		if _, err := io.ReadFull(r, arg); err != nil {
+			return nil, err
+		}
+		args[i] = string(arg[:size])
+	}
+	return args, nil
+}
+
+func TestRedis(t *testing.T) {
+	ctx := context.Background()
+	addr, commands := fakeRedis(t, "secreto")
+	c := NewRedis(addr, "secreto", 0)
+	c.Prefix = "pittsix:"
+
+	if _, ok, err := c.Get(ctx, "a"); ok || err != nil {
+		t.Fatalf("miss: %v %v", ok, err)
+	}
+	if err := c.Set(ctx, "a", []byte("hola\r\nmundo"), 1500*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok, err := c.Get(ctx, "a"); !ok || err != nil || string(v) != "hola\r\nmundo" {
+		t.Errorf("hit: %q %v %v", v, ok, err)
+	}
+	if err := c.Delete(ctx, "a", "b"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := c.Get(ctx, "a"); ok {
+		t.Error("deleted")
+	}
+	joined := strings.Join(*commands, "|")
+	if !strings.Contains(joined, "AUTH secreto|GET pittsix:a|SET pittsix:a hola") || !strings.Contains(strings.ToUpper(joined), "PX 1500") || !strings.Contains(joined, "DEL pittsix:a pittsix:b") {
+		t.Errorf("commands: %q", joined)
+	}
+
+	bad := NewRedis(addr, "otra", 0)
+	if _, _, err := bad.Get(ctx, "a"); err == nil {
+		t.Error("a rejected AUTH is an error")
+	}
+}
@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisPoolSize    = 8
+	redisDialTimeout = 2 * time.Second
+	redisIOTimeout   = 2 * time.Second
+)
+
+// Redis es un Store contra un servidor compatible con Redis (Redis, Valkey,
+// KeyDB, Dragonfly...) con go-redis. Usa solo GET, SET con vencimiento y DEL,
+// y habla RESP2, que entienden todos.
+type Redis struct {
+	// Prefix se antepone a todas las claves para compartir el servidor
+	Prefix string
+	client *redis.Client
+}
+
+func NewRedis(addr, password string, db int) *Redis {
+	return &Redis{client: redis.NewClient(&redis.Options{
+		Addr:             addr,
+		Password:         password,
+		DB:               db,
+		Protocol:         2,
+		DisableIndentity: true,
+		PoolSize:         redisPoolSize,
+		DialTimeout:      redisDialTimeout,
+		ReadTimeout:      redisIOTimeout,
+		WriteTimeout:     redisIOTimeout,
+	})}
+}
+
+func (c *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	b, err := c.client.Get(ctx, c.Prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return b, true, nil
+}
+
+func (c *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	// go-redis manda PX (o EX si son segundos justos); 0 es sin vencimiento
+	return c.client.Set(ctx, c.Prefix+key, value, max(ttl, 0)).Err()
+}
+
+func (c *Redis) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	prefixed := make([]string, len(keys))
+	for i, k := range keys {
+		prefixed[i] = c.Prefix + k
+	}
+	return c.client.Del(ctx, prefixed...).Err()
+}
@@ -0,0 +1,29 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// 🗄️ Store es un caché clave/valor con vencimiento. Hay una versión en memoria
+// (LRU) y otra contra cualquier servidor compatible con Redis.
+
+// Store guarda bytes por clave; ttl 0 no vence (aunque el LRU puede desalojarla)
+type Store interface {
+	// Get devuelve el valor y si estaba; un error es un fallo del backend, no un miss
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// StoreStats son los contadores propios del backend
+type StoreStats struct {
+	Entries   int    `json:"entries"`
+	Evictions uint64 `json:"evictions"`
+	Expired   uint64 `json:"expired"`
+}
+
+// StatsReporter lo implementan los backends que llevan contadores
+type StatsReporter interface {
+	Stats() StoreStats
+}
@@ -2,12 +2,23 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
-	Env      string
-	Server   ServerConfig
-	Security SecurityConfig
+	Env       string
+	Server    ServerConfig
+	Security  SecurityConfig
+	Scheduler SchedulerConfig
+	Tenancy   TenancyConfig
+	Events    EventsConfig
+	Webhooks  WebhooksConfig
+	HTTPCache HTTPCacheConfig
+	ReadCache ReadCacheConfig
+	Site      SiteConfig
+	Images    ImagesConfig
 }
 
 type ServerConfig struct {
@@ -16,6 +27,82 @@ type ServerConfig struct {
 
 type SecurityConfig struct {
 	Pepper string
+	// PreviewSecret firma los enlaces de vista previa; es obligatorio con
+	// ENV=production. Fuera de producción, si falta se genera uno al arrancar y
+	// los enlaces emitidos no sobreviven a un reinicio ni sirven en otra réplica.
+	PreviewSecret string
+}
+
+type SchedulerConfig struct {
+	Interval time.Duration
+}
+
+type TenancyConfig struct {
+	// DefaultOrgSlug resuelve las lecturas públicas que no indican organización
+	// ni llegan por un host conocido; por defecto la que crea bootstrap
+	DefaultOrgSlug string
+}
+
+type EventsConfig struct {
+	// BufferSize es cuántos eventos se guardan para reanudar streams con Last-Event-ID
+	BufferSize int
+	// TicketTTL es cuánto vale un ticket de POST /events/ticket; el cliente pide otro al reconectar
+	TicketTTL time.Duration
+}
+
+type WebhooksConfig struct {
+	// Interval es cada cuánto se revisa la cola de entregas
+	Interval time.Duration
+	// MaxAttempts es cuántas veces se intenta una entrega antes de darla por fallida
+	MaxAttempts int
+	// DisableAfter es cuántos intentos fallidos seguidos apagan un webhook
+	DisableAfter int
+}
+
+type HTTPCacheConfig struct {
+	// CacheControl pisa el Cache-Control por defecto de una ruta pública; la
+	// clave es la ruta (articles.list, articles.id, articles.slug, authors, feeds,
+	// sitemap, robots)
+	CacheControl map[string]string
+	// PurgeURL recibe un POST con el header Surrogate-Key al cambiar contenido
+	PurgeURL   string
+	PurgeToken string
+}
+
+type ReadCacheConfig struct {
+	// Disabled apaga el caché de lecturas públicas
+	Disabled bool
+	Size     int
+	TTL      time.Duration
+	// RedisAddr (host:puerto) usa un servidor compatible con Redis en lugar
+	// del LRU en memoria, compartido entre réplicas. El LRU es de cada réplica
+	// y sólo lo invalida la réplica que atendió la escritura: con más de una
+	// réplica, las demás sirven datos viejos hasta que vence el TTL.
+	RedisAddr     string
+	RedisPassword string
+	RedisDB       int
+}
+
+type SiteConfig struct {
+	// BaseURL es la raíz pública del sitio para las URLs absolutas (feeds);
+	// las organizaciones con hosts propios usan el suyo con este esquema
+	BaseURL string
+	// ArticlePath es la ruta pública de un artículo, con {id} o {slug}
+	ArticlePath string
+	// Robots son las reglas de robots.txt ("\n" separa líneas); vacío usa las por defecto
+	Robots string
+}
+
+type ImagesConfig struct {
+	// Renditions son las versiones de cada imagen subida: "nombre:ancho,..."
+	Renditions string
+	// WebP suma una variante WebP de cada versión
+	WebP bool
+	// Quality es la calidad JPEG y WebP de las versiones (1 a 100)
+	Quality int
+	// MaxBytes limita el archivo subido y MaxPixels las dimensiones de una imagen
+	MaxBytes  int
+	MaxPixels int
 }
 
 func LoadConfig() Config {
@@ -25,7 +112,92 @@ func LoadConfig() Config {
 			Port: ":8080",
 		},
 		Security: SecurityConfig{
-			Pepper: os.Getenv("PEPPER"),
+			Pepper:        os.Getenv("PEPPER"),
+			PreviewSecret: os.Getenv("PREVIEW_SECRET"),
+		},
+		Scheduler: SchedulerConfig{
+			Interval: durationEnv("SCHEDULER_INTERVAL", 30*time.Second),
+		},
+		Tenancy: TenancyConfig{
+			DefaultOrgSlug: stringEnv("DEFAULT_ORG_SLUG", "org-principal"),
 		},
+		Events: EventsConfig{
+			BufferSize: intEnv("EVENTS_BUFFER_SIZE", 1024),
+			TicketTTL:  durationEnv("EVENTS_TICKET_TTL", 30*time.Second),
+		},
+		Webhooks: WebhooksConfig{
+			Interval:     durationEnv("WEBHOOKS_INTERVAL", 5*time.Second),
+			MaxAttempts:  intEnv("WEBHOOKS_MAX_ATTEMPTS", 8),
+			DisableAfter: intEnv("WEBHOOKS_DISABLE_AFTER", 20),
+		},
+		HTTPCache: HTTPCacheConfig{
+			CacheControl: cacheControlEnv(map[string]string{
+				"articles.list": "CACHE_CONTROL_ARTICLES_LIST",
+				"articles.id":   "CACHE_CONTROL_ARTICLES_ID",
+				"articles.slug": "CACHE_CONTROL_ARTICLES_SLUG",
+				"authors":       "CACHE_CONTROL_AUTHORS",
+				"feeds":         "CACHE_CONTROL_FEEDS",
+				"sitemap":       "CACHE_CONTROL_SITEMAP",
+				"robots":        "CACHE_CONTROL_ROBOTS",
+			}),
+			PurgeURL:   os.Getenv("CDN_PURGE_URL"),
+			PurgeToken: os.Getenv("CDN_PURGE_TOKEN"),
+		},
+		ReadCache: ReadCacheConfig{
+			Disabled:      os.Getenv("READ_CACHE_DISABLED") == "true",
+			Size:          intEnv("READ_CACHE_SIZE", 10000),
+			TTL:           durationEnv("READ_CACHE_TTL", time.Minute),
+			RedisAddr:     os.Getenv("REDIS_ADDR"),
+			RedisPassword: os.Getenv("REDIS_PASSWORD"),
+			RedisDB:       intEnv("REDIS_DB", 0),
+		},
+		Site: SiteConfig{
+			BaseURL:     stringEnv("SITE_BASE_URL", "https://pittsix.com"),
+			ArticlePath: stringEnv("SITE_ARTICLE_PATH", "/article/{id}"),
+			Robots:      strings.ReplaceAll(os.Getenv("ROBOTS_TXT"), `\n`, "\n"),
+		},
+		Images: ImagesConfig{
+			Renditions: stringEnv("IMAGE_RENDITIONS", "thumbnail:320,medium:800,large:1600"),
+			WebP:       os.Getenv("IMAGE_WEBP") != "false",
+			Quality:    min(intEnv("IMAGE_QUALITY", 82), 100),
+			MaxBytes:   intEnv("UPLOAD_MAX_BYTES", 20<<20),
+			MaxPixels:  intEnv("IMAGE_MAX_PIXELS", 50_000_000),
+		},
+	}
+}
+
+// durationEnv lee una duración tipo "45s" o "2m"; si falta o es inválida usa el default
+func durationEnv(key string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
+}
+
+func stringEnv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// intEnv lee un entero positivo; si falta o es inválido usa el default
+func intEnv(key string, def int) int {
+	n, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || n <= 0 {
+		return def
+	}
+	return n
+}
+
+// cacheControlEnv lee las variables de cada ruta; solo quedan las definidas
+func cacheControlEnv(keys map[string]string) map[string]string {
+	out := map[string]string{}
+	for route, key := range keys {
+		if v := os.Getenv(key); v != "" {
+			out[route] = v
+		}
 	}
+	return out
 }
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -19,3 +20,107 @@ func TestLoadConfig(t *testing.T) {
 		t.Errorf("expected default port :8080, got %s", cfg.Server.Port)
 	}
 }
+
+func TestLoadConfig_SchedulerInterval(t *testing.T) {
+	os.Setenv("SCHEDULER_INTERVAL", "")
+	if got := LoadConfig().Scheduler.Interval; got != 30*time.Second {
+		t.Errorf("expected default interval 30s, got %s", got)
+	}
+	os.Setenv("SCHEDULER_INTERVAL", "5s")
+	if got := LoadConfig().Scheduler.Interval; got != 5*time.Second {
+		t.Errorf("expected interval 5s, got %s", got)
+	}
+	os.Setenv("SCHEDULER_INTERVAL", "nope")
+	if got := LoadConfig().Scheduler.Interval; got != 30*time.Second {
+		t.Errorf("expected fallback to 30s, got %s", got)
+	}
+}
+
+func TestLoadConfig_DefaultOrgSlug(t *testing.T) {
+	os.Setenv("DEFAULT_ORG_SLUG", "")
+	if got := LoadConfig().Tenancy.DefaultOrgSlug; got != "org-principal" {
+		t.Errorf("expected default org slug org-principal, got %s", got)
+	}
+	os.Setenv("DEFAULT_ORG_SLUG", "acme")
+	if got := LoadConfig().Tenancy.DefaultOrgSlug; got != "acme" {
+		t.Errorf("expected org slug acme, got %s", got)
+	}
+}
+
+func TestLoadConfig_EventsBufferSize(t *testing.T) {
+	os.Setenv("EVENTS_BUFFER_SIZE", "")
+	if got := LoadConfig().Events.BufferSize; got != 1024 {
+		t.Errorf("expected default buffer 1024, got %d", got)
+	}
+	os.Setenv("EVENTS_BUFFER_SIZE", "50")
+	if got := LoadConfig().Events.BufferSize; got != 50 {
+		t.Errorf("expected buffer 50, got %d", got)
+	}
+	os.Setenv("EVENTS_BUFFER_SIZE", "-3")
+	if got := LoadConfig().Events.BufferSize; got != 1024 {
+		t.Errorf("expected fallback to 1024, got %d", got)
+	}
+}
+
+func TestLoadConfig_Webhooks(t *testing.T) {
+	os.Setenv("WEBHOOKS_INTERVAL", "")
+	os.Setenv("WEBHOOKS_MAX_ATTEMPTS", "3")
+	os.Setenv("WEBHOOKS_DISABLE_AFTER", "")
+	defer os.Setenv("WEBHOOKS_MAX_ATTEMPTS", "")
+	cfg := LoadConfig().Webhooks
+	if cfg.Interval != 5*time.Second || cfg.MaxAttempts != 3 || cfg.DisableAfter != 20 {
+		t.Errorf("unexpected webhooks config: %+v", cfg)
+	}
+}
+
+func TestLoadConfig_HTTPCache(t *testing.T) {
+	os.Setenv("CACHE_CONTROL_ARTICLES_LIST", "no-store")
+	defer os.Setenv("CACHE_CONTROL_ARTICLES_LIST", "")
+	cc := LoadConfig().HTTPCache.CacheControl
+	if len(cc) != 1 || cc["articles.list"] != "no-store" {
+		t.Errorf("only the overridden routes are set: %v", cc)
+	}
+}
+
+func TestLoadConfig_ReadCache(t *testing.T) {
+	os.Setenv("READ_CACHE_TTL", "")
+	os.Setenv("REDIS_ADDR", "localhost:6379")
+	defer os.Setenv("REDIS_ADDR", "")
+	cfg := LoadConfig().ReadCache
+	if cfg.Disabled || cfg.Size != 10000 || cfg.TTL != time.Minute || cfg.RedisAddr != "localhost:6379" {
+		t.Errorf("unexpected read cache config: %+v", cfg)
+	}
+}
+
+func TestLoadConfig_Site(t *testing.T) {
+	os.Setenv("SITE_BASE_URL", "")
+	os.Setenv("SITE_ARTICLE_PATH", "")
+	cfg := LoadConfig().Site
+	if cfg.BaseURL != "https://pittsix.com" || cfg.ArticlePath != "/article/{id}" {
+		t.Errorf("unexpected defaults: %+v", cfg)
+	}
+	if cfg.Robots != "" {
+		t.Errorf("robots rules should default to empty, got %q", cfg.Robots)
+	}
+	os.Setenv("ROBOTS_TXT", `User-agent: *\nDisallow: /`)
+	if got := LoadConfig().Site.Robots; got != "User-agent: *\nDisallow: /" {
+		t.Errorf("expected escaped newlines to be expanded, got %q", got)
+	}
+	os.Setenv("SITE_BASE_URL", "https://noticias.test")
+	os.Setenv("SITE_ARTICLE_PATH", "/notas/{slug}")
+	cfg = LoadConfig().Site
+	if cfg.BaseURL != "https://noticias.test" || cfg.ArticlePath != "/notas/{slug}" {
+		t.Errorf("unexpected site config: %+v", cfg)
+	}
+}
+
+func TestLoadConfig_Images(t *testing.T) {
+	os.Setenv("IMAGE_QUALITY", "150")
+	os.Setenv("IMAGE_WEBP", "false")
+	defer os.Setenv("IMAGE_QUALITY", "")
+	defer os.Setenv("IMAGE_WEBP", "")
+	cfg := LoadConfig().Images
+	if cfg.Quality != 100 || cfg.WebP || cfg.Renditions != "thumbnail:320,medium:800,large:1600" || cfg.MaxBytes != 20<<20 {
+		t.Errorf("unexpected images config: %+v", cfg)
+	}
+}
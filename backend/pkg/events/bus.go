@@ -0,0 +1,179 @@
+package events
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 📣 Bus de eventos en proceso: los handlers publican lo que cambió y los
+// suscriptores (el stream SSE, los webhooks) lo reciben. Guarda los últimos
+// eventos para que un cliente que se reconecta recupere lo que se perdió.
+
+// Tipos de evento
+const (
+	ArticleCreated       = "article.created"
+	ArticleUpdated       = "article.updated"
+	ArticleStatusChanged = "article.status_changed"
+	ArticleDeleted       = "article.deleted"
+	UserCreated          = "user.created"
+	UserUpdated          = "user.updated"
+	UserDeleted          = "user.deleted"
+	OrganizationCreated  = "organization.created"
+	OrganizationUpdated  = "organization.updated"
+	OrganizationDeleted  = "organization.deleted"
+	// Reset avisa al cliente que se perdió eventos y debe recargar
+	Reset = "reset"
+)
+
+const (
+	defaultBufferSize = 1024
+	subscriberBuffer  = 64
+)
+
+// Audience limita quién ve el evento dentro de la organización. Vacía, lo ven
+// todos; si no, los usuarios listados y quien tenga alguno de los permisos.
+// org_admin y superadmin ven siempre todo.
+type Audience struct {
+	UserIDs     []string
+	Permissions []string
+}
+
+// Event es algo que pasó en una organización
+type Event struct {
+	ID             uint64      `json:"id"`
+	Type           string      `json:"type"`
+	OrganizationID string      `json:"organization_id,omitempty"`
+	ActorID        string      `json:"actor_id,omitempty"`
+	SubjectID      string      `json:"subject_id,omitempty"`
+	Data           interface{} `json:"data,omitempty"`
+	Time           time.Time   `json:"time"`
+	Audience       Audience    `json:"-"`
+}
+
+// Subscription recibe los eventos que pasan su filtro
+type Subscription struct {
+	C      <-chan Event
+	ch     chan Event
+	filter func(Event) bool
+	bus    *Bus
+	once   sync.Once
+}
+
+// Close da de baja la suscripción
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	s.close()
+}
+
+// close requiere tener el lock del bus
+func (s *Subscription) close() {
+	s.once.Do(func() {
+		delete(s.bus.subs, s)
+		close(s.ch)
+	})
+}
+
+// Bus reparte eventos y guarda los últimos en un buffer circular
+type Bus struct {
+	mu     sync.Mutex
+	nextID uint64
+	buffer []Event
+	start  int
+	subs   map[*Subscription]struct{}
+}
+
+// NewBus crea un bus que recuerda los últimos size eventos (1024 si size <= 0)
+func NewBus(size int) *Bus {
+	if size <= 0 {
+		size = defaultBufferSize
+	}
+	return &Bus{
+		nextID: 1,
+		buffer: make([]Event, 0, size),
+		subs:   map[*Subscription]struct{}{},
+	}
+}
+
+// Publish numera el evento y lo reparte. Un bus nil no hace nada, así los
+// handlers publican sin chequear si hay bus. Al suscriptor que no da abasto se
+// le cierra el canal: se reconecta y recupera lo perdido desde el buffer.
+func (b *Bus) Publish(e Event) Event {
+	if b == nil {
+		return e
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e.ID = b.nextID
+	b.nextID++
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if len(b.buffer) < cap(b.buffer) {
+		b.buffer = append(b.buffer, e)
+	} else {
+		b.buffer[b.start] = e
+		b.start = (b.start + 1) % len(b.buffer)
+	}
+	for s := range b.subs {
+		if !s.filter(e) {
+			continue
+		}
+		select {
+		case s.ch <- e:
+		default:
+			s.close()
+		}
+	}
+	return e
+}
+
+// Subscribe da de alta un suscriptor. Con lastID > 0 devuelve además los
+// eventos posteriores que siguen en el buffer; complete es false si algunos
+// ya se descartaron (o lastID es de otro proceso) y no se pueden recuperar.
+func (b *Bus) Subscribe(lastID uint64, filter func(Event) bool) (sub *Subscription, replay []Event, complete bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ch := make(chan Event, subscriberBuffer)
+	sub = &Subscription{C: ch, ch: ch, filter: filter, bus: b}
+	b.subs[sub] = struct{}{}
+
+	complete = true
+	if lastID == 0 {
+		return sub, nil, complete
+	}
+	if lastID >= b.nextID {
+		return sub, nil, false
+	}
+	n := len(b.buffer)
+	if n > 0 && b.buffer[b.start].ID > lastID+1 {
+		complete = false
+	}
+	for i := 0; i < n; i++ {
+		e := b.buffer[(b.start+i)%n]
+		if e.ID > lastID && filter(e) {
+			replay = append(replay, e)
+		}
+	}
+	return sub, replay, complete
+}
+
+// ParseID lee un Last-Event-ID; 0 si falta o es inválido
+func ParseID(s string) uint64 {
+	id, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+// Close cierra todas las suscripciones; el servidor lo llama al apagarse para
+// que los streams abiertos terminen y no demoren el Shutdown
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for s := range b.subs {
+		s.close()
+	}
+}
@@ -0,0 +1,75 @@
+package events
+
+import "testing"
+
+func all(Event) bool { return true }
+
+func TestBus_ReplayAfterLastID(t *testing.T) {
+	b := NewBus(10)
+	for i := 0; i < 3; i++ {
+		b.Publish(Event{Type: ArticleUpdated})
+	}
+	sub, replay, complete := b.Subscribe(1, all)
+	defer sub.Close()
+	if !complete || len(replay) != 2 || replay[0].ID != 2 || replay[1].ID != 3 {
+		t.Fatalf("unexpected replay: %v %+v", complete, replay)
+	}
+	b.Publish(Event{Type: ArticleDeleted})
+	if e := <-sub.C; e.ID != 4 || e.Type != ArticleDeleted {
+		t.Errorf("live event after replay: %+v", e)
+	}
+}
+
+func TestBus_ReplayIncomplete(t *testing.T) {
+	b := NewBus(2)
+	for i := 0; i < 5; i++ {
+		b.Publish(Event{Type: ArticleUpdated})
+	}
+	sub, replay, complete := b.Subscribe(1, all)
+	sub.Close()
+	if complete || len(replay) != 2 || replay[0].ID != 4 {
+		t.Errorf("events 2 and 3 were dropped: %v %+v", complete, replay)
+	}
+	if sub, _, complete := b.Subscribe(99, all); complete {
+		t.Error("an ID from the future (another process) can't be resumed")
+	} else {
+		sub.Close()
+	}
+	if sub, _, complete := b.Subscribe(5, all); !complete {
+		t.Error("up to date")
+	} else {
+		sub.Close()
+	}
+}
+
+func TestBus_FilterAndSlowSubscriber(t *testing.T) {
+	b := NewBus(0)
+	sub, _, _ := b.Subscribe(0, func(e Event) bool { return e.OrganizationID == "a" })
+	b.Publish(Event{Type: ArticleCreated, OrganizationID: "b"})
+	for i := 0; i < subscriberBuffer+1; i++ {
+		b.Publish(Event{Type: ArticleCreated, OrganizationID: "a"})
+	}
+	n := 0
+	for e := range sub.C {
+		if e.OrganizationID != "a" {
+			t.Fatalf("filtered event delivered: %+v", e)
+		}
+		n++
+	}
+	if n != subscriberBuffer {
+		t.Errorf("expected %d events before closing the slow subscriber, got %d", subscriberBuffer, n)
+	}
+	sub.Close() // cerrar dos veces no rompe
+}
+
+func TestBus_NilAndClose(t *testing.T) {
+	var nilBus *Bus
+	nilBus.Publish(Event{Type: UserCreated})
+
+	b := NewBus(0)
+	sub, _, _ := b.Subscribe(0, all)
+	b.Close()
+	if _, ok := <-sub.C; ok {
+		t.Error("Close must end every subscription")
+	}
+}
@@ -0,0 +1,158 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"pittsix/pkg/middleware"
+)
+
+const defaultHeartbeat = 25 * time.Second
+
+// Stream sirve el bus como Server-Sent Events. Va detrás de
+// StreamTickets.Auth: cada conexión recibe solo los eventos de la organización
+// del ticket (o del token) que su usuario puede ver.
+type Stream struct {
+	Bus *Bus
+	// Heartbeat es cada cuánto se manda un comentario para que los proxies no corten
+	Heartbeat time.Duration
+}
+
+func NewStream(bus *Bus) *Stream {
+	return &Stream{Bus: bus, Heartbeat: defaultHeartbeat}
+}
+
+// visibleTo arma el filtro del usuario autenticado. ?all_orgs=true (solo
+// superadmin) recibe todas las organizaciones; ?types=article,user.deleted
+// deja solo esos tipos o prefijos.
+func visibleTo(r *http.Request) (func(Event) bool, error) {
+	ctx := r.Context()
+	userID, _ := ctx.Value("user_id").(string)
+	orgID, _ := ctx.Value("organization_id").(string)
+	all := r.URL.Query().Get("all_orgs") == "true"
+	if all && !middleware.HasRole(ctx, "superadmin") {
+		return nil, errors.New("Cross-organization access requires superadmin")
+	}
+	if !all && orgID == "" {
+		return nil, errors.New("No organization")
+	}
+	admin := middleware.HasRole(ctx, "superadmin", "org_admin")
+	var types []string
+	if s := r.URL.Query().Get("types"); s != "" {
+		types = strings.Split(s, ",")
+	}
+
+	return func(e Event) bool {
+		if !all && e.OrganizationID != orgID {
+			return false
+		}
+		if len(types) > 0 && !matchesType(e.Type, types) {
+			return false
+		}
+		if admin || (len(e.Audience.UserIDs) == 0 && len(e.Audience.Permissions) == 0) {
+			return true
+		}
+		for _, id := range e.Audience.UserIDs {
+			if id == userID {
+				return true
+			}
+		}
+		for _, perm := range e.Audience.Permissions {
+			if middleware.HasPermission(ctx, perm) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+func matchesType(t string, wanted []string) bool {
+	for _, w := range wanted {
+		w = strings.TrimSpace(w)
+		if t == w || strings.HasPrefix(t, w+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// writeEvent escribe un evento en formato SSE
+func writeEvent(w http.ResponseWriter, e Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if e.ID > 0 {
+		fmt.Fprintf(w, "id: %d\n", e.ID)
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+	return err
+}
+
+// 📡 GET /events: stream de eventos. Reanuda desde Last-Event-ID (o
+// ?last_event_id=) con lo que siga en el buffer; si se perdieron eventos
+// manda primero un "reset" para que el cliente recargue.
+func (s *Stream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	filter, err := visibleTo(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("last_event_id")
+	}
+	sub, replay, complete := s.Bus.Subscribe(ParseID(lastID), filter)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 3000\n\n")
+	if !complete {
+		writeEvent(w, Event{Type: Reset, Time: time.Now()})
+	}
+	for _, e := range replay {
+		if writeEvent(w, e) != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := s.Heartbeat
+	if heartbeat <= 0 {
+		heartbeat = defaultHeartbeat
+	}
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		case e, ok := <-sub.C:
+			// Canal cerrado: el cliente no daba abasto y se reconecta con Last-Event-ID
+			if !ok {
+				return
+			}
+			if writeEvent(w, e) != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,124 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// streamAs sirve el stream con el contexto que dejaría JWTAuth
+func streamAs(s *Stream, userID, orgID string, roles, perms []string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), "user_id", userID)
+		ctx = context.WithValue(ctx, "organization_id", orgID)
+		ctx = context.WithValue(ctx, "roles", roles)
+		ctx = context.WithValue(ctx, "permissions", perms)
+		s.ServeHTTP(w, r.WithContext(ctx))
+	}))
+}
+
+// readEvents lee los primeros n eventos (líneas "event:" e "id:") del stream
+func readEvents(t *testing.T, url, lastID string, n int) []string {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if lastID != "" {
+		req.Header.Set("Last-Event-ID", lastID)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("status %d", res.StatusCode)
+	}
+	if ct := res.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("content type %q", ct)
+	}
+	var got []string
+	id := ""
+	scanner := bufio.NewScanner(res.Body)
+	for len(got) < n && scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			got = append(got, id+" "+strings.TrimPrefix(line, "event: "))
+			id = ""
+		}
+	}
+	return got
+}
+
+func TestStream_FiltersAndReplays(t *testing.T) {
+	bus := NewBus(0)
+	bus.Publish(Event{Type: ArticleCreated, OrganizationID: "a"})
+	bus.Publish(Event{Type: ArticleCreated, OrganizationID: "b"})
+	bus.Publish(Event{Type: ArticleUpdated, OrganizationID: "a", Audience: Audience{UserIDs: []string{"otro"}}})
+	bus.Publish(Event{Type: ArticleUpdated, OrganizationID: "a", Audience: Audience{Permissions: []string{"articles:review"}}})
+	bus.Publish(Event{Type: UserUpdated, OrganizationID: "a", Audience: Audience{UserIDs: []string{"u1"}}})
+
+	s := NewStream(bus)
+	member := streamAs(s, "u1", "a", nil, nil)
+	defer member.Close()
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		bus.Publish(Event{Type: ArticleDeleted, OrganizationID: "a"})
+	}()
+	if got := readEvents(t, member.URL, "", 1); strings.Join(got, ",") != "6 article.deleted" {
+		t.Errorf("without Last-Event-ID only live events arrive: %v", got)
+	}
+	if got := readEvents(t, member.URL, "1", 2); strings.Join(got, ",") != "5 user.updated,6 article.deleted" {
+		t.Errorf("replay for a plain member: %v", got)
+	}
+	if got := readEvents(t, member.URL+"?last_event_id=5", "", 1); strings.Join(got, ",") != "6 article.deleted" {
+		t.Errorf("replay from the query string: %v", got)
+	}
+
+	reviewer := streamAs(s, "u2", "a", nil, []string{"articles:review"})
+	defer reviewer.Close()
+	if got := readEvents(t, reviewer.URL, "1", 2); strings.Join(got, ",") != "4 article.updated,6 article.deleted" {
+		t.Errorf("replay for a reviewer: %v", got)
+	}
+	admin := streamAs(s, "u3", "a", []string{"org_admin"}, nil)
+	defer admin.Close()
+	if got := readEvents(t, admin.URL+"?types=article", "1", 3); strings.Join(got, ",") != "3 article.updated,4 article.updated,6 article.deleted" {
+		t.Errorf("replay for an org_admin filtered by type: %v", got)
+	}
+}
+
+func TestStream_ResetAndAccess(t *testing.T) {
+	bus := NewBus(1)
+	for i := 0; i < 3; i++ {
+		bus.Publish(Event{Type: ArticleCreated, OrganizationID: "a"})
+	}
+	s := NewStream(bus)
+	member := streamAs(s, "u1", "a", nil, nil)
+	defer member.Close()
+	if got := readEvents(t, member.URL, "1", 2); strings.Join(got, ",") != " reset,3 article.created" {
+		t.Errorf("lost events announce a reset first: %v", got)
+	}
+
+	res, err := http.Get(member.URL + "?all_orgs=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusForbidden {
+		t.Errorf("all_orgs is superadmin only, got %d", res.StatusCode)
+	}
+	orphan := streamAs(s, "u1", "", nil, nil)
+	defer orphan.Close()
+	if res, err := http.Get(orphan.URL); err != nil || res.StatusCode != http.StatusForbidden {
+		t.Errorf("users without an organization can't stream: %v", err)
+	} else {
+		res.Body.Close()
+	}
+}
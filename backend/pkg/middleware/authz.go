@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"strings"
 )
@@ -8,12 +9,9 @@ import (
 func RequireRole(role string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			roles, _ := r.Context().Value("roles").([]string)
-			for _, rle := range roles {
-				if rle == role {
-					next.ServeHTTP(w, r)
-					return
-				}
+			if HasRole(r.Context(), role) {
+				next.ServeHTTP(w, r)
+				return
 			}
 			http.Error(w, "Forbidden", http.StatusForbidden)
 		})
@@ -23,12 +21,9 @@ func RequireRole(role string) func(http.Handler) http.Handler {
 func RequireOrgAdminOrSuperadmin() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			roles, _ := r.Context().Value("roles").([]string)
-			for _, rle := range roles {
-				if rle == "superadmin" || rle == "org_admin" {
-					next.ServeHTTP(w, r)
-					return
-				}
+			if HasRole(r.Context(), "superadmin", "org_admin") {
+				next.ServeHTTP(w, r)
+				return
 			}
 			http.Error(w, "Forbidden", http.StatusForbidden)
 		})
@@ -38,14 +33,35 @@ func RequireOrgAdminOrSuperadmin() func(http.Handler) http.Handler {
 func RequirePermission(perm string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			perms, _ := r.Context().Value("permissions").([]string)
-			for _, p := range perms {
-				if p == perm || p == "*" || strings.HasPrefix(p, perm+":") {
-					next.ServeHTTP(w, r)
-					return
-				}
+			if HasPermission(r.Context(), perm) {
+				next.ServeHTTP(w, r)
+				return
 			}
 			http.Error(w, "Forbidden", http.StatusForbidden)
 		})
 	}
 }
+
+// HasRole indica si el contexto (cargado por JWTAuth) tiene alguno de los roles
+func HasRole(ctx context.Context, roles ...string) bool {
+	have, _ := ctx.Value("roles").([]string)
+	for _, rle := range have {
+		for _, role := range roles {
+			if rle == role {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasPermission aplica las mismas reglas que RequirePermission para chequeos dentro de un handler
+func HasPermission(ctx context.Context, perm string) bool {
+	perms, _ := ctx.Value("permissions").([]string)
+	for _, p := range perms {
+		if p == perm || p == "*" || strings.HasPrefix(p, perm+":") {
+			return true
+		}
+	}
+	return false
+}
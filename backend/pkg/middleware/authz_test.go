@@ -73,3 +73,26 @@ func TestRequirePermission(t *testing.T) {
 		}
 	}
 }
+
+func TestHasPermission(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "permissions", []string{"articles:publish"})
+	if !HasPermission(ctx, "articles:publish") {
+		t.Errorf("should grant exact permission")
+	}
+	if HasPermission(ctx, "articles:review") {
+		t.Errorf("should not grant unrelated permission")
+	}
+	if HasPermission(context.Background(), "articles:publish") {
+		t.Errorf("should not grant without permissions")
+	}
+}
+
+func TestHasRole(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "roles", []string{"org_admin"})
+	if !HasRole(ctx, "superadmin", "org_admin") {
+		t.Errorf("should match any of the roles")
+	}
+	if HasRole(ctx, "superadmin") {
+		t.Errorf("should not match missing role")
+	}
+}
@@ -37,25 +37,31 @@ func JWTAuth(next http.Handler) http.Handler {
 			http.Error(w, "Unauthorized", http.StatusUnauthorized)
 			return
 		}
-		orgID, _ := claims["organization_id"].(string)
-		roles, _ := claims["roles"].([]interface{})
-		permissions, _ := claims["permissions"].([]interface{})
-		// Convert []interface{} a []string
-		var rolesStr, permsStr []string
-		for _, r := range roles {
-			if s, ok := r.(string); ok {
-				rolesStr = append(rolesStr, s)
-			}
-		}
-		for _, p := range permissions {
-			if s, ok := p.(string); ok {
-				permsStr = append(permsStr, s)
-			}
-		}
-		ctx := context.WithValue(r.Context(), "user_id", userID)
-		ctx = context.WithValue(ctx, "organization_id", orgID)
-		ctx = context.WithValue(ctx, "roles", rolesStr)
-		ctx = context.WithValue(ctx, "permissions", permsStr)
+		ctx := withClaims(r.Context(), userID, claims)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// withClaims deja en el contexto el usuario, la organización, los roles y
+// los permisos del token
+func withClaims(ctx context.Context, userID string, claims jwt.MapClaims) context.Context {
+	orgID, _ := claims["organization_id"].(string)
+	roles, _ := claims["roles"].([]interface{})
+	permissions, _ := claims["permissions"].([]interface{})
+	// Convert []interface{} a []string
+	var rolesStr, permsStr []string
+	for _, r := range roles {
+		if s, ok := r.(string); ok {
+			rolesStr = append(rolesStr, s)
+		}
+	}
+	for _, p := range permissions {
+		if s, ok := p.(string); ok {
+			permsStr = append(permsStr, s)
+		}
+	}
+	ctx = context.WithValue(ctx, "user_id", userID)
+	ctx = context.WithValue(ctx, "organization_id", orgID)
+	ctx = context.WithValue(ctx, "roles", rolesStr)
+	return context.WithValue(ctx, "permissions", permsStr)
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 🎟️ EventSource no puede mandar headers, así que GET /events no puede usar
+// el Authorization de siempre. En vez de poner el token de acceso en la URL,
+// donde lo guardan los logs de acceso y de los proxies, el cliente pide un
+// ticket con su token y conecta con ?ticket=. El ticket sólo sirve para el
+// stream (audience "events") y vence en segundos; hasta entonces se puede
+// reusar, así que el reintento automático del navegador no se corta. Pasado
+// ese plazo el cliente pide otro y reconecta con ?last_event_id= (ver
+// frontend/src/api/events.ts).
+
+const ticketAudience = "events"
+
+// StreamTickets emite y valida los tickets del stream. Son tokens firmados,
+// así que cualquier réplica los acepta sin estado compartido.
+type StreamTickets struct {
+	// TTL es cuánto vale un ticket
+	TTL time.Duration
+}
+
+func NewStreamTickets(ttl time.Duration) *StreamTickets {
+	return &StreamTickets{TTL: ttl}
+}
+
+// 🎟️ POST /events/ticket: va detrás de JWTAuth y devuelve un ticket con el
+// usuario, la organización, los roles y los permisos del token
+func (t *StreamTickets) Issue(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := ctx.Value("user_id").(string)
+	orgID, _ := ctx.Value("organization_id").(string)
+	roles, _ := ctx.Value("roles").([]string)
+	permissions, _ := ctx.Value("permissions").([]string)
+	expires := time.Now().Add(t.TTL)
+	// Sin user_id: JWTAuth no lo acepta como token de acceso
+	ticket, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"aud":             ticketAudience,
+		"sub":             userID,
+		"organization_id": orgID,
+		"roles":           roles,
+		"permissions":     permissions,
+		"exp":             expires.Unix(),
+	}).SignedString([]byte("secret"))
+	if err != nil {
+		http.Error(w, "Could not issue ticket", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"ticket":     ticket,
+		"expires_at": expires.UTC(),
+	})
+}
+
+// Auth autentica el stream con ?ticket=; sin ticket cae en JWTAuth, para los
+// clientes que sí pueden mandar el header
+func (t *StreamTickets) Auth(next http.Handler) http.Handler {
+	withHeader := JWTAuth(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ticket := r.URL.Query().Get("ticket")
+		if ticket == "" {
+			withHeader.ServeHTTP(w, r)
+			return
+		}
+		claims := jwt.MapClaims{}
+		token, err := jwt.ParseWithClaims(ticket, claims, func(*jwt.Token) (interface{}, error) {
+			return []byte("secret"), nil
+		}, jwt.WithAudience(ticketAudience), jwt.WithExpirationRequired(), jwt.WithValidMethods([]string{"HS256"}))
+		if err != nil || !token.Valid {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		userID, _ := claims["sub"].(string)
+		if userID == "" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(withClaims(r.Context(), userID, claims)))
+	})
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// issueTicket pide un ticket con el token de u1 en o1
+func issueTicket(t *testing.T, tickets *StreamTickets) string {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/events/ticket", nil)
+	req.Header.Set("Authorization", "Bearer "+makeJWT(t, jwt.MapClaims{"user_id": "u1", "organization_id": "o1", "roles": []string{"org_admin"}}))
+	w := httptest.NewRecorder()
+	JWTAuth(http.HandlerFunc(tickets.Issue)).ServeHTTP(w, req)
+	var body struct {
+		Ticket string `json:"ticket"`
+	}
+	json.NewDecoder(w.Body).Decode(&body)
+	if w.Code != http.StatusOK || body.Ticket == "" {
+		t.Fatalf("issue: %d %s", w.Code, w.Body.String())
+	}
+	return body.Ticket
+}
+
+func TestStreamTickets(t *testing.T) {
+	tickets := NewStreamTickets(time.Minute)
+	var user, org string
+	var roles []string
+	stream := tickets.Auth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, _ = r.Context().Value("user_id").(string)
+		org, _ = r.Context().Value("organization_id").(string)
+		roles, _ = r.Context().Value("roles").([]string)
+		w.WriteHeader(200)
+	}))
+	connect := func(target, bearer string) int {
+		req := httptest.NewRequest("GET", target, nil)
+		if bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		w := httptest.NewRecorder()
+		stream.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	ticket := issueTicket(t, tickets)
+	if code := connect("/events?ticket="+ticket, ""); code != 200 || user != "u1" || org != "o1" || len(roles) != 1 {
+		t.Fatalf("the ticket authenticates as its user: %d %q %q %v", code, user, org, roles)
+	}
+	// El reintento automático de EventSource repite la misma URL
+	if code := connect("/events?ticket="+ticket, ""); code != 200 {
+		t.Errorf("a ticket can be reused until it expires, got %d", code)
+	}
+
+	access := makeJWT(t, jwt.MapClaims{"user_id": "u1", "organization_id": "o1"})
+	if code := connect("/events?ticket="+access, ""); code != http.StatusUnauthorized {
+		t.Errorf("an access token is not a ticket, got %d", code)
+	}
+	if code := connect("/events?access_token="+access, ""); code != http.StatusUnauthorized {
+		t.Errorf("access tokens in the query string are not accepted, got %d", code)
+	}
+	if code := connect("/events", access); code != 200 {
+		t.Errorf("the Authorization header still works, got %d", code)
+	}
+
+	// Un ticket no sirve como token de acceso en el resto de la API
+	ticket = issueTicket(t, tickets)
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+ticket)
+	w := httptest.NewRecorder()
+	JWTAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(200) })).ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("a ticket is not an access token, got %d", w.Code)
+	}
+
+	expired := NewStreamTickets(-time.Second)
+	stream = expired.Auth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(200) }))
+	if code := connect("/events?ticket="+issueTicket(t, expired), ""); code != http.StatusUnauthorized {
+		t.Errorf("expired tickets are rejected, got %d", code)
+	}
+}
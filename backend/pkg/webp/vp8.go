@@ -0,0 +1,641 @@
+package webp
+
+// Codificador VP8 con pérdida, sólo fotogramas clave (RFC 6386). Cada
+// macrobloque se predice entero, 16x16 en luma y 8x8 en croma, con el mejor de
+// los modos DC, V, H y TM; el residuo pasa por DCT 4x4 más la WHT de los DC y
+// los coeficientes se codifican con las probabilidades por defecto. No hace
+// predicción 4x4 ni segmentos, pero genera VP8 válido que lee cualquier
+// navegador.
+
+const (
+	predDC = iota
+	predTM
+	predVE
+	predHE
+)
+
+// modeOrder es el orden en que se prueban los modos de predicción
+var modeOrder = [4]uint8{predDC, predVE, predHE, predTM}
+
+// boolEncoder es el codificador aritmético booleano de la sección 7
+type boolEncoder struct {
+	buf      []byte
+	rng      uint32
+	bottom   uint32
+	bitCount int
+}
+
+func newBoolEncoder() *boolEncoder {
+	return &boolEncoder{rng: 255, bitCount: 24}
+}
+
+// putBit codifica bit con probabilidad prob/256 de que sea false
+func (e *boolEncoder) putBit(bit bool, prob uint8) {
+	split := 1 + (e.rng-1)*uint32(prob)>>8
+	if bit {
+		e.bottom += split
+		e.rng -= split
+	} else {
+		e.rng = split
+	}
+	for e.rng < 128 {
+		e.rng <<= 1
+		if e.bottom&(1<<31) != 0 {
+			e.carry()
+		}
+		e.bottom <<= 1
+		e.bitCount--
+		if e.bitCount == 0 {
+			e.buf = append(e.buf, byte(e.bottom>>24))
+			e.bottom &= 1<<24 - 1
+			e.bitCount = 8
+		}
+	}
+}
+
+// putLiteral escribe los n bits de v, el más significativo primero
+func (e *boolEncoder) putLiteral(v uint32, n int) {
+	for n > 0 {
+		n--
+		e.putBit(v>>uint(n)&1 == 1, 128)
+	}
+}
+
+// carry propaga un acarreo sobre los bytes ya emitidos
+func (e *boolEncoder) carry() {
+	for i := len(e.buf) - 1; i >= 0; i-- {
+		if e.buf[i] != 255 {
+			e.buf[i]++
+			return
+		}
+		e.buf[i] = 0
+	}
+}
+
+// flush vacía lo pendiente y devuelve la partición completa
+func (e *boolEncoder) flush() []byte {
+	c := e.bitCount
+	v := e.bottom
+	if v&(1<<uint(32-c)) != 0 {
+		e.carry()
+	}
+	v <<= uint(c & 7)
+	for c >>= 3; c > 0; c-- {
+		v <<= 8
+	}
+	for i := 0; i < 4; i++ {
+		e.buf = append(e.buf, byte(v>>24))
+		v <<= 8
+	}
+	return e.buf
+}
+
+// quant son los pasos de cuantización DC y AC de cada tipo de bloque (9.6)
+type quant struct {
+	y1, y2, uv [2]int32
+}
+
+func newQuant(qi int) quant {
+	var q quant
+	q.y1 = [2]int32{int32(dcTable[qi]), int32(acTable[qi])}
+	q.y2 = [2]int32{int32(dcTable[qi]) * 2, max(int32(acTable[qi])*155/100, 8)}
+	q.uv = [2]int32{int32(dcTable[min(qi, 117)]), int32(acTable[qi])}
+	return q
+}
+
+// nzContext dice qué bloques del borde derecho o inferior de un macrobloque
+// tuvieron coeficientes; es el contexto de los bloques vecinos
+type nzContext struct {
+	y  [4]uint8
+	u  [2]uint8
+	v  [2]uint8
+	y2 uint8
+}
+
+type macroblock struct {
+	ymode, uvmode uint8
+	skip          bool
+}
+
+// plane es un plano de 8 bits relleno hasta múltiplos del macrobloque
+type plane struct {
+	pix    []uint8
+	stride int
+}
+
+type vp8Encoder struct {
+	mbw, mbh int
+	qi       int
+	q        quant
+	// src son los planos Y, U y V de origen y rec lo que reconstruirá el
+	// decodificador, que es de donde salen las predicciones
+	src, rec [3]plane
+	mbs      []macroblock
+	tokens   *boolEncoder
+	up       []nzContext
+	left     nzContext
+}
+
+// encodeVP8 codifica los planos YUV 4:2:0 (ya rellenos) en un fotograma VP8
+func encodeVP8(src [3]plane, width, height, qi int) []byte {
+	e := &vp8Encoder{
+		mbw:    (width + 15) / 16,
+		mbh:    (height + 15) / 16,
+		qi:     qi,
+		q:      newQuant(qi),
+		src:    src,
+		tokens: newBoolEncoder(),
+	}
+	for i := range e.rec {
+		e.rec[i] = plane{pix: make([]uint8, len(src[i].pix)), stride: src[i].stride}
+	}
+	e.mbs = make([]macroblock, e.mbw*e.mbh)
+	e.up = make([]nzContext, e.mbw)
+	for mby := 0; mby < e.mbh; mby++ {
+		e.left = nzContext{}
+		for mbx := 0; mbx < e.mbw; mbx++ {
+			e.encodeMacroblock(mbx, mby)
+		}
+	}
+	first := e.header().flush()
+	tokens := e.tokens.flush()
+
+	out := make([]byte, 0, 10+len(first)+len(tokens))
+	// Etiqueta del fotograma: clave, versión 0, visible y tamaño de la primera partición
+	tag := uint32(len(first))<<5 | 1<<4
+	out = append(out, byte(tag), byte(tag>>8), byte(tag>>16))
+	out = append(out, 0x9d, 0x01, 0x2a)
+	out = append(out, byte(width), byte(width>>8), byte(height), byte(height>>8))
+	out = append(out, first...)
+	return append(out, tokens...)
+}
+
+// header escribe la primera partición: encabezado del fotograma y modos de
+// cada macrobloque
+func (e *vp8Encoder) header() *boolEncoder {
+	h := newBoolEncoder()
+	h.putBit(false, 128) // espacio de color
+	h.putBit(false, 128) // clamping
+	h.putBit(false, 128) // sin segmentos
+	h.putBit(false, 128) // filtro normal
+	h.putLiteral(uint32(min(e.qi*5/8, 63)), 6)
+	h.putLiteral(0, 3)   // nitidez
+	h.putBit(false, 128) // sin deltas del filtro
+	h.putLiteral(0, 2)   // una sola partición de coeficientes
+	h.putLiteral(uint32(e.qi), 7)
+	for i := 0; i < 5; i++ {
+		h.putBit(false, 128) // sin deltas de cuantización
+	}
+	h.putBit(false, 128) // refresh_entropy_probs
+	for i := range tokenProbUpdateProb {
+		for j := range tokenProbUpdateProb[i] {
+			for k := range tokenProbUpdateProb[i][j] {
+				for _, p := range tokenProbUpdateProb[i][j][k] {
+					h.putBit(false, p)
+				}
+			}
+		}
+	}
+
+	skipped := 0
+	for _, mb := range e.mbs {
+		if mb.skip {
+			skipped++
+		}
+	}
+	h.putBit(skipped > 0, 128)
+	skipProb := uint8(0)
+	if skipped > 0 {
+		skipProb = uint8(min(max((len(e.mbs)-skipped)*256/len(e.mbs), 1), 255))
+		h.putLiteral(uint32(skipProb), 8)
+	}
+	for _, mb := range e.mbs {
+		if skipped > 0 {
+			h.putBit(mb.skip, skipProb)
+		}
+		h.putBit(true, 145) // predicción 16x16
+		switch mb.ymode {
+		case predDC:
+			h.putBit(false, 156)
+			h.putBit(false, 163)
+		case predVE:
+			h.putBit(false, 156)
+			h.putBit(true, 163)
+		case predHE:
+			h.putBit(true, 156)
+			h.putBit(false, 128)
+		case predTM:
+			h.putBit(true, 156)
+			h.putBit(true, 128)
+		}
+		h.putBit(mb.uvmode != predDC, 142)
+		if mb.uvmode != predDC {
+			h.putBit(mb.uvmode != predVE, 114)
+			if mb.uvmode != predVE {
+				h.putBit(mb.uvmode == predTM, 183)
+			}
+		}
+	}
+	return h
+}
+
+// edges devuelve los bordes superior e izquierdo y la esquina de un bloque de
+// n x n con los mismos valores de relleno que usa el decodificador
+func (e *vp8Encoder) edges(p, n, mbx, mby int) (top, left []int32, corner int32) {
+	pl := &e.rec[p]
+	x0, y0 := mbx*n, mby*n
+	top, left = make([]int32, n), make([]int32, n)
+	for i := 0; i < n; i++ {
+		top[i], left[i] = 127, 129
+		if mby > 0 {
+			top[i] = int32(pl.pix[(y0-1)*pl.stride+x0+i])
+		}
+		if mbx > 0 {
+			left[i] = int32(pl.pix[(y0+i)*pl.stride+x0-1])
+		}
+	}
+	switch {
+	case mby == 0:
+		corner = 127
+	case mbx == 0:
+		corner = 129
+	default:
+		corner = int32(pl.pix[(y0-1)*pl.stride+x0-1])
+	}
+	return top, left, corner
+}
+
+// predict llena pred (n x n) con el modo dado; DC cambia de variante en los
+// bordes de la imagen igual que en el decodificador
+func predict(pred []int32, mode uint8, n, mbx, mby int, top, left []int32, corner int32) {
+	shift := 4
+	if n == 8 {
+		shift = 3
+	}
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			var v int32
+			switch mode {
+			case predVE:
+				v = top[x]
+			case predHE:
+				v = left[y]
+			case predTM:
+				v = min(max(left[y]+top[x]-corner, 0), 255)
+			}
+			pred[y*n+x] = v
+		}
+	}
+	if mode != predDC {
+		return
+	}
+	var sum, dc int32
+	switch {
+	case mbx == 0 && mby == 0:
+		dc = 128
+	case mbx == 0:
+		for _, v := range top {
+			sum += v
+		}
+		dc = (sum + int32(n/2)) >> shift
+	case mby == 0:
+		for _, v := range left {
+			sum += v
+		}
+		dc = (sum + int32(n/2)) >> shift
+	default:
+		for i := range top {
+			sum += top[i] + left[i]
+		}
+		dc = (sum + int32(n)) >> (shift + 1)
+	}
+	for i := range pred[:n*n] {
+		pred[i] = dc
+	}
+}
+
+// bestMode elige el modo de menor error cuadrático sobre los planos dados;
+// croma prueba U y V juntos
+func (e *vp8Encoder) bestMode(planes []int, n, mbx, mby int) uint8 {
+	best, bestErr := uint8(predDC), int64(-1)
+	pred := make([]int32, n*n)
+	for _, mode := range modeOrder {
+		var sse int64
+		for _, p := range planes {
+			top, left, corner := e.edges(p, n, mbx, mby)
+			predict(pred, mode, n, mbx, mby, top, left, corner)
+			src := &e.src[p]
+			for y := 0; y < n; y++ {
+				row := src.pix[(mby*n+y)*src.stride+mbx*n:]
+				for x := 0; x < n; x++ {
+					d := int64(int32(row[x]) - pred[y*n+x])
+					sse += d * d
+				}
+			}
+		}
+		if bestErr < 0 || sse < bestErr {
+			best, bestErr = mode, sse
+		}
+	}
+	return best
+}
+
+// residual calcula la DCT del bloque 4x4 (bx, by) de un bloque de n x n
+func (e *vp8Encoder) residual(p, n, mbx, mby, bx, by int, pred []int32) [16]int32 {
+	src := &e.src[p]
+	var diff [16]int32
+	for y := 0; y < 4; y++ {
+		row := src.pix[(mby*n+by*4+y)*src.stride+mbx*n+bx*4:]
+		for x := 0; x < 4; x++ {
+			diff[y*4+x] = int32(row[x]) - pred[(by*4+y)*n+bx*4+x]
+		}
+	}
+	return fdct(diff)
+}
+
+// reconstruct suma el residuo decodificado a la predicción y lo guarda en rec
+func (e *vp8Encoder) reconstruct(p, n, mbx, mby, bx, by int, pred []int32, coeffs [16]int32) {
+	out := idct(coeffs)
+	rec := &e.rec[p]
+	for y := 0; y < 4; y++ {
+		row := rec.pix[(mby*n+by*4+y)*rec.stride+mbx*n+bx*4:]
+		for x := 0; x < 4; x++ {
+			row[x] = uint8(min(max(pred[(by*4+y)*n+bx*4+x]+out[y*4+x], 0), 255))
+		}
+	}
+}
+
+func (e *vp8Encoder) encodeMacroblock(mbx, mby int) {
+	mb := &e.mbs[mby*e.mbw+mbx]
+	nonZero := false
+
+	// Luma: los DC de los 16 bloques van aparte por la WHT (bloque Y2)
+	mb.ymode = e.bestMode([]int{0}, 16, mbx, mby)
+	top, left, corner := e.edges(0, 16, mbx, mby)
+	predY := make([]int32, 256)
+	predict(predY, mb.ymode, 16, mbx, mby, top, left, corner)
+	var yLevels [16][16]int32
+	var dcs [16]int32
+	for b := 0; b < 16; b++ {
+		c := e.residual(0, 16, mbx, mby, b%4, b/4, predY)
+		dcs[b] = c[0]
+		for i := 1; i < 16; i++ {
+			yLevels[b][i] = quantize(c[i], e.q.y1[1], false)
+			nonZero = nonZero || yLevels[b][i] != 0
+		}
+	}
+	var y2Levels, y2 [16]int32
+	for i, c := range fwht(dcs) {
+		y2Levels[i] = quantize(c, e.q.y2[min(i, 1)], true)
+		y2[i] = y2Levels[i] * e.q.y2[min(i, 1)]
+		nonZero = nonZero || y2Levels[i] != 0
+	}
+	dcOut := iwht(y2)
+	for b := 0; b < 16; b++ {
+		var c [16]int32
+		c[0] = dcOut[b]
+		for i := 1; i < 16; i++ {
+			c[i] = yLevels[b][i] * e.q.y1[1]
+		}
+		e.reconstruct(0, 16, mbx, mby, b%4, b/4, predY, c)
+	}
+
+	// Croma: cuatro bloques 4x4 por plano
+	mb.uvmode = e.bestMode([]int{1, 2}, 8, mbx, mby)
+	var uvLevels [2][4][16]int32
+	predC := make([]int32, 64)
+	for p := 1; p <= 2; p++ {
+		top, left, corner := e.edges(p, 8, mbx, mby)
+		predict(predC, mb.uvmode, 8, mbx, mby, top, left, corner)
+		for b := 0; b < 4; b++ {
+			c := e.residual(p, 8, mbx, mby, b%2, b/2, predC)
+			var dq [16]int32
+			for i := range c {
+				uvLevels[p-1][b][i] = quantize(c[i], e.q.uv[min(i, 1)], i == 0)
+				dq[i] = uvLevels[p-1][b][i] * e.q.uv[min(i, 1)]
+				nonZero = nonZero || uvLevels[p-1][b][i] != 0
+			}
+			e.reconstruct(p, 8, mbx, mby, b%2, b/2, predC, dq)
+		}
+	}
+
+	mb.skip = !nonZero
+	up := &e.up[mbx]
+	if mb.skip {
+		*up, e.left = nzContext{}, nzContext{}
+		return
+	}
+	nz := e.putCoeffs(planeY2, up.y2+e.left.y2, &y2Levels, 0)
+	up.y2, e.left.y2 = nz, nz
+	for b := 0; b < 16; b++ {
+		x, y := b%4, b/4
+		nz := e.putCoeffs(planeY1WithY2, up.y[x]+e.left.y[y], &yLevels[b], 1)
+		up.y[x], e.left.y[y] = nz, nz
+	}
+	for p, ctx := range [2][2]*[2]uint8{{&up.u, &e.left.u}, {&up.v, &e.left.v}} {
+		for b := 0; b < 4; b++ {
+			x, y := b%2, b/2
+			nz := e.putCoeffs(planeUV, ctx[0][x]+ctx[1][y], &uvLevels[p][b], 0)
+			ctx[0][x], ctx[1][y] = nz, nz
+		}
+	}
+}
+
+// putCoeffs codifica los coeficientes cuantizados de un bloque (en orden de
+// trama) desde la posición first del zigzag y dice si alguno no era cero
+func (e *vp8Encoder) putCoeffs(plane int, ctx uint8, levels *[16]int32, first int) uint8 {
+	t, probs := e.tokens, &defaultTokenProb[plane]
+	last := -1
+	for n := first; n < 16; n++ {
+		if levels[zigzag[n]] != 0 {
+			last = n
+		}
+	}
+	p := &probs[bands[first]][ctx]
+	if last < 0 {
+		t.putBit(false, p[0]) // fin de bloque
+		return 0
+	}
+	t.putBit(true, p[0])
+	for n := first; n < 16; {
+		v := levels[zigzag[n]]
+		n++
+		if v == 0 {
+			t.putBit(false, p[1])
+			p = &probs[bands[n]][0]
+			continue
+		}
+		t.putBit(true, p[1])
+		a := abs(v)
+		if a == 1 {
+			t.putBit(false, p[2])
+			p = &probs[bands[n]][1]
+		} else {
+			t.putBit(true, p[2])
+			switch {
+			case a <= 4:
+				t.putBit(false, p[3])
+				t.putBit(a > 2, p[4])
+				if a > 2 {
+					t.putBit(a == 4, p[5])
+				}
+			case a <= 10:
+				t.putBit(true, p[3])
+				t.putBit(false, p[6])
+				t.putBit(a > 6, p[7])
+				if a <= 6 {
+					t.putBit(a == 6, 159)
+				} else {
+					t.putBit(a >= 9, 165)
+					t.putBit((a-7)&1 == 1, 145)
+				}
+			default:
+				t.putBit(true, p[3])
+				t.putBit(true, p[6])
+				cat := 3
+				switch {
+				case a < 19:
+					cat = 0
+				case a < 35:
+					cat = 1
+				case a < 67:
+					cat = 2
+				}
+				t.putBit(cat >= 2, p[8])
+				t.putBit(cat&1 == 1, p[9+(cat>>1)])
+				extra, tab := a-(3+8<<cat), &cat3456[cat]
+				bits := 0
+				for tab[bits] != 0 {
+					bits++
+				}
+				for i := 0; i < bits; i++ {
+					t.putBit(extra>>uint(bits-1-i)&1 == 1, tab[i])
+				}
+			}
+			p = &probs[bands[n]][2]
+		}
+		t.putBit(v < 0, 128)
+		if n == 16 {
+			break
+		}
+		t.putBit(n <= last, p[0])
+		if n > last {
+			break
+		}
+	}
+	return 1
+}
+
+// quantize pasa un coeficiente a nivel; los AC redondean con zona muerta
+func quantize(c, q int32, dc bool) int32 {
+	bias := q * 3 / 8
+	if dc {
+		bias = q / 2
+	}
+	// El decodificador guarda el coeficiente ya multiplicado en 16 bits
+	l := min((abs(c)+bias)/q, 2047, 32767/q)
+	if c < 0 {
+		return -l
+	}
+	return l
+}
+
+func abs(v int32) int32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// fdct es la DCT 4x4 directa; el resultado queda en la escala que espera la
+// inversa del decodificador (14.3)
+func fdct(in [16]int32) [16]int32 {
+	var tmp, out [16]int32
+	for i := 0; i < 4; i++ {
+		d := in[i*4 : i*4+4]
+		a0, a1 := d[0]+d[3], d[1]+d[2]
+		a2, a3 := d[1]-d[2], d[0]-d[3]
+		tmp[i*4+0] = (a0 + a1) * 8
+		tmp[i*4+1] = (a2*2217 + a3*5352 + 1812) >> 9
+		tmp[i*4+2] = (a0 - a1) * 8
+		tmp[i*4+3] = (a3*2217 - a2*5352 + 937) >> 9
+	}
+	for i := 0; i < 4; i++ {
+		a0, a1 := tmp[i]+tmp[12+i], tmp[4+i]+tmp[8+i]
+		a2, a3 := tmp[4+i]-tmp[8+i], tmp[i]-tmp[12+i]
+		out[i] = (a0 + a1 + 7) >> 4
+		out[4+i] = (a2*2217 + a3*5352 + 12000) >> 16
+		if a3 != 0 {
+			out[4+i]++
+		}
+		out[8+i] = (a0 - a1 + 7) >> 4
+		out[12+i] = (a3*2217 - a2*5352 + 51000) >> 16
+	}
+	return out
+}
+
+// idct es la DCT inversa tal como la hace el decodificador
+func idct(in [16]int32) [16]int32 {
+	const (
+		c1 = 85627 // 65536 * cos(pi/8) * sqrt(2)
+		c2 = 35468 // 65536 * sin(pi/8) * sqrt(2)
+	)
+	var m [4][4]int32
+	var out [16]int32
+	for i := 0; i < 4; i++ {
+		a := in[i] + in[8+i]
+		b := in[i] - in[8+i]
+		c := (in[4+i]*c2)>>16 - (in[12+i]*c1)>>16
+		d := (in[4+i]*c1)>>16 + (in[12+i]*c2)>>16
+		m[i][0], m[i][1], m[i][2], m[i][3] = a+d, b+c, b-c, a-d
+	}
+	for j := 0; j < 4; j++ {
+		dc := m[0][j] + 4
+		a := dc + m[2][j]
+		b := dc - m[2][j]
+		c := (m[1][j]*c2)>>16 - (m[3][j]*c1)>>16
+		d := (m[1][j]*c1)>>16 + (m[3][j]*c2)>>16
+		out[j*4+0], out[j*4+1], out[j*4+2], out[j*4+3] = (a+d)>>3, (b+c)>>3, (b-c)>>3, (a-d)>>3
+	}
+	return out
+}
+
+// fwht es la Walsh-Hadamard directa de los 16 DC de luma (en orden de trama)
+func fwht(in [16]int32) [16]int32 {
+	var tmp, out [16]int32
+	for i := 0; i < 4; i++ {
+		r := in[i*4 : i*4+4]
+		a0, a1 := r[0]+r[2], r[1]+r[3]
+		a2, a3 := r[1]-r[3], r[0]-r[2]
+		tmp[i*4+0] = a0 + a1
+		tmp[i*4+1] = a3 + a2
+		tmp[i*4+2] = a3 - a2
+		tmp[i*4+3] = a0 - a1
+	}
+	for i := 0; i < 4; i++ {
+		a0, a1 := tmp[i]+tmp[8+i], tmp[4+i]+tmp[12+i]
+		a2, a3 := tmp[4+i]-tmp[12+i], tmp[i]-tmp[8+i]
+		out[i] = (a0 + a1) >> 1
+		out[4+i] = (a3 + a2) >> 1
+		out[8+i] = (a3 - a2) >> 1
+		out[12+i] = (a0 - a1) >> 1
+	}
+	return out
+}
+
+// iwht es la Walsh-Hadamard inversa: devuelve el DC de cada bloque de luma
+func iwht(in [16]int32) [16]int32 {
+	var m, out [16]int32
+	for i := 0; i < 4; i++ {
+		a0, a1 := in[i]+in[12+i], in[4+i]+in[8+i]
+		a2, a3 := in[4+i]-in[8+i], in[i]-in[12+i]
+		m[i], m[8+i], m[4+i], m[12+i] = a0+a1, a0-a1, a3+a2, a3-a2
+	}
+	for i := 0; i < 4; i++ {
+		dc := m[i*4] + 3
+		a0, a1 := dc+m[i*4+3], m[i*4+1]+m[i*4+2]
+		a2, a3 := m[i*4+1]-m[i*4+2], dc-m[i*4+3]
+		out[i*4+0], out[i*4+1], out[i*4+2], out[i*4+3] = (a0+a1)>>3, (a3+a2)>>3, (a0-a1)>>3, (a3-a2)>>3
+	}
+	return out
+}
@@ -0,0 +1,149 @@
+// Package webp codifica imágenes en WebP con pérdida (VP8) sin cgo ni
+// binarios externos: la librería estándar y golang.org/x/image sólo traen el
+// decodificador, y el binario se compila estático (CGO_ENABLED=0), así que
+// libwebp queda descartada. Las imágenes con transparencia llevan además un
+// canal alfa sin comprimir (chunk ALPH). FuzzDecode comprueba que
+// golang.org/x/image/webp lea todo lo que sale de acá.
+package webp
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"io"
+)
+
+// DefaultQuality es la calidad si no se indica otra
+const DefaultQuality = 80
+
+// MaxSize es el lado máximo que admite el formato
+const MaxSize = 16383
+
+// Options son los parámetros de codificación; Quality va de 1 a 100
+type Options struct {
+	Quality int
+}
+
+// Encode escribe m en w como WebP; o puede ser nil
+func Encode(w io.Writer, m image.Image, o *Options) error {
+	b := m.Bounds()
+	width, height := b.Dx(), b.Dy()
+	if width < 1 || height < 1 || width > MaxSize || height > MaxSize {
+		return errors.New("webp: invalid image size")
+	}
+	quality := DefaultQuality
+	if o != nil && o.Quality > 0 {
+		quality = min(o.Quality, 100)
+	}
+	// Calidad 100 es el cuantizador más fino (0) y 1 el más grueso (127)
+	qi := (100 - quality) * 127 / 99
+
+	nrgba, ok := m.(*image.NRGBA)
+	if !ok || b.Min != (image.Point{}) {
+		nrgba = image.NewNRGBA(image.Rect(0, 0, width, height))
+		draw.Draw(nrgba, nrgba.Bounds(), m, b.Min, draw.Src)
+	}
+	planes, alpha := toYUV(nrgba)
+	frame := encodeVP8(planes, width, height, qi)
+
+	var chunks [][]byte
+	if alpha != nil {
+		// VP8X con la marca de alfa y el lienzo, luego ALPH sin comprimir
+		vp8x := make([]byte, 10)
+		vp8x[0] = 0x10
+		putUint24(vp8x[4:], width-1)
+		putUint24(vp8x[7:], height-1)
+		chunks = append(chunks, chunk("VP8X", vp8x), chunk("ALPH", append([]byte{0}, alpha...)))
+	}
+	chunks = append(chunks, chunk("VP8 ", frame))
+
+	size := 4
+	for _, c := range chunks {
+		size += len(c)
+	}
+	header := make([]byte, 12)
+	copy(header, "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(size))
+	copy(header[8:], "WEBP")
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	for _, c := range chunks {
+		if _, err := w.Write(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chunk arma un chunk RIFF con su relleno a tamaño par
+func chunk(fourCC string, data []byte) []byte {
+	out := make([]byte, 8, 8+len(data)+1)
+	copy(out, fourCC)
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(data)))
+	out = append(out, data...)
+	if len(data)%2 == 1 {
+		out = append(out, 0)
+	}
+	return out
+}
+
+func putUint24(b []byte, v int) {
+	b[0], b[1], b[2] = byte(v), byte(v>>8), byte(v>>16)
+}
+
+// toYUV convierte a YUV 4:2:0 de rango limitado (BT.601, como libwebp) con los
+// planos rellenos hasta múltiplos de 16 repitiendo el borde. Devuelve el alfa
+// sólo si hay algún píxel no opaco.
+func toYUV(m *image.NRGBA) ([3]plane, []byte) {
+	w, h := m.Rect.Dx(), m.Rect.Dy()
+	pw, ph := (w+15)&^15, (h+15)&^15
+	y := plane{pix: make([]uint8, pw*ph), stride: pw}
+	u := plane{pix: make([]uint8, pw*ph/4), stride: pw / 2}
+	v := plane{pix: make([]uint8, pw*ph/4), stride: pw / 2}
+	rgb := func(px, py int) (int32, int32, int32) {
+		i := min(py, h-1)*m.Stride + min(px, w-1)*4
+		return int32(m.Pix[i]), int32(m.Pix[i+1]), int32(m.Pix[i+2])
+	}
+	for py := 0; py < ph; py++ {
+		for px := 0; px < pw; px++ {
+			r, g, b := rgb(px, py)
+			y.pix[py*pw+px] = uint8((16839*r + 33059*g + 6420*b + 16<<16 + 1<<15) >> 16)
+		}
+	}
+	for py := 0; py < ph/2; py++ {
+		for px := 0; px < pw/2; px++ {
+			var r, g, b int32
+			for _, d := range [4][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+				pr, pg, pb := rgb(2*px+d[0], 2*py+d[1])
+				r, g, b = r+pr, g+pg, b+pb
+			}
+			u.pix[py*u.stride+px] = clipUV(-9719*r - 19081*g + 28800*b)
+			v.pix[py*v.stride+px] = clipUV(28800*r - 24116*g - 4684*b)
+		}
+	}
+
+	var alpha []byte
+	for py := 0; py < h && alpha == nil; py++ {
+		for px := 0; px < w; px++ {
+			if m.Pix[py*m.Stride+px*4+3] != 0xff {
+				alpha = make([]byte, 0, w*h)
+				break
+			}
+		}
+	}
+	if alpha != nil {
+		for py := 0; py < h; py++ {
+			for px := 0; px < w; px++ {
+				alpha = append(alpha, m.Pix[py*m.Stride+px*4+3])
+			}
+		}
+	}
+	return [3]plane{y, u, v}, alpha
+}
+
+// clipUV escala la suma de cuatro píxeles a un valor de croma
+func clipUV(c int32) uint8 {
+	return uint8(min(max((c+1<<17+128<<18)>>18, 0), 255))
+}
@@ -0,0 +1,170 @@
+package webp
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"testing"
+
+	xwebp "golang.org/x/image/webp"
+)
+
+// sample es una imagen con degradados, bordes duros y ruido
+func sample(w, h int, alpha bool) *image.NRGBA {
+	m := image.NewNRGBA(image.Rect(0, 0, w, h))
+	seed := uint32(7)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			seed = seed*1664525 + 1013904223
+			c := color.NRGBA{uint8(x * 255 / w), uint8(y * 255 / h), uint8(seed >> 28), 255}
+			if (x/9+y/7)%3 == 0 {
+				c.R, c.G = 240, 30
+			}
+			if alpha {
+				c.A = uint8(x * 255 / w)
+			}
+			m.SetNRGBA(x, y, c)
+		}
+	}
+	return m
+}
+
+func psnr(a, b []uint8, w, h, strideA, strideB int) float64 {
+	var sse float64
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			d := float64(a[y*strideA+x]) - float64(b[y*strideB+x])
+			sse += d * d
+		}
+	}
+	if sse == 0 {
+		return math.Inf(1)
+	}
+	return 10 * math.Log10(255*255*float64(w*h)/sse)
+}
+
+func TestEncode_RoundTrip(t *testing.T) {
+	for _, size := range [][2]int{{64, 48}, {37, 21}, {1, 1}, {300, 17}} {
+		w, h := size[0], size[1]
+		src := sample(w, h, false)
+		var buf bytes.Buffer
+		if err := Encode(&buf, src, &Options{Quality: 90}); err != nil {
+			t.Fatalf("%dx%d: %v", w, h, err)
+		}
+		m, err := xwebp.Decode(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatalf("%dx%d: the decoder rejected the output: %v", w, h, err)
+		}
+		got, ok := m.(*image.YCbCr)
+		if !ok || got.Bounds() != image.Rect(0, 0, w, h) {
+			t.Fatalf("%dx%d: unexpected image %T %v", w, h, m, m.Bounds())
+		}
+		planes, _ := toYUV(src)
+		if p := psnr(planes[0].pix, got.Y, w, h, planes[0].stride, got.YStride); p < 32 {
+			t.Errorf("%dx%d: luma psnr too low: %.1f dB", w, h, p)
+		}
+		cw, ch := (w+1)/2, (h+1)/2
+		if p := psnr(planes[1].pix, got.Cb, cw, ch, planes[1].stride, got.CStride); p < 32 {
+			t.Errorf("%dx%d: chroma psnr too low: %.1f dB", w, h, p)
+		}
+	}
+}
+
+func TestEncode_QualityAndSize(t *testing.T) {
+	src := sample(256, 256, false)
+	size := func(q int) int {
+		var buf bytes.Buffer
+		if err := Encode(&buf, src, &Options{Quality: q}); err != nil {
+			t.Fatal(err)
+		}
+		n := buf.Len()
+		if _, err := xwebp.Decode(&buf); err != nil {
+			t.Fatalf("quality %d: %v", q, err)
+		}
+		return n
+	}
+	low, high := size(30), size(95)
+	if low >= high || high >= 256*256*3 {
+		t.Errorf("lower quality must be smaller than higher quality and raw pixels: %d / %d", low, high)
+	}
+	flat := image.NewNRGBA(image.Rect(0, 0, 512, 512))
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.NRGBA{200, 120, 40, 255}), image.Point{}, draw.Src)
+	var buf bytes.Buffer
+	Encode(&buf, flat, nil)
+	if buf.Len() > 2000 {
+		t.Errorf("a flat image should be tiny, got %d bytes", buf.Len())
+	}
+}
+
+func TestEncode_Alpha(t *testing.T) {
+	src := sample(40, 30, true)
+	var buf bytes.Buffer
+	if err := Encode(&buf, src, nil); err != nil {
+		t.Fatal(err)
+	}
+	m, err := xwebp.Decode(&buf)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	got, ok := m.(*image.NYCbCrA)
+	if !ok {
+		t.Fatalf("expected an image with alpha, got %T", m)
+	}
+	for y := 0; y < 30; y++ {
+		for x := 0; x < 40; x++ {
+			if got.A[y*got.AStride+x] != src.Pix[y*src.Stride+x*4+3] {
+				t.Fatalf("alpha differs at %d,%d", x, y)
+			}
+		}
+	}
+}
+
+func TestEncode_InvalidSize(t *testing.T) {
+	if err := Encode(&bytes.Buffer{}, image.NewNRGBA(image.Rect(0, 0, 0, 5)), nil); err == nil {
+		t.Error("expected an error for an empty image")
+	}
+}
+
+// FuzzDecode arma imágenes con los bytes de entrada y exige que x/image/webp
+// decodifique lo que sale de Encode, con el tamaño y el alfa exactos
+func FuzzDecode(f *testing.F) {
+	f.Add(uint8(1), uint8(1), uint8(80), false, []byte{0, 0, 0})
+	f.Add(uint8(17), uint8(5), uint8(1), true, []byte("degradado con transparencia"))
+	f.Add(uint8(64), uint8(48), uint8(100), false, bytes.Repeat([]byte{255, 0}, 500))
+	f.Fuzz(func(t *testing.T, w, h, quality uint8, alpha bool, pix []byte) {
+		if w == 0 || h == 0 || len(pix) == 0 {
+			return
+		}
+		src := image.NewNRGBA(image.Rect(0, 0, int(w), int(h)))
+		for i := range src.Pix {
+			src.Pix[i] = pix[i%len(pix)]
+			if !alpha && i%4 == 3 {
+				src.Pix[i] = 255
+			}
+		}
+		var buf bytes.Buffer
+		if err := Encode(&buf, src, &Options{Quality: int(quality)}); err != nil {
+			t.Fatalf("%dx%d q%d: %v", w, h, quality, err)
+		}
+		m, err := xwebp.Decode(&buf)
+		if err != nil {
+			t.Fatalf("%dx%d q%d: the decoder rejected the output: %v", w, h, quality, err)
+		}
+		if m.Bounds() != src.Bounds() {
+			t.Fatalf("bounds %v, want %v", m.Bounds(), src.Bounds())
+		}
+		if got, ok := m.(*image.NYCbCrA); ok {
+			for y := 0; y < int(h); y++ {
+				for x := 0; x < int(w); x++ {
+					if got.A[y*got.AStride+x] != src.Pix[y*src.Stride+x*4+3] {
+						t.Fatalf("alpha differs at %d,%d", x, y)
+					}
+				}
+			}
+		} else if alpha && !src.Opaque() {
+			t.Fatalf("expected an image with alpha, got %T", m)
+		}
+	})
+}
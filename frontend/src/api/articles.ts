@@ -0,0 +1,18 @@
+import API from "./axios";
+
+// 📚 Listados paginados de artículos. El backend devuelve páginas de a lo sumo
+// 100 con next_cursor; para mostrar la lista completa se piden todas hasta que
+// el cursor deje de venir.
+
+const PAGE_SIZE = 100;
+
+export async function fetchAllArticles(path: string, params: Record<string, string> = {}): Promise<any[]> {
+  const items: any[] = [];
+  let cursor = "";
+  do {
+    const res = await API.get(path, { params: { ...params, limit: PAGE_SIZE, ...(cursor ? { cursor } : {}) } });
+    items.push(...res.data.items);
+    cursor = res.data.next_cursor || "";
+  } while (cursor);
+  return items;
+}
@@ -0,0 +1,71 @@
+import API from "./axios";
+
+// 📣 Cliente del stream de eventos (GET /events).
+// EventSource no manda headers: cada conexión pide un ticket con el token
+// (POST /events/ticket) y conecta con ?ticket=. El ticket vence en segundos,
+// así que el reintento automático del navegador no alcanza: ante un error se
+// cierra la conexión y se abre otra con un ticket nuevo y ?last_event_id=, para
+// que el servidor reenvíe lo que se perdió en el medio.
+
+export type ServerEvent = {
+  id: string;
+  type: string;
+  data: unknown;
+};
+
+type Options = {
+  // Tipos de evento a escuchar (p. ej. "article.published")
+  types: string[];
+  onEvent: (event: ServerEvent) => void;
+  // Espera antes de reconectar, en milisegundos
+  retryMs?: number;
+};
+
+export function subscribeEvents({ types, onEvent, retryMs = 3000 }: Options): () => void {
+  const baseURL = API.defaults.baseURL || "";
+  let source: EventSource | null = null;
+  let lastEventId = "";
+  let timer: ReturnType<typeof setTimeout> | undefined;
+  let closed = false;
+
+  const handle = (e: MessageEvent) => {
+    if (e.lastEventId) lastEventId = e.lastEventId;
+    let data: unknown = e.data;
+    try {
+      data = JSON.parse(e.data);
+    } catch {
+      // Se entrega tal cual
+    }
+    onEvent({ id: e.lastEventId, type: e.type, data });
+  };
+
+  const retry = () => {
+    source?.close();
+    source = null;
+    if (!closed) timer = setTimeout(connect, retryMs);
+  };
+
+  const connect = async () => {
+    try {
+      const res = await API.post("/events/ticket");
+      if (closed) return;
+      const params = new URLSearchParams({ ticket: res.data.ticket });
+      if (lastEventId) params.set("last_event_id", lastEventId);
+      source = new EventSource(`${baseURL}/events?${params}`);
+      // "reset" avisa que se perdieron eventos: quien escucha recarga lo que muestra
+      for (const type of [...types, "reset"]) {
+        source.addEventListener(type, handle as EventListener);
+      }
+      source.onerror = retry;
+    } catch {
+      retry();
+    }
+  };
+
+  connect();
+  return () => {
+    closed = true;
+    clearTimeout(timer);
+    source?.close();
+  };
+}
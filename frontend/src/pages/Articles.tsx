@@ -15,7 +15,7 @@ export default function Articles() {
   const [articles, setArticles] = useState<Article[]>([]);
 
   useEffect(() => {
-    API.get("/articles").then((res) => setArticles(res.data));
+    API.get("/articles").then((res) => setArticles(res.data.items));
   }, []);
 
   return (
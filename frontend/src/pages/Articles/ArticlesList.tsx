@@ -2,6 +2,7 @@ import React, { useEffect, useState } from 'react';
 import { Container, Typography, Box, Button, Paper, Dialog, DialogTitle, DialogContent, DialogActions, TextField, Snackbar, Alert, CircularProgress, IconButton, Tooltip, DialogContentText, MenuItem } from '@mui/material';
 import { DataGrid, GridColDef, GridValueGetter } from '@mui/x-data-grid';
 import API from '../../api/axios';
+import { fetchAllArticles } from '../../api/articles';
 import EditIcon from '@mui/icons-material/Edit';
 import DeleteIcon from '@mui/icons-material/Delete';
 import { useAuth } from '../../auth/AuthContext';
@@ -39,9 +40,10 @@ export default function ArticlesList() {
 
   const fetchArticles = () => {
     setLoading(true);
-    API.get('/articles')
-      .then(res => {
-        setRows(res.data.map((a: any, idx: number) => ({
+    // Todos los artículos de la organización, en cualquier estado (requiere permisos de revisión)
+    fetchAllArticles('/my-articles', { scope: 'organization' })
+      .then(items => {
+        setRows(items.map((a: any, idx: number) => ({
           id: a.id || a._id || idx + 1,
           _id: a.id || a._id || idx + 1,
           title: a.title,
@@ -1,5 +1,6 @@
 import { useEffect, useState } from "react";
 import API from "../api/axios";
+import { fetchAllArticles } from "../api/articles";
 import { useNavigate } from "react-router-dom";
 import { format } from "date-fns";
 import ReactMarkdown from "react-markdown";
@@ -19,8 +20,8 @@ export default function ArticlesSection() {
   const { theme } = useTheme();
 
   const fetchArticles = async () => {
-    const res = await API.get("/my-articles");
-    const normalized = res.data.map((a: any) => ({
+    const items = await fetchAllArticles("/my-articles");
+    const normalized = items.map((a: any) => ({
       ...a,
       id: a._id?.$oid || a._id || a.id,
     }));